@@ -0,0 +1,192 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"go.undo.io/bindings/undolr/ctlclient"
+)
+
+// Instance is the last known state of an instance in the fleet.
+type Instance struct {
+	URL     string            `json:"url"`
+	Status  *ctlclient.Status `json:"status,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Checked time.Time         `json:"checked"`
+}
+
+// Recording is a recording saved by an instance.
+type Recording struct {
+	Instance string    `json:"instance"`
+	Saved    time.Time `json:"saved"`
+	ctlclient.SaveResult
+}
+
+// Fleet coordinates recording across a set of instances.
+type Fleet struct {
+	clients map[string]*ctlclient.Client
+
+	// sample is the fraction of instances to record.
+	sample float64
+	rand   *rand.Rand
+
+	mu         sync.Mutex
+	instances  map[string]*Instance
+	recordings []Recording
+}
+
+// NewFleet returns a Fleet for the instances whose handlers are mounted at urls.
+func NewFleet(urls []string, sample float64, seed int64) *Fleet {
+	f := &Fleet{
+		clients:   make(map[string]*ctlclient.Client),
+		sample:    sample,
+		rand:      rand.New(rand.NewSource(seed)),
+		instances: make(map[string]*Instance),
+	}
+	for _, u := range urls {
+		f.clients[u] = ctlclient.New(u)
+		f.instances[u] = &Instance{URL: u}
+	}
+	return f
+}
+
+// each calls fn for every instance concurrently, waiting for all to return.
+func (f *Fleet) each(fn func(url string, c *ctlclient.Client)) {
+	var wg sync.WaitGroup
+	for u, c := range f.clients {
+		wg.Add(1)
+		go func(u string, c *ctlclient.Client) {
+			defer wg.Done()
+			fn(u, c)
+		}(u, c)
+	}
+	wg.Wait()
+}
+
+// Refresh updates the status of every instance.
+func (f *Fleet) Refresh(ctx context.Context) {
+	f.each(func(u string, c *ctlclient.Client) {
+		status, err := c.Status(ctx)
+		instance := &Instance{URL: u, Status: status, Checked: time.Now()}
+		if err != nil {
+			instance.Error = err.Error()
+		}
+		f.mu.Lock()
+		f.instances[u] = instance
+		f.mu.Unlock()
+	})
+}
+
+// Instances returns the last known state of every instance, sorted by URL.
+func (f *Fleet) Instances() []Instance {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	instances := make([]Instance, 0, len(f.instances))
+	for _, instance := range f.instances {
+		instances = append(instances, *instance)
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].URL < instances[j].URL })
+	return instances
+}
+
+func recording(instance Instance) bool {
+	return instance.Status != nil && instance.Status.Mode == "recording"
+}
+
+// Sample starts recording on instances until the sampled fraction of the
+// reachable instances are recording. Instances already recording are left
+// alone, so repeated sampling converges without churn.
+func (f *Fleet) Sample(ctx context.Context) {
+	f.Refresh(ctx)
+
+	var idle []string
+	reachable, active := 0, 0
+	for _, instance := range f.Instances() {
+		switch {
+		case instance.Status == nil:
+		case recording(instance):
+			reachable++
+			active++
+		default:
+			reachable++
+			if instance.Status.Mode == "inactive" {
+				idle = append(idle, instance.URL)
+			}
+		}
+	}
+
+	want := int(f.sample*float64(reachable) + 0.5)
+	f.mu.Lock()
+	f.rand.Shuffle(len(idle), func(i, j int) { idle[i], idle[j] = idle[j], idle[i] })
+	f.mu.Unlock()
+
+	var start []string
+	for _, u := range idle {
+		if active+len(start) >= want {
+			break
+		}
+		start = append(start, u)
+	}
+
+	var wg sync.WaitGroup
+	for _, u := range start {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			f.clients[u].Start(ctx)
+		}(u)
+	}
+	wg.Wait()
+	f.Refresh(ctx)
+}
+
+// Snapshot saves a recording on every instance which is recording,
+// returning the recordings saved.
+func (f *Fleet) Snapshot(ctx context.Context, file string) []Recording {
+	var saved []Recording
+	var mu sync.Mutex
+
+	instances := make(map[string]bool)
+	for _, instance := range f.Instances() {
+		instances[instance.URL] = recording(instance)
+	}
+
+	f.each(func(u string, c *ctlclient.Client) {
+		if !instances[u] {
+			return
+		}
+		result, err := c.Snapshot(ctx, file, nil)
+		if err != nil {
+			f.mu.Lock()
+			f.instances[u].Error = err.Error()
+			f.mu.Unlock()
+			return
+		}
+		mu.Lock()
+		saved = append(saved, Recording{Instance: u, Saved: time.Now(), SaveResult: *result})
+		mu.Unlock()
+	})
+
+	sort.Slice(saved, func(i, j int) bool { return saved[i].Instance < saved[j].Instance })
+	f.mu.Lock()
+	f.recordings = append(f.recordings, saved...)
+	f.mu.Unlock()
+	return saved
+}
+
+// Recordings returns every recording saved through the fleet.
+func (f *Fleet) Recordings() []Recording {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Recording(nil), f.recordings...)
+}
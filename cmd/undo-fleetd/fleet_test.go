@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// fakeInstance serves enough of the undohttp endpoints to test the fleet.
+type fakeInstance struct {
+	mu        sync.Mutex
+	recording bool
+	saves     int
+}
+
+func (i *fakeInstance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/undo/status":
+		mode := "inactive"
+		if i.recording {
+			mode = "recording"
+		}
+		fmt.Fprintf(w, `{"mode":%q,"dir":"/rec"}`, mode)
+	case "/undo/start":
+		i.recording = true
+		w.WriteHeader(http.StatusNoContent)
+	case "/undo/save":
+		i.saves++
+		fmt.Fprintf(w, `{"file":"/rec/%s","bytes":%d}`, r.FormValue("file"), i.saves)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeFleet(n int, sample float64) (*Fleet, []*fakeInstance, func()) {
+	var urls []string
+	var instances []*fakeInstance
+	var servers []*httptest.Server
+	for i := 0; i < n; i++ {
+		instance := &fakeInstance{}
+		server := httptest.NewServer(instance)
+		instances = append(instances, instance)
+		servers = append(servers, server)
+		urls = append(urls, server.URL+"/undo/")
+	}
+	return NewFleet(urls, sample, 1), instances, func() {
+		for _, server := range servers {
+			server.Close()
+		}
+	}
+}
+
+func countRecording(instances []*fakeInstance) int {
+	n := 0
+	for _, instance := range instances {
+		instance.mu.Lock()
+		if instance.recording {
+			n++
+		}
+		instance.mu.Unlock()
+	}
+	return n
+}
+
+func TestFleetSample(t *testing.T) {
+	fleet, instances, cleanup := newFakeFleet(10, 0.3)
+	defer cleanup()
+
+	fleet.Sample(context.Background())
+	if n := countRecording(instances); n != 3 {
+		t.Fatalf("%d instances recording, expected 3", n)
+	}
+
+	fleet.Sample(context.Background())
+	if n := countRecording(instances); n != 3 {
+		t.Fatalf("%d instances recording after resampling, expected 3", n)
+	}
+
+	recording := 0
+	for _, instance := range fleet.Instances() {
+		if instance.Status == nil {
+			t.Fatalf("Instance not refreshed: %+v", instance)
+		}
+		if instance.Status.Mode == "recording" {
+			recording++
+		}
+	}
+	if recording != 3 {
+		t.Fatalf("%d instances reported recording, expected 3", recording)
+	}
+}
+
+func TestFleetSnapshot(t *testing.T) {
+	fleet, _, cleanup := newFakeFleet(4, 0.5)
+	defer cleanup()
+
+	fleet.Sample(context.Background())
+	saved := fleet.Snapshot(context.Background(), "incident.undo")
+	if len(saved) != 2 {
+		t.Fatalf("Unexpected recordings: %+v", saved)
+	}
+	for _, recording := range saved {
+		if recording.File != "/rec/incident.undo" {
+			t.Fatalf("Unexpected recording: %+v", recording)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	NewAPI(fleet).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recordings", nil))
+	var recordings []Recording
+	err := json.Unmarshal(w.Body.Bytes(), &recordings)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if len(recordings) != 2 {
+		t.Fatalf("Unexpected recordings from API: %s", w.Body)
+	}
+}
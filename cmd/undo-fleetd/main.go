@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Command undo-fleetd is a reference controller for recording across a fleet of instances.
+//
+// Each instance serves the control endpoints of package undohttp. The
+// controller keeps a sampled fraction of them recording, saves snapshots
+// across the fleet on request and keeps an index of the recordings saved.
+// Instances are given as a static list, on the command line or in a file
+// with one URL per line:
+//
+//	undo-fleetd -listen :9090 -sample 0.1 -targets http://app-1:8080/debug/undo/,http://app-2:8080/debug/undo/
+//
+// The controller serves its own API:
+//
+//	GET  /instances        the last known status of each instance
+//	POST /snapshot?file=   save a recording on every recording instance
+//	GET  /recordings       the recordings saved through the controller
+//
+// Discovery from service registries or Kubernetes labels is left to
+// deployments: generate the targets file from them and restart, or use
+// this command as a starting point.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	listen := flag.String("listen", ":9090", "`address` to serve the controller API on")
+	targets := flag.String("targets", "", "comma separated instance `URLs`")
+	targetsFile := flag.String("targets-file", "", "`file` of instance URLs, one per line")
+	sample := flag.Float64("sample", 0.1, "`fraction` of instances to keep recording")
+	interval := flag.Duration("interval", time.Minute, "`interval` between sampling passes")
+	flag.Parse()
+
+	urls, err := readTargets(*targets, *targetsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(urls) == 0 || *sample < 0 || *sample > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fleet := NewFleet(urls, *sample, time.Now().UnixNano())
+	go func() {
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), *interval)
+			fleet.Sample(ctx)
+			cancel()
+			time.Sleep(*interval)
+		}
+	}()
+
+	log.Fatal(http.ListenAndServe(*listen, NewAPI(fleet)))
+}
+
+func readTargets(list, file string) ([]string, error) {
+	var urls []string
+	for _, u := range strings.Split(list, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if file == "" {
+		return urls, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		u := strings.TrimSpace(scanner.Text())
+		if u != "" && !strings.HasPrefix(u, "#") {
+			urls = append(urls, u)
+		}
+	}
+	return urls, scanner.Err()
+}
+
+// NewAPI returns the controller's HTTP API for fleet.
+func NewAPI(fleet *Fleet) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/instances", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, fleet.Instances())
+	})
+	mux.HandleFunc("/snapshot", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, fleet.Snapshot(r.Context(), r.FormValue("file")))
+	})
+	mux.HandleFunc("/recordings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, fleet.Recordings())
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
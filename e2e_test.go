@@ -0,0 +1,262 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undodb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.undo.io/bindings/undoex"
+	"go.undo.io/bindings/undolr"
+)
+
+// recordingHeader is the start of every saved recording file.
+var recordingHeader = []byte("HD\x10\x00\x00\x00UndoDB recording")
+
+// workload runs a few goroutines doing some work worth recording.
+func workload() int {
+	var wg sync.WaitGroup
+	results := make([]int, 4)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10000; j++ {
+				results[i] += j * i
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, result := range results {
+		total += result
+	}
+	return total
+}
+
+// TestEndToEnd records a workload, annotates it, saves it and checks the
+// result, exercising the packages together.
+//
+// The annotations are read back from the annotation index, and the
+// recording is split and its manifest checked. Reading annotations back
+// from the recording itself requires UndoDB, so the UndoDB subtest is
+// skipped unless udb is found on the path.
+func TestEndToEnd(t *testing.T) {
+	tempfile, err := ioutil.TempFile("", "undodb_test_")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	filename := tempfile.Name()
+	tempfile.Close()
+	defer os.Remove(filename)
+
+	err = undolr.Start()
+	if errors.Is(err, undolr.ErrNotSupportedByLibrary) {
+		t.Skip("Live Recorder library not available:", err)
+	}
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	var annotations bytes.Buffer
+	undoex.SetIndex(&annotations)
+	defer undoex.SetIndex(nil)
+	undolr.SaveChecksums(true)
+	defer undolr.SaveChecksums(false)
+
+	suite, err := undoex.AnnotationSuiteNew("e2e")
+	if err != nil {
+		t.Fatal("AnnotationSuiteNew:", err)
+	}
+	err = suite.Start()
+	if err != nil {
+		t.Fatal("Suite Start:", err)
+	}
+
+	test, err := suite.NewTest("workload", false)
+	if err != nil {
+		t.Fatal("NewTest:", err)
+	}
+	defer test.Free()
+
+	err = test.Start()
+	if err != nil {
+		t.Fatal("Test Start:", err)
+	}
+	total := workload()
+	err = test.AddInt("total", int64(total))
+	if err != nil {
+		t.Fatal("AddInt:", err)
+	}
+	err = test.SetResult(undoex.Success)
+	if err != nil {
+		t.Fatal("SetResult:", err)
+	}
+	err = test.End()
+	if err != nil {
+		t.Fatal("Test End:", err)
+	}
+
+	err = suite.End()
+	if err != nil {
+		t.Fatal("Suite End:", err)
+	}
+	if result := suite.Result(); result.Tests != 1 || result.Success != 1 {
+		t.Fatalf("Unexpected suite result: %+v", result)
+	}
+
+	err = undoex.AnnotationAddText("e2e-summary", "", undoex.JSON, `{"ok": true}`)
+	if err != nil {
+		t.Fatal("AnnotationAddText:", err)
+	}
+
+	context, err := undolr.Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	ch, err := context.SaveNotify(filename)
+	if err != nil {
+		t.Fatal("SaveNotify:", err)
+	}
+
+	var res undolr.SaveResult
+	select {
+	case res = <-ch:
+	case <-time.After(time.Second * 30):
+		t.Fatal("Save hadn't completed after 30 seconds")
+	}
+	if res.Err != nil {
+		t.Fatal("Save:", res.Err)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal("ReadFile:", err)
+	}
+	if !bytes.HasPrefix(data, recordingHeader) {
+		n := len(recordingHeader)
+		if len(data) < n {
+			n = len(data)
+		}
+		t.Fatalf("Header not as expected: %q", data[:n])
+	}
+	if res.Stats.BytesWritten != int64(len(data)) {
+		t.Fatalf("BytesWritten doesn't match (%d vs %d)", res.Stats.BytesWritten, len(data))
+	}
+
+	verifyIndex(t, &annotations, total)
+	verifyManifest(t, filename, res.Stats)
+	t.Run("UndoDB", func(t *testing.T) {
+		verifyUndoDB(t, filename)
+	})
+}
+
+// e2eAnnotations are the annotations TestEndToEnd expects to find, by
+// name and detail.
+var e2eAnnotations = [][2]string{
+	{"e2e", undoex.SuiteStartDetail},
+	{"e2e/workload", "total"},
+	{"e2e", undoex.SuiteResultDetail},
+	{"e2e-summary", ""},
+}
+
+// verifyIndex checks the annotation index holds the annotations added, in
+// order.
+func verifyIndex(t *testing.T, index *bytes.Buffer, total int) {
+	var entries []undoex.IndexEntry
+	scanner := bufio.NewScanner(index)
+	for scanner.Scan() {
+		var entry undoex.IndexEntry
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			t.Fatalf("Index entry %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	next := 0
+	for _, entry := range entries {
+		if next < len(e2eAnnotations) &&
+			entry.Name == e2eAnnotations[next][0] && entry.Detail == e2eAnnotations[next][1] {
+			next++
+		}
+		if entry.Name == "e2e/workload" && entry.Detail == "total" && entry.Value != int64(total) {
+			t.Fatalf("Unexpected total annotated: %d (expected %d)", entry.Value, total)
+		}
+		if entry.Name == "e2e" && entry.Detail == undoex.SuiteResultDetail {
+			var result undoex.AnnotationSuiteResult
+			err := json.Unmarshal([]byte(entry.Text), &result)
+			if err != nil || result.Tests != 1 || result.Success != 1 {
+				t.Fatalf("Unexpected suite result annotated: %q (%v)", entry.Text, err)
+			}
+		}
+	}
+	if next != len(e2eAnnotations) {
+		t.Fatalf("Annotation %q not indexed in order: %+v", e2eAnnotations[next], entries)
+	}
+}
+
+// verifyManifest splits the recording, checks the manifest describes it,
+// and joins it again.
+func verifyManifest(t *testing.T, filename string, stats undolr.SaveStats) {
+	manifestFile, err := undolr.SplitRecording(filename, stats.BytesWritten/3+1)
+	if err != nil {
+		t.Fatal("SplitRecording:", err)
+	}
+	manifest, err := undolr.ReadSplitManifest(manifestFile)
+	if err != nil {
+		t.Fatal("ReadSplitManifest:", err)
+	}
+	defer os.Remove(manifestFile)
+	for _, part := range manifest.Parts {
+		defer os.Remove(filepath.Join(filepath.Dir(manifestFile), part.File))
+	}
+
+	if manifest.Size != stats.BytesWritten || manifest.SHA256 != stats.SHA256 || len(manifest.Parts) != 3 {
+		t.Fatalf("Unexpected manifest: %+v (saved %d bytes, SHA-256 %s)",
+			manifest, stats.BytesWritten, stats.SHA256)
+	}
+
+	joined := filename + ".joined"
+	defer os.Remove(joined)
+	err = undolr.JoinRecording(manifestFile, joined)
+	if err != nil {
+		t.Fatal("JoinRecording:", err)
+	}
+}
+
+// verifyUndoDB loads the recording in UndoDB and checks the annotations
+// can be read back from it.
+func verifyUndoDB(t *testing.T, filename string) {
+	udb, err := exec.LookPath("udb")
+	if err != nil {
+		t.Skip("UndoDB not available:", err)
+	}
+	out, err := exec.Command(udb, "-batch", "-ex", "info annotations", filename).CombinedOutput()
+	if err != nil {
+		t.Fatalf("udb: %v\n%s", err, out)
+	}
+	for _, annotation := range e2eAnnotations {
+		if !strings.Contains(string(out), annotation[0]) {
+			t.Fatalf("Annotation %q not found in recording:\n%s", annotation[0], out)
+		}
+	}
+}
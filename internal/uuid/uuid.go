@@ -0,0 +1,36 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package uuid generates the UUIDs used as IDs by the undolr and undoex packages.
+package uuid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// NewV7 returns a time-ordered UUID, as described in RFC 9562, with t in
+// milliseconds in its first 48 bits, followed by random bits.
+//
+// The time is passed in so that each package can use its own clock.
+func NewV7(t time.Time) (string, error) {
+	var u [16]byte
+	_, err := rand.Read(u[6:])
+	if err != nil {
+		return "", err
+	}
+
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixNano()/1e6))
+	copy(u[:6], ms[2:])
+	u[6] = u[6]&0x0f | 0x70
+	u[8] = u[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+}
@@ -0,0 +1,36 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package uuid
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+var v7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewV7(t *testing.T) {
+	when := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	first, err := NewV7(when)
+	if err != nil {
+		t.Fatal("NewV7:", err)
+	}
+	if !v7Pattern.MatchString(first) {
+		t.Fatalf("%q is not a UUIDv7", first)
+	}
+	// 1772600767000 milliseconds since the epoch.
+	if first[:13] != "019cb73d-3218" {
+		t.Errorf("%q does not start with the time", first)
+	}
+
+	second, _ := NewV7(when)
+	if first == second {
+		t.Error("UUIDs not unique:", first)
+	}
+}
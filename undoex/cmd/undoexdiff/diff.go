@@ -0,0 +1,260 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"go.undo.io/bindings/undoex"
+)
+
+// maxText is the length at which text content is truncated when printed.
+const maxText = 60
+
+type opKind int
+
+const (
+	opSame opKind = iota
+	opChanged
+	opDeleted
+	opInserted
+)
+
+// An op is a step of the alignment of two timelines. a is nil for
+// opInserted and b is nil for opDeleted.
+type op struct {
+	kind opKind
+	a, b *undoex.IndexEntry
+}
+
+// defaultIgnored returns the details of annotations whose content always
+// differs between runs.
+func defaultIgnored() map[string]bool {
+	return map[string]bool{
+		undoex.TestStartTimeDetail: true,
+		undoex.TestEndTimeDetail:   true,
+		undoex.TestDurationDetail:  true,
+		undoex.SuiteStartDetail:    true,
+		undoex.SuiteEndDetail:      true,
+		undoex.SuiteResultDetail:   true,
+	}
+}
+
+func sameAnnotation(a, b *undoex.IndexEntry) bool {
+	return a.Name == b.Name && a.Detail == b.Detail && a.Payload == b.Payload
+}
+
+func sameContent(a, b *undoex.IndexEntry) bool {
+	return a.ContentType == b.ContentType && a.Text == b.Text && a.Value == b.Value && a.Size == b.Size
+}
+
+// align aligns the timelines a and b by annotation name and detail, using
+// a longest common subsequence.
+func align(a, b []undoex.IndexEntry, ignored map[string]bool) []op {
+	var ops []op
+	pair := func(i, j int) {
+		kind := opSame
+		if !ignored[a[i].Detail] && !sameContent(&a[i], &b[j]) {
+			kind = opChanged
+		}
+		ops = append(ops, op{kind, &a[i], &b[j]})
+	}
+
+	// Trim the common prefix and suffix, which is usually most of the
+	// timeline, before the quadratic search.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && sameAnnotation(&a[prefix], &b[prefix]) {
+		pair(prefix, prefix)
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		sameAnnotation(&a[len(a)-1-suffix], &b[len(b)-1-suffix]) {
+		suffix++
+	}
+
+	n, m := len(a)-prefix-suffix, len(b)-prefix-suffix
+	// lcs[i][j] is the length of the longest common subsequence of the
+	// middles of a and b from i and j.
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if sameAnnotation(&a[prefix+i], &b[prefix+j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && sameAnnotation(&a[prefix+i], &b[prefix+j]):
+			pair(prefix+i, prefix+j)
+			i++
+			j++
+		case j == m || i < n && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDeleted, &a[prefix+i], nil})
+			i++
+		default:
+			ops = append(ops, op{opInserted, nil, &b[prefix+j]})
+			j++
+		}
+	}
+
+	for k := suffix; k > 0; k-- {
+		pair(len(a)-k, len(b)-k)
+	}
+	return ops
+}
+
+// divergence returns the index in ops of the first difference, or -1 if
+// there is none.
+func divergence(ops []op) int {
+	for i, op := range ops {
+		if op.kind != opSame {
+			return i
+		}
+	}
+	return -1
+}
+
+func describe(entry *undoex.IndexEntry) string {
+	s := entry.Name
+	if entry.Detail != "" {
+		s += " [" + entry.Detail + "]"
+	}
+	return s
+}
+
+func content(entry *undoex.IndexEntry) string {
+	switch entry.Payload {
+	case undoex.PayloadInt:
+		return strconv.FormatInt(entry.Value, 10)
+	case undoex.PayloadText:
+		if entry.Text == "" && entry.Size > 0 {
+			return fmt.Sprintf("(%d bytes of text)", entry.Size)
+		}
+		text := entry.Text
+		if len(text) > maxText {
+			text = text[:maxText] + "..."
+		}
+		return strconv.Quote(text)
+	default:
+		if entry.Size == 0 {
+			return ""
+		}
+		return fmt.Sprintf("(%d bytes)", entry.Size)
+	}
+}
+
+func line(w io.Writer, prefix string, entry *undoex.IndexEntry, extra string) error {
+	s := fmt.Sprintf("%s #%d %s", prefix, entry.Seq, describe(entry))
+	if c := content(entry); c != "" {
+		s += ": " + c
+	}
+	_, err := fmt.Fprintln(w, s+extra)
+	return err
+}
+
+func writeOp(w io.Writer, op op) error {
+	switch op.kind {
+	case opSame:
+		return line(w, " ", op.a, "")
+	case opChanged:
+		return line(w, "~", op.a, fmt.Sprintf(" -> #%d: %s", op.b.Seq, content(op.b)))
+	case opDeleted:
+		return line(w, "-", op.a, "")
+	default:
+		return line(w, "+", op.b, "")
+	}
+}
+
+// report writes a summary of the first divergence followed by the
+// differences, with context operations either side.
+func report(w io.Writer, nameA, nameB string, ops []op, context int) error {
+	first := divergence(ops)
+	if first < 0 {
+		_, err := fmt.Fprintf(w, "%s and %s have the same %d annotations\n", nameA, nameB, len(ops))
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", nameA, nameB)
+	if err != nil {
+		return err
+	}
+	diverged := ops[first].a
+	if diverged == nil {
+		diverged = ops[first].b
+	}
+	_, err = fmt.Fprintf(w, "first divergence: %s\n", describe(diverged))
+	if err != nil {
+		return err
+	}
+	if first > 0 {
+		common := ops[first-1]
+		_, err = fmt.Fprintf(w, "last common annotation: %s (#%d in %s, #%d in %s)\n",
+			describe(common.a), common.a.Seq, nameA, common.b.Seq, nameB)
+	} else {
+		_, err = fmt.Fprintln(w, "the runs diverge at their first annotation")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Print each run of differences with its context, separating
+	// non-adjacent hunks.
+	end := -1
+	for i := first; i < len(ops); i++ {
+		if ops[i].kind == opSame {
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		if start <= end {
+			start = end + 1
+		} else {
+			_, err = fmt.Fprintln(w, "@@")
+			if err != nil {
+				return err
+			}
+		}
+
+		// Extend the hunk while further differences fall within its
+		// trailing context.
+		stop := i + context
+		for k := i + 1; k < len(ops) && k <= stop; k++ {
+			if ops[k].kind != opSame {
+				stop = k + context
+			}
+		}
+		if stop >= len(ops) {
+			stop = len(ops) - 1
+		}
+
+		for k := start; k <= stop; k++ {
+			err = writeOp(w, ops[k])
+			if err != nil {
+				return err
+			}
+		}
+		end = stop
+		i = stop
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go.undo.io/bindings/undoex"
+)
+
+func timeline(entries ...undoex.IndexEntry) []undoex.IndexEntry {
+	for i := range entries {
+		entries[i].Seq = int64(i + 1)
+	}
+	return entries
+}
+
+func text(name, detail, s string) undoex.IndexEntry {
+	return undoex.IndexEntry{Name: name, Detail: detail, Payload: undoex.PayloadText, ContentType: undoex.UnstructuredText, Text: s, Size: len(s)}
+}
+
+func integer(name, detail string, value int64) undoex.IndexEntry {
+	return undoex.IndexEntry{Name: name, Detail: detail, Payload: undoex.PayloadInt, Value: value}
+}
+
+func kinds(ops []op) string {
+	var b strings.Builder
+	for _, op := range ops {
+		b.WriteByte(" ~-+"[op.kind])
+	}
+	return b.String()
+}
+
+func TestAlign(t *testing.T) {
+	pass := timeline(
+		text("test", "u-test-start", ""),
+		integer("test", undoex.TestStartTimeDetail, 100),
+		integer("connect", "", 1),
+		text("query", "", "SELECT 1"),
+		integer("test", "u-test-result", int64(undoex.Success)),
+	)
+	fail := timeline(
+		text("test", "u-test-start", ""),
+		integer("test", undoex.TestStartTimeDetail, 200),
+		integer("connect", "", 1),
+		integer("retry", "", 1),
+		text("query", "", "SELECT 2"),
+		integer("test", "u-test-result", int64(undoex.Failure)),
+	)
+
+	ops := align(pass, fail, defaultIgnored())
+	if got, want := kinds(ops), "   +~~"; got != want {
+		t.Errorf("alignment = %q, want %q", got, want)
+	}
+	if got := divergence(ops); got != 3 {
+		t.Errorf("divergence = %d, want 3", got)
+	}
+}
+
+func TestAlignSame(t *testing.T) {
+	a := timeline(integer("x", "", 1), integer("y", "", 2))
+	b := timeline(integer("x", "", 1), integer("y", "", 2))
+	ops := align(a, b, nil)
+	if divergence(ops) != -1 {
+		t.Errorf("divergence found in identical timelines: %q", kinds(ops))
+	}
+}
+
+func TestAlignDeleted(t *testing.T) {
+	a := timeline(integer("x", "", 1), integer("y", "", 2), integer("z", "", 3))
+	b := timeline(integer("x", "", 1), integer("z", "", 3))
+	if got, want := kinds(align(a, b, nil)), " - "; got != want {
+		t.Errorf("alignment = %q, want %q", got, want)
+	}
+}
+
+func TestReport(t *testing.T) {
+	var a, b []undoex.IndexEntry
+	for i := 0; i < 20; i++ {
+		a = append(a, integer("step", "", int64(i)))
+		b = append(b, integer("step", "", int64(i)))
+	}
+	b[10].Value = 99
+	a, b = timeline(a...), timeline(b...)
+
+	var out strings.Builder
+	err := report(&out, "pass.idx", "fail.idx", align(a, b, nil), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- pass.idx
++++ fail.idx
+first divergence: step
+last common annotation: step (#10 in pass.idx, #10 in fail.idx)
+@@
+  #9 step: 8
+  #10 step: 9
+~ #11 step: 10 -> #11: 99
+  #12 step: 11
+  #13 step: 12
+`
+	if out.String() != want {
+		t.Errorf("report:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestReportSame(t *testing.T) {
+	a := timeline(integer("x", "", 1))
+	var out strings.Builder
+	err := report(&out, "a", "b", align(a, a, nil), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "same 1 annotations") {
+		t.Errorf("report = %q", out.String())
+	}
+}
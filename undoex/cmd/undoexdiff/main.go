@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Command undoexdiff compares the annotation timelines of two runs.
+//
+// Each run is described by the annotation index written by
+// undoex.SetIndex, for example from a passing and a failing run of the
+// same test:
+//
+//	undoexdiff pass.idx fail.idx
+//
+// The annotations are aligned by name and detail, and the differences are
+// printed with a few annotations of context, marked as in diff: "-" for
+// annotations only in the first run, "+" for those only in the second and
+// "~" for annotations in both runs with different content. The first
+// divergence is reported along with the last annotation common to both
+// runs before it, which is where to start replaying the recordings in
+// UndoDB.
+//
+// Annotations whose content is expected to differ between runs, such as
+// test and suite timings, are compared by name and detail only; more may
+// be given with -ignore.
+//
+// The exit status is 0 if the timelines are the same, 1 if they differ
+// and 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.undo.io/bindings/undoex"
+)
+
+func main() {
+	context := flag.Int("context", 3, "`lines` of context around differences")
+	ignore := flag.String("ignore", "", "comma-separated annotation `details` whose content is not compared")
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: undoexdiff [flags] index1 index2")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	a, err := readIndex(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undoexdiff:", err)
+		os.Exit(2)
+	}
+	b, err := readIndex(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undoexdiff:", err)
+		os.Exit(2)
+	}
+
+	ignored := defaultIgnored()
+	for _, detail := range strings.Split(*ignore, ",") {
+		if detail != "" {
+			ignored[detail] = true
+		}
+	}
+
+	ops := align(a, b, ignored)
+	err = report(os.Stdout, flag.Arg(0), flag.Arg(1), ops, *context)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undoexdiff:", err)
+		os.Exit(2)
+	}
+	if divergence(ops) >= 0 {
+		os.Exit(1)
+	}
+}
+
+func readIndex(filename string) ([]undoex.IndexEntry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := undoex.ReadIndex(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"go.undo.io/bindings/undoex"
+)
+
+// initialisms are name components written in upper case in identifiers.
+var initialisms = map[string]bool{
+	"api": true, "db": true, "http": true, "id": true, "ip": true,
+	"json": true, "sql": true, "uri": true, "url": true, "uuid": true,
+	"xml": true,
+}
+
+// words splits a name in to its alphanumeric components.
+func words(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// identifier converts a name such as "order-placed" to "OrderPlaced", or
+// to "orderPlaced" if exported is false.
+func identifier(name string, exported bool) string {
+	var b strings.Builder
+	for i, word := range words(name) {
+		word = strings.ToLower(word)
+		switch {
+		case i == 0 && !exported:
+			b.WriteString(word)
+		case initialisms[word]:
+			b.WriteString(strings.ToUpper(word))
+		default:
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	id := b.String()
+	if id == "" || unicode.IsDigit(rune(id[0])) || token.Lookup(id).IsKeyword() {
+		id = "_" + id
+	}
+	return id
+}
+
+type param struct {
+	Name  string
+	Field string
+	Key   string
+	Type  string
+}
+
+type emitter struct {
+	Func        string
+	Name        string
+	Detail      string
+	Description string
+	Payload     string
+	ContentType string
+	Params      []param
+}
+
+var contentTypes = map[undoex.AnnotationContentType]string{
+	undoex.JSON:             "undoex.JSON",
+	undoex.XML:              "undoex.XML",
+	undoex.UnstructuredText: "undoex.UnstructuredText",
+}
+
+var payloads = map[undoex.AnnotationPayload]string{
+	undoex.PayloadRawData: "undoex.PayloadRawData",
+	undoex.PayloadText:    "undoex.PayloadText",
+	undoex.PayloadInt:     "undoex.PayloadInt",
+}
+
+func newEmitter(spec undoex.AnnotationSpec) emitter {
+	e := emitter{
+		Func:        identifier(spec.Name+" "+spec.Detail, true),
+		Name:        spec.Name,
+		Detail:      spec.Detail,
+		Description: strings.Join(strings.Fields(spec.Description), " "),
+		Payload:     payloads[spec.Payload],
+		ContentType: contentTypes[spec.ContentType],
+	}
+
+	switch {
+	case len(spec.Fields) > 0:
+		used := make(map[string]bool)
+		for _, field := range spec.Fields {
+			name := identifier(field.Name, false)
+			for used[name] || name == "data" || name == "undoex" || name == "json" {
+				name += "_"
+			}
+			used[name] = true
+			e.Params = append(e.Params, param{name, identifier(field.Name, true), field.Name, field.Type})
+		}
+	case spec.Payload == undoex.PayloadInt:
+		e.Params = []param{{Name: "value", Type: "int64"}}
+	case spec.Payload == undoex.PayloadText:
+		e.Params = []param{{Name: "text", Type: "string"}}
+	default:
+		e.Params = []param{{Name: "rawData", Type: "[]byte"}}
+	}
+	return e
+}
+
+var tmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`// Code generated by undoexgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .JSON}}
+	"encoding/json"
+{{end}}
+	"go.undo.io/bindings/undoex"
+)
+
+// Schema declares the annotations emitted by this package.
+var Schema = undoex.NewSchema(){{range .Emitters}}.
+	MustDeclare(undoex.AnnotationSpec{
+		Name: {{quote .Name}},
+		{{- if .Detail}}
+		Detail: {{quote .Detail}},
+		{{- end}}
+		Payload: {{.Payload}},
+		{{- if eq .Payload "undoex.PayloadText"}}
+		ContentType: {{.ContentType}},
+		{{- end}}
+		{{- if .Description}}
+		Description: {{quote .Description}},
+		{{- end}}
+		{{- if (index .Params 0).Key}}
+		Fields: []undoex.AnnotationField{
+			{{- range .Params}}
+			{Name: {{quote .Key}}, Type: {{quote .Type}}},
+			{{- end}}
+		},
+		{{- end}}
+	}){{end}}
+{{range .Emitters}}
+// {{.Func}} adds an annotation named {{quote .Name}}
+{{- if .Detail}} with detail {{quote .Detail}}{{end}} at the current execution point.
+{{- if .Description}}
+//
+// {{.Description}}
+{{- end}}
+func {{.Func}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}} {{$p.Type}}{{end}}) error {
+{{- if (index .Params 0).Key}}
+	data, err := json.Marshal(struct {
+		{{- range .Params}}
+		{{.Field}} {{.Type}} ` + "`" + `json:{{quote .Key}}` + "`" + `
+		{{- end}}
+	}{ {{- range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}}{{end -}} })
+	if err != nil {
+		return err
+	}
+	return undoex.AnnotationAddText({{quote .Name}}, {{quote .Detail}}, undoex.JSON, string(data))
+{{- else if eq .Payload "undoex.PayloadInt"}}
+	return undoex.AnnotationAddInt({{quote .Name}}, {{quote .Detail}}, value)
+{{- else if eq .Payload "undoex.PayloadText"}}
+	return undoex.AnnotationAddText({{quote .Name}}, {{quote .Detail}}, {{.ContentType}}, text)
+{{- else}}
+	return undoex.AnnotationAddRawData({{quote .Name}}, {{quote .Detail}}, rawData)
+{{- end}}
+}
+{{end}}`))
+
+// generate returns Go source in package pkg with an emitter for each annotation in schema.
+func generate(schema *undoex.Schema, pkg string) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	data := struct {
+		Package  string
+		JSON     bool
+		Emitters []emitter
+	}{Package: pkg}
+
+	funcs := make(map[string]string)
+	for _, spec := range schema.Specs() {
+		e := newEmitter(spec)
+		if other, ok := funcs[e.Func]; ok {
+			return nil, fmt.Errorf("annotations %s and %q (%q) both generate %s",
+				other, spec.Name, spec.Detail, e.Func)
+		}
+		funcs[e.Func] = fmt.Sprintf("%q (%q)", spec.Name, spec.Detail)
+		if len(spec.Fields) > 0 {
+			data.JSON = true
+		}
+		data.Emitters = append(data.Emitters, e)
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"go.undo.io/bindings/undoex"
+)
+
+func TestIdentifier(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		exported bool
+		expected string
+	}{
+		{"order-placed", true, "OrderPlaced"},
+		{"order_id", false, "orderID"},
+		{"order_id", true, "OrderID"},
+		{"HTTP request", true, "HTTPRequest"},
+		{"type", false, "_type"},
+		{"2fa", true, "_2fa"},
+	} {
+		id := identifier(test.name, test.exported)
+		if id != test.expected {
+			t.Errorf("identifier(%q, %v) = %q, expected %q", test.name, test.exported, id, test.expected)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	schema := undoex.NewSchema().
+		MustDeclare(undoex.AnnotationSpec{
+			Name: "order-placed", Payload: undoex.PayloadText, ContentType: undoex.JSON,
+			Description: "An order was accepted.",
+			Fields: []undoex.AnnotationField{
+				{Name: "order_id", Type: "string"},
+				{Name: "amount", Type: "int64"},
+			},
+		}).
+		MustDeclare(undoex.AnnotationSpec{Name: "queue", Detail: "depth", Payload: undoex.PayloadInt}).
+		MustDeclare(undoex.AnnotationSpec{Name: "config", Payload: undoex.PayloadText, ContentType: undoex.XML}).
+		MustDeclare(undoex.AnnotationSpec{Name: "packet", Payload: undoex.PayloadRawData})
+
+	src, err := generate(schema, "annot")
+	if err != nil {
+		t.Fatal("generate:", err)
+	}
+
+	_, err = parser.ParseFile(token.NewFileSet(), "annot_gen.go", src, 0)
+	if err != nil {
+		t.Fatalf("Generated code doesn't parse: %v\n%s", err, src)
+	}
+
+	for _, expected := range []string{
+		"package annot\n",
+		`"encoding/json"`,
+		"func OrderPlaced(orderID string, amount int64) error {",
+		"OrderID string `json:\"order_id\"`",
+		`return undoex.AnnotationAddText("order-placed", "", undoex.JSON, string(data))`,
+		"func QueueDepth(value int64) error {",
+		`return undoex.AnnotationAddInt("queue", "depth", value)`,
+		"func Config(text string) error {",
+		`return undoex.AnnotationAddText("config", "", undoex.XML, text)`,
+		"func Packet(rawData []byte) error {",
+		"// An order was accepted.",
+		"var Schema = undoex.NewSchema().",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Fatalf("Generated code doesn't contain %q:\n%s", expected, src)
+		}
+	}
+}
+
+func TestGenerateConflict(t *testing.T) {
+	schema := undoex.NewSchema().
+		MustDeclare(undoex.AnnotationSpec{Name: "a-b", Payload: undoex.PayloadInt}).
+		MustDeclare(undoex.AnnotationSpec{Name: "a_b", Payload: undoex.PayloadInt})
+
+	_, err := generate(schema, "annot")
+	if err == nil {
+		t.Fatal("Expected conflicting names to fail")
+	}
+
+	_, err = generate(undoex.NewSchema(), "not a package")
+	if err == nil {
+		t.Fatal("Expected invalid package name to fail")
+	}
+}
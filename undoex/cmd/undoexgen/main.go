@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Command undoexgen generates typed annotation emitters from an annotation schema.
+//
+// The schema is read as JSON in the form exported by undoex.Schema, and a
+// Go source file is written with one function per declared annotation.
+// For example, an annotation named "order-placed" with a JSON payload
+// declaring fields "order_id" (string) and "amount" (int64) generates
+//
+//	func OrderPlaced(orderID string, amount int64) error
+//
+// Integer, text and raw data annotations without fields take a single
+// value of the appropriate type. The generated file also declares the
+// schema itself as a variable named Schema.
+//
+// Typical use is from a go:generate directive:
+//
+//	//go:generate undoexgen -schema annotations.json -package annot -o annot_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"go.undo.io/bindings/undoex"
+)
+
+func main() {
+	schemaFile := flag.String("schema", "", "annotation schema JSON `file`")
+	pkg := flag.String("package", "", "package `name` for the generated code")
+	output := flag.String("o", "", "output `file` (default standard output)")
+	flag.Parse()
+
+	if *schemaFile == "" || *pkg == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	err := run(*schemaFile, *pkg, *output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undoexgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaFile, pkg, output string) error {
+	data, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	schema := undoex.NewSchema()
+	err = schema.UnmarshalJSON(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", schemaFile, err)
+	}
+
+	src, err := generate(schema, pkg)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(output, src, 0644)
+}
@@ -15,6 +15,10 @@ SPDX-License-Identifier: BSD-3-Clause
 //
 // Names starting with "u-" are reserved for internal use.
 //
+// Names and details must be valid UTF-8 without '\0' characters, and text
+// content may not contain '\0'. Inputs which could not be passed to the
+// annotation library intact are rejected with an <InvalidInputError>.
+//
 // The detail is useful to distinguish between different, but related,
 // annotations with the same name.
 // For instance, a test could insert an annotation (with its name as name)
@@ -31,6 +35,22 @@ SPDX-License-Identifier: BSD-3-Clause
 // This allows the debugger to present the data more appropriately.
 //
 // The AnnotationTest* API provides a wrapper for the basic annotation
-// interface with helpers for recording test running and results.
+// interface with helpers for recording test running and results. Tests can
+// be grouped in to suites using the AnnotationSuite* API.
+//
+// Larger applications can hand each subsystem its own <Annotator>, from
+// <Named>, which prefixes annotation names with the subsystem's name.
+// They can also declare their annotations up front in a <Schema>,
+// which validates annotations as they are added and can be exported as
+// JSON for analysis tooling. The undoexgen command generates typed emitter
+// functions from an exported schema.
+//
+// <SetIndex> writes an index of the annotations added as they are added.
+// The undoexdiff command compares the indexes of two runs, such as a
+// passing and a failing run of the same test, to find where they diverge.
 //
+// Runtime behaviour can be marked in the recording as it happens: an
+// <AllocationSampler> annotates bursts of heap allocation, and
+// <AnnotateGoroutineLeaks> the goroutines still running when they should
+// have finished.
 package undoex
@@ -0,0 +1,263 @@
+//go:build go1.16
+// +build go1.16
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AllocationSpikeName is the annotation name used by an <AllocationSampler>.
+const AllocationSpikeName = "allocation-spike"
+
+// Defaults for AllocationSamplerOptions.
+const (
+	DefaultAllocationInterval = 100 * time.Millisecond
+	DefaultAllocationSites    = 5
+)
+
+// allocsMetric is the runtime/metrics counter of bytes allocated on the heap.
+const allocsMetric = "/gc/heap/allocs:bytes"
+
+// ErrAllocationThresholdInvalid indicates an allocation rate threshold which isn't positive.
+var ErrAllocationThresholdInvalid = errors.New("allocation rate threshold not valid")
+
+// AllocationSamplerOptions controls an <AllocationSampler>.
+type AllocationSamplerOptions struct {
+	// Threshold is the allocation rate, in bytes per second, above which
+	// a spike is annotated.
+	Threshold float64
+
+	// Interval is the time between samples. It defaults to
+	// DefaultAllocationInterval.
+	Interval time.Duration
+
+	// Sites is the number of call sites reported for each spike. It
+	// defaults to DefaultAllocationSites.
+	Sites int
+}
+
+// An AllocationSite is a call site allocating during a spike.
+type AllocationSite struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Bytes    int64  `json:"bytes"`
+	Objects  int64  `json:"objects"`
+}
+
+// An AllocationSpike is stored as JSON by an <AllocationSampler>.
+type AllocationSpike struct {
+	// Rate is the allocation rate over the sample, in bytes per second.
+	Rate     float64       `json:"rate_bytes_per_sec"`
+	Bytes    uint64        `json:"bytes"`
+	Interval time.Duration `json:"interval_ns"`
+
+	// Sites are the call sites which allocated most since the previous
+	// sample, according to the heap profile. The profile is sampled and
+	// only updated by garbage collection, so these indicate likely
+	// culprits rather than exact amounts.
+	Sites []AllocationSite `json:"sites,omitempty"`
+}
+
+// An AllocationSampler annotates bursts of heap allocation.
+type AllocationSampler struct {
+	opts    AllocationSamplerOptions
+	add     addTextFunc
+	samples []metrics.Sample
+	last    uint64
+	lastAt  time.Time
+	sites   map[[32]uintptr]runtime.MemProfileRecord
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// StartAllocationSampler starts annotating allocation spikes.
+//
+// The heap allocation counter from runtime/metrics is sampled every
+// <opts.Interval>. When the allocation rate over a sample exceeds
+// <opts.Threshold>, an annotation with AllocationSpikeName as name and an
+// <AllocationSpike> as JSON is added, marking the burst in the recording
+// timeline. The sampler must be stopped with Stop.
+func StartAllocationSampler(opts AllocationSamplerOptions) (*AllocationSampler, error) {
+	s, err := newAllocationSampler(opts, AnnotationAddText)
+	if err != nil {
+		return nil, err
+	}
+	go s.run()
+	return s, nil
+}
+
+func newAllocationSampler(opts AllocationSamplerOptions, add addTextFunc) (*AllocationSampler, error) {
+	if opts.Threshold <= 0 {
+		return nil, ErrAllocationThresholdInvalid
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultAllocationInterval
+	}
+	if opts.Sites <= 0 {
+		opts.Sites = DefaultAllocationSites
+	}
+
+	s := &AllocationSampler{
+		opts:    opts,
+		add:     add,
+		samples: []metrics.Sample{{Name: allocsMetric}},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.last = s.read()
+	s.lastAt = now()
+	s.sites = memProfile()
+	return s, nil
+}
+
+// read returns the total bytes allocated on the heap.
+func (s *AllocationSampler) read() uint64 {
+	metrics.Read(s.samples)
+	if s.samples[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s.samples[0].Value.Uint64()
+}
+
+func (s *AllocationSampler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.sample(s.read(), now())
+		}
+	}
+}
+
+// sample records the allocation counter at t, annotating a spike if the
+// rate since the previous sample exceeds the threshold.
+func (s *AllocationSampler) sample(allocated uint64, t time.Time) *AllocationSpike {
+	elapsed := t.Sub(s.lastAt)
+	delta := allocated - s.last
+	s.last, s.lastAt = allocated, t
+
+	sites := memProfile()
+	previous := s.sites
+	s.sites = sites
+	if elapsed <= 0 {
+		return nil
+	}
+
+	rate := float64(delta) / elapsed.Seconds()
+	if rate <= s.opts.Threshold {
+		return nil
+	}
+
+	spike := &AllocationSpike{
+		Rate:     rate,
+		Bytes:    delta,
+		Interval: elapsed,
+		Sites:    topAllocationSites(previous, sites, s.opts.Sites),
+	}
+	text, err := json.Marshal(spike)
+	if err == nil {
+		s.add(AllocationSpikeName, "", JSON, string(text))
+	}
+	return spike
+}
+
+// Stop stops the sampler, waiting for any annotation in progress.
+func (s *AllocationSampler) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// memProfile returns the heap profile records by stack.
+func memProfile() map[[32]uintptr]runtime.MemProfileRecord {
+	var records []runtime.MemProfileRecord
+	n, _ := runtime.MemProfile(nil, true)
+	for {
+		records = make([]runtime.MemProfileRecord, n+50)
+		var ok bool
+		n, ok = runtime.MemProfile(records, true)
+		if ok {
+			break
+		}
+	}
+
+	profile := make(map[[32]uintptr]runtime.MemProfileRecord, n)
+	for _, r := range records[:n] {
+		profile[r.Stack0] = r
+	}
+	return profile
+}
+
+// topAllocationSites returns the n call sites allocating the most bytes
+// between the profiles previous and current.
+func topAllocationSites(previous, current map[[32]uintptr]runtime.MemProfileRecord, n int) []AllocationSite {
+	bySite := make(map[string]*AllocationSite)
+	for stack, r := range current {
+		p := previous[stack]
+		bytes := r.AllocBytes - p.AllocBytes
+		if bytes <= 0 {
+			continue
+		}
+		frame, ok := allocationFrame(r.Stack())
+		if !ok {
+			continue
+		}
+		key := frame.Function + "\x00" + frame.File
+		site := bySite[key]
+		if site == nil {
+			site = &AllocationSite{Function: frame.Function, File: frame.File, Line: frame.Line}
+			bySite[key] = site
+		}
+		site.Bytes += bytes
+		site.Objects += r.AllocObjects - p.AllocObjects
+	}
+
+	sites := make([]AllocationSite, 0, len(bySite))
+	for _, site := range bySite {
+		sites = append(sites, *site)
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Bytes != sites[j].Bytes {
+			return sites[i].Bytes > sites[j].Bytes
+		}
+		return sites[i].Function < sites[j].Function
+	})
+	if len(sites) > n {
+		sites = sites[:n]
+	}
+	return sites
+}
+
+// allocationFrame returns the first frame of stack outside the runtime.
+func allocationFrame(stack []uintptr) (runtime.Frame, bool) {
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
@@ -0,0 +1,79 @@
+//go:build go1.16
+// +build go1.16
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+var allocationSink [][]byte
+
+func allocateForTest() {
+	for i := 0; i < 1000; i++ {
+		allocationSink = append(allocationSink, make([]byte, 64*1024))
+	}
+	allocationSink = nil
+}
+
+func TestAllocationSampler(t *testing.T) {
+	_, err := StartAllocationSampler(AllocationSamplerOptions{})
+	if err != ErrAllocationThresholdInvalid {
+		t.Fatal("Expected zero threshold to fail:", err)
+	}
+
+	saved := runtime.MemProfileRate
+	runtime.MemProfileRate = 1
+	defer func() { runtime.MemProfileRate = saved }()
+
+	var stored []string
+	add := func(name, detail string, contentType AnnotationContentType, text string) error {
+		if name != AllocationSpikeName || contentType != JSON {
+			t.Errorf("Unexpected annotation %q %v", name, contentType)
+		}
+		stored = append(stored, text)
+		return nil
+	}
+	s, err := newAllocationSampler(AllocationSamplerOptions{Threshold: 1 << 20}, add)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := s.lastAt
+
+	if spike := s.sample(s.last+1024, start.Add(time.Second)); spike != nil {
+		t.Fatal("Spike reported below threshold:", spike)
+	}
+
+	allocateForTest()
+	runtime.GC()
+	spike := s.sample(s.last+64<<20, start.Add(2*time.Second))
+	if spike == nil {
+		t.Fatal("Spike not reported above threshold")
+	}
+	if spike.Rate != 64<<20 || spike.Interval != time.Second {
+		t.Errorf("Unexpected spike: %+v", spike)
+	}
+	if len(spike.Sites) == 0 || !strings.HasSuffix(spike.Sites[0].Function, "allocateForTest") {
+		t.Errorf("Expected allocateForTest as top site, got %+v", spike.Sites)
+	}
+
+	if len(stored) != 1 {
+		t.Fatalf("Stored %d annotations, expected 1", len(stored))
+	}
+	var decoded AllocationSpike
+	err = json.Unmarshal([]byte(stored[0]), &decoded)
+	if err != nil || decoded.Bytes != 64<<20 {
+		t.Errorf("Unexpected annotation %s (%v)", stored[0], err)
+	}
+}
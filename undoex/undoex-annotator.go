@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"io"
+)
+
+// An Annotator adds annotations on behalf of one component of an application.
+//
+// Annotation names are prefixed with the component's name, separated by a
+// '/' as for tests in a suite, and an empty detail is replaced by the
+// Annotator's default detail. This lets each subsystem of a large
+// application be handed its own Annotator rather than repeating name
+// constants everywhere:
+//
+//	var annotate = undoex.Named("storage")
+//
+//	annotate.AddInt("flush", "bytes", n) // "storage/flush"
+//
+// Annotations are added at LevelInfo unless another level is chosen with
+// WithLevel, and are discarded if below the component's level, see
+// SetAnnotationLevel:
+//
+//	annotate.WithLevel(undoex.LevelDebug).AddText("page", "", undoex.JSON, dump)
+//
+// An Annotator is safe for concurrent use, and its methods otherwise
+// behave as the corresponding package functions.
+type Annotator struct {
+	component string
+	detail    string
+	level     AnnotationLevel
+}
+
+// Named returns an Annotator for the named component.
+func Named(component string) *Annotator {
+	return &Annotator{component: component, level: LevelInfo}
+}
+
+// Named returns an Annotator for a subcomponent, named "component/subcomponent", with the same default detail and level.
+func (a *Annotator) Named(subcomponent string) *Annotator {
+	return &Annotator{component: a.component + "/" + subcomponent, detail: a.detail, level: a.level}
+}
+
+// WithDetail returns a copy of the Annotator using detail for annotations added without one.
+func (a *Annotator) WithDetail(detail string) *Annotator {
+	return &Annotator{component: a.component, detail: detail, level: a.level}
+}
+
+// WithLevel returns a copy of the Annotator adding annotations at level.
+func (a *Annotator) WithLevel(level AnnotationLevel) *Annotator {
+	return &Annotator{component: a.component, detail: a.detail, level: level}
+}
+
+// Enabled reports whether annotations added by the Annotator are currently kept.
+//
+// It can be used to avoid building annotation content which would be
+// discarded.
+func (a *Annotator) Enabled() bool {
+	return a.level >= componentLevel(a.component)
+}
+
+// Component returns the name of the Annotator's component.
+func (a *Annotator) Component() string {
+	return a.component
+}
+
+// names returns the full name and detail of an annotation.
+func (a *Annotator) names(name, detail string) (string, string) {
+	if name != "" {
+		name = a.component + "/" + name
+	} else {
+		name = a.component
+	}
+	if detail == "" {
+		detail = a.detail
+	}
+	return name, detail
+}
+
+// AddRawData adds an annotation as AnnotationAddRawData, if enabled.
+func (a *Annotator) AddRawData(name, detail string, rawData []byte) error {
+	if !a.Enabled() {
+		return nil
+	}
+	name, detail = a.names(name, detail)
+	return AnnotationAddRawData(name, detail, rawData)
+}
+
+// AddText adds an annotation as AnnotationAddText, if enabled.
+func (a *Annotator) AddText(name, detail string, contentType AnnotationContentType, text string) error {
+	if !a.Enabled() {
+		return nil
+	}
+	name, detail = a.names(name, detail)
+	return AnnotationAddText(name, detail, contentType, text)
+}
+
+// AddInt adds an annotation as AnnotationAddInt, if enabled.
+func (a *Annotator) AddInt(name, detail string, value int64) error {
+	if !a.Enabled() {
+		return nil
+	}
+	name, detail = a.names(name, detail)
+	return AnnotationAddInt(name, detail, value)
+}
+
+// AddReader adds an annotation as AnnotationAddReader, if enabled.
+func (a *Annotator) AddReader(name, detail string, contentType AnnotationContentType, r io.Reader, limit int64) error {
+	if !a.Enabled() {
+		return nil
+	}
+	name, detail = a.names(name, detail)
+	return AnnotationAddReader(name, detail, contentType, r, limit)
+}
+
+// AddTime adds an annotation as AnnotationAddTime, if enabled.
+func (a *Annotator) AddTime(name, detail string) error {
+	if !a.Enabled() {
+		return nil
+	}
+	name, detail = a.names(name, detail)
+	return AnnotationAddTime(name, detail)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAnnotatorNames(t *testing.T) {
+	storage := Named("storage")
+	flush := storage.Named("flush").WithDetail("shard-1")
+
+	for _, test := range []struct {
+		annotator      *Annotator
+		name, detail   string
+		expectedName   string
+		expectedDetail string
+	}{
+		{storage, "write", "", "storage/write", ""},
+		{storage, "write", "bytes", "storage/write", "bytes"},
+		{storage, "", "open", "storage", "open"},
+		{flush, "done", "", "storage/flush/done", "shard-1"},
+		{flush, "done", "bytes", "storage/flush/done", "bytes"},
+	} {
+		name, detail := test.annotator.names(test.name, test.detail)
+		if name != test.expectedName || detail != test.expectedDetail {
+			t.Errorf("%s: names(%q, %q) = %q, %q, expected %q, %q", test.annotator.Component(),
+				test.name, test.detail, name, detail, test.expectedName, test.expectedDetail)
+		}
+	}
+
+	if storage.Component() != "storage" {
+		t.Errorf("WithDetail changed the parent Annotator: %q", storage.Component())
+	}
+	if _, detail := storage.names("x", ""); detail != "" {
+		t.Errorf("WithDetail changed the parent's detail: %q", detail)
+	}
+}
+
+func TestAnnotatorInvalidInput(t *testing.T) {
+	err := Named("bad\x00").AddInt("value", "", 1)
+	if !errors.Is(err, ErrInputNul) {
+		t.Errorf("Expected ErrInputNul, got %v", err)
+	}
+
+	err = Named("storage").WithDetail("\xff").AddText("value", "", UnstructuredText, "")
+	if !errors.Is(err, ErrInputInvalidUTF8) {
+		t.Errorf("Expected ErrInputInvalidUTF8, got %v", err)
+	}
+}
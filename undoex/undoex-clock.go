@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A Clock provides the times stored in annotations.
+//
+// This includes the start and end times of tests and suites, and the
+// durations derived from them.
+//
+// Replacing the clock with <SetClock> lets simulated-time systems store
+// consistent times in recordings, and tests of timed annotations to be
+// deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// clock holds a clockHolder, as atomic.Value requires a consistent type.
+var clock atomic.Value
+
+type clockHolder struct {
+	Clock
+}
+
+// SetClock sets the Clock used by the package. Passing nil restores the system clock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = systemClock{}
+	}
+	clock.Store(clockHolder{c})
+}
+
+// now returns the current time from the package Clock.
+func now() time.Time {
+	c, ok := clock.Load().(clockHolder)
+	if !ok {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+// timeText formats t as stored by <AnnotationAddTime>.
+func timeText(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// AnnotationAddTime adds an annotation storing the current time from the package Clock at the current execution point.
+//
+// The time is stored as unstructured text in RFC 3339 format, in UTC.
+func AnnotationAddTime(name, detail string) error {
+	return AnnotationAddText(name, detail, UnstructuredText, timeText(now()))
+}
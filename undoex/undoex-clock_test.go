@@ -0,0 +1,55 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestSetClock(t *testing.T) {
+	when := time.Date(2001, 2, 3, 4, 5, 6, 7, time.FixedZone("X", 3600))
+	clock := &fakeClock{when}
+	SetClock(clock)
+	defer SetClock(nil)
+
+	if !now().Equal(when) {
+		t.Fatal("Clock not used:", now())
+	}
+	if timeText(now()) != "2001-02-03T03:05:06.000000007Z" {
+		t.Fatal("Unexpected time text:", timeText(now()))
+	}
+
+	suite, err := AnnotationSuiteNew("clock")
+	if err != nil {
+		t.Fatal("AnnotationSuiteNew:", err)
+	}
+	suite.start = now()
+	clock.now = when.Add(time.Second)
+	if suite.Result().Duration != int64(time.Second) {
+		t.Fatal("Suite duration not from clock:", suite.Result().Duration)
+	}
+
+	context := &AnnotationTestContext{start: when}
+	if context.Duration() != time.Second {
+		t.Fatal("Test duration not from clock:", context.Duration())
+	}
+
+	SetClock(nil)
+	if time.Since(now()) > time.Minute {
+		t.Fatal("System clock not restored:", now())
+	}
+}
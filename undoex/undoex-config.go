@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+)
+
+// ConfigAnnotationName is the annotation name used by <AnnotateConfig>.
+const ConfigAnnotationName = "config-load"
+
+// A ConfigLoad describes a load of application configuration, as stored by <AnnotateConfig>.
+type ConfigLoad struct {
+	// Source identifies the configuration, for instance its file name.
+	Source string `json:"source"`
+
+	// Generation counts the loads of the configuration from Source,
+	// starting from 1.
+	Generation int64 `json:"generation"`
+
+	// SHA256 is the hex encoded SHA-256 hash of the configuration.
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+
+	// Changed is true if the configuration differs from the previous load
+	// from Source, or if this is the first load.
+	Changed bool `json:"changed"`
+}
+
+type configRegistry struct {
+	mu    sync.Mutex
+	loads map[string]ConfigLoad
+}
+
+var configs = configRegistry{loads: make(map[string]ConfigLoad)}
+
+type addTextFunc func(name, detail string, contentType AnnotationContentType, text string) error
+
+// annotate stores a ConfigLoad for data using add, counting it as a new
+// generation only if it was stored.
+func (r *configRegistry) annotate(source string, data []byte, add addTextFunc) (ConfigLoad, error) {
+	sum := sha256.Sum256(data)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, loaded := r.loads[source]
+	load := ConfigLoad{
+		Source:     source,
+		Generation: previous.Generation + 1,
+		SHA256:     hex.EncodeToString(sum[:]),
+		Size:       len(data),
+	}
+	load.Changed = !loaded || load.SHA256 != previous.SHA256
+
+	text, err := json.Marshal(load)
+	if err != nil {
+		return ConfigLoad{}, err
+	}
+	err = add(ConfigAnnotationName, source, JSON, string(text))
+	if err != nil {
+		return ConfigLoad{}, err
+	}
+	r.loads[source] = load
+	return load, nil
+}
+
+// AnnotateConfig adds an annotation recording a load of application configuration at the current execution point.
+//
+// Call it whenever configuration is loaded or reloaded, so the recording
+// shows which generation of the configuration was active at each point.
+// The annotation has ConfigAnnotationName as name and <source> as detail,
+// and stores a <ConfigLoad> as JSON. Only a hash of the configuration is
+// stored, so secrets it contains are not copied in to the recording.
+func AnnotateConfig(source string, data []byte) (ConfigLoad, error) {
+	return configs.annotate(source, data, AnnotationAddText)
+}
+
+// AnnotateConfigValue behaves as <AnnotateConfig>, hashing the JSON encoding of <value>.
+//
+// This suits configuration libraries which expose the loaded settings as a
+// map, as map keys are encoded in sorted order. For example, with viper:
+//
+//	v.OnConfigChange(func(fsnotify.Event) {
+//		undoex.AnnotateConfigValue(v.ConfigFileUsed(), v.AllSettings())
+//	})
+func AnnotateConfigValue(source string, value interface{}) (ConfigLoad, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ConfigLoad{}, err
+	}
+	return AnnotateConfig(source, data)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestConfigAnnotate(t *testing.T) {
+	r := configRegistry{loads: make(map[string]ConfigLoad)}
+
+	var stored []ConfigLoad
+	add := func(name, detail string, contentType AnnotationContentType, text string) error {
+		if name != ConfigAnnotationName || detail != "app.yaml" || contentType != JSON {
+			t.Errorf("Unexpected annotation %q %q %v", name, detail, contentType)
+		}
+		var load ConfigLoad
+		err := json.Unmarshal([]byte(text), &load)
+		if err != nil {
+			t.Fatal("Unmarshal:", err)
+		}
+		stored = append(stored, load)
+		return nil
+	}
+
+	first, err := r.annotate("app.yaml", []byte("a: 1\n"), add)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Generation != 1 || !first.Changed || first.Size != 5 || len(first.SHA256) != 64 {
+		t.Errorf("Unexpected first load: %+v", first)
+	}
+
+	same, err := r.annotate("app.yaml", []byte("a: 1\n"), add)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.Generation != 2 || same.Changed || same.SHA256 != first.SHA256 {
+		t.Errorf("Unexpected unchanged load: %+v", same)
+	}
+
+	failing := func(string, string, AnnotationContentType, string) error { return errors.New("failed") }
+	_, err = r.annotate("app.yaml", []byte("a: 2\n"), failing)
+	if err == nil {
+		t.Fatal("Expected annotation failure")
+	}
+
+	changed, err := r.annotate("app.yaml", []byte("a: 2\n"), add)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Generation != 3 || !changed.Changed {
+		t.Errorf("Unexpected changed load: %+v", changed)
+	}
+
+	if len(stored) != 3 || stored[2] != changed {
+		t.Errorf("Stored loads don't match: %+v", stored)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GoroutineLeakName is the annotation name used by <AnnotateGoroutineLeaks>,
+// and GoroutineLeakDetail the detail used by the test context method.
+const (
+	GoroutineLeakName   = "goroutine-leak"
+	GoroutineLeakDetail = "goroutine-leak"
+)
+
+// goroutineLeakPoll is how often leaked goroutines are re-checked during
+// the grace period.
+const goroutineLeakPoll = 10 * time.Millisecond
+
+// A Goroutine describes a goroutine found by <TakeGoroutineSnapshot>.
+type Goroutine struct {
+	ID    uint64
+	State string
+
+	// Stack is the goroutine's entry in the output of runtime.Stack,
+	// including its header line.
+	Stack string
+}
+
+// A GoroutineSnapshot records the goroutines running at a point in time, by ID.
+type GoroutineSnapshot map[uint64]Goroutine
+
+// GoroutineLeakOptions controls the check made by <AnnotateGoroutineLeaks>.
+type GoroutineLeakOptions struct {
+	// Grace is how long to wait for goroutines to exit before they are
+	// considered leaked.
+	Grace time.Duration
+
+	// Ignore lists substrings of the stacks of goroutines which are
+	// expected to outlive the check, such as a package's background
+	// workers.
+	Ignore []string
+}
+
+// TakeGoroutineSnapshot records the goroutines currently running.
+//
+// Take a snapshot before the code under test starts goroutines, and pass
+// it to <AnnotateGoroutineLeaks> once they should all have finished.
+func TakeGoroutineSnapshot() GoroutineSnapshot {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return parseGoroutines(string(buf[:n]))
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// parseGoroutines parses the output of runtime.Stack for all goroutines.
+func parseGoroutines(dump string) GoroutineSnapshot {
+	snapshot := make(GoroutineSnapshot)
+	for _, stack := range strings.Split(dump, "\n\n") {
+		stack = strings.TrimSpace(stack)
+		// The header has the form "goroutine 7 [chan receive, 2 minutes]:".
+		if !strings.HasPrefix(stack, "goroutine ") {
+			continue
+		}
+		header := stack
+		if i := strings.IndexByte(stack, '\n'); i >= 0 {
+			header = stack[:i]
+		}
+		fields := strings.SplitN(strings.TrimPrefix(header, "goroutine "), " ", 2)
+		id, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		g := Goroutine{ID: id, Stack: stack}
+		if len(fields) == 2 {
+			state := strings.TrimSuffix(fields[1], ":")
+			state = strings.TrimSuffix(strings.TrimPrefix(state, "["), "]")
+			g.State = state
+		}
+		snapshot[id] = g
+	}
+	return snapshot
+}
+
+// leakedSince returns the goroutines in snapshot not in baseline or
+// matched by ignore, ordered by ID.
+func (snapshot GoroutineSnapshot) leakedSince(baseline GoroutineSnapshot, ignore []string) []Goroutine {
+	var leaked []Goroutine
+Goroutines:
+	for id, g := range snapshot {
+		if _, ok := baseline[id]; ok {
+			continue
+		}
+		for _, s := range ignore {
+			if strings.Contains(g.Stack, s) {
+				continue Goroutines
+			}
+		}
+		leaked = append(leaked, g)
+	}
+	sort.Slice(leaked, func(i, j int) bool { return leaked[i].ID < leaked[j].ID })
+	return leaked
+}
+
+// findGoroutineLeaks returns the goroutines started since baseline which
+// are still running once the grace period has expired.
+func findGoroutineLeaks(baseline GoroutineSnapshot, opts GoroutineLeakOptions) []Goroutine {
+	deadline := now().Add(opts.Grace)
+	for {
+		leaked := TakeGoroutineSnapshot().leakedSince(baseline, opts.Ignore)
+		if len(leaked) == 0 || !now().Before(deadline) {
+			return leaked
+		}
+		time.Sleep(goroutineLeakPoll)
+	}
+}
+
+// AnnotateGoroutineLeaks adds an annotation for each goroutine started since <baseline> which is still running.
+//
+// Goroutines are given <opts.Grace> to exit. Each leaked goroutine is
+// stored as an annotation with GoroutineLeakName as name, "goroutine <id>"
+// as detail and its stack as unstructured text, so the recording can be
+// replayed to where the goroutine was started and blocked. Call it before
+// stopping recording; the leaked goroutines are returned.
+func AnnotateGoroutineLeaks(baseline GoroutineSnapshot, opts GoroutineLeakOptions) ([]Goroutine, error) {
+	leaked := findGoroutineLeaks(baseline, opts)
+	for _, g := range leaked {
+		err := AnnotationAddText(GoroutineLeakName, fmt.Sprintf("goroutine %d", g.ID), UnstructuredText, g.Stack)
+		if err != nil {
+			return leaked, err
+		}
+	}
+	return leaked, nil
+}
+
+// AnnotateGoroutineLeaks adds an annotation to the test for each goroutine started since <baseline> which is still running.
+//
+// Each leaked goroutine's stack is stored with GoroutineLeakDetail as
+// detail. Call it once the test has finished, usually just before <End>.
+// See <AnnotateGoroutineLeaks> for details.
+func (context *AnnotationTestContext) AnnotateGoroutineLeaks(baseline GoroutineSnapshot, opts GoroutineLeakOptions) ([]Goroutine, error) {
+	if !context.valid {
+		return nil, ErrAnnotationTestContextInvalid
+	}
+
+	leaked := findGoroutineLeaks(baseline, opts)
+	for _, g := range leaked {
+		err := context.AddText(GoroutineLeakDetail, UnstructuredText, g.Stack)
+		if err != nil {
+			return leaked, err
+		}
+	}
+	return leaked, nil
+}
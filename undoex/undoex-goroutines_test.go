@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testStackDump = `goroutine 1 [running]:
+main.main()
+	/src/main.go:10 +0x1d
+
+goroutine 7 [chan receive, 2 minutes]:
+main.worker(0xc000010000)
+	/src/worker.go:20 +0x45
+created by main.main
+	/src/main.go:8 +0x2a
+`
+
+func TestParseGoroutines(t *testing.T) {
+	snapshot := parseGoroutines(testStackDump)
+	if len(snapshot) != 2 {
+		t.Fatalf("Parsed %d goroutines, expected 2", len(snapshot))
+	}
+	g := snapshot[7]
+	if g.State != "chan receive, 2 minutes" {
+		t.Errorf("State doesn't match: %q", g.State)
+	}
+	if !strings.HasPrefix(g.Stack, "goroutine 7 ") || !strings.HasSuffix(g.Stack, "+0x2a") {
+		t.Errorf("Stack doesn't match: %q", g.Stack)
+	}
+}
+
+func TestFindGoroutineLeaks(t *testing.T) {
+	baseline := TakeGoroutineSnapshot()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() { <-stop }()
+
+	exited := make(chan struct{})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(exited)
+	}()
+
+	leaked := findGoroutineLeaks(baseline, GoroutineLeakOptions{Grace: 200 * time.Millisecond})
+	if len(leaked) != 1 {
+		t.Fatalf("Found %d leaked goroutines, expected 1: %v", len(leaked), leaked)
+	}
+	if !strings.Contains(leaked[0].Stack, "TestFindGoroutineLeaks") {
+		t.Errorf("Unexpected leaked goroutine: %s", leaked[0].Stack)
+	}
+	select {
+	case <-exited:
+	default:
+		t.Error("Grace period didn't wait for exiting goroutine")
+	}
+
+	leaked = findGoroutineLeaks(baseline, GoroutineLeakOptions{Ignore: []string{"TestFindGoroutineLeaks"}})
+	if len(leaked) != 0 {
+		t.Errorf("Ignored goroutine reported: %v", leaked)
+	}
+}
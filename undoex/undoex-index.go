@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// An IndexEntry describes an annotation added to the recording.
+//
+// Entries are written to the annotation index set by <SetIndex>, so that
+// the annotation timelines of different runs can be compared without
+// loading their recordings; see the undoexdiff command.
+type IndexEntry struct {
+	// Seq is the position of the annotation in the index, from 1.
+	Seq int64 `json:"seq"`
+
+	// Time is when the annotation was added, from the package Clock.
+	Time time.Time `json:"time"`
+
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+
+	Payload     AnnotationPayload     `json:"payload"`
+	ContentType AnnotationContentType `json:"content_type,omitempty"`
+
+	// Text is the content of a text annotation, and Value that of an int
+	// annotation. Raw data is not stored, only its Size. Size is also the
+	// length of Text.
+	Text  string `json:"text,omitempty"`
+	Value int64  `json:"value,omitempty"`
+	Size  int    `json:"size,omitempty"`
+}
+
+var index struct {
+	sync.Mutex
+	encoder *json.Encoder
+	seq     int64
+}
+
+// SetIndex sets a writer to which an index of the annotations added is written.
+//
+// Each annotation added successfully through this package, including by
+// test and suite contexts, is written as a line of JSON encoding an
+// <IndexEntry>. Test annotations are indexed under the test's base
+// name, without any run suffix. Write errors are ignored, as the index is an aid to
+// analysis and must not affect the program. Passing nil stops indexing.
+func SetIndex(w io.Writer) {
+	index.Lock()
+	defer index.Unlock()
+	index.encoder = nil
+	if w != nil {
+		index.encoder = json.NewEncoder(w)
+	}
+	index.seq = 0
+}
+
+// indexAdd writes an entry to the annotation index, if one is set.
+func indexAdd(entry IndexEntry) {
+	index.Lock()
+	defer index.Unlock()
+	if index.encoder == nil {
+		return
+	}
+	index.seq++
+	entry.Seq = index.seq
+	entry.Time = now()
+	index.encoder.Encode(entry)
+}
+
+func indexRawData(name, detail string, rawData []byte) {
+	indexAdd(IndexEntry{Name: name, Detail: detail, Payload: PayloadRawData, Size: len(rawData)})
+}
+
+func indexText(name, detail string, contentType AnnotationContentType, text string) {
+	indexAdd(IndexEntry{Name: name, Detail: detail, Payload: PayloadText, ContentType: contentType, Text: text, Size: len(text)})
+}
+
+func indexInt(name, detail string, value int64) {
+	indexAdd(IndexEntry{Name: name, Detail: detail, Payload: PayloadInt, Value: value})
+}
+
+// ReadIndex reads an annotation index written by <SetIndex>.
+func ReadIndex(r io.Reader) ([]IndexEntry, error) {
+	var entries []IndexEntry
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var entry IndexEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+}
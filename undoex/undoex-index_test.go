@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndex(t *testing.T) {
+	SetClock(&fakeClock{time.Unix(1700000000, 0)})
+	defer SetClock(nil)
+
+	var buf bytes.Buffer
+	SetIndex(&buf)
+	defer SetIndex(nil)
+
+	indexRawData("blob", "", []byte{0, 1, 2})
+	indexText("order", "placed", JSON, `{"id":1}`)
+	indexInt("counter", "", 42)
+
+	entries, err := ReadIndex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []IndexEntry{
+		{Seq: 1, Name: "blob", Payload: PayloadRawData, Size: 3},
+		{Seq: 2, Name: "order", Detail: "placed", Payload: PayloadText, ContentType: JSON, Text: `{"id":1}`, Size: 8},
+		{Seq: 3, Name: "counter", Payload: PayloadInt, Value: 42},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if !entry.Time.Equal(time.Unix(1700000000, 0)) {
+			t.Errorf("entry %d time = %v", i, entry.Time)
+		}
+		entry.Time = time.Time{}
+		if entry != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+}
+
+func TestIndexUnset(t *testing.T) {
+	var buf bytes.Buffer
+	SetIndex(&buf)
+	SetIndex(nil)
+	indexInt("counter", "", 1)
+	if buf.Len() != 0 {
+		t.Errorf("index written after SetIndex(nil): %q", buf.String())
+	}
+}
+
+func TestReadIndexInvalid(t *testing.T) {
+	_, err := ReadIndex(strings.NewReader("{\"seq\":1}\nnot json\n"))
+	if err == nil {
+		t.Error("ReadIndex accepted invalid JSON")
+	}
+}
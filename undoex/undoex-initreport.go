@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// procMapsPath is the file listing the mappings of the process.
+var procMapsPath = "/proc/self/maps"
+
+// InitInfo describes how the annotation bindings initialized in the running program.
+//
+// It is intended to be attached to support requests as JSON, alongside
+// the report from undolr.InitReport.
+type InitInfo struct {
+	// Library is the path of the annotations shared library mapped by
+	// the process. It is empty if the library is linked statically, or
+	// not at all.
+	Library string `json:"library,omitempty"`
+
+	// Stub is true if no library functions are available, so annotations
+	// cannot be added.
+	Stub bool `json:"stub"`
+
+	// Loader is how the library is found: "linked" when building, or
+	// "dlopen" when built with the undo_dlopen tag.
+	Loader string `json:"loader"`
+
+	// LoadError is why the library could not be loaded by dlopen.
+	LoadError string `json:"load_error,omitempty"`
+
+	// Missing lists the library functions not available.
+	Missing []string `json:"missing,omitempty"`
+
+	// GoVersion is the Go release the program was built with.
+	GoVersion string `json:"go_version"`
+
+	// BuildTags lists the tags the program was built with. They are
+	// only recorded by Go 1.18 and later.
+	BuildTags []string `json:"build_tags,omitempty"`
+}
+
+// InitReport describes how the annotation bindings initialized in the running program.
+func InitReport() *InitInfo {
+	r := &InitInfo{
+		Library:   mappedLibrary("libundoex"),
+		Loader:    libLoader,
+		GoVersion: runtime.Version(),
+	}
+	libInit()
+	if libLoadErr != nil {
+		r.LoadError = libLoadErr.Error()
+	}
+	for fn := libFunction(0); fn < numLibFunctions; fn++ {
+		if require(fn) != nil {
+			r.Missing = append(r.Missing, fn.String())
+		}
+	}
+	r.Stub = !Available()
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		r.BuildTags = buildTags(info)
+	}
+	return r
+}
+
+// mappedLibrary returns the path of the first shared library mapped by
+// the process whose name starts with prefix, or "" if there is none.
+func mappedLibrary(prefix string) string {
+	file, err := os.Open(procMapsPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		path := fields[5]
+		if strings.HasPrefix(filepath.Base(path), prefix) {
+			return path
+		}
+	}
+	return ""
+}
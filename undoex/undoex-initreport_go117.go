@@ -0,0 +1,21 @@
+//go:build !go1.18
+// +build !go1.18
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"runtime/debug"
+)
+
+// buildTags returns the tags recorded in the build information, which are
+// only available from Go 1.18.
+func buildTags(info *debug.BuildInfo) []string {
+	return nil
+}
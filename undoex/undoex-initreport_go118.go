@@ -0,0 +1,26 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// buildTags returns the tags recorded in the build information.
+func buildTags(info *debug.BuildInfo) []string {
+	for _, setting := range info.Settings {
+		if setting.Key == "-tags" && setting.Value != "" {
+			return strings.Split(setting.Value, ",")
+		}
+	}
+	return nil
+}
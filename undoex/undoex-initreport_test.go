@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestInitReport(t *testing.T) {
+	r := InitReport()
+	if r.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", r.GoVersion, runtime.Version())
+	}
+	if r.Stub != (len(r.Missing) == len(libFunctionNames)) {
+		t.Errorf("Stub = %v with %d of %d functions missing", r.Stub, len(r.Missing), len(libFunctionNames))
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+	var decoded InitInfo
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if !reflect.DeepEqual(&decoded, r) {
+		t.Errorf("decoded report = %+v, want %+v", decoded, *r)
+	}
+}
+
+func TestMappedLibrary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undoex-initreport")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	maps := filepath.Join(dir, "maps")
+	err = ioutil.WriteFile(maps, []byte(
+		"7f0000000000-7f0000001000 r-xp 00000000 08:01 1 /usr/lib/libc.so.6\n"+
+			"7f0000002000-7f0000003000 r-xp 00000000 08:01 2 /opt/undo/libundoex_pic_x64.so\n"), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	oldMaps := procMapsPath
+	procMapsPath = maps
+	defer func() { procMapsPath = oldMaps }()
+
+	if got := mappedLibrary("libundoex"); got != "/opt/undo/libundoex_pic_x64.so" {
+		t.Errorf("mappedLibrary = %q, want the annotations library", got)
+	}
+	if got := mappedLibrary("libundolr"); got != "" {
+		t.Errorf("mappedLibrary for a missing library = %q, want empty", got)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxAnnotationNameLength is the longest annotation name or detail accepted, in bytes.
+const MaxAnnotationNameLength = 4096
+
+// MaxAnnotationDataLength is the largest text or raw data payload accepted, in bytes.
+const MaxAnnotationDataLength = readerMaxLimit
+
+// A set of reasons for rejecting annotation inputs, wrapped by *InvalidInputError.
+var (
+	ErrInputNul         = errors.New("contains '\\0'")
+	ErrInputInvalidUTF8 = errors.New("is not valid UTF-8")
+	ErrInputTooLong     = errors.New("exceeds maximum length")
+)
+
+// An InvalidInputError reports an input rejected before being passed to the annotation library.
+//
+// Strings are passed to the library '\0' terminated, so one containing a
+// '\0' would be silently truncated. Names and details must also be valid
+// UTF-8 so they can be displayed by the debugger. The reason is one of
+// ErrInputNul, ErrInputInvalidUTF8 or ErrInputTooLong, and can be matched
+// with errors.Is.
+type InvalidInputError struct {
+	Field  string
+	Reason error
+}
+
+func (e *InvalidInputError) Error() string {
+	return fmt.Sprintf("annotation %s %v", e.Field, e.Reason)
+}
+
+// Unwrap returns the reason the input was rejected.
+func (e *InvalidInputError) Unwrap() error {
+	return e.Reason
+}
+
+// checkName checks an annotation name or detail, identified by field.
+func checkName(field, s string) error {
+	if len(s) > MaxAnnotationNameLength {
+		return &InvalidInputError{field, ErrInputTooLong}
+	}
+	if strings.IndexByte(s, 0) >= 0 {
+		return &InvalidInputError{field, ErrInputNul}
+	}
+	if !utf8.ValidString(s) {
+		return &InvalidInputError{field, ErrInputInvalidUTF8}
+	}
+	return nil
+}
+
+// checkNames checks the name and detail of an annotation.
+func checkNames(name, detail string) error {
+	err := checkName("name", name)
+	if err != nil {
+		return err
+	}
+	return checkName("detail", detail)
+}
+
+// checkText checks textual content, which may be in any encoding but is
+// passed to the library '\0' terminated.
+func checkText(text string) error {
+	if len(text) > MaxAnnotationDataLength {
+		return &InvalidInputError{"text", ErrInputTooLong}
+	}
+	if strings.IndexByte(text, 0) >= 0 {
+		return &InvalidInputError{"text", ErrInputNul}
+	}
+	return nil
+}
+
+// checkRawData checks raw data, which may contain any bytes.
+func checkRawData(rawData []byte) error {
+	if len(rawData) > MaxAnnotationDataLength {
+		return &InvalidInputError{"raw data", ErrInputTooLong}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzCheckNames(f *testing.F) {
+	f.Add("testname", "testdetail")
+	f.Add("", "")
+	f.Add("a\x00b", "detail")
+	f.Add("name", "\xff\xfe")
+	f.Add(strings.Repeat("a", MaxAnnotationNameLength+1), "")
+
+	f.Fuzz(func(t *testing.T, name, detail string) {
+		err := checkNames(name, detail)
+		valid := func(s string) bool {
+			return len(s) <= MaxAnnotationNameLength && strings.IndexByte(s, 0) < 0 && utf8.ValidString(s)
+		}
+		if valid(name) && valid(detail) {
+			if err != nil {
+				t.Fatalf("Unexpected error for %q, %q: %v", name, detail, err)
+			}
+			return
+		}
+		var inputErr *InvalidInputError
+		if !errors.As(err, &inputErr) {
+			t.Fatalf("Expected InvalidInputError for %q, %q, got %v", name, detail, err)
+		}
+		if !errors.Is(err, ErrInputNul) && !errors.Is(err, ErrInputInvalidUTF8) && !errors.Is(err, ErrInputTooLong) {
+			t.Fatalf("Unexpected reason for %q, %q: %v", name, detail, err)
+		}
+	})
+}
+
+func FuzzCheckPayload(f *testing.F) {
+	f.Add([]byte("key1: value1"))
+	f.Add([]byte{})
+	f.Add([]byte{0, 0xff, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := checkRawData(data); err != nil {
+			t.Fatalf("Unexpected error for raw data %q: %v", data, err)
+		}
+		err := checkText(string(data))
+		hasNul := strings.IndexByte(string(data), 0) >= 0
+		if hasNul != errors.Is(err, ErrInputNul) {
+			t.Fatalf("Unexpected result for text %q: %v", data, err)
+		}
+	})
+}
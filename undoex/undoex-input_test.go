@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckName(t *testing.T) {
+	for _, name := range []string{"", "testname", "café", strings.Repeat("a", MaxAnnotationNameLength)} {
+		if err := checkName("name", name); err != nil {
+			t.Errorf("Unexpected error for %q: %v", name, err)
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		reason error
+	}{
+		{"a\x00b", ErrInputNul},
+		{"\xff\xfe", ErrInputInvalidUTF8},
+		{strings.Repeat("a", MaxAnnotationNameLength+1), ErrInputTooLong},
+	} {
+		err := checkName("name", test.name)
+		if !errors.Is(err, test.reason) {
+			t.Errorf("Expected %v for %.20q, got %v", test.reason, test.name, err)
+		}
+		var inputErr *InvalidInputError
+		if !errors.As(err, &inputErr) || inputErr.Field != "name" {
+			t.Errorf("Expected InvalidInputError for name, got %v", err)
+		}
+	}
+}
+
+func TestCheckText(t *testing.T) {
+	if err := checkText("\xff not UTF-8 \xfe"); err != nil {
+		t.Error("Unexpected error for non UTF-8 text:", err)
+	}
+	if err := checkText("a\x00b"); !errors.Is(err, ErrInputNul) {
+		t.Error("Expected ErrInputNul, got", err)
+	}
+	if err := checkRawData([]byte{0, 0xff, 0}); err != nil {
+		t.Error("Unexpected error for raw data:", err)
+	}
+}
+
+func TestAnnotationInvalidInputRejected(t *testing.T) {
+	if err := AnnotationAddRawData("a\x00b", "", nil); !errors.Is(err, ErrInputNul) {
+		t.Error("AnnotationAddRawData: expected ErrInputNul, got", err)
+	}
+	if err := AnnotationAddText("testname", "\xff", JSON, "{}"); !errors.Is(err, ErrInputInvalidUTF8) {
+		t.Error("AnnotationAddText: expected ErrInputInvalidUTF8, got", err)
+	}
+	if err := AnnotationAddText("testname", "testdetail", JSON, "{}\x00"); !errors.Is(err, ErrInputNul) {
+		t.Error("AnnotationAddText: expected ErrInputNul, got", err)
+	}
+	if err := AnnotationAddInt(strings.Repeat("a", MaxAnnotationNameLength+1), "", 42); !errors.Is(err, ErrInputTooLong) {
+		t.Error("AnnotationAddInt: expected ErrInputTooLong, got", err)
+	}
+	if err := AnnotationAddReader("a\x00b", "", UnstructuredText, strings.NewReader("text"), 4); !errors.Is(err, ErrInputNul) {
+		t.Error("AnnotationAddReader: expected ErrInputNul, got", err)
+	}
+	if _, err := AnnotationTestNew("a\x00b", false); !errors.Is(err, ErrInputNul) {
+		t.Error("AnnotationTestNew: expected ErrInputNul, got", err)
+	}
+
+	context := &AnnotationTestContext{valid: true}
+	if err := context.AddInt("a\x00b", 42); !errors.Is(err, ErrInputNul) {
+		t.Error("AddInt: expected ErrInputNul, got", err)
+	}
+	if err := context.AddText("testdetail", UnstructuredText, "a\x00b"); !errors.Is(err, ErrInputNul) {
+		t.Error("AddText: expected ErrInputNul, got", err)
+	}
+	if err := context.SetOutput(UnstructuredText, "a\x00b"); !errors.Is(err, ErrInputNul) {
+		t.Error("SetOutput: expected ErrInputNul, got", err)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// An AnnotationLevel is the verbosity of annotations added by an Annotator.
+type AnnotationLevel int
+
+// Values for AnnotationLevel, from most to least verbose.
+const (
+	LevelDebug AnnotationLevel = iota - 1
+	LevelInfo
+	LevelError
+)
+
+func (level AnnotationLevel) String() string {
+	switch level {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("AnnotationLevel(%d)", int(level))
+	}
+}
+
+// ErrAnnotationLevelInvalid indicates an annotation level name was not recognised.
+var ErrAnnotationLevelInvalid = errors.New("not a valid annotation level")
+
+// MarshalText returns the name of the level.
+func (level AnnotationLevel) MarshalText() ([]byte, error) {
+	return []byte(level.String()), nil
+}
+
+// UnmarshalText parses a level name as ParseAnnotationLevel.
+func (level *AnnotationLevel) UnmarshalText(text []byte) error {
+	parsed, err := ParseAnnotationLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*level = parsed
+	return nil
+}
+
+// ParseAnnotationLevel returns the level named "debug", "info" or "error", ignoring case.
+func ParseAnnotationLevel(name string) (AnnotationLevel, error) {
+	for _, level := range []AnnotationLevel{LevelDebug, LevelInfo, LevelError} {
+		if strings.EqualFold(name, level.String()) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("%q: %w", name, ErrAnnotationLevelInvalid)
+}
+
+var levelsLock sync.Mutex
+var levels = make(map[string]AnnotationLevel)
+
+// SetAnnotationLevel sets the minimum level of annotations added by a component's Annotators.
+//
+// Annotations below the level are discarded. The level applies to the
+// component's subcomponents unless they have a level of their own, and
+// the empty component sets the default for all components, which is
+// otherwise LevelInfo. Levels may be changed at any time, for instance
+// through the undohttp control endpoints, so that annotation volume can be
+// raised during an investigation and lowered afterwards.
+func SetAnnotationLevel(component string, level AnnotationLevel) {
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+	levels[component] = level
+}
+
+// ClearAnnotationLevel removes a level set by SetAnnotationLevel, so the component inherits its parent's level.
+func ClearAnnotationLevel(component string) {
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+	delete(levels, component)
+}
+
+// AnnotationLevels returns the levels set by SetAnnotationLevel, by component.
+func AnnotationLevels() map[string]AnnotationLevel {
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+	copied := make(map[string]AnnotationLevel, len(levels))
+	for component, level := range levels {
+		copied[component] = level
+	}
+	return copied
+}
+
+// componentLevel returns the minimum level for component, from the
+// nearest of it and its parents with a level set.
+func componentLevel(component string) AnnotationLevel {
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+	for {
+		if level, ok := levels[component]; ok {
+			return level
+		}
+		if component == "" {
+			return LevelInfo
+		}
+		i := strings.LastIndexByte(component, '/')
+		if i < 0 {
+			component = ""
+		} else {
+			component = component[:i]
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseAnnotationLevel(t *testing.T) {
+	for _, level := range []AnnotationLevel{LevelDebug, LevelInfo, LevelError} {
+		parsed, err := ParseAnnotationLevel(level.String())
+		if err != nil || parsed != level {
+			t.Errorf("ParseAnnotationLevel(%q) = %v, %v", level.String(), parsed, err)
+		}
+	}
+	if parsed, err := ParseAnnotationLevel("ERROR"); err != nil || parsed != LevelError {
+		t.Errorf("ParseAnnotationLevel(\"ERROR\") = %v, %v", parsed, err)
+	}
+	if _, err := ParseAnnotationLevel("verbose"); !errors.Is(err, ErrAnnotationLevelInvalid) {
+		t.Errorf("Expected ErrAnnotationLevelInvalid, got %v", err)
+	}
+
+	var levels map[string]AnnotationLevel
+	err := json.Unmarshal([]byte(`{"storage":"debug"}`), &levels)
+	if err != nil || levels["storage"] != LevelDebug {
+		t.Errorf("Unmarshal: %v, %v", levels, err)
+	}
+}
+
+func TestAnnotatorEnabled(t *testing.T) {
+	defer ClearAnnotationLevel("")
+	defer ClearAnnotationLevel("storage")
+	defer ClearAnnotationLevel("storage/flush")
+
+	storage := Named("storage")
+	flush := storage.Named("flush")
+	other := Named("other")
+
+	check := func(annotator *Annotator, level AnnotationLevel, expected bool) {
+		t.Helper()
+		if enabled := annotator.WithLevel(level).Enabled(); enabled != expected {
+			t.Errorf("%s at %v: enabled %v, expected %v", annotator.Component(), level, enabled, expected)
+		}
+	}
+
+	check(storage, LevelDebug, false)
+	check(storage, LevelInfo, true)
+
+	SetAnnotationLevel("storage", LevelDebug)
+	check(storage, LevelDebug, true)
+	check(flush, LevelDebug, true)
+	check(other, LevelDebug, false)
+
+	SetAnnotationLevel("storage/flush", LevelError)
+	check(flush, LevelInfo, false)
+	check(flush, LevelError, true)
+	check(storage, LevelDebug, true)
+
+	SetAnnotationLevel("", LevelError)
+	check(other, LevelInfo, false)
+
+	ClearAnnotationLevel("storage")
+	check(storage, LevelInfo, false)
+
+	// Discarded annotations are not passed to the library.
+	if err := flush.WithLevel(LevelDebug).AddInt("bytes", "", 1); err != nil {
+		t.Error("Discarded annotation returned", err)
+	}
+}
@@ -0,0 +1,106 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"unsafe"
+)
+
+// These stubs stand in for the library calls in undoex-libcalls.go where
+// the annotations library cannot run. The library is never available, so
+// they are only called when annotations are to be dropped, see
+// SetUnsupportedPlatformNoOp, and succeed without doing anything.
+
+// platformSupported is false as the annotations library does not run on
+// this platform, or cannot be called without cgo.
+const platformSupported = false
+
+// libLoader describes how the library was found, for InitReport.
+const libLoader = "stub"
+
+// loadLibrary always fails, as there is no library for this platform.
+func loadLibrary() error {
+	return ErrUnsupportedPlatform
+}
+
+func libFunctionPresent(fn libFunction) bool {
+	return false
+}
+
+// libTestAnnotation stands in for a test annotation context of the
+// library.
+type libTestAnnotation struct{}
+
+// libAlloc and libRealloc allocate Go memory, so AnnotationAddReader
+// still checks the content it reads.
+func libAlloc(size int64) unsafe.Pointer {
+	return unsafe.Pointer(&make([]byte, size)[0])
+}
+
+func libRealloc(p unsafe.Pointer, oldSize, size int64) unsafe.Pointer {
+	newP := libAlloc(size)
+	copy((*[1 << 30]byte)(newP)[:size], (*[1 << 30]byte)(p)[:oldSize])
+	return newP
+}
+
+func libFree(p unsafe.Pointer) {
+}
+
+func libAnnotationAddRawData(name, detail string, rawData []byte) (int, error) {
+	return 0, nil
+}
+
+func libAnnotationAddText(name, detail string, contentType AnnotationContentType, text string) (int, error) {
+	return 0, nil
+}
+
+func libAnnotationAddTextBuffer(name, detail string, contentType AnnotationContentType, text unsafe.Pointer) (int, error) {
+	return 0, nil
+}
+
+func libAnnotationAddInt(name, detail string, value int64) (int, error) {
+	return 0, nil
+}
+
+func libTestAnnotationNew(name string, addRunSuffix bool) (ctx libTestAnnotation, ok bool, err error) {
+	return libTestAnnotation{}, true, nil
+}
+
+func libTestAnnotationFree(ctx libTestAnnotation) {
+}
+
+func libTestAnnotationStart(ctx libTestAnnotation) (int, error) {
+	return 0, nil
+}
+
+func libTestAnnotationEnd(ctx libTestAnnotation) (int, error) {
+	return 0, nil
+}
+
+func libTestAnnotationSetResult(ctx libTestAnnotation, result AnnotationTestResult) (int, error) {
+	return 0, nil
+}
+
+func libTestAnnotationSetOutput(ctx libTestAnnotation, contentType AnnotationContentType, output string) (int, error) {
+	return 0, nil
+}
+
+func libTestAnnotationAddRawData(ctx libTestAnnotation, detail string, rawData []byte) (int, error) {
+	return 0, nil
+}
+
+func libTestAnnotationAddText(ctx libTestAnnotation, detail string, contentType AnnotationContentType, text string) (int, error) {
+	return 0, nil
+}
+
+func libTestAnnotationAddInt(ctx libTestAnnotation, detail string, value int64) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,58 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUnsupportedPlatform(t *testing.T) {
+	err := AnnotationAddInt("undoex-test", "", 1)
+	if !errors.Is(err, ErrUnsupportedPlatform) || !errors.Is(err, ErrNotAvailable) {
+		t.Fatal("Expected AnnotationAddInt() to fail with ErrUnsupportedPlatform:", err)
+	}
+	if report := InitReport(); !report.Stub || report.Loader != "stub" {
+		t.Fatal("Unexpected report on an unsupported platform:", report)
+	}
+}
+
+func TestUnsupportedPlatformNoOp(t *testing.T) {
+	SetUnsupportedPlatformNoOp(true)
+	defer SetUnsupportedPlatformNoOp(false)
+
+	if err := AnnotationAddInt("undoex-test", "", 1); err != nil {
+		t.Fatal("AnnotationAddInt:", err)
+	}
+	text := strings.Repeat("x", readerChunk+1)
+	if err := AnnotationAddReader("undoex-test", "", UnstructuredText, strings.NewReader(text), int64(len(text))); err != nil {
+		t.Fatal("AnnotationAddReader:", err)
+	}
+	if err := AnnotationAddReader("undoex-test", "", UnstructuredText, strings.NewReader(text), 10); err != ErrAnnotationReaderLimit {
+		t.Fatal("Expected AnnotationAddReader() to fail with ErrAnnotationReaderLimit:", err)
+	}
+
+	context, err := AnnotationTestNew("undoex-test", false)
+	if err != nil {
+		t.Fatal("AnnotationTestNew:", err)
+	}
+	defer context.Free()
+	if err := context.Start(); err != nil {
+		t.Fatal("Start:", err)
+	}
+	if err := context.SetResult(Success); err != nil {
+		t.Fatal("SetResult:", err)
+	}
+	if err := context.End(); err != nil {
+		t.Fatal("End:", err)
+	}
+}
@@ -0,0 +1,171 @@
+//go:build linux && cgo
+// +build linux,cgo
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+// #include <undoex-annotations.h>
+// #include <undoex-test-annotations.h>
+// #include <stdlib.h>
+// #include <errno.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// The functions below wrap the library calls, so the rest of the package
+// is independent of cgo and builds on other platforms against the stubs
+// in undoex-libcalls-stub.go. Each returns the library's result code, and
+// errno as an error as cgo reports it. An empty detail is passed as NULL.
+// Callers check for the function with require first.
+
+// platformSupported is true as the annotations library runs on this
+// platform.
+const platformSupported = true
+
+// libTestAnnotation is a test annotation context of the library.
+type libTestAnnotation = *C.undoex_test_annotation_t
+
+// cDetailString returns detail as a C string, or NULL if it is empty.
+// The result must be freed with C.free.
+func cDetailString(detail string) *C.char {
+	if len(detail) == 0 {
+		return nil
+	}
+	return C.CString(detail)
+}
+
+// libAlloc, libRealloc and libFree manage the memory AnnotationAddReader
+// reads into, which is passed to libAnnotationAddTextBuffer.
+func libAlloc(size int64) unsafe.Pointer {
+	return C.malloc(C.size_t(size))
+}
+
+func libRealloc(p unsafe.Pointer, oldSize, size int64) unsafe.Pointer {
+	return C.realloc(p, C.size_t(size))
+}
+
+func libFree(p unsafe.Pointer) {
+	C.free(p)
+}
+
+func libAnnotationAddRawData(name, detail string, rawData []byte) (int, error) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cDetail := cDetailString(detail)
+	defer C.free(unsafe.Pointer(cDetail))
+
+	cRawData := (*C.uint8_t)(C.CBytes(rawData))
+	defer C.free(unsafe.Pointer(cRawData))
+
+	rc, err := C.undoex_annotation_add_raw_data(cName, cDetail, cRawData, C.size_t(len(rawData)))
+	return int(rc), err
+}
+
+func libAnnotationAddText(name, detail string, contentType AnnotationContentType, text string) (int, error) {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+	return libAnnotationAddTextBuffer(name, detail, contentType, unsafe.Pointer(cText))
+}
+
+// libAnnotationAddTextBuffer adds a text annotation from text, a '\0'
+// terminated string allocated by libAlloc.
+func libAnnotationAddTextBuffer(name, detail string, contentType AnnotationContentType, text unsafe.Pointer) (int, error) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cDetail := cDetailString(detail)
+	defer C.free(unsafe.Pointer(cDetail))
+
+	rc, err := C.undoex_annotation_add_text(cName, cDetail,
+		(C.undoex_annotation_content_type_t)(contentType), (*C.char)(text))
+	return int(rc), err
+}
+
+func libAnnotationAddInt(name, detail string, value int64) (int, error) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cDetail := cDetailString(detail)
+	defer C.free(unsafe.Pointer(cDetail))
+
+	rc, err := C.undoex_annotation_add_int(cName, cDetail, (C.int64_t)(value))
+	return int(rc), err
+}
+
+// libTestAnnotationNew creates a test annotation context, with ok false
+// if it failed.
+func libTestAnnotationNew(name string, addRunSuffix bool) (ctx libTestAnnotation, ok bool, err error) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	ctx, err = C.undoex_test_annotation_new(cName, (C.bool)(addRunSuffix))
+	return ctx, ctx != nil, err
+}
+
+func libTestAnnotationFree(ctx libTestAnnotation) {
+	C.undoex_test_annotation_free(ctx)
+}
+
+func libTestAnnotationStart(ctx libTestAnnotation) (int, error) {
+	rc, err := C.undoex_test_annotation_start(ctx)
+	return int(rc), err
+}
+
+func libTestAnnotationEnd(ctx libTestAnnotation) (int, error) {
+	rc, err := C.undoex_test_annotation_end(ctx)
+	return int(rc), err
+}
+
+func libTestAnnotationSetResult(ctx libTestAnnotation, result AnnotationTestResult) (int, error) {
+	rc, err := C.undoex_test_annotation_set_result(ctx, (C.undoex_test_result_t)(result))
+	return int(rc), err
+}
+
+func libTestAnnotationSetOutput(ctx libTestAnnotation, contentType AnnotationContentType, output string) (int, error) {
+	cOutput := C.CString(output)
+	defer C.free(unsafe.Pointer(cOutput))
+
+	rc, err := C.undoex_test_annotation_set_output(ctx,
+		(C.undoex_annotation_content_type_t)(contentType), cOutput)
+	return int(rc), err
+}
+
+// libTestAnnotationAddRawData adds a raw data annotation, passing NULL for
+// empty data.
+func libTestAnnotationAddRawData(ctx libTestAnnotation, detail string, rawData []byte) (int, error) {
+	cDetail := C.CString(detail)
+	defer C.free(unsafe.Pointer(cDetail))
+
+	var cRawData *C.uint8_t
+	if len(rawData) > 0 {
+		cRawData = (*C.uint8_t)(C.CBytes(rawData))
+		defer C.free(unsafe.Pointer(cRawData))
+	}
+
+	rc, err := C.undoex_test_annotation_add_raw_data(ctx, cDetail, cRawData, C.size_t(len(rawData)))
+	return int(rc), err
+}
+
+func libTestAnnotationAddText(ctx libTestAnnotation, detail string, contentType AnnotationContentType, text string) (int, error) {
+	cDetail := C.CString(detail)
+	defer C.free(unsafe.Pointer(cDetail))
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	rc, err := C.undoex_test_annotation_add_text(ctx, cDetail,
+		(C.undoex_annotation_content_type_t)(contentType), cText)
+	return int(rc), err
+}
+
+func libTestAnnotationAddInt(ctx libTestAnnotation, detail string, value int64) (int, error) {
+	cDetail := C.CString(detail)
+	defer C.free(unsafe.Pointer(cDetail))
+
+	rc, err := C.undoex_test_annotation_add_int(ctx, cDetail, (C.int64_t)(value))
+	return int(rc), err
+}
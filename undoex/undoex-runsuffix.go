@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"sync"
+
+	"go.undo.io/bindings/internal/uuid"
+)
+
+var runSuffixLock sync.Mutex
+var runSuffixFunc func() (string, error)
+
+// SetRunSuffixFunc sets the function generating the suffix added to test names by AnnotationTestNew.
+//
+// By default the suffix is generated by <UUIDv7RunSuffix>, so runs sort
+// by the time they were created, as undolr's default IDs do. Setting a
+// function allows test runs to follow an organization's own numbering,
+// for instance the IDs generated by undolr:
+//
+//	undoex.SetRunSuffixFunc(func() (string, error) {
+//		return undolr.NewID(undolr.IDTestRun)
+//	})
+//
+// The suffix is appended to the test name after a '-'. Passing nil
+// restores the default.
+func SetRunSuffixFunc(f func() (string, error)) {
+	runSuffixLock.Lock()
+	defer runSuffixLock.Unlock()
+	runSuffixFunc = f
+}
+
+// testName returns the name of a test to pass to the library, with a run
+// suffix added if addRunSuffix is set.
+func testName(baseName string, addRunSuffix bool) (string, error) {
+	if !addRunSuffix {
+		return baseName, nil
+	}
+
+	runSuffixLock.Lock()
+	f := runSuffixFunc
+	runSuffixLock.Unlock()
+	if f == nil {
+		f = UUIDv7RunSuffix
+	}
+
+	suffix, err := f()
+	if err != nil {
+		return "", err
+	}
+	name := baseName + "-" + suffix
+	err = checkName("name", name)
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// UUIDv7RunSuffix returns a time-ordered UUID, as described in RFC 9562, for use as a run suffix.
+//
+// It is the default run suffix function. The time is that of the package
+// clock, see <SetClock>.
+func UUIDv7RunSuffix() (string, error) {
+	return uuid.NewV7(now())
+}
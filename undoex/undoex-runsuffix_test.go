@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTestName(t *testing.T) {
+	defer SetRunSuffixFunc(nil)
+
+	name, err := testName("test", true)
+	if err != nil || !uuidv7Pattern.MatchString(strings.TrimPrefix(name, "test-")) {
+		t.Errorf("Default: %q, %v", name, err)
+	}
+
+	SetRunSuffixFunc(func() (string, error) { return "INC-42", nil })
+	name, err = testName("test", true)
+	if err != nil || name != "test-INC-42" {
+		t.Errorf("With suffix: %q, %v", name, err)
+	}
+
+	name, err = testName("test", false)
+	if err != nil || name != "test" {
+		t.Errorf("Without suffix: %q, %v", name, err)
+	}
+
+	SetRunSuffixFunc(func() (string, error) { return "bad\x00", nil })
+	_, err = testName("test", true)
+	if !errors.Is(err, ErrInputNul) {
+		t.Errorf("Expected ErrInputNul, got %v", err)
+	}
+
+	failed := errors.New("no incident")
+	SetRunSuffixFunc(func() (string, error) { return "", failed })
+	_, err = testName("test", true)
+	if err != failed {
+		t.Errorf("Expected %v, got %v", failed, err)
+	}
+}
+
+var uuidv7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestUUIDv7RunSuffix(t *testing.T) {
+	SetClock(&fakeClock{time.Unix(0, 0x0123456789ab*1e6)})
+	defer SetClock(nil)
+
+	suffix, err := UUIDv7RunSuffix()
+	if err != nil {
+		t.Fatal("UUIDv7RunSuffix:", err)
+	}
+	if !uuidv7Pattern.MatchString(suffix) || !strings.HasPrefix(suffix, "01234567-89ab-7") {
+		t.Fatalf("Unexpected suffix %q", suffix)
+	}
+}
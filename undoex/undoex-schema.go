@@ -0,0 +1,285 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// An AnnotationPayload identifies the kind of data stored by an annotation.
+type AnnotationPayload int
+
+// Payload values for AnnotationPayload
+const (
+	PayloadRawData AnnotationPayload = iota
+	PayloadText
+	PayloadInt
+)
+
+func (payload AnnotationPayload) String() string {
+	switch payload {
+	case PayloadRawData:
+		return "raw"
+	case PayloadText:
+		return "text"
+	case PayloadInt:
+		return "int"
+	default:
+		return fmt.Sprintf("AnnotationPayload(%d)", int(payload))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (payload AnnotationPayload) MarshalText() ([]byte, error) {
+	return []byte(payload.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (payload *AnnotationPayload) UnmarshalText(text []byte) error {
+	for _, p := range []AnnotationPayload{PayloadRawData, PayloadText, PayloadInt} {
+		if string(text) == p.String() {
+			*payload = p
+			return nil
+		}
+	}
+	return fmt.Errorf("%q: %w", text, ErrSchemaPayloadInvalid)
+}
+
+// Errors returned when declaring annotations or validating them against a Schema.
+var (
+	ErrSchemaNameReserved    = errors.New("annotation names starting with \"u-\" are reserved")
+	ErrSchemaNameEmpty       = errors.New("annotation name is empty")
+	ErrSchemaDuplicate       = errors.New("annotation already declared")
+	ErrSchemaPayloadInvalid  = errors.New("payload type not valid")
+	ErrSchemaUndeclared      = errors.New("annotation not declared in schema")
+	ErrSchemaPayloadMismatch = errors.New("annotation payload does not match schema")
+	ErrSchemaContentMismatch = errors.New("annotation content type does not match schema")
+	ErrSchemaFieldInvalid    = errors.New("annotation field not valid")
+)
+
+// AnnotationField declares a field of a JSON annotation payload.
+//
+// Fields are used by code generators to produce typed emitters; they are
+// not checked when annotations are added.
+type AnnotationField struct {
+	// Name is the JSON object key.
+	Name string `json:"name"`
+
+	// Type is the Go type of the field: one of "string", "int64",
+	// "float64" or "bool".
+	Type string `json:"type"`
+}
+
+// AnnotationSpec declares an annotation which an application may add to a recording.
+type AnnotationSpec struct {
+	// Name is the annotation name. Names starting with "u-" are reserved.
+	Name string `json:"name"`
+
+	// Detail is the annotation detail. An empty detail matches only
+	// annotations added without one.
+	Detail string `json:"detail,omitempty"`
+
+	// Payload is the kind of data stored.
+	Payload AnnotationPayload `json:"payload"`
+
+	// ContentType is the type of textual content, for PayloadText only.
+	ContentType AnnotationContentType `json:"content_type,omitempty"`
+
+	// Description documents the annotation for analysis tooling.
+	Description string `json:"description,omitempty"`
+
+	// Fields optionally declares the fields of a JSON object payload.
+	Fields []AnnotationField `json:"fields,omitempty"`
+}
+
+type schemaKey struct {
+	name   string
+	detail string
+}
+
+// A Schema is a registry of the annotations an application adds to recordings.
+//
+// Annotations are declared up front with Declare, and then added through
+// the Schema's methods, which reject annotations that weren't declared or
+// don't match their declaration. A Schema may be used concurrently.
+type Schema struct {
+	mu    sync.Mutex
+	specs map[schemaKey]AnnotationSpec
+}
+
+// NewSchema creates an empty schema.
+func NewSchema() *Schema {
+	return &Schema{specs: make(map[schemaKey]AnnotationSpec)}
+}
+
+// Declare adds an annotation to the schema.
+func (schema *Schema) Declare(spec AnnotationSpec) error {
+	if spec.Name == "" {
+		return ErrSchemaNameEmpty
+	}
+	if strings.HasPrefix(spec.Name, "u-") {
+		return fmt.Errorf("%q: %w", spec.Name, ErrSchemaNameReserved)
+	}
+	switch spec.Payload {
+	case PayloadRawData, PayloadInt:
+		spec.ContentType = 0
+	case PayloadText:
+		switch spec.ContentType {
+		case JSON, XML, UnstructuredText:
+			break
+		default:
+			return ErrAnnotationContentTypeInvalid
+		}
+	default:
+		return ErrSchemaPayloadInvalid
+	}
+	if len(spec.Fields) > 0 && (spec.Payload != PayloadText || spec.ContentType != JSON) {
+		return fmt.Errorf("%q: fields require a JSON payload: %w", spec.Name, ErrSchemaFieldInvalid)
+	}
+	seen := make(map[string]bool)
+	for _, field := range spec.Fields {
+		switch field.Type {
+		case "string", "int64", "float64", "bool":
+			break
+		default:
+			return fmt.Errorf("%q: field %q has type %q: %w",
+				spec.Name, field.Name, field.Type, ErrSchemaFieldInvalid)
+		}
+		if field.Name == "" || seen[field.Name] {
+			return fmt.Errorf("%q: field %q: %w", spec.Name, field.Name, ErrSchemaFieldInvalid)
+		}
+		seen[field.Name] = true
+	}
+
+	key := schemaKey{spec.Name, spec.Detail}
+
+	schema.mu.Lock()
+	defer schema.mu.Unlock()
+	if _, ok := schema.specs[key]; ok {
+		return fmt.Errorf("%q (%q): %w", spec.Name, spec.Detail, ErrSchemaDuplicate)
+	}
+	schema.specs[key] = spec
+	return nil
+}
+
+// MustDeclare is like Declare but panics if the annotation cannot be declared.
+//
+// It simplifies declaring schemas in package-level variable initialisation.
+func (schema *Schema) MustDeclare(spec AnnotationSpec) *Schema {
+	err := schema.Declare(spec)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Validate checks an annotation against the schema without adding it.
+//
+// The content type is ignored for payloads other than PayloadText.
+func (schema *Schema) Validate(name, detail string, payload AnnotationPayload, contentType AnnotationContentType) error {
+	schema.mu.Lock()
+	spec, ok := schema.specs[schemaKey{name, detail}]
+	schema.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%q (%q): %w", name, detail, ErrSchemaUndeclared)
+	}
+	if spec.Payload != payload {
+		return fmt.Errorf("%q (%q) declared %v, not %v: %w",
+			name, detail, spec.Payload, payload, ErrSchemaPayloadMismatch)
+	}
+	if payload == PayloadText && spec.ContentType != contentType {
+		return fmt.Errorf("%q (%q): %w", name, detail, ErrSchemaContentMismatch)
+	}
+	return nil
+}
+
+// AddRawData validates and adds an annotation as AnnotationAddRawData.
+func (schema *Schema) AddRawData(name, detail string, rawData []byte) error {
+	err := schema.Validate(name, detail, PayloadRawData, 0)
+	if err != nil {
+		return err
+	}
+	return AnnotationAddRawData(name, detail, rawData)
+}
+
+// AddText validates and adds an annotation as AnnotationAddText.
+func (schema *Schema) AddText(name, detail string, contentType AnnotationContentType, text string) error {
+	err := schema.Validate(name, detail, PayloadText, contentType)
+	if err != nil {
+		return err
+	}
+	return AnnotationAddText(name, detail, contentType, text)
+}
+
+// AddInt validates and adds an annotation as AnnotationAddInt.
+func (schema *Schema) AddInt(name, detail string, value int64) error {
+	err := schema.Validate(name, detail, PayloadInt, 0)
+	if err != nil {
+		return err
+	}
+	return AnnotationAddInt(name, detail, value)
+}
+
+// Specs returns the declared annotations, sorted by name and detail.
+func (schema *Schema) Specs() []AnnotationSpec {
+	schema.mu.Lock()
+	specs := make([]AnnotationSpec, 0, len(schema.specs))
+	for _, spec := range schema.specs {
+		specs = append(specs, spec)
+	}
+	schema.mu.Unlock()
+
+	sort.Slice(specs, func(i, j int) bool {
+		if specs[i].Name != specs[j].Name {
+			return specs[i].Name < specs[j].Name
+		}
+		return specs[i].Detail < specs[j].Detail
+	})
+	return specs
+}
+
+// MarshalJSON exports the schema for analysis tooling.
+//
+// The schema is encoded as an object with an "annotations" array of the
+// declared annotations, as returned by Specs.
+func (schema *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Annotations []AnnotationSpec `json:"annotations"`
+	}{schema.Specs()})
+}
+
+// UnmarshalJSON imports a schema exported by MarshalJSON, declaring each of its annotations.
+func (schema *Schema) UnmarshalJSON(data []byte) error {
+	var exported struct {
+		Annotations []AnnotationSpec `json:"annotations"`
+	}
+	err := json.Unmarshal(data, &exported)
+	if err != nil {
+		return err
+	}
+
+	schema.mu.Lock()
+	if schema.specs == nil {
+		schema.specs = make(map[schemaKey]AnnotationSpec)
+	}
+	schema.mu.Unlock()
+
+	for _, spec := range exported.Annotations {
+		err = schema.Declare(spec)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
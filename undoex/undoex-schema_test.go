@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSchemaDeclare(t *testing.T) {
+	schema := NewSchema()
+
+	err := schema.Declare(AnnotationSpec{Name: "request", Payload: PayloadText, ContentType: JSON})
+	if err != nil {
+		t.Fatal("Declare:", err)
+	}
+
+	err = schema.Declare(AnnotationSpec{Name: "request", Payload: PayloadInt})
+	if !errors.Is(err, ErrSchemaDuplicate) {
+		t.Fatal("Expected duplicate to fail:", err)
+	}
+	err = schema.Declare(AnnotationSpec{Name: "u-internal", Payload: PayloadInt})
+	if !errors.Is(err, ErrSchemaNameReserved) {
+		t.Fatal("Expected reserved name to fail:", err)
+	}
+	err = schema.Declare(AnnotationSpec{Payload: PayloadInt})
+	if err != ErrSchemaNameEmpty {
+		t.Fatal("Expected empty name to fail:", err)
+	}
+	err = schema.Declare(AnnotationSpec{Name: "text", Payload: PayloadText})
+	if err != ErrAnnotationContentTypeInvalid {
+		t.Fatal("Expected missing content type to fail:", err)
+	}
+	err = schema.Declare(AnnotationSpec{Name: "other", Payload: AnnotationPayload(42)})
+	if err != ErrSchemaPayloadInvalid {
+		t.Fatal("Expected invalid payload to fail:", err)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := NewSchema().
+		MustDeclare(AnnotationSpec{Name: "request", Payload: PayloadText, ContentType: JSON}).
+		MustDeclare(AnnotationSpec{Name: "count", Detail: "items", Payload: PayloadInt})
+
+	err := schema.Validate("request", "", PayloadText, JSON)
+	if err != nil {
+		t.Fatal("Validate:", err)
+	}
+	err = schema.Validate("count", "items", PayloadInt, 0)
+	if err != nil {
+		t.Fatal("Validate:", err)
+	}
+
+	err = schema.AddInt("missing", "", 1)
+	if !errors.Is(err, ErrSchemaUndeclared) {
+		t.Fatal("Expected undeclared annotation to fail:", err)
+	}
+	err = schema.AddInt("count", "", 1)
+	if !errors.Is(err, ErrSchemaUndeclared) {
+		t.Fatal("Expected undeclared detail to fail:", err)
+	}
+	err = schema.AddRawData("request", "", nil)
+	if !errors.Is(err, ErrSchemaPayloadMismatch) {
+		t.Fatal("Expected payload mismatch to fail:", err)
+	}
+	err = schema.AddText("request", "", XML, "<a/>")
+	if !errors.Is(err, ErrSchemaContentMismatch) {
+		t.Fatal("Expected content type mismatch to fail:", err)
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	schema := NewSchema().
+		MustDeclare(AnnotationSpec{Name: "request", Payload: PayloadText, ContentType: JSON,
+			Description: "incoming request"}).
+		MustDeclare(AnnotationSpec{Name: "count", Payload: PayloadInt})
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+
+	expected := `{"annotations":[{"name":"count","payload":"int"},` +
+		`{"name":"request","payload":"text","content_type":101,"description":"incoming request"}]}`
+	if string(data) != expected {
+		t.Fatalf("Unexpected JSON:\n %s\n vs\n %s", data, expected)
+	}
+}
+
+func TestSchemaFields(t *testing.T) {
+	schema := NewSchema()
+
+	err := schema.Declare(AnnotationSpec{Name: "order", Payload: PayloadText, ContentType: JSON,
+		Fields: []AnnotationField{{"id", "string"}, {"amount", "int64"}}})
+	if err != nil {
+		t.Fatal("Declare:", err)
+	}
+
+	for _, spec := range []AnnotationSpec{
+		{Name: "a", Payload: PayloadInt, Fields: []AnnotationField{{"id", "string"}}},
+		{Name: "b", Payload: PayloadText, ContentType: XML, Fields: []AnnotationField{{"id", "string"}}},
+		{Name: "c", Payload: PayloadText, ContentType: JSON, Fields: []AnnotationField{{"id", "uint8"}}},
+		{Name: "d", Payload: PayloadText, ContentType: JSON, Fields: []AnnotationField{{"", "bool"}}},
+		{Name: "e", Payload: PayloadText, ContentType: JSON,
+			Fields: []AnnotationField{{"id", "bool"}, {"id", "bool"}}},
+	} {
+		err = schema.Declare(spec)
+		if !errors.Is(err, ErrSchemaFieldInvalid) {
+			t.Fatalf("Expected %s to fail: %v", spec.Name, err)
+		}
+	}
+}
+
+func TestSchemaUnmarshal(t *testing.T) {
+	schema := NewSchema().
+		MustDeclare(AnnotationSpec{Name: "order", Payload: PayloadText, ContentType: JSON,
+			Fields: []AnnotationField{{"id", "string"}}}).
+		MustDeclare(AnnotationSpec{Name: "count", Detail: "items", Payload: PayloadInt})
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+
+	var imported Schema
+	err = json.Unmarshal(data, &imported)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	err = imported.Validate("count", "items", PayloadInt, 0)
+	if err != nil {
+		t.Fatal("Validate:", err)
+	}
+
+	reexported, err := json.Marshal(&imported)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+	if string(reexported) != string(data) {
+		t.Fatalf("Schema changed on import:\n %s\n vs\n %s", reexported, data)
+	}
+
+	err = json.Unmarshal([]byte(`{"annotations":[{"name":"x","payload":"float"}]}`), NewSchema())
+	if !errors.Is(err, ErrSchemaPayloadInvalid) {
+		t.Fatal("Expected invalid payload to fail:", err)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Annotation details used for test suites.
+//
+// Each is stored with the suite name as annotation name. The start and end
+// are stored as int annotations holding nanoseconds since the Unix epoch;
+// the result is stored as JSON in the form of AnnotationSuiteResult.
+const (
+	SuiteStartDetail  = "u-suite-start"
+	SuiteEndDetail    = "u-suite-end"
+	SuiteResultDetail = "u-suite-result"
+)
+
+// ErrAnnotationSuiteMissingName indicates a suite was created without a name.
+var ErrAnnotationSuiteMissingName = errors.New("suite name must not be empty")
+
+// An AnnotationSuiteContext groups the test contexts of a test suite.
+//
+// Tests created with <NewTest> are named "<suite>/<test>", giving
+// recordings of large test binaries a two-level hierarchy in the same way
+// as JUnit suites, and their results are aggregated when the suite ends.
+type AnnotationSuiteContext struct {
+	name  string
+	mu    sync.Mutex
+	start time.Time
+
+	// results holds the result of each test, indexed by its suiteIndex.
+	// The suite holds no references to its tests, so that a test context
+	// which is not freed is still found by its finalizer.
+	results []AnnotationTestResult
+}
+
+// AnnotationSuiteResult is the aggregate result of a suite, stored as JSON by <End>.
+type AnnotationSuiteResult struct {
+	Tests    int   `json:"tests"`
+	Success  int   `json:"success"`
+	Failure  int   `json:"failure"`
+	Skipped  int   `json:"skipped"`
+	Other    int   `json:"other"`
+	Unknown  int   `json:"unknown"`
+	Duration int64 `json:"duration_ns"`
+}
+
+// AnnotationSuiteNew creates a context for a test suite that can be stored in a recording.
+//
+// Unlike <AnnotationTestNew> no resources are allocated by the annotation
+// library, so the returned context does not need to be freed. The test
+// contexts created from it do.
+func AnnotationSuiteNew(name string) (*AnnotationSuiteContext, error) {
+	if len(name) == 0 {
+		return nil, ErrAnnotationSuiteMissingName
+	}
+	return &AnnotationSuiteContext{name: name}, nil
+}
+
+// Name returns the name of the suite.
+func (suite *AnnotationSuiteContext) Name() string {
+	return suite.name
+}
+
+// Start will store an annotation for the start of the suite.
+func (suite *AnnotationSuiteContext) Start() error {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+
+	suite.start = now()
+	return AnnotationAddInt(suite.name, SuiteStartDetail, suite.start.UnixNano())
+}
+
+// NewTest creates a context for a test in the suite.
+//
+// This is as <AnnotationTestNew> with the test name prefixed by the suite
+// name. Results set on the test with <SetResult> are included in the
+// suite result.
+func (suite *AnnotationSuiteContext) NewTest(baseName string, addRunSuffix bool) (*AnnotationTestContext, error) {
+	context, err := AnnotationTestNew(suite.name+"/"+baseName, addRunSuffix)
+	if err != nil {
+		return nil, err
+	}
+	// Report leaks against our caller, not ourselves.
+	_, context.file, context.line, _ = runtime.Caller(1)
+	context.suite = suite
+
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+	context.suiteIndex = len(suite.results)
+	suite.results = append(suite.results, Unknown)
+	return context, nil
+}
+
+func (suite *AnnotationSuiteContext) setResult(index int, result AnnotationTestResult) {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+	suite.results[index] = result
+}
+
+// Result returns the aggregate result of the tests in the suite so far.
+//
+// Tests which have not had a result set are counted as Unknown.
+func (suite *AnnotationSuiteContext) Result() AnnotationSuiteResult {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+	return suite.result(now())
+}
+
+func (suite *AnnotationSuiteContext) result(now time.Time) AnnotationSuiteResult {
+	result := AnnotationSuiteResult{Tests: len(suite.results)}
+	for _, r := range suite.results {
+		switch r {
+		case Success:
+			result.Success++
+		case Failure:
+			result.Failure++
+		case Skipped:
+			result.Skipped++
+		case Other:
+			result.Other++
+		default:
+			result.Unknown++
+		}
+	}
+	if !suite.start.IsZero() {
+		result.Duration = int64(now.Sub(suite.start))
+	}
+	return result
+}
+
+// End will store annotations for the end of the suite and its aggregate result.
+func (suite *AnnotationSuiteContext) End() error {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+
+	end := now()
+	err := AnnotationAddInt(suite.name, SuiteEndDetail, end.UnixNano())
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(suite.result(end))
+	if err != nil {
+		return err
+	}
+	return AnnotationAddText(suite.name, SuiteResultDetail, JSON, string(data))
+}
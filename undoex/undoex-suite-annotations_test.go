@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestAnnotationSuiteNew(t *testing.T) {
+	_, err := AnnotationSuiteNew("")
+	if err != ErrAnnotationSuiteMissingName {
+		t.Fatal("Expected AnnotationSuiteNew() to fail with missing name")
+	}
+
+	suite, err := AnnotationSuiteNew("suitename")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Name() != "suitename" {
+		t.Fatal("Unexpected suite name", suite.Name())
+	}
+}
+
+func TestAnnotationSuite(t *testing.T) {
+	suite, err := AnnotationSuiteNew("suitename")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = suite.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := []AnnotationTestResult{Success, Success, Failure, Skipped}
+	for _, r := range results {
+		context, err := suite.NewTest("testname", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer context.Free()
+
+		err = context.SetResult(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A test without a result is counted as unknown.
+	context, err := suite.NewTest("testname", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer context.Free()
+
+	err = suite.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := suite.Result()
+	if result.Tests != 5 || result.Success != 2 || result.Failure != 1 ||
+		result.Skipped != 1 || result.Unknown != 1 {
+		t.Fatalf("Unexpected suite result %+v", result)
+	}
+}
+
+func TestAnnotationSuiteTestCollected(t *testing.T) {
+	suite, err := AnnotationSuiteNew("suitename")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collected := make(chan struct{})
+	func() {
+		context, err := suite.NewTest("testname", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		context.SetResult(Success)
+		context.Free()
+		runtime.SetFinalizer(context, nil)
+		runtime.SetFinalizer(context, func(*AnnotationTestContext) { close(collected) })
+	}()
+
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			if result := suite.Result(); result.Tests != 1 || result.Success != 1 {
+				t.Fatalf("Unexpected suite result %+v", result)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("Test context held by suite was not collected")
+}
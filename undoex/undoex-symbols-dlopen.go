@@ -0,0 +1,165 @@
+//go:build linux && cgo && undo_dlopen
+// +build linux,cgo,undo_dlopen
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+/*
+#cgo LDFLAGS: -ldl
+
+#include <dlfcn.h>
+#include <errno.h>
+#include <stddef.h>
+#include <stdlib.h>
+#include <undoex-annotations.h>
+#include <undoex-test-annotations.h>
+
+// The library functions are looked up when the library is loaded, in the
+// order of libFunction, and defined here to call through the pointers
+// found, so the library is not needed when linking.
+static const char *undoex_go_names[] = {
+	"undoex_annotation_add_raw_data",
+	"undoex_annotation_add_text",
+	"undoex_annotation_add_int",
+	"undoex_test_annotation_new",
+	"undoex_test_annotation_free",
+	"undoex_test_annotation_start",
+	"undoex_test_annotation_end",
+	"undoex_test_annotation_set_result",
+	"undoex_test_annotation_set_output",
+	"undoex_test_annotation_add_raw_data",
+	"undoex_test_annotation_add_text",
+	"undoex_test_annotation_add_int",
+};
+
+#define UNDOEX_GO_NUM_FNS (sizeof(undoex_go_names) / sizeof(undoex_go_names[0]))
+
+static void *undoex_go_fns[UNDOEX_GO_NUM_FNS];
+
+static int undoex_go_dlopen(const char *path)
+{
+	void *handle = dlopen(path, RTLD_NOW | RTLD_LOCAL);
+	size_t i;
+
+	if (handle == NULL) {
+		return -1;
+	}
+	for (i = 0; i < UNDOEX_GO_NUM_FNS; i++) {
+		undoex_go_fns[i] = dlsym(handle, undoex_go_names[i]);
+	}
+	return 0;
+}
+
+static const char *undoex_go_dlerror(void)
+{
+	return dlerror();
+}
+
+static int undoex_go_has(int fn)
+{
+	return undoex_go_fns[fn] != NULL;
+}
+
+#define UNDOEX_GO_FORWARD(index, name, params, args) \
+	int name params \
+	{ \
+		int (*f) params = (int (*) params)undoex_go_fns[index]; \
+		if (f == NULL) { \
+			errno = ENOSYS; \
+			return -1; \
+		} \
+		return f args; \
+	}
+
+UNDOEX_GO_FORWARD(0, undoex_annotation_add_raw_data,
+	(const char *name, const char *detail, const uint8_t *raw_data, size_t raw_data_len),
+	(name, detail, raw_data, raw_data_len))
+UNDOEX_GO_FORWARD(1, undoex_annotation_add_text,
+	(const char *name, const char *detail, undoex_annotation_content_type_t content_type, const char *text),
+	(name, detail, content_type, text))
+UNDOEX_GO_FORWARD(2, undoex_annotation_add_int,
+	(const char *name, const char *detail, int64_t value),
+	(name, detail, value))
+UNDOEX_GO_FORWARD(5, undoex_test_annotation_start,
+	(undoex_test_annotation_t *test_annotation), (test_annotation))
+UNDOEX_GO_FORWARD(6, undoex_test_annotation_end,
+	(undoex_test_annotation_t *test_annotation), (test_annotation))
+UNDOEX_GO_FORWARD(7, undoex_test_annotation_set_result,
+	(undoex_test_annotation_t *test_annotation, undoex_test_result_t test_result),
+	(test_annotation, test_result))
+UNDOEX_GO_FORWARD(8, undoex_test_annotation_set_output,
+	(undoex_test_annotation_t *test_annotation, undoex_annotation_content_type_t content_type, const char *output),
+	(test_annotation, content_type, output))
+UNDOEX_GO_FORWARD(9, undoex_test_annotation_add_raw_data,
+	(undoex_test_annotation_t *test_annotation, const char *detail, const uint8_t *raw_data, size_t raw_data_len),
+	(test_annotation, detail, raw_data, raw_data_len))
+UNDOEX_GO_FORWARD(10, undoex_test_annotation_add_text,
+	(undoex_test_annotation_t *test_annotation, const char *detail, undoex_annotation_content_type_t content_type, const char *text),
+	(test_annotation, detail, content_type, text))
+UNDOEX_GO_FORWARD(11, undoex_test_annotation_add_int,
+	(undoex_test_annotation_t *test_annotation, const char *detail, int64_t value),
+	(test_annotation, detail, value))
+
+undoex_test_annotation_t *
+undoex_test_annotation_new(const char *base_test_name, bool add_run_suffix)
+{
+	undoex_test_annotation_t *(*f)(const char *, bool) =
+		(undoex_test_annotation_t *(*)(const char *, bool))undoex_go_fns[3];
+
+	if (f == NULL) {
+		errno = ENOSYS;
+		return NULL;
+	}
+	return f(base_test_name, add_run_suffix);
+}
+
+void
+undoex_test_annotation_free(undoex_test_annotation_t *test_annotation)
+{
+	void (*f)(undoex_test_annotation_t *) = (void (*)(undoex_test_annotation_t *))undoex_go_fns[4];
+
+	if (f != NULL) {
+		f(test_annotation);
+	}
+}
+*/
+import "C"
+import (
+	"errors"
+	"os"
+	"unsafe"
+)
+
+// libLoader describes how the library was found, for InitReport.
+const libLoader = "dlopen"
+
+// loadLibrary loads the library named by EnvLibrary, or the first of
+// libraryNames found.
+func loadLibrary() error {
+	names := libraryNames
+	if name := os.Getenv(EnvLibrary); name != "" {
+		names = []string{name}
+	}
+
+	var err error
+	for _, name := range names {
+		cname := C.CString(name)
+		rc := C.undoex_go_dlopen(cname)
+		C.free(unsafe.Pointer(cname))
+		if rc == 0 {
+			return nil
+		}
+		err = errors.New(C.GoString(C.undoex_go_dlerror()))
+	}
+	return err
+}
+
+func libFunctionPresent(fn libFunction) bool {
+	return C.undoex_go_has(C.int(fn)) != 0
+}
@@ -0,0 +1,53 @@
+//go:build linux && cgo && !undo_dlopen
+// +build linux,cgo,!undo_dlopen
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+/*
+#include <stddef.h>
+#include <undoex-annotations.h>
+#include <undoex-test-annotations.h>
+
+// The library functions are declared weak, so those missing from the
+// program resolve to NULL rather than failing to link.
+static int undoex_go_has(int fn)
+{
+	void *p = NULL;
+
+	switch (fn) {
+	case 0: p = (void *)undoex_annotation_add_raw_data; break;
+	case 1: p = (void *)undoex_annotation_add_text; break;
+	case 2: p = (void *)undoex_annotation_add_int; break;
+	case 3: p = (void *)undoex_test_annotation_new; break;
+	case 4: p = (void *)undoex_test_annotation_free; break;
+	case 5: p = (void *)undoex_test_annotation_start; break;
+	case 6: p = (void *)undoex_test_annotation_end; break;
+	case 7: p = (void *)undoex_test_annotation_set_result; break;
+	case 8: p = (void *)undoex_test_annotation_set_output; break;
+	case 9: p = (void *)undoex_test_annotation_add_raw_data; break;
+	case 10: p = (void *)undoex_test_annotation_add_text; break;
+	case 11: p = (void *)undoex_test_annotation_add_int; break;
+	}
+	return p != NULL;
+}
+*/
+import "C"
+
+// libLoader describes how the library was found, for InitReport.
+const libLoader = "linked"
+
+// loadLibrary does nothing, as the library is linked when building.
+func loadLibrary() error {
+	return nil
+}
+
+func libFunctionPresent(fn libFunction) bool {
+	return C.undoex_go_has(C.int(fn)) != 0
+}
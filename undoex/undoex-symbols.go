@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// libFunction identifies a function of the annotations library. The
+// values must match the order of the functions in the C code behind
+// libFunctionPresent.
+type libFunction int
+
+const (
+	fnAnnotationAddRawData libFunction = iota
+	fnAnnotationAddText
+	fnAnnotationAddInt
+	fnTestAnnotationNew
+	fnTestAnnotationFree
+	fnTestAnnotationStart
+	fnTestAnnotationEnd
+	fnTestAnnotationSetResult
+	fnTestAnnotationSetOutput
+	fnTestAnnotationAddRawData
+	fnTestAnnotationAddText
+	fnTestAnnotationAddInt
+	numLibFunctions
+)
+
+var libFunctionNames = [numLibFunctions]string{
+	"undoex_annotation_add_raw_data",
+	"undoex_annotation_add_text",
+	"undoex_annotation_add_int",
+	"undoex_test_annotation_new",
+	"undoex_test_annotation_free",
+	"undoex_test_annotation_start",
+	"undoex_test_annotation_end",
+	"undoex_test_annotation_set_result",
+	"undoex_test_annotation_set_output",
+	"undoex_test_annotation_add_raw_data",
+	"undoex_test_annotation_add_text",
+	"undoex_test_annotation_add_int",
+}
+
+func (fn libFunction) String() string {
+	return libFunctionNames[fn]
+}
+
+// EnvLibrary is the annotations shared library to load when built with the undo_dlopen tag.
+//
+// It may be a path, or a name searched for as by dlopen. If unset, the
+// names in libraryNames are tried in turn.
+const EnvLibrary = "UNDOEX_LIBRARY"
+
+// libraryNames are the names of the annotations shared library tried in
+// turn when built with the undo_dlopen tag.
+var libraryNames = []string{"libundoex_pic_x64.so", "libundoex.so"}
+
+// ErrNotAvailable indicates the annotations library, or a function of it, is not available to the process.
+//
+// Errors returned for missing functions are of type *NotAvailableError
+// and match this value with errors.Is.
+var ErrNotAvailable = errors.New("annotations library not available")
+
+// ErrUnsupportedPlatform indicates the package was built for a platform the annotations library does not support.
+//
+// The annotations library only runs on Linux, and the package needs cgo to
+// call it. Elsewhere the package builds with stubs, and functions adding
+// annotations fail with a *NotAvailableError which also matches this
+// value, unless SetUnsupportedPlatformNoOp is used.
+var ErrUnsupportedPlatform = errors.New("platform not supported by the annotations library")
+
+// A NotAvailableError reports a library function called while it is not available.
+type NotAvailableError struct {
+	Function string
+}
+
+func (e *NotAvailableError) Error() string {
+	if !platformSupported {
+		return fmt.Sprintf("%s: %v", e.Function, ErrUnsupportedPlatform)
+	}
+	return fmt.Sprintf("%s: %v", e.Function, ErrNotAvailable)
+}
+
+// Is reports whether target is ErrNotAvailable, or ErrUnsupportedPlatform
+// when built for an unsupported platform.
+func (e *NotAvailableError) Is(target error) bool {
+	if target == ErrUnsupportedPlatform {
+		return !platformSupported
+	}
+	return target == ErrNotAvailable
+}
+
+// libHas records which library functions are available, and libAvailable
+// whether any are. They are filled in on first use, loading the library
+// if built with the undo_dlopen tag.
+var libHas [numLibFunctions]bool
+var libAvailable bool
+var libLoadErr error
+var libHasOnce sync.Once
+
+func libInit() {
+	libHasOnce.Do(func() {
+		libLoadErr = loadLibrary()
+		for i := range libHas {
+			libHas[i] = libFunctionPresent(libFunction(i))
+			libAvailable = libAvailable || libHas[i]
+		}
+	})
+}
+
+// unsupportedNoOp is set by SetUnsupportedPlatformNoOp.
+var unsupportedNoOp int32
+
+// SetUnsupportedPlatformNoOp controls whether annotations are silently dropped on an unsupported platform.
+//
+// By default, functions adding annotations fail with an error matching
+// ErrUnsupportedPlatform when the package is built for a platform other
+// than Linux, or without cgo. With noop set they succeed without doing
+// anything, so instrumented code runs unchanged. This has no effect on
+// Linux, where a missing library is reported as ErrNotAvailable.
+func SetUnsupportedPlatformNoOp(noop bool) {
+	var value int32
+	if noop {
+		value = 1
+	}
+	atomic.StoreInt32(&unsupportedNoOp, value)
+}
+
+// require returns a *NotAvailableError if fn is missing, so it is not
+// called through a NULL pointer. On an unsupported platform it returns
+// nil if annotations are to be dropped, in which case the stub called in
+// place of fn succeeds.
+func require(fn libFunction) error {
+	libInit()
+	if !platformSupported && atomic.LoadInt32(&unsupportedNoOp) != 0 {
+		return nil
+	}
+	if !libHas[fn] {
+		return &NotAvailableError{fn.String()}
+	}
+	return nil
+}
+
+// Available reports whether the annotations library is available to the process.
+//
+// If it is not, functions adding annotations fail with an error matching
+// ErrNotAvailable. By default the library is linked when building, so is
+// only missing if it was not linked. Built with the undo_dlopen tag, the
+// library is instead loaded when first needed, so the same binary runs on
+// hosts with and without it installed; see EnvLibrary.
+func Available() bool {
+	libInit()
+	return libAvailable
+}
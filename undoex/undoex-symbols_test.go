@@ -0,0 +1,41 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNotAvailableError(t *testing.T) {
+	var err error = &NotAvailableError{fnAnnotationAddInt.String()}
+	if !errors.Is(err, ErrNotAvailable) {
+		t.Fatal("Expected error to match ErrNotAvailable")
+	}
+	if errors.Is(err, ErrUnsupportedPlatform) != !platformSupported {
+		t.Fatal("Unexpected match of ErrUnsupportedPlatform", err)
+	}
+	if platformSupported && err.Error() != "undoex_annotation_add_int: annotations library not available" {
+		t.Fatal("Unexpected error string", err)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	if Available() {
+		t.Skip("annotations library available")
+	}
+	if err := AnnotationAddInt("undoex-test", "", 1); !errors.Is(err, ErrNotAvailable) {
+		t.Fatal("Expected AnnotationAddInt() to fail with ErrNotAvailable:", err)
+	}
+	if _, err := AnnotationTestNew("undoex-test", false); !errors.Is(err, ErrNotAvailable) {
+		t.Fatal("Expected AnnotationTestNew() to fail with ErrNotAvailable:", err)
+	}
+	if report := InitReport(); !report.Stub || report.Loader != libLoader {
+		t.Fatal("Unexpected report without the library:", report)
+	}
+}
@@ -7,27 +7,24 @@ SPDX-License-Identifier: BSD-3-Clause
 
 package undoex
 
-// #include <undoex-test-annotations.h>
-// #include <stdlib.h>
-// #include <errno.h>
-import "C"
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime"
-	"unsafe"
+	"time"
 )
 
 // An AnnotationTestResult is used to specify the result of a test.
 type AnnotationTestResult int
 
-// Test result values for AnnotationTestResult
+// Test result values for AnnotationTestResult, as in undoex_test_result_t.
 const (
-	Unknown AnnotationTestResult = C.undoex_test_result_UNKNOWN
-	Success AnnotationTestResult = C.undoex_test_result_SUCCESS
-	Failure AnnotationTestResult = C.undoex_test_result_FAILURE
-	Skipped AnnotationTestResult = C.undoex_test_result_SKIPPED
-	Other   AnnotationTestResult = C.undoex_test_result_OTHER
+	Unknown AnnotationTestResult = iota
+	Success
+	Failure
+	Skipped
+	Other
 )
 
 // An AnnotationTestContext keeps track of a test run through annotations.
@@ -39,17 +36,37 @@ const (
 // When you are done and don't need the object any more, free with
 // <Free>.
 type AnnotationTestContext struct {
-	ctx   *C.undoex_test_annotation_t
-	valid bool
-	file  string
-	line  int
+	ctx        libTestAnnotation
+	name       string
+	valid      bool
+	file       string
+	line       int
+	output     *outputCapture
+	start      time.Time
+	end        time.Time
+	suite      *AnnotationSuiteContext
+	suiteIndex int
 }
 
+// Annotation details used for the timing of a test.
+//
+// Each is stored as an int annotation with the test name as annotation
+// name when <End> is called. Times are in nanoseconds since the Unix epoch.
+const (
+	TestStartTimeDetail = "u-test-start-time"
+	TestEndTimeDetail   = "u-test-end-time"
+	TestDurationDetail  = "u-test-duration"
+)
+
+// TestExternalIDDetail is the annotation detail used by <SetExternalID>.
+const TestExternalIDDetail = "u-test-external-id"
+
 // A set of error codes returned by methods handling test annotation contexts.
 var (
 	ErrAnnotationTestContextInvalid = errors.New("annotation test context invalid - already freed?")
 	ErrAnnotationTestResultInvalid  = errors.New("not a valid AnnotationTestResult")
 	ErrAnnotationTestMissingDetail  = errors.New("detail must not be empty")
+	ErrAnnotationTestMissingID      = errors.New("external ID must not be empty")
 )
 
 // AnnotationTestNew creates a context for a test that can be stored in a recording.
@@ -60,20 +77,33 @@ var (
 // In case your program makes it possible to execute the same test twice
 // during a single execution of the program, you can pass true as
 // <addRunSuffix> to help disambiguate between different runs of the
-// same test.
+// same test. The suffix is a UUIDv7 unless chosen with SetRunSuffixFunc.
 //
 // The AnnotationTestContext returned must eventually be freed using Free.
 func AnnotationTestNew(baseName string, addRunSuffix bool) (*AnnotationTestContext, error) {
-	cName := C.CString(baseName)
-	defer C.free(unsafe.Pointer(cName))
+	err := checkName("name", baseName)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := testName(baseName, addRunSuffix)
+	if err != nil {
+		return nil, err
+	}
 
-	ctx, err := C.undoex_test_annotation_new(cName, (C.bool)(addRunSuffix))
-	if ctx == nil {
+	if err := require(fnTestAnnotationNew); err != nil {
+		return nil, err
+	}
+
+	// Any run suffix has been added by testName.
+	ctx, ok, err := libTestAnnotationNew(name, false)
+	if !ok {
 		return nil, err
 	}
 
 	newContext := &AnnotationTestContext{
 		ctx:   ctx,
+		name:  name,
 		valid: true,
 	}
 	_, newContext.file, newContext.line, _ = runtime.Caller(1)
@@ -94,7 +124,9 @@ func annotationTestContextFinalizer(context *AnnotationTestContext) {
 func (context *AnnotationTestContext) Free() {
 	if context.valid {
 		context.valid = false
-		C.undoex_test_annotation_free(context.ctx)
+		if require(fnTestAnnotationFree) == nil {
+			libTestAnnotationFree(context.ctx)
+		}
 	}
 }
 
@@ -108,10 +140,16 @@ func (context *AnnotationTestContext) Start() error {
 		return ErrAnnotationTestContextInvalid
 	}
 
-	rc, err := C.undoex_test_annotation_start(context.ctx)
+	if err := require(fnTestAnnotationStart); err != nil {
+		return err
+	}
+
+	rc, err := libTestAnnotationStart(context.ctx)
 	if rc != 0 {
 		return err
 	}
+	indexRawData(context.name, "u-test-start", nil)
+	context.start = now()
 	return nil
 }
 
@@ -126,18 +164,64 @@ func (context *AnnotationTestContext) Start() error {
 // not available yet.
 // It's possible to call any of the other functions operating on
 // <AnnotationTestContext> after the test is marked as finished.
+//
+// If <Start> was called, the start time, end time and duration of the test
+// are also stored (see TestDurationDetail). If output is being captured
+// with <OutputWriter>, the output captured so far is stored as if by
+// <SetOutput>.
 func (context *AnnotationTestContext) End() error {
 	if !context.valid {
 		return ErrAnnotationTestContextInvalid
 	}
 
-	rc, err := C.undoex_test_annotation_end(context.ctx)
+	if err := require(fnTestAnnotationEnd); err != nil {
+		return err
+	}
+
+	rc, err := libTestAnnotationEnd(context.ctx)
 	if rc != 0 {
 		return err
 	}
+	indexRawData(context.name, "u-test-end", nil)
+
+	context.end = now()
+	if !context.start.IsZero() {
+		err = context.addTiming(context.end)
+		if err != nil {
+			return err
+		}
+	}
+
+	if context.output != nil {
+		return context.SetOutput(context.output.contentType, context.output.String())
+	}
 	return nil
 }
 
+func (context *AnnotationTestContext) addTiming(end time.Time) error {
+	err := context.AddInt(TestStartTimeDetail, context.start.UnixNano())
+	if err != nil {
+		return err
+	}
+	err = context.AddInt(TestEndTimeDetail, end.UnixNano())
+	if err != nil {
+		return err
+	}
+	return context.AddInt(TestDurationDetail, int64(end.Sub(context.start)))
+}
+
+// Duration returns the time between the calls to <Start> and <End>, or
+// since <Start> if the test has not ended.
+func (context *AnnotationTestContext) Duration() time.Duration {
+	if context.start.IsZero() {
+		return 0
+	}
+	if !context.end.IsZero() {
+		return context.end.Sub(context.start)
+	}
+	return now().Sub(context.start)
+}
+
 // SetResult stores whether the test passed or not as an annotation in the recording.
 //
 // This is stored in the recording as an annotation with the test name as
@@ -158,11 +242,18 @@ func (context *AnnotationTestContext) SetResult(result AnnotationTestResult) err
 		return ErrAnnotationTestResultInvalid
 	}
 
-	rc, err := C.undoex_test_annotation_set_result(context.ctx,
-		(C.undoex_test_result_t)(result))
+	if err := require(fnTestAnnotationSetResult); err != nil {
+		return err
+	}
+
+	rc, err := libTestAnnotationSetResult(context.ctx, result)
 	if rc != 0 {
 		return err
 	}
+	indexInt(context.name, "u-test-result", int64(result))
+	if context.suite != nil {
+		context.suite.setResult(context.suiteIndex, result)
+	}
 	return nil
 }
 
@@ -183,17 +274,50 @@ func (context *AnnotationTestContext) SetOutput(contentType AnnotationContentTyp
 		return ErrAnnotationContentTypeInvalid
 	}
 
-	cOutput := C.CString(output)
-	defer C.free(unsafe.Pointer(cOutput))
+	err := checkText(output)
+	if err != nil {
+		return err
+	}
 
-	rc, err := C.undoex_test_annotation_set_output(context.ctx,
-		(C.undoex_annotation_content_type_t)(contentType), cOutput)
+	if err := require(fnTestAnnotationSetOutput); err != nil {
+		return err
+	}
+
+	rc, err := libTestAnnotationSetOutput(context.ctx, contentType, output)
 	if rc != 0 {
 		return err
 	}
+	indexText(context.name, "u-test-output", contentType, output)
 	return nil
 }
 
+// SetExternalID links the test to a test case in an external test management system.
+//
+// This is stored in the recording as an annotation with the test name as
+// annotation name and TestExternalIDDetail as detail. The data is JSON of
+// the form {"system": "<system>", "id": "<id>"}, where <system> names the
+// test management system (for instance "TestRail" or "Xray") and may be
+// empty. It may be called more than once to link several systems.
+func (context *AnnotationTestContext) SetExternalID(system, id string) error {
+	if !context.valid {
+		return ErrAnnotationTestContextInvalid
+	}
+
+	if len(id) == 0 {
+		return ErrAnnotationTestMissingID
+	}
+
+	data, err := json.Marshal(struct {
+		System string `json:"system"`
+		ID     string `json:"id"`
+	}{system, id})
+	if err != nil {
+		return err
+	}
+
+	return context.AddText(TestExternalIDDetail, JSON, string(data))
+}
+
 // AddRawData adds an annotation (which stores <rawData>) at the current execution point.
 //
 // See <AnnotationAddRawData> for extra details.
@@ -206,22 +330,24 @@ func (context *AnnotationTestContext) AddRawData(detail string, rawData []byte)
 		return ErrAnnotationTestMissingDetail
 	}
 
-	cDetail := C.CString(detail)
-	defer C.free(unsafe.Pointer(cDetail))
+	err := checkName("detail", detail)
+	if err != nil {
+		return err
+	}
+	err = checkRawData(rawData)
+	if err != nil {
+		return err
+	}
 
-	var cRawData *C.uint8_t
-	cRawDataLen := (C.size_t)(0)
-	if len(rawData) > 0 {
-		cRawData = (*C.uint8_t)(C.CBytes(rawData))
-		defer C.free(unsafe.Pointer(cRawData))
-		cRawDataLen = (C.size_t)(len(rawData))
+	if err := require(fnTestAnnotationAddRawData); err != nil {
+		return err
 	}
 
-	rc, err := C.undoex_test_annotation_add_raw_data(context.ctx,
-		cDetail, cRawData, cRawDataLen)
+	rc, err := libTestAnnotationAddRawData(context.ctx, detail, rawData)
 	if rc != 0 {
 		return err
 	}
+	indexRawData(context.name, detail, rawData)
 	return nil
 }
 
@@ -244,17 +370,24 @@ func (context *AnnotationTestContext) AddText(detail string, contentType Annotat
 		return ErrAnnotationTestMissingDetail
 	}
 
-	cDetail := C.CString(detail)
-	defer C.free(unsafe.Pointer(cDetail))
+	err := checkName("detail", detail)
+	if err != nil {
+		return err
+	}
+	err = checkText(text)
+	if err != nil {
+		return err
+	}
 
-	cText := C.CString(text)
-	defer C.free(unsafe.Pointer(cText))
+	if err := require(fnTestAnnotationAddText); err != nil {
+		return err
+	}
 
-	rc, err := C.undoex_test_annotation_add_text(context.ctx, cDetail,
-		(C.undoex_annotation_content_type_t)(contentType), cText)
+	rc, err := libTestAnnotationAddText(context.ctx, detail, contentType, text)
 	if rc != 0 {
 		return err
 	}
+	indexText(context.name, detail, contentType, text)
 	return nil
 }
 
@@ -270,13 +403,19 @@ func (context *AnnotationTestContext) AddInt(detail string, value int64) error {
 		return ErrAnnotationTestMissingDetail
 	}
 
-	cDetail := C.CString(detail)
-	defer C.free(unsafe.Pointer(cDetail))
+	err := checkName("detail", detail)
+	if err != nil {
+		return err
+	}
+
+	if err := require(fnTestAnnotationAddInt); err != nil {
+		return err
+	}
 
-	rc, err := C.undoex_test_annotation_add_int(context.ctx, cDetail,
-		(C.int64_t)(value))
+	rc, err := libTestAnnotationAddInt(context.ctx, detail, value)
 	if rc != 0 {
 		return err
 	}
+	indexInt(context.name, detail, value)
 	return nil
 }
@@ -39,6 +39,40 @@ func TestAnnotationTestStartEnd(t *testing.T) {
 	}
 }
 
+func TestAnnotationTestTiming(t *testing.T) {
+	context, err := AnnotationTestNew("testname", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer context.Free()
+
+	if context.Duration() != 0 {
+		t.Fatal("Unexpected duration before Start()")
+	}
+
+	err = context.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	err = context.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	duration := context.Duration()
+	if duration < 10*time.Millisecond {
+		t.Fatal("Duration shorter than expected:", duration)
+	}
+
+	time.Sleep(time.Millisecond)
+	if context.Duration() != duration {
+		t.Fatal("Duration changed after End()")
+	}
+}
+
 func TestAnnotationTestSetResult(t *testing.T) {
 	context, err := AnnotationTestNew("testname", false)
 	if err != nil {
@@ -106,6 +140,29 @@ func TestAnnotationTestSetOutput(t *testing.T) {
 	}
 }
 
+func TestAnnotationTestSetExternalID(t *testing.T) {
+	context, err := AnnotationTestNew("testname", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer context.Free()
+
+	err = context.SetExternalID("TestRail", "C1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = context.SetExternalID("", "PROJ-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = context.SetExternalID("TestRail", "")
+	if err != ErrAnnotationTestMissingID {
+		t.Fatal("Expected SetExternalID() to fail with missing ID")
+	}
+}
+
 func TestAnnotationTestAdd(t *testing.T) {
 	context, err := AnnotationTestNew("testname", false)
 	if err != nil {
@@ -190,6 +247,11 @@ func TestAnnotationTestUseAfterFree(t *testing.T) {
 	if err != ErrAnnotationTestContextInvalid {
 		t.Fatal("Expected AddInt() to fail with use after free")
 	}
+
+	err = context.SetExternalID("TestRail", "C1234")
+	if err != ErrAnnotationTestContextInvalid {
+		t.Fatal("Expected SetExternalID() to fail with use after free")
+	}
 }
 
 func TestAnnotationTestMissingDetail(t *testing.T) {
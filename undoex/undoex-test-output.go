@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// outputCapture accumulates up to limit bytes of test output.
+type outputCapture struct {
+	mu          sync.Mutex
+	buf         bytes.Buffer
+	limit       int
+	truncated   bool
+	contentType AnnotationContentType
+	tee         io.Writer
+}
+
+// Write stores p, up to the capture limit, and passes it on to the tee writer, if any.
+func (c *outputCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	room := c.limit - c.buf.Len()
+	if room >= len(p) {
+		c.buf.Write(p)
+	} else {
+		if room > 0 {
+			c.buf.Write(p[:room])
+		}
+		c.truncated = true
+	}
+	c.mu.Unlock()
+
+	if c.tee != nil {
+		return c.tee.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns the captured output.
+func (c *outputCapture) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String()
+}
+
+// OutputWriter returns a writer which captures the output of the test.
+//
+// Everything written is passed on to <w>, if not nil, so the writer can be
+// placed between a test and its usual destination, such as the Stdout of
+// an exec.Cmd. Up to <limit> bytes are kept and stored with <SetOutput>
+// when <End> is called, so harnesses don't need to buffer output
+// themselves. Output beyond the limit is still passed on to <w>.
+//
+// Calling OutputWriter again replaces any earlier capture.
+func (context *AnnotationTestContext) OutputWriter(w io.Writer, contentType AnnotationContentType, limit int) (io.Writer, error) {
+	if !context.valid {
+		return nil, ErrAnnotationTestContextInvalid
+	}
+
+	switch contentType {
+	case JSON, XML, UnstructuredText:
+		break
+	default:
+		return nil, ErrAnnotationContentTypeInvalid
+	}
+
+	context.output = &outputCapture{
+		limit:       limit,
+		contentType: contentType,
+		tee:         w,
+	}
+	return context.output, nil
+}
+
+// OutputTruncated reports whether output written to the <OutputWriter> exceeded its limit.
+func (context *AnnotationTestContext) OutputTruncated() bool {
+	if context.output == nil {
+		return false
+	}
+	context.output.mu.Lock()
+	defer context.output.mu.Unlock()
+	return context.output.truncated
+}
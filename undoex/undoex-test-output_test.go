@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undoex
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestOutputCapture(t *testing.T) {
+	var tee bytes.Buffer
+	capture := &outputCapture{limit: 8, tee: &tee}
+
+	fmt.Fprint(capture, "0123")
+	fmt.Fprint(capture, "456789")
+	fmt.Fprint(capture, "abc")
+
+	if capture.String() != "01234567" {
+		t.Fatalf("Captured output doesn't match (%q)", capture.String())
+	}
+	if !capture.truncated {
+		t.Fatal("Expected capture to be truncated")
+	}
+	if tee.String() != "0123456789abc" {
+		t.Fatalf("Tee output doesn't match (%q)", tee.String())
+	}
+}
+
+func TestAnnotationTestOutputWriter(t *testing.T) {
+	context, err := AnnotationTestNew("testname", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer context.Free()
+
+	_, err = context.OutputWriter(nil, 42, 1024)
+	if err != ErrAnnotationContentTypeInvalid {
+		t.Fatal("Expected OutputWriter() to fail with invalid content type")
+	}
+
+	var tee bytes.Buffer
+	w, err := context.OutputWriter(&tee, UnstructuredText, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = context.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Fprintln(w, "test output")
+
+	err = context.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if context.OutputTruncated() {
+		t.Fatal("Unexpected truncated output")
+	}
+	if tee.String() != "test output\n" {
+		t.Fatalf("Tee output doesn't match (%q)", tee.String())
+	}
+}
@@ -7,28 +7,41 @@ SPDX-License-Identifier: BSD-3-Clause
 
 package undoex
 
-// #include <undoex-annotations.h>
-// #include <stdlib.h>
-// #include <errno.h>
-import "C"
 import (
 	"errors"
-	"unsafe"
+	"fmt"
+	"io"
 )
 
 // An AnnotationContentType identifies the type of textual context to be stored in a recording.
 type AnnotationContentType int
 
-// Content type values for AnnotationContentType
+// Content type values for AnnotationContentType, as in
+// undoex_annotation_content_type_t.
 const (
-	JSON             AnnotationContentType = C.undoex_annotation_content_type_JSON
-	XML              AnnotationContentType = C.undoex_annotation_content_type_XML
-	UnstructuredText AnnotationContentType = C.undoex_annotation_content_type_UNSTRUCTURED_TEXT
+	JSON             AnnotationContentType = 101
+	XML              AnnotationContentType = 102
+	UnstructuredText AnnotationContentType = 100
 )
 
 // ErrAnnotationContentTypeInvalid indicates the content type is outside the valid range.
 var ErrAnnotationContentTypeInvalid = errors.New("content type not valid")
 
+// ErrAnnotationReaderLimit indicates a reader supplied more data than the limit allowed.
+var ErrAnnotationReaderLimit = errors.New("annotation content exceeds limit")
+
+// ErrAnnotationReaderNul indicates a reader supplied textual content containing a '\0'.
+//
+// It is the reason of the *InvalidInputError returned, and wraps
+// ErrInputNul, so either may be matched with errors.Is.
+var ErrAnnotationReaderNul = fmt.Errorf("%w (read from reader)", ErrInputNul)
+
+// readerChunk is the initial size of, and growth for, the buffer used by AnnotationAddReader.
+const readerChunk = 64 * 1024
+
+// readerMaxLimit is the largest limit accepted by AnnotationAddReader.
+const readerMaxLimit = 1<<30 - 2
+
 // AnnotationAddRawData adds an annotation (which stores <raw_data> if not NULL) at the current execution point.
 //
 // The stored data can contain any sequence of bytes (including '\0').
@@ -36,24 +49,24 @@ var ErrAnnotationContentTypeInvalid = errors.New("content type not valid")
 // If your data is textual add AnnotationAddText() instead. If it's
 // numeric use AnnotationAddInt().
 func AnnotationAddRawData(name, detail string, rawData []byte) error {
-	cName := C.CString(name)
-	defer C.free(unsafe.Pointer(cName))
-
-	var cDetail *C.char
-	if len(detail) > 0 {
-		cDetail = C.CString(detail)
-		defer C.free(unsafe.Pointer(cDetail))
+	err := checkNames(name, detail)
+	if err != nil {
+		return err
+	}
+	err = checkRawData(rawData)
+	if err != nil {
+		return err
 	}
 
-	cRawData := (*C.uint8_t)(C.CBytes(rawData))
-	defer C.free(unsafe.Pointer(cRawData))
-
-	cRawDataLen := (C.size_t)(len(rawData))
+	if err := require(fnAnnotationAddRawData); err != nil {
+		return err
+	}
 
-	rc, err := C.undoex_annotation_add_raw_data(cName, cDetail, cRawData, cRawDataLen)
+	rc, err := libAnnotationAddRawData(name, detail, rawData)
 	if rc != 0 {
 		return err
 	}
+	indexRawData(name, detail, rawData)
 	return nil
 }
 
@@ -72,41 +85,129 @@ func AnnotationAddText(name, detail string, contentType AnnotationContentType, t
 		return ErrAnnotationContentTypeInvalid
 	}
 
-	cName := C.CString(name)
-	defer C.free(unsafe.Pointer(cName))
-
-	var cDetail *C.char
-	if len(detail) > 0 {
-		cDetail = C.CString(detail)
-		defer C.free(unsafe.Pointer(cDetail))
+	err := checkNames(name, detail)
+	if err != nil {
+		return err
+	}
+	err = checkText(text)
+	if err != nil {
+		return err
 	}
 
-	cText := C.CString(text)
-	defer C.free(unsafe.Pointer(cText))
+	if err := require(fnAnnotationAddText); err != nil {
+		return err
+	}
 
-	rc, err := C.undoex_annotation_add_text(cName, cDetail,
-		(C.undoex_annotation_content_type_t)(contentType), cText)
+	rc, err := libAnnotationAddText(name, detail, contentType, text)
 	if rc != 0 {
 		return err
 	}
+	indexText(name, detail, contentType, text)
 	return nil
 }
 
 // AnnotationAddInt adds an annotation (which stores <value>) at the current execution point.
 func AnnotationAddInt(name, detail string, value int64) error {
-	cName := C.CString(name)
-	defer C.free(unsafe.Pointer(cName))
+	err := checkNames(name, detail)
+	if err != nil {
+		return err
+	}
+
+	if err := require(fnAnnotationAddInt); err != nil {
+		return err
+	}
 
-	var cDetail *C.char
-	if len(detail) > 0 {
-		cDetail = C.CString(detail)
-		defer C.free(unsafe.Pointer(cDetail))
+	rc, err := libAnnotationAddInt(name, detail, value)
+	if rc != 0 {
+		return err
+	}
+	indexInt(name, detail, value)
+	return nil
+}
+
+// AnnotationAddReader adds an annotation (which stores text read from <r>) at the current execution point.
+//
+// The content is read until EOF directly in to memory passed to the
+// annotation library, so large payloads don't need to be buffered in Go
+// memory first. No more than <limit> bytes are accepted: if <r> supplies
+// more, ErrAnnotationReaderLimit is returned and no annotation is added.
+// The limit may not exceed 1GiB.
+//
+// See <AnnotationAddText> for details of the content type.
+func AnnotationAddReader(name, detail string, contentType AnnotationContentType, r io.Reader, limit int64) error {
+	switch contentType {
+	case JSON, XML, UnstructuredText:
+		break
+	default:
+		return ErrAnnotationContentTypeInvalid
+	}
+
+	if limit < 0 || limit > readerMaxLimit {
+		return ErrAnnotationReaderLimit
+	}
+
+	err := checkNames(name, detail)
+	if err != nil {
+		return err
+	}
+
+	// Room for the '\0' terminator and one extra byte to detect content
+	// exceeding the limit.
+	capacity := limit + 2
+	if capacity > readerChunk {
+		capacity = readerChunk
+	}
+	cText := libAlloc(capacity)
+	if cText == nil {
+		return ErrAnnotationReaderLimit
+	}
+	defer func() { libFree(cText) }()
+
+	var length int64
+	for {
+		if length == capacity-1 {
+			if length > limit {
+				return ErrAnnotationReaderLimit
+			}
+			newCapacity := capacity + readerChunk
+			if newCapacity > limit+2 {
+				newCapacity = limit + 2
+			}
+			newText := libRealloc(cText, capacity, newCapacity)
+			if newText == nil {
+				return ErrAnnotationReaderLimit
+			}
+			cText = newText
+			capacity = newCapacity
+		}
+
+		buf := (*[1 << 30]byte)(cText)[length : capacity-1 : capacity-1]
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			if b == 0 {
+				return &InvalidInputError{"text", ErrAnnotationReaderNul}
+			}
+		}
+		length += int64(n)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	if length > limit {
+		return ErrAnnotationReaderLimit
+	}
+	(*[1 << 30]byte)(cText)[length] = 0
+
+	if err := require(fnAnnotationAddText); err != nil {
+		return err
 	}
 
-	rc, err := C.undoex_annotation_add_int(cName, cDetail,
-		(C.int64_t)(value))
+	rc, err := libAnnotationAddTextBuffer(name, detail, contentType, cText)
 	if rc != 0 {
 		return err
 	}
+	indexAdd(IndexEntry{Name: name, Detail: detail, Payload: PayloadText, ContentType: contentType, Size: int(length)})
 	return nil
 }
@@ -8,6 +8,9 @@ SPDX-License-Identifier: BSD-3-Clause
 package undoex
 
 import (
+	"bytes"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -65,3 +68,45 @@ func TestAnnotationAddInt(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAnnotationAddReader(t *testing.T) {
+	text := strings.Repeat("key1: value1\n", 10000)
+	err := AnnotationAddReader(
+		"testname", "testdetail", UnstructuredText, strings.NewReader(text), int64(len(text)))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnnotationAddReaderLimit(t *testing.T) {
+	text := strings.Repeat("x", 100000)
+	err := AnnotationAddReader(
+		"testname", "testdetail", UnstructuredText, strings.NewReader(text), int64(len(text)-1))
+	if err != ErrAnnotationReaderLimit {
+		t.Fatal("Expected AnnotationAddReader() to fail with content over limit:", err)
+	}
+
+	err = AnnotationAddReader(
+		"testname", "testdetail", UnstructuredText, strings.NewReader(text), -1)
+	if err != ErrAnnotationReaderLimit {
+		t.Fatal("Expected AnnotationAddReader() to fail with negative limit:", err)
+	}
+}
+
+func TestAnnotationAddReaderInvalid(t *testing.T) {
+	err := AnnotationAddReader(
+		"testname", "testdetail", 42, strings.NewReader("junk"), 4)
+	if err != ErrAnnotationContentTypeInvalid {
+		t.Fatal("Expected AnnotationAddReader() to fail with invalid content type:", err)
+	}
+
+	err = AnnotationAddReader(
+		"testname", "testdetail", UnstructuredText, bytes.NewReader([]byte{'a', 0, 'b'}), 3)
+	var inputErr *InvalidInputError
+	if !errors.As(err, &inputErr) || !errors.Is(err, ErrAnnotationReaderNul) || !errors.Is(err, ErrInputNul) {
+		t.Fatal("Expected AnnotationAddReader() to fail with embedded NUL:", err)
+	}
+	if err.Error() != "annotation text contains '\\0' (read from reader)" {
+		t.Fatal("Unexpected error message:", err)
+	}
+}
@@ -0,0 +1,484 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package undohttp provides an HTTP handler for controlling Live Recorder in a running server.
+//
+// In the spirit of net/http/pprof, the handler can be mounted in an
+// existing server:
+//
+//	http.Handle("/debug/undo/", undohttp.NewHandler("/var/lib/recordings"))
+//
+// The following endpoints are provided below the mount point:
+//
+//	GET  status            report the recording and event log state as JSON
+//	POST start             start recording
+//	POST stop              stop recording, saving it first if ?file= is given
+//	POST save?file=<name>  save the recording so far
+//	GET  levels            report the annotation levels set, by component
+//	POST levels?component=<name>&level=<level>
+//	                       set a component's annotation level, or clear it
+//	                       if no level is given
+//
+// Saves without a file given are named by expanding the handler's
+// NameTemplate, so they follow the IDs set with undolr.SetIDGenerator.
+//
+// If the handler has a Queue, saves are made through it so they run one at
+// a time alongside snapshots from other sources, and the status reports
+// the queue. The priority of a save defaults to undolr.PriorityOperator
+// and may be given with ?priority=.
+//
+// Annotation levels are those of undoex.SetAnnotationLevel, so the volume
+// of annotations from undoex.Annotators can be changed without
+// redeploying. The empty component sets the default level.
+//
+// Saves on stop report progress as a stream of server-sent events if the
+// request accepts "text/event-stream". Each "progress" event carries the
+// percentage saved, and the stream ends with a "complete" event carrying
+// the same JSON as a plain request would receive, or an "error" event:
+//
+//	curl -N -X POST -H 'Accept: text/event-stream' \
+//		'http://localhost:8080/debug/undo/stop?file=snapshot.undo'
+//
+// Unlike net/http/pprof nothing is registered automatically, as the
+// endpoints change the behaviour of the process. Mount the handler only
+// where it is protected from untrusted clients. Recordings are only ever
+// written to the handler's directory.
+package undohttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.undo.io/bindings/undoex"
+	"go.undo.io/bindings/undolr"
+)
+
+// Status is the JSON body returned by the status endpoint.
+type Status struct {
+	// Mode is the recording mode, see undolr.Mode.
+	Mode string `json:"mode"`
+
+	// DegradedReason is why recording fell back to annotation-only mode,
+	// if it did.
+	DegradedReason string `json:"degraded_reason,omitempty"`
+
+	// Version is the Live Recorder library version.
+	Version string `json:"version,omitempty"`
+
+	// EventLogSize is the maximum size of the event log in bytes. It is
+	// also reported in EventLog, and kept for existing clients.
+	EventLogSize int64 `json:"event_log_size,omitempty"`
+
+	// EventLog describes the event log, see undolr.EventLogStats.
+	EventLog *undolr.EventLogStatistics `json:"event_log,omitempty"`
+
+	// EventLogFill is the estimated fraction of the event log in use,
+	// from 0 to 1, if the handler has an EventLogRate. The library does
+	// not report it, so it is estimated as for undolr.WatchEventLog.
+	EventLogFill float64 `json:"event_log_fill,omitempty"`
+
+	// Held lists the IDs of held recordings, see undolr.HoldRecording.
+	Held []string `json:"held,omitempty"`
+
+	// Dir is the directory recordings are saved to.
+	Dir string `json:"dir"`
+
+	// Queue lists the snapshots running and queued, if the handler has a
+	// queue.
+	Queue []undolr.QueuedSnapshot `json:"queue,omitempty"`
+
+	// Overhead is the estimated overhead of recording, if
+	// undolr.StartOverheadMonitor has been called.
+	Overhead *undolr.OverheadEstimate `json:"overhead,omitempty"`
+}
+
+// saveResponse is the JSON body returned by successful saves.
+type saveResponse struct {
+	File     string        `json:"file"`
+	Bytes    int64         `json:"bytes"`
+	Duration time.Duration `json:"duration_ns"`
+	SHA256   string        `json:"sha256,omitempty"`
+	Profiles []string      `json:"profiles,omitempty"`
+}
+
+// A Handler serves the recorder control endpoints.
+type Handler struct {
+	// Dir is the directory recordings are saved to.
+	Dir string
+
+	// Queue, if set, runs saves requested through the handler.
+	Queue *undolr.SnapshotQueue
+
+	// Profiles selects profiles captured alongside recordings saved
+	// through the save endpoint, once each save completes.
+	Profiles undolr.ProfileOptions
+
+	// EventLogRate, if set, is the rate at which history is added to the
+	// event log in bytes per second, as for undolr.EventLogWatchOptions.
+	// The status then reports the estimated fill of the event log.
+	EventLogRate int64
+
+	// NameTemplate is the template, expanded by undolr.ExpandTemplate,
+	// naming recordings saved without a file given. If empty,
+	// DefaultNameTemplate is used.
+	NameTemplate string
+}
+
+// DefaultNameTemplate names recordings saved without a file given. IDs
+// come from the undolr IDGenerator, so are UUIDv7s unless another
+// generator is set with undolr.SetIDGenerator.
+const DefaultNameTemplate = "recording-{pid}-{id}.undo"
+
+// NewHandler returns a Handler saving recordings to dir.
+func NewHandler(dir string) *Handler {
+	return &Handler{Dir: dir}
+}
+
+// ServeHTTP dispatches on the last element of the request path, so the
+// handler works wherever it is mounted.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := path.Base(r.URL.Path)
+	if strings.HasSuffix(r.URL.Path, "/") {
+		endpoint = "status"
+	}
+
+	switch endpoint {
+	case "status":
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		h.status(w)
+	case "start":
+		if !allow(w, r, http.MethodPost) {
+			return
+		}
+		h.start(w)
+	case "stop":
+		if !allow(w, r, http.MethodPost) {
+			return
+		}
+		h.stop(w, r)
+	case "save":
+		if !allow(w, r, http.MethodPost) {
+			return
+		}
+		h.save(w, r)
+	case "levels":
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			writeJSON(w, undoex.AnnotationLevels())
+		case http.MethodPost:
+			h.setLevel(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method || (method == http.MethodGet && r.Method == http.MethodHead) {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError reports err with an appropriate status code.
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, undolr.ErrNotSupportedByLibrary):
+		code = http.StatusNotImplemented
+	case errors.Is(err, undolr.ErrAlreadyRecording), errors.Is(err, errNotRecording):
+		code = http.StatusConflict
+	case errors.Is(err, errBadFile), errors.Is(err, undoex.ErrAnnotationLevelInvalid):
+		code = http.StatusBadRequest
+	}
+	http.Error(w, err.Error(), code)
+}
+
+var (
+	errNotRecording = errors.New("not recording")
+	errBadFile      = errors.New("file must be a plain file name")
+)
+
+func (h *Handler) status(w http.ResponseWriter) {
+	status := Status{
+		Mode:    undolr.CurrentMode().String(),
+		Version: undolr.GetVersionString(),
+		Held:    undolr.HeldRecordings(),
+		Dir:     h.Dir,
+	}
+	if reason := undolr.DegradedReason(); reason != nil {
+		status.DegradedReason = reason.Error()
+	}
+	if stats, err := undolr.EventLogStats(); err == nil {
+		status.EventLogSize = stats.Size
+		status.EventLog = &stats
+		status.EventLogFill = eventLogFill(stats, h.EventLogRate)
+	}
+	if h.Queue != nil {
+		status.Queue = h.Queue.Pending()
+	}
+	if overhead := undolr.Overhead(); overhead.Monitoring {
+		status.Overhead = &overhead
+	}
+	writeJSON(w, status)
+}
+
+// eventLogFill estimates the fraction of the event log in use from the
+// rate history is added to it, or returns zero if the rate is not known.
+func eventLogFill(stats undolr.EventLogStatistics, rate int64) float64 {
+	if rate <= 0 || stats.Size <= 0 || !stats.Recording {
+		return 0
+	}
+	fill := float64(rate) * stats.Elapsed.Seconds() / float64(stats.Size)
+	if fill > 1 {
+		return 1
+	}
+	return fill
+}
+
+func (h *Handler) start(w http.ResponseWriter) {
+	if undolr.CurrentMode() == undolr.ModeRecording {
+		writeError(w, undolr.ErrAlreadyRecording)
+		return
+	}
+	err := undolr.Start()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// filename returns the path in the handler's directory for the file
+// requested by r. If none was given a name is generated from the
+// handler's NameTemplate, or if generate is false an empty path is
+// returned.
+func (h *Handler) filename(r *http.Request, generate bool) (string, error) {
+	name := r.FormValue("file")
+	if name == "" {
+		if !generate {
+			return "", nil
+		}
+		template := h.NameTemplate
+		if template == "" {
+			template = DefaultNameTemplate
+		}
+		var err error
+		name, err = undolr.ExpandTemplate(template, undolr.PathMetadata{
+			Kind: undolr.SaveKindSync,
+			Time: undolr.Now(),
+			PID:  os.Getpid(),
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return "", errBadFile
+	}
+
+	err := os.MkdirAll(h.Dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(h.Dir, name), nil
+}
+
+func (h *Handler) stop(w http.ResponseWriter, r *http.Request) {
+	if undolr.CurrentMode() != undolr.ModeRecording {
+		writeError(w, errNotRecording)
+		return
+	}
+
+	filename, err := h.filename(r, false)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if filename == "" {
+		err = undolr.StopAndDiscard()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	context, err := undolr.Stop()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer context.Discard()
+
+	if flusher, ok := w.(http.Flusher); ok && acceptsEvents(r) {
+		streamSave(w, flusher, context, filename)
+		return
+	}
+
+	// The recording has been stopped, so wait for the save to complete
+	// even if the client goes away, rather than abandon it and lose the
+	// history.
+	ch, err := context.SaveNotify(filename)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res := <-ch
+	if res.Err != nil {
+		writeError(w, res.Err)
+		return
+	}
+	stats := res.Stats
+	writeJSON(w, saveResponse{stats.Filename, stats.BytesWritten, stats.Duration, stats.SHA256, nil})
+}
+
+func (h *Handler) save(w http.ResponseWriter, r *http.Request) {
+	if undolr.CurrentMode() != undolr.ModeRecording {
+		writeError(w, errNotRecording)
+		return
+	}
+
+	filename, err := h.filename(r, true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var stats undolr.SaveStats
+	if h.Queue == nil {
+		stats, err = undolr.SaveWithStats(filename)
+	} else {
+		req := undolr.SnapshotRequest{
+			Source:   "http " + r.RemoteAddr,
+			Priority: undolr.PriorityOperator,
+			Filename: filename,
+		}
+		if priority := r.FormValue("priority"); priority != "" {
+			req.Priority, err = strconv.Atoi(priority)
+			if err != nil {
+				http.Error(w, "invalid priority", http.StatusBadRequest)
+				return
+			}
+		}
+		stats, err = h.Queue.Submit(r.Context(), req)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp := saveResponse{stats.Filename, stats.BytesWritten, stats.Duration, stats.SHA256, nil}
+	resp.Profiles, err = undolr.CaptureProfiles(stats.Filename, h.Profiles)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func (h *Handler) setLevel(w http.ResponseWriter, r *http.Request) {
+	component := r.FormValue("component")
+	name := r.FormValue("level")
+	if name == "" {
+		undoex.ClearAnnotationLevel(component)
+	} else {
+		level, err := undoex.ParseAnnotationLevel(name)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		undoex.SetAnnotationLevel(component, level)
+	}
+	writeJSON(w, undoex.AnnotationLevels())
+}
+
+// progressInterval is the time between progress events.
+const progressInterval = 250 * time.Millisecond
+
+func acceptsEvents(r *http.Request) bool {
+	for _, accept := range r.Header["Accept"] {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+			if mediaType == "text/event-stream" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeEvent(w http.ResponseWriter, flusher http.Flusher, event string, v interface{}) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	flusher.Flush()
+}
+
+// streamSave saves context to filename, sending its progress as
+// server-sent events.
+func streamSave(w http.ResponseWriter, flusher http.Flusher, context *undolr.RecordingContext, filename string) {
+	ch, err := context.SaveProgress(filename, progressInterval)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// The save can't be aborted, so keep receiving until it completes
+	// even if the client goes away.
+	var final undolr.SaveStatus
+	for status := range ch {
+		final = status
+		if !status.Complete && status.Err == nil {
+			writeEvent(w, flusher, "progress", struct {
+				Progress int `json:"progress"`
+			}{status.Progress})
+		}
+	}
+
+	err = final.Err
+	if err == nil && !final.Complete {
+		err = undolr.ErrRecordingContextSaveIncomplete
+	}
+	var stats undolr.SaveStats
+	if err == nil {
+		stats, err = context.SaveStats()
+	}
+	if err != nil {
+		writeEvent(w, flusher, "error", struct {
+			Error string `json:"error"`
+		}{err.Error()})
+		return
+	}
+	writeEvent(w, flusher, "complete", saveResponse{stats.Filename, stats.BytesWritten, stats.Duration, stats.SHA256, nil})
+}
@@ -0,0 +1,396 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undohttp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.undo.io/bindings/undoex"
+	"go.undo.io/bindings/undolr"
+)
+
+func request(t *testing.T, h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestStatus(t *testing.T) {
+	h := NewHandler("/tmp/recordings")
+
+	for _, target := range []string{"/debug/undo/", "/debug/undo/status"} {
+		w := request(t, h, http.MethodGet, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected status %d", target, w.Code)
+		}
+
+		var status Status
+		err := json.Unmarshal(w.Body.Bytes(), &status)
+		if err != nil {
+			t.Fatal("Unmarshal:", err)
+		}
+		if status.Mode != undolr.CurrentMode().String() || status.Dir != "/tmp/recordings" {
+			t.Fatalf("Unexpected status: %+v", status)
+		}
+	}
+}
+
+func TestRouting(t *testing.T) {
+	h := NewHandler(os.TempDir())
+
+	for _, test := range []struct {
+		method string
+		target string
+		code   int
+	}{
+		{http.MethodPost, "/debug/undo/status", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/debug/undo/start", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/debug/undo/stop", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/debug/undo/save", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/debug/undo/other", http.StatusNotFound},
+	} {
+		w := request(t, h, test.method, test.target)
+		if w.Code != test.code {
+			t.Errorf("%s %s: status %d, expected %d", test.method, test.target, w.Code, test.code)
+		}
+	}
+}
+
+func TestNotRecording(t *testing.T) {
+	if undolr.CurrentMode() == undolr.ModeRecording {
+		t.Skip("Already recording")
+	}
+	h := NewHandler(os.TempDir())
+
+	for _, target := range []string{"/debug/undo/stop", "/debug/undo/save"} {
+		w := request(t, h, http.MethodPost, target)
+		if w.Code != http.StatusConflict {
+			t.Errorf("%s: status %d, expected %d", target, w.Code, http.StatusConflict)
+		}
+	}
+}
+
+func TestRecording(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undohttp_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := NewHandler(dir)
+
+	w := request(t, h, http.MethodPost, "/debug/undo/start")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("start: status %d: %s", w.Code, w.Body)
+	}
+	w = request(t, h, http.MethodPost, "/debug/undo/start")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second start: status %d, expected %d", w.Code, http.StatusConflict)
+	}
+
+	w = request(t, h, http.MethodPost, "/debug/undo/save?file=../escape.undo")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("save outside directory: status %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = request(t, h, http.MethodPost, "/debug/undo/save?file=first.undo")
+	if w.Code != http.StatusOK {
+		t.Fatalf("save: status %d: %s", w.Code, w.Body)
+	}
+	var saved saveResponse
+	err = json.Unmarshal(w.Body.Bytes(), &saved)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if saved.File != filepath.Join(dir, "first.undo") || saved.Bytes == 0 {
+		t.Fatalf("Unexpected save response: %+v", saved)
+	}
+
+	w = request(t, h, http.MethodPost, "/debug/undo/stop?file=last.undo")
+	if w.Code != http.StatusOK {
+		t.Fatalf("stop: status %d: %s", w.Code, w.Body)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "last.undo")); err != nil {
+		t.Fatal("Recording not saved on stop:", err)
+	}
+}
+
+func TestStopFailed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undohttp_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A directory in the way of the recording makes the save fail.
+	err = os.Mkdir(filepath.Join(dir, "taken.undo"), 0755)
+	if err != nil {
+		t.Fatal("Mkdir:", err)
+	}
+
+	h := NewHandler(dir)
+	w := request(t, h, http.MethodPost, "/debug/undo/start")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("start: status %d: %s", w.Code, w.Body)
+	}
+
+	w = request(t, h, http.MethodPost, "/debug/undo/stop?file=taken.undo")
+	if w.Code < 500 {
+		t.Fatalf("stop with failed save: status %d, expected 5xx: %s", w.Code, w.Body)
+	}
+}
+
+func TestStopCancelled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undohttp_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := NewHandler(dir)
+	w := request(t, h, http.MethodPost, "/debug/undo/start")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("start: status %d: %s", w.Code, w.Body)
+	}
+
+	// The client has gone away before the save completes.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/undo/stop?file=cancelled.undo", nil).WithContext(ctx))
+
+	info, err := os.Stat(filepath.Join(dir, "cancelled.undo"))
+	if err != nil || info.Size() == 0 {
+		t.Fatal("Recording not saved on stop after client went away:", err)
+	}
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time                         { return time.Time(c) }
+func (c fixedClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func TestGeneratedFilename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undohttp_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	undolr.SetClock(fixedClock(time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)))
+	defer undolr.SetClock(nil)
+	undolr.SetIDGenerator(undolr.IDGeneratorFunc(func(kind undolr.IDKind) (string, error) {
+		return "INC-42", nil
+	}))
+	defer undolr.SetIDGenerator(nil)
+
+	h := NewHandler(dir)
+	r := httptest.NewRequest(http.MethodPost, "/save", nil)
+	filename, err := h.filename(r, true)
+	if err != nil {
+		t.Fatal("filename:", err)
+	}
+	expected := filepath.Join(dir, fmt.Sprintf("recording-%d-INC-42.undo", os.Getpid()))
+	if filename != expected {
+		t.Fatalf("Generated %s, expected %s", filename, expected)
+	}
+
+	h.NameTemplate = "snapshot-{timestamp}.undo"
+	filename, err = h.filename(r, true)
+	if err != nil {
+		t.Fatal("filename:", err)
+	}
+	if expected := filepath.Join(dir, "snapshot-20260102T030405Z.undo"); filename != expected {
+		t.Fatalf("Generated %s, expected %s", filename, expected)
+	}
+
+	h.NameTemplate = "{unknown}.undo"
+	if _, err = h.filename(r, true); !errors.Is(err, undolr.ErrTemplateUnknownToken) {
+		t.Fatal("Expected unknown token to fail:", err)
+	}
+}
+
+func TestEventLogFill(t *testing.T) {
+	stats := undolr.EventLogStatistics{Size: 1000, Recording: true, Elapsed: 2 * time.Second}
+	for _, test := range []struct {
+		rate     int64
+		expected float64
+	}{
+		{0, 0},
+		{100, 0.2},
+		{1000, 1},
+	} {
+		if fill := eventLogFill(stats, test.rate); fill != test.expected {
+			t.Errorf("eventLogFill at rate %d: %v, expected %v", test.rate, fill, test.expected)
+		}
+	}
+
+	stats.Recording = false
+	if fill := eventLogFill(stats, 100); fill != 0 {
+		t.Errorf("eventLogFill when not recording: %v", fill)
+	}
+}
+
+func TestAcceptsEvents(t *testing.T) {
+	for _, test := range []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"text/event-stream", true},
+		{"application/json, text/event-stream;q=0.9", true},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/stop", nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		if acceptsEvents(r) != test.expected {
+			t.Errorf("acceptsEvents(%q) != %v", test.accept, test.expected)
+		}
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeEvent(w, w, "progress", struct {
+		Progress int `json:"progress"`
+	}{42})
+	if w.Body.String() != "event: progress\ndata: {\"progress\":42}\n\n" {
+		t.Fatalf("Unexpected event: %q", w.Body.String())
+	}
+	if !w.Flushed {
+		t.Fatal("Event not flushed")
+	}
+}
+
+func TestStopEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undohttp_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := NewHandler(dir)
+	w := request(t, h, http.MethodPost, "/debug/undo/start")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("start: status %d: %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/debug/undo/stop?file=events.undo", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	h.ServeHTTP(w, r)
+	if w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Unexpected response: %d %s", w.Code, w.Body)
+	}
+
+	var events []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "event: ") {
+			events = append(events, strings.TrimPrefix(scanner.Text(), "event: "))
+		}
+	}
+	if len(events) == 0 || events[len(events)-1] != "complete" {
+		t.Fatalf("Unexpected events: %v\n%s", events, w.Body)
+	}
+}
+
+func TestStatusQueue(t *testing.T) {
+	h := NewHandler(os.TempDir())
+	h.Queue = undolr.NewSnapshotQueue()
+	defer h.Queue.Close()
+
+	w := request(t, h, http.MethodGet, "/debug/undo/status")
+	if !strings.Contains(w.Body.String(), `"dir"`) || strings.Contains(w.Body.String(), `"queue"`) {
+		t.Fatalf("Unexpected status for empty queue: %s", w.Body)
+	}
+
+	if undolr.CurrentMode() != undolr.ModeRecording {
+		w = request(t, h, http.MethodPost, "/debug/undo/save?priority=high")
+		if w.Code != http.StatusConflict {
+			t.Fatalf("save when not recording: status %d, expected %d", w.Code, http.StatusConflict)
+		}
+	}
+}
+
+func TestStatusOverhead(t *testing.T) {
+	h := NewHandler(os.TempDir())
+
+	w := request(t, h, http.MethodGet, "/debug/undo/status")
+	if strings.Contains(w.Body.String(), `"overhead"`) {
+		t.Fatalf("Unexpected overhead without monitor: %s", w.Body)
+	}
+
+	err := undolr.StartOverheadMonitor(undolr.OverheadOptions{})
+	if err != nil {
+		t.Fatal("StartOverheadMonitor:", err)
+	}
+	defer undolr.StopOverheadMonitor()
+
+	w = request(t, h, http.MethodGet, "/debug/undo/status")
+	var status Status
+	err = json.Unmarshal(w.Body.Bytes(), &status)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if status.Overhead == nil || !status.Overhead.Monitoring || status.Overhead.Interval != undolr.DefaultOverheadInterval {
+		t.Fatalf("Unexpected overhead: %+v", status.Overhead)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	h := NewHandler(os.TempDir())
+	defer undoex.ClearAnnotationLevel("storage")
+
+	w := request(t, h, http.MethodPost, "/debug/undo/levels?component=storage&level=DEBUG")
+	if w.Code != http.StatusOK {
+		t.Fatalf("set level: status %d: %s", w.Code, w.Body)
+	}
+	if level := undoex.AnnotationLevels()["storage"]; level != undoex.LevelDebug {
+		t.Fatalf("Level set to %v, expected %v", level, undoex.LevelDebug)
+	}
+
+	w = request(t, h, http.MethodGet, "/debug/undo/levels")
+	var levels map[string]string
+	err := json.Unmarshal(w.Body.Bytes(), &levels)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if levels["storage"] != "debug" {
+		t.Fatalf("Unexpected levels: %s", w.Body)
+	}
+
+	w = request(t, h, http.MethodPost, "/debug/undo/levels?component=storage&level=loud")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid level: status %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = request(t, h, http.MethodPut, "/debug/undo/levels")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("PUT: status %d, expected %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	w = request(t, h, http.MethodPost, "/debug/undo/levels?component=storage")
+	if _, ok := undoex.AnnotationLevels()["storage"]; ok || w.Code != http.StatusOK {
+		t.Fatalf("Level not cleared: status %d: %s", w.Code, w.Body)
+	}
+}
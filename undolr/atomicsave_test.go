@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAtomicWriteThrough(t *testing.T) {
+	for _, mode := range []AtomicSaveMode{AtomicSaveRename, AtomicSaveSync} {
+		SetAtomicSave(mode)
+		defer SetAtomicSave(AtomicSaveOff)
+
+		filename, err := tmpnam("")
+		if err != nil {
+			t.Fatal("Filename:", err)
+		}
+		defer os.Remove(filename)
+		os.Remove(filename)
+
+		identity := func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil }
+		_, err = writeThrough(filename, identity, func(w io.Writer) error {
+			if _, err := os.Stat(filename); !os.IsNotExist(err) {
+				t.Error("Recording visible while being written:", err)
+			}
+			if _, err := os.Stat(filename + AtomicSaveSuffix); err != nil {
+				t.Error("Temporary file not written:", err)
+			}
+			_, err := io.WriteString(w, "recording")
+			return err
+		})
+		if err != nil {
+			t.Fatal("writeThrough:", err)
+		}
+		if data, _ := ioutil.ReadFile(filename); string(data) != "recording" {
+			t.Fatalf("Unexpected contents %q with mode %d", data, mode)
+		}
+		if _, err := os.Stat(filename + AtomicSaveSuffix); !os.IsNotExist(err) {
+			t.Fatal("Temporary file not renamed:", err)
+		}
+
+		os.Remove(filename)
+		failed := errors.New("write failed")
+		_, err = writeThrough(filename, identity, func(w io.Writer) error { return failed })
+		if err != failed {
+			t.Fatal("Expected write error:", err)
+		}
+		for _, name := range []string{filename, filename + AtomicSaveSuffix} {
+			if _, err := os.Stat(name); !os.IsNotExist(err) {
+				t.Fatal("Unexpected file after failed write:", name, err)
+			}
+		}
+	}
+}
+
+func TestCommitSaveFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr-atomic")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "recording.undo"+AtomicSaveSuffix)
+	if err := ioutil.WriteFile(target, []byte("recording"), 0644); err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+	err = commitSave(target, filepath.Join(dir, "missing", "recording.undo"), AtomicSaveRename)
+	if err == nil {
+		t.Fatal("Expected rename into a missing directory to fail")
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatal("Temporary file not removed:", err)
+	}
+}
+
+func TestSaveAtomic(t *testing.T) {
+	SetAtomicSave(AtomicSaveSync)
+	defer SetAtomicSave(AtomicSaveOff)
+
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer StopAndDiscard()
+
+	stats, err := SaveWithStats(filename)
+	if err != nil {
+		t.Fatal("SaveWithStats:", err)
+	}
+	if stats.Filename != filename {
+		t.Fatal("Unexpected filename in stats:", stats.Filename)
+	}
+	if _, err := os.Stat(filename + AtomicSaveSuffix); !os.IsNotExist(err) {
+		t.Fatal("Temporary file not renamed:", err)
+	}
+	verifyRecording(t, filename)
+}
@@ -0,0 +1,35 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package autoconfig configures the Undo Live Recorder from environment variables when imported.
+//
+// Importing it for its side effects calls undolr.ConfigureFromEnv as the
+// program starts, so recording can be enabled in a deployment by setting
+// UNDOLR_AUTOSTART and the other UNDOLR_* variables:
+//
+//	import _ "go.undo.io/bindings/undolr/autoconfig"
+//
+// A failure to configure or start recording does not stop the program: it
+// is reported on standard error and available as Err.
+package autoconfig
+
+import (
+	"fmt"
+	"os"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// Err is the error from configuring recording, if any.
+var Err error
+
+func init() {
+	Err = undolr.ConfigureFromEnv()
+	if Err != nil {
+		fmt.Fprintf(os.Stderr, "undolr: configuring from environment: %v\n", Err)
+	}
+}
@@ -0,0 +1,24 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package autoconfig
+
+import (
+	"os"
+	"testing"
+
+	"go.undo.io/bindings/undolr"
+)
+
+func TestInit(t *testing.T) {
+	if os.Getenv(undolr.EnvAutostart) != "" {
+		t.Skip("Environment configures recording")
+	}
+	if Err != nil {
+		t.Fatal("Unexpected error with no configuration:", Err)
+	}
+}
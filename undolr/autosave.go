@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ErrAutoSaveIntervalInvalid indicates an AutoSave interval which isn't positive.
+var ErrAutoSaveIntervalInvalid = errors.New("auto save interval not valid")
+
+// Jitter and backoff applied by AutoSave.
+const (
+	// AutoSaveJitter is the largest fraction of the interval by which
+	// each delay is randomly lengthened or shortened.
+	AutoSaveJitter = 0.1
+
+	// AutoSaveMaxBackoff is the largest multiple of the interval waited
+	// after consecutive failed saves.
+	AutoSaveMaxBackoff = 8
+)
+
+// autoSaveRandom returns a random number in [0, 1) for jitter. It is a
+// variable so tests can make it predictable.
+var autoSaveRandom = rand.Float64
+
+// AutoSaveResult describes the outcome of a save made by AutoSave.
+type AutoSaveResult struct {
+	// Filename is the name the recording was saved as, as given by the
+	// namer.
+	Filename string
+
+	// Stats holds statistics for the save. It is only valid if Err is nil.
+	Stats SaveStats
+
+	// Err is nil if the save succeeded.
+	Err error
+
+	// Failures counts the consecutive failed saves, including this one.
+	Failures int
+
+	// Next is the delay until the next save.
+	Next time.Duration
+}
+
+// AutoSave saves a recording every interval in the background until ctx is done.
+//
+// This keeps recent history on disk in case the process is killed
+// without a chance to save, for instance by SIGKILL, where
+// SaveOnTermination cannot run. Combine it with a Retention to bound the
+// disk used. Each save is made with SaveWithStats while recording, so
+// recording must have been started, to the filename returned by namer
+// for the time of the save; a nil namer names recordings
+// "autosave-<time>.undo".
+//
+// Each delay is randomly lengthened or shortened by up to AutoSaveJitter
+// of the interval, so processes started together don't save at once.
+// After a failed save the delay is doubled for each consecutive failure,
+// up to AutoSaveMaxBackoff times the interval, so a full disk or stopped
+// recording isn't retried continually. Delays are timed by the package
+// Clock.
+//
+// The outcome of each save is sent on the returned channel, dropping it
+// rather than delaying later saves if the channel is not received from.
+// The channel is closed once ctx is done and any save in progress has
+// finished.
+func AutoSave(ctx context.Context, interval time.Duration, namer func(time.Time) string) (<-chan AutoSaveResult, error) {
+	if interval <= 0 {
+		return nil, ErrAutoSaveIntervalInvalid
+	}
+	if namer == nil {
+		namer = autoSaveName
+	}
+
+	results := make(chan AutoSaveResult, 1)
+	go func() {
+		defer close(results)
+
+		failures := 0
+		delay := autoSaveDelay(interval, failures)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-currentClock().After(delay):
+			}
+
+			filename := namer(now())
+			stats, err := SaveWithStats(filename)
+			if err != nil {
+				failures++
+			} else {
+				failures = 0
+			}
+			delay = autoSaveDelay(interval, failures)
+
+			result := AutoSaveResult{
+				Filename: filename,
+				Stats:    stats,
+				Err:      err,
+				Failures: failures,
+				Next:     delay,
+			}
+			select {
+			case results <- result:
+			default:
+			}
+		}
+	}()
+	return results, nil
+}
+
+// autoSaveName is the default AutoSave namer.
+func autoSaveName(t time.Time) string {
+	return "autosave-" + t.Format(rotatorTimeFormat) + rotatorSuffix
+}
+
+// autoSaveDelay returns the delay before the next save after the given
+// number of consecutive failures, with backoff and jitter.
+func autoSaveDelay(interval time.Duration, failures int) time.Duration {
+	delay := interval
+	for i := 0; i < failures && delay < AutoSaveMaxBackoff*interval; i++ {
+		delay *= 2
+	}
+	if delay > AutoSaveMaxBackoff*interval {
+		delay = AutoSaveMaxBackoff * interval
+	}
+	jitter := (autoSaveRandom()*2 - 1) * AutoSaveJitter * float64(interval)
+	return delay + time.Duration(jitter)
+}
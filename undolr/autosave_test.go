@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAutoSaveDelay(t *testing.T) {
+	defer func(random func() float64) { autoSaveRandom = random }(autoSaveRandom)
+	autoSaveRandom = func() float64 { return 0.5 }
+
+	for failures, expected := range []time.Duration{
+		time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 8 * time.Minute,
+	} {
+		if delay := autoSaveDelay(time.Minute, failures); delay != expected {
+			t.Errorf("autoSaveDelay(%d failures) = %v; expected %v", failures, delay, expected)
+		}
+	}
+
+	autoSaveRandom = func() float64 { return 0 }
+	if delay := autoSaveDelay(time.Minute, 0); delay != 54*time.Second {
+		t.Error("Unexpected delay with least jitter", delay)
+	}
+	autoSaveRandom = func() float64 { return 1 }
+	if delay := autoSaveDelay(time.Minute, 3); delay != 8*time.Minute+6*time.Second {
+		t.Error("Unexpected delay with most jitter", delay)
+	}
+}
+
+func TestAutoSaveInvalid(t *testing.T) {
+	if _, err := AutoSave(context.Background(), 0, nil); err != ErrAutoSaveIntervalInvalid {
+		t.Fatal("Expected AutoSave() to fail with ErrAutoSaveIntervalInvalid:", err)
+	}
+}
+
+func TestAutoSaveFailures(t *testing.T) {
+	defer func(random func() float64) { autoSaveRandom = random }(autoSaveRandom)
+	autoSaveRandom = func() float64 { return 0.5 }
+	when := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	clock := newFakeClock(when)
+	SetClock(clock)
+	defer SetClock(nil)
+
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := AutoSave(ctx, time.Minute, func(t time.Time) string {
+		return filepath.Join(dir, t.Format("150405")+".undo")
+	})
+	if err != nil {
+		t.Fatal("AutoSave:", err)
+	}
+
+	// Saving fails while not recording, backing off each time.
+	for i, expected := range []time.Duration{2 * time.Minute, 4 * time.Minute} {
+		clock.Advance(time.Minute)
+		timer := <-clock.timers
+		timer <- clock.Now()
+		result := <-results
+		if result.Err == nil || result.Failures != i+1 || result.Next != expected {
+			t.Fatalf("Unexpected result %+v", result)
+		}
+		if result.Filename != filepath.Join(dir, clock.Now().Format("150405")+".undo") {
+			t.Fatal("Unexpected filename", result.Filename)
+		}
+	}
+
+	<-clock.timers
+	cancel()
+	if _, ok := <-results; ok {
+		t.Fatal("Expected results to be closed once cancelled")
+	}
+}
+
+func TestAutoSaveName(t *testing.T) {
+	when := time.Date(2001, 2, 3, 4, 5, 6, 7, time.UTC)
+	if name := autoSaveName(when); name != "autosave-20010203T040506.000000007.undo" {
+		t.Fatal("Unexpected name", name)
+	}
+}
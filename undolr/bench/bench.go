@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package bench provides reproducible benchmarks of Undo Live Recorder overheads.
+//
+// The benchmarks measure the time taken to attach the recorder, the cost
+// of each annotation, the stop-the-world duration of synchronous saves for
+// a range of event log sizes and the throughput of asynchronous saves.
+//
+// Run them with:
+//
+//	go test -bench . go.undo.io/bindings/undolr/bench -args -bench.json results.json
+//
+// When -bench.json is given the results are also written as JSON, in the
+// format described by Results, so they can be compared across hosts and
+// library versions.
+package bench
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// A Result holds the measurements from a single benchmark.
+type Result struct {
+	Name         string        `json:"name"`
+	Iterations   int           `json:"iterations"`
+	PerOperation time.Duration `json:"per_operation_ns"`
+
+	// EventLogSize is the event log size in use, for benchmarks which
+	// vary it, in bytes.
+	EventLogSize int64 `json:"event_log_size,omitempty"`
+
+	// BytesPerSecond is the save throughput, for benchmarks which save.
+	BytesPerSecond float64 `json:"bytes_per_second,omitempty"`
+}
+
+// Results is the JSON document written for a benchmark run.
+type Results struct {
+	LibraryVersion string    `json:"library_version"`
+	GoVersion      string    `json:"go_version"`
+	GOOS           string    `json:"goos"`
+	GOARCH         string    `json:"goarch"`
+	NumCPU         int       `json:"num_cpu"`
+	Timestamp      time.Time `json:"timestamp"`
+	Results        []Result  `json:"results"`
+}
+
+// A Collector accumulates Results from benchmarks, which may run concurrently.
+type Collector struct {
+	mu      sync.Mutex
+	results map[string]Result
+}
+
+// Add records a result, replacing any earlier result of the same name.
+//
+// The testing package runs each benchmark several times with increasing
+// iteration counts; only the last, and longest, run is kept.
+func (c *Collector) Add(result Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.results == nil {
+		c.results = make(map[string]Result)
+	}
+	c.results[result.Name] = result
+}
+
+// Results returns the collected results along with details of the environment.
+func (c *Collector) Results() Results {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := Results{
+		LibraryVersion: undolr.GetVersionString(),
+		GoVersion:      runtime.Version(),
+		GOOS:           runtime.GOOS,
+		GOARCH:         runtime.GOARCH,
+		NumCPU:         runtime.NumCPU(),
+		Timestamp:      time.Now().UTC(),
+	}
+	for _, result := range c.results {
+		results.Results = append(results.Results, result)
+	}
+	sort.Slice(results.Results, func(i, j int) bool {
+		return results.Results[i].Name < results.Results[j].Name
+	})
+	return results
+}
+
+// WriteJSON writes the collected results to the named file.
+func (c *Collector) WriteJSON(filename string) error {
+	data, err := json.MarshalIndent(c.Results(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0644)
+}
@@ -0,0 +1,214 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package bench
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"go.undo.io/bindings/undoex"
+	"go.undo.io/bindings/undolr"
+)
+
+var jsonOutput = flag.String("bench.json", "", "write benchmark results as JSON to this file")
+
+var collector Collector
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	rc := m.Run()
+	if *jsonOutput != "" {
+		if err := collector.WriteJSON(*jsonOutput); err != nil {
+			fmt.Fprintln(os.Stderr, "Writing results:", err)
+			rc = 1
+		}
+	}
+	os.Exit(rc)
+}
+
+// eventLogSizes are the sizes used for the synchronous save benchmarks.
+var eventLogSizes = []int64{
+	16 << 20,
+	64 << 20,
+	256 << 20,
+}
+
+func record(b *testing.B, result Result, elapsed time.Duration) {
+	result.Iterations = b.N
+	if b.N > 0 {
+		result.PerOperation = elapsed / time.Duration(b.N)
+	}
+	collector.Add(result)
+}
+
+// workload generates some recorded history between saves.
+func workload() {
+	f, err := ioutil.TempFile("", "undolr_bench_")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	data := make([]byte, 4096)
+	for i := 0; i < 256; i++ {
+		f.Write(data)
+	}
+}
+
+func tempRecording(b *testing.B) string {
+	f, err := ioutil.TempFile("", "undolr_bench_")
+	if err != nil {
+		b.Fatal("Filename:", err)
+	}
+	f.Close()
+	return f.Name()
+}
+
+func BenchmarkAttach(b *testing.B) {
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		err := undolr.Start()
+		if err != nil {
+			b.Fatal("Start:", err)
+		}
+		err = undolr.StopAndDiscard()
+		if err != nil {
+			b.Fatal("Stop:", err)
+		}
+	}
+	record(b, Result{Name: "Attach"}, time.Since(start))
+}
+
+func BenchmarkAnnotation(b *testing.B) {
+	err := undolr.Start()
+	if err != nil {
+		b.Fatal("Start:", err)
+	}
+	defer undolr.StopAndDiscard()
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		err = undoex.AnnotationAddInt("bench", "annotation", int64(i))
+		if err != nil {
+			b.Fatal("AnnotationAddInt:", err)
+		}
+	}
+	elapsed := time.Since(start)
+	b.StopTimer()
+
+	record(b, Result{Name: "Annotation"}, elapsed)
+}
+
+func BenchmarkSaveSync(b *testing.B) {
+	defaultSize, err := undolr.EventLogSizeGet()
+	if err != nil {
+		b.Fatal("EventLogSizeGet:", err)
+	}
+	defer undolr.EventLogSizeSet(defaultSize)
+
+	for _, size := range eventLogSizes {
+		size := size
+		b.Run(fmt.Sprintf("EventLog%dMiB", size>>20), func(b *testing.B) {
+			benchmarkSaveSync(b, size)
+		})
+	}
+}
+
+func benchmarkSaveSync(b *testing.B, size int64) {
+	err := undolr.EventLogSizeSet(size)
+	if err != nil {
+		b.Fatal("EventLogSizeSet:", err)
+	}
+
+	filename := tempRecording(b)
+	defer os.Remove(filename)
+
+	err = undolr.Start()
+	if err != nil {
+		b.Fatal("Start:", err)
+	}
+	defer undolr.StopAndDiscard()
+
+	var stopTheWorld time.Duration
+	var written int64
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		workload()
+		b.StartTimer()
+
+		stats, err := undolr.SaveWithStats(filename)
+		if err != nil {
+			b.Fatal("SaveWithStats:", err)
+		}
+		stopTheWorld += stats.StopTheWorld
+		written += stats.BytesWritten
+	}
+	b.StopTimer()
+
+	result := Result{
+		Name:         b.Name(),
+		EventLogSize: size,
+	}
+	if stopTheWorld > 0 {
+		result.BytesPerSecond = float64(written) / stopTheWorld.Seconds()
+	}
+	record(b, result, stopTheWorld)
+}
+
+func BenchmarkSaveAsync(b *testing.B) {
+	filename := tempRecording(b)
+	defer os.Remove(filename)
+
+	var elapsed time.Duration
+	var written int64
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		err := undolr.Start()
+		if err != nil {
+			b.Fatal("Start:", err)
+		}
+		workload()
+		context, err := undolr.Stop()
+		if err != nil {
+			b.Fatal("Stop:", err)
+		}
+		b.StartTimer()
+
+		ch := make(chan error, 1)
+		go context.SaveBackground(filename, ch)
+		err = <-ch
+		if err != nil {
+			b.Fatal("SaveBackground:", err)
+		}
+
+		b.StopTimer()
+		stats, err := context.SaveStats()
+		if err != nil {
+			b.Fatal("SaveStats:", err)
+		}
+		elapsed += stats.Duration
+		written += stats.BytesWritten
+		context.Discard()
+		b.StartTimer()
+	}
+
+	result := Result{Name: "SaveAsync"}
+	if elapsed > 0 {
+		result.BytesPerSecond = float64(written) / elapsed.Seconds()
+	}
+	record(b, result, elapsed)
+}
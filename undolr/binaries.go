@@ -0,0 +1,176 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BinariesSuffix is appended to a recording file name to name the archive written by CaptureBinaries.
+const BinariesSuffix = ".binaries.tar"
+
+// DefaultBinariesMaxBytes is the size cap used when BinaryOptions.MaxBytes is zero.
+const DefaultBinariesMaxBytes = 256 << 20
+
+// ErrBinariesCapExceeded indicates binaries were left out of the archive written by CaptureBinaries to keep within its size cap.
+var ErrBinariesCapExceeded = errors.New("binaries exceed size cap")
+
+// executablePath returns the path of the executable, a variable so tests
+// can substitute their own.
+var executablePath = os.Executable
+
+// BinaryOptions selects the binaries archived alongside a recording.
+type BinaryOptions struct {
+	// Executable includes the executable of the process.
+	Executable bool
+
+	// Libraries is an allowlist of patterns, in the syntax of
+	// filepath.Match, matched against the base names of the shared
+	// objects mapped by the process. For instance "libssl.so*" or "*".
+	Libraries []string
+
+	// MaxBytes caps the total size of the binaries archived, or zero for
+	// DefaultBinariesMaxBytes.
+	MaxBytes int64
+}
+
+// enabled reports whether any binary is selected.
+func (opts BinaryOptions) enabled() bool {
+	return opts.Executable || len(opts.Libraries) > 0
+}
+
+// allowed reports whether the library at path matches the allowlist.
+func (opts BinaryOptions) allowed(path string) bool {
+	base := filepath.Base(path)
+	for _, pattern := range opts.Libraries {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CaptureBinaries archives the selected binaries alongside a recording, so it can be replayed with identical binaries.
+//
+// The binaries are written to a tar archive named by appending
+// BinariesSuffix to recording, each under its absolute path without the
+// leading "/". The executable is added first, then the allowed libraries
+// in order of path. Binaries which would take the archive over the size
+// cap are left out, and reported by an error wrapping
+// ErrBinariesCapExceeded once the rest are written. The paths of the
+// binaries archived are returned; if there are none no archive is
+// written.
+func CaptureBinaries(recording string, opts BinaryOptions) ([]string, error) {
+	maxBytes := opts.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultBinariesMaxBytes
+	}
+
+	var paths []string
+	if opts.Executable {
+		exe, err := executablePath()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, exe)
+	}
+	if len(opts.Libraries) > 0 {
+		f := &Fingerprint{Libraries: make(map[string]string)}
+		readLibraries(f)
+		var libraries []string
+		for path := range f.Libraries {
+			if opts.allowed(path) {
+				libraries = append(libraries, path)
+			}
+		}
+		sort.Strings(libraries)
+		paths = append(paths, libraries...)
+	}
+
+	var selected, skipped []string
+	var total int64
+	for _, path := range paths {
+		fileinfo, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if total+fileinfo.Size() > maxBytes {
+			skipped = append(skipped, path)
+			continue
+		}
+		total += fileinfo.Size()
+		selected = append(selected, path)
+	}
+
+	if len(selected) > 0 {
+		err := writeBinaries(recording+BinariesSuffix, selected)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(skipped) > 0 {
+		return selected, fmt.Errorf("%w (%d bytes): %s", ErrBinariesCapExceeded, maxBytes, strings.Join(skipped, ", "))
+	}
+	return selected, nil
+}
+
+// writeBinaries writes a tar archive of paths to filename, removing it on failure.
+func writeBinaries(filename string, paths []string) (err error) {
+	out, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(filename)
+		}
+	}()
+
+	tw := tar.NewWriter(out)
+	for _, path := range paths {
+		err = addBinary(tw, path)
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func addBinary(tw *tar.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fileinfo, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(fileinfo, "")
+	if err != nil {
+		return err
+	}
+	header.Name = strings.TrimPrefix(path, "/")
+	err = tw.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, f, fileinfo.Size())
+	return err
+}
@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readBinaries returns the contents of the archive written by CaptureBinaries, by path.
+func readBinaries(t *testing.T, filename string) map[string]string {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	defer f.Close()
+
+	contents := make(map[string]string)
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return contents
+		} else if err != nil {
+			t.Fatal("Next:", err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal("ReadAll:", err)
+		}
+		contents["/"+header.Name] = string(data)
+	}
+}
+
+func TestCaptureBinaries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal("WriteFile:", err)
+		}
+		return path
+	}
+	exe := write("server", "executable")
+	libssl := write("libssl.so.3", "ssl library")
+	libc := write("libc.so.6", "c library")
+	maps := write("maps", fmt.Sprintf(
+		"7f0000000000-7f0000001000 r-xp 00000000 08:01 1 %s\n"+
+			"7f0000001000-7f0000002000 r--p 00001000 08:01 1 %s\n"+
+			"7f0000002000-7f0000003000 r-xp 00000000 08:01 2 %s\n"+
+			"7ffc00000000-7ffc00021000 rw-p 00000000 00:00 0 [stack]\n", libssl, libssl, libc))
+
+	oldExecutable, oldMaps := executablePath, procMapsPath
+	executablePath = func() (string, error) { return exe, nil }
+	procMapsPath = maps
+	defer func() { executablePath, procMapsPath = oldExecutable, oldMaps }()
+
+	recording := filepath.Join(dir, "recording.undo")
+
+	included, err := CaptureBinaries(recording, BinaryOptions{})
+	if err != nil || len(included) != 0 {
+		t.Fatal("Unexpected binaries with none selected:", included, err)
+	}
+	if _, err := os.Stat(recording + BinariesSuffix); !os.IsNotExist(err) {
+		t.Fatal("Unexpected archive with none selected:", err)
+	}
+
+	included, err = CaptureBinaries(recording, BinaryOptions{Executable: true, Libraries: []string{"libssl.so*"}})
+	if err != nil {
+		t.Fatal("CaptureBinaries:", err)
+	}
+	if strings.Join(included, " ") != exe+" "+libssl {
+		t.Fatal("Unexpected binaries included:", included)
+	}
+	contents := readBinaries(t, recording+BinariesSuffix)
+	if len(contents) != 2 || contents[exe] != "executable" || contents[libssl] != "ssl library" {
+		t.Fatalf("Unexpected archive contents: %q", contents)
+	}
+
+	included, err = CaptureBinaries(recording, BinaryOptions{Executable: true, Libraries: []string{"*"}, MaxBytes: 21})
+	if !errors.Is(err, ErrBinariesCapExceeded) || !strings.Contains(err.Error(), libssl) {
+		t.Fatal("Expected ErrBinariesCapExceeded for libssl, got", err)
+	}
+	if strings.Join(included, " ") != exe+" "+libc {
+		t.Fatal("Unexpected binaries included within cap:", included)
+	}
+	contents = readBinaries(t, recording+BinariesSuffix)
+	if len(contents) != 2 || contents[libc] != "c library" {
+		t.Fatalf("Unexpected archive contents: %q", contents)
+	}
+
+	if name := recordingName("recording.undo" + BinariesSuffix); name != "recording.undo" {
+		t.Fatal("Archive not grouped with recording:", name)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var checksumLock sync.Mutex
+var checksumSaves bool
+var checksumManifest string
+
+// SaveChecksums controls whether a SHA-256 checksum is computed for each saved recording.
+//
+// The checksum is reported as SaveStats.SHA256 and to the save hook, so
+// it is known before anything else, such as a Rotator, can remove or
+// replace the file. It is computed by reading the recording back once it
+// is saved, outside the stop-the-world period of synchronous saves,
+// except for saves streamed by SaveToWriter which are hashed as they are
+// written. Checksums are off by default.
+func SaveChecksums(enable bool) {
+	checksumLock.Lock()
+	defer checksumLock.Unlock()
+	checksumSaves = enable
+}
+
+// SetChecksumManifest sets a file to which each checksum computed is appended.
+//
+// Lines are written in the format of sha256sum, so transferred recordings
+// can be verified with "sha256sum -c". Passing "" stops writing the
+// manifest. Checksums must also be enabled with SaveChecksums.
+func SetChecksumManifest(filename string) {
+	checksumLock.Lock()
+	defer checksumLock.Unlock()
+	checksumManifest = filename
+}
+
+func checksumsEnabled() bool {
+	checksumLock.Lock()
+	defer checksumLock.Unlock()
+	return checksumSaves
+}
+
+// checksumFile returns the hex encoded SHA-256 checksum of filename.
+func checksumFile(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// addChecksum fills in the checksum of the recording file described by
+// stats, if checksums are enabled. The checksum is left empty if the file
+// can't be read.
+func addChecksum(stats *SaveStats) {
+	if stats.Filename == "" || !checksumsEnabled() {
+		return
+	}
+	sum, err := checksumFile(stats.Filename)
+	if err != nil {
+		return
+	}
+	stats.SHA256 = sum
+	appendManifest(stats)
+}
+
+// appendManifest appends the checksum in stats to the manifest, if one is set.
+func appendManifest(stats *SaveStats) {
+	checksumLock.Lock()
+	defer checksumLock.Unlock()
+	if checksumManifest == "" || stats.Filename == "" {
+		return
+	}
+
+	f, err := os.OpenFile(checksumManifest, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s  %s\n", stats.SHA256, stats.Filename)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+	err = ioutil.WriteFile(filename, []byte("recording"), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	want, err := checksumFile(filename)
+	if err != nil {
+		t.Fatal("checksumFile:", err)
+	}
+	if len(want) != 64 {
+		t.Fatalf("Unexpected checksum %q", want)
+	}
+
+	stats := SaveStats{Filename: filename}
+	addChecksum(&stats)
+	if stats.SHA256 != "" {
+		t.Fatal("Checksum computed while disabled")
+	}
+
+	manifest, err := tmpnam(".sha256")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(manifest)
+
+	SaveChecksums(true)
+	SetChecksumManifest(manifest)
+	defer SaveChecksums(false)
+	defer SetChecksumManifest("")
+
+	addChecksum(&stats)
+	if stats.SHA256 != want {
+		t.Fatalf("Checksum doesn't match (%s vs %s)", stats.SHA256, want)
+	}
+
+	missing := SaveStats{Filename: filename + ".missing"}
+	addChecksum(&missing)
+	if missing.SHA256 != "" {
+		t.Fatal("Checksum reported for missing file")
+	}
+
+	data, err := ioutil.ReadFile(manifest)
+	if err != nil {
+		t.Fatal("ReadFile:", err)
+	}
+	if string(data) != want+"  "+filename+"\n" {
+		t.Fatalf("Unexpected manifest %q", data)
+	}
+}
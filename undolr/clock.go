@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A Clock provides the current time and timers to the package.
+//
+// Replacing the clock with SetClock allows deterministic tests of timed
+// behaviour, such as Rotator schedules and FlightRecorder rate limits, and
+// lets simulated-time systems report consistent times in save statistics
+// and recording file names.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// clock holds a clockHolder, as atomic.Value requires a consistent type.
+var clock atomic.Value
+
+type clockHolder struct {
+	Clock
+}
+
+// SetClock sets the Clock used by the package. Passing nil restores the system clock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = systemClock{}
+	}
+	clock.Store(clockHolder{c})
+}
+
+func currentClock() Clock {
+	c, ok := clock.Load().(clockHolder)
+	if !ok {
+		return systemClock{}
+	}
+	return c.Clock
+}
+
+// now returns the current time from the package Clock.
+func now() time.Time {
+	return currentClock().Now()
+}
+
+// since returns the time elapsed since t by the package Clock.
+func since(t time.Time) time.Duration {
+	return now().Sub(t)
+}
+
+// Now returns the current time from the package Clock.
+//
+// Packages building on this one use it, so that SetClock also applies to
+// the times they report and the file names they generate.
+func Now() time.Time {
+	return now()
+}
@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock under test control.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers chan chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, timers: make(chan chan time.Time, 16)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.timers <- ch
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestSetClock(t *testing.T) {
+	when := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	clock := newFakeClock(when)
+	SetClock(clock)
+	defer SetClock(nil)
+
+	if !now().Equal(when) {
+		t.Fatal("Clock not used:", now())
+	}
+	clock.Advance(time.Minute)
+	if since(when) != time.Minute {
+		t.Fatal("Unexpected time since:", since(when))
+	}
+
+	SetClock(nil)
+	if time.Since(now()) > time.Minute {
+		t.Fatal("System clock not restored:", now())
+	}
+}
+
+func TestClockFlightRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	when := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	clock := newFakeClock(when)
+	SetClock(clock)
+	defer SetClock(nil)
+
+	dumped := make(chan string, 1)
+	f := &FlightRecorder{opts: FlightRecorderOptions{
+		Dir:         dir,
+		MinInterval: time.Hour,
+		OnDump: func(filename string, stats SaveStats, err error) {
+			dumped <- filename
+		},
+	}}
+
+	err = f.Trigger("first")
+	if err != nil {
+		t.Fatal("Trigger:", err)
+	}
+	filename := <-dumped
+	if filepath.Base(filename) != dumpName(when, "first") {
+		t.Fatal("Dump not named by clock:", filename)
+	}
+
+	clock.Advance(time.Minute * 59)
+	err = f.Trigger("second")
+	if err != ErrFlightRecorderLimited {
+		t.Fatal("Expected Trigger within MinInterval to fail:", err)
+	}
+
+	clock.Advance(time.Minute)
+	err = f.Trigger("third")
+	if err != nil {
+		t.Fatal("Trigger after MinInterval:", err)
+	}
+	<-dumped
+}
+
+func TestClockRotator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	clock := newFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	// Recording isn't running, so each scheduled save reports an error.
+	saves := make(chan error, 1)
+	r, err := NewRotator(dir, RotationPolicy{
+		Interval: time.Hour,
+		OnError:  func(err error) { saves <- err },
+	})
+	if err != nil {
+		t.Fatal("NewRotator:", err)
+	}
+
+	err = r.Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer r.Stop()
+
+	for i := 0; i < 3; i++ {
+		timer := <-clock.timers
+		select {
+		case err = <-saves:
+			t.Fatal("Save before interval elapsed:", err)
+		default:
+		}
+		timer <- clock.Now()
+		<-saves
+	}
+}
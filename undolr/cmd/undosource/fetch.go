@@ -0,0 +1,204 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SourceSuffix is appended to a recording filename to name the directory the source is extracted into by default.
+const SourceSuffix = ".source"
+
+var errUnsafePath = errors.New("unsafe path in archive")
+
+// fetchArchive downloads the archive at url and extracts it into dir.
+func fetchArchive(url, dir string) error {
+	f, err := ioutil.TempFile("", "undosource")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	err = download(url, f)
+	if err != nil {
+		return err
+	}
+	return extract(f, dir)
+}
+
+func download(url string, w io.Writer) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// extract extracts the gzipped tar or zip archive in f into dir,
+// detecting the format from its contents.
+func extract(f *os.File, dir string) error {
+	magic := make([]byte, 4)
+	_, err := f.ReadAt(magic, 0)
+	if err != nil {
+		return fmt.Errorf("reading archive: %w", err)
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		return extractTarGz(f, dir)
+	case bytes.Equal(magic, []byte("PK\x03\x04")):
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return extractZip(f, info.Size(), dir)
+	}
+	return errors.New("archive is not a gzipped tar or zip file")
+}
+
+func extractTarGz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path, err := destination(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg:
+			err = writeFile(path, tr, os.FileMode(header.Mode).Perm())
+		case tar.TypeSymlink:
+			err = symlink(dir, path, header.Linkname)
+		}
+		// Other entries, such as the pax headers in GitHub archives, are
+		// skipped.
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(r io.ReaderAt, size int64, dir string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range zr.File {
+		path, err := destination(dir, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			err = os.MkdirAll(path, 0755)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(path, rc, file.Mode().Perm())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destination returns the path in dir for an archive entry, rejecting
+// names which would be extracted outside dir.
+func destination(dir, name string) (string, error) {
+	path := filepath.Join(dir, name)
+	if !within(dir, path) {
+		return "", fmt.Errorf("%q: %w", name, errUnsafePath)
+	}
+	return path, nil
+}
+
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// symlink creates a symbolic link at path, rejecting targets outside dir.
+func symlink(dir, path, target string) error {
+	if filepath.IsAbs(target) || !within(dir, filepath.Join(filepath.Dir(path), target)) {
+		return fmt.Errorf("%q -> %q: %w", path, target, errUnsafePath)
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(target, path)
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm|0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
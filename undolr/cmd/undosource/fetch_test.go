@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "undosource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func serve(t *testing.T, archive []byte) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestFetchArchive(t *testing.T) {
+	files := map[string]string{
+		"service-abc123/go.mod":        "module example.com/service\n",
+		"service-abc123/cmd/main.go":   "package main\n",
+		"service-abc123/internal/x.go": "package internal\n",
+	}
+	for name, archive := range map[string][]byte{
+		"tar.gz": tarGz(t, files),
+		"zip":    zipped(t, files),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join(tempDir(t), "server.undo"+SourceSuffix)
+			err := fetchArchive(serve(t, archive), dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range files {
+				got, err := ioutil.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchArchiveUnsafe(t *testing.T) {
+	for _, name := range []string{"../escape.go", "a/../../escape.go"} {
+		for format, archive := range map[string][]byte{
+			"tar.gz": tarGz(t, map[string]string{name: "x"}),
+			"zip":    zipped(t, map[string]string{name: "x"}),
+		} {
+			dir := filepath.Join(tempDir(t), "source")
+			err := fetchArchive(serve(t, archive), dir)
+			if !errors.Is(err, errUnsafePath) {
+				t.Errorf("%s %q: error = %v, want %v", format, name, err, errUnsafePath)
+			}
+		}
+	}
+}
+
+func TestFetchArchiveUnsafeSymlink(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "link", Linkname: "../../etc", Typeflag: tar.TypeSymlink})
+	tw.Close()
+	gz.Close()
+
+	err := fetchArchive(serve(t, buf.Bytes()), filepath.Join(tempDir(t), "source"))
+	if !errors.Is(err, errUnsafePath) {
+		t.Errorf("error = %v, want %v", err, errUnsafePath)
+	}
+}
+
+func TestFetchArchiveErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	if err := fetchArchive(server.URL, tempDir(t)); err == nil {
+		t.Error("fetching missing archive succeeded")
+	}
+
+	if err := fetchArchive(serve(t, []byte("not an archive")), tempDir(t)); err == nil {
+		t.Error("fetching invalid archive succeeded")
+	}
+}
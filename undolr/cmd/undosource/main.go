@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Command undosource shows and fetches the source a recording was made from.
+//
+// The source is identified by the fingerprint written alongside the
+// recording by undolr.WriteFingerprint, which records the module and
+// version control revision from the program's build information, and the
+// archive URL set with undolr.SetSourceArchiveURL:
+//
+//	undosource show server.undo
+//	undosource fetch server.undo
+//
+// show prints the source information. fetch downloads the archive and
+// extracts it next to the recording, in server.undo.source by default, so
+// the recording can be replayed with the matching source. Archives must be
+// gzipped tar or zip files; entries which would be extracted outside the
+// directory are rejected.
+//
+// A warning is printed if the program was built from a checkout with
+// uncommitted changes, as the archive does not then exactly match the
+// source built. The exit status is 0 on success and 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go.undo.io/bindings/undolr"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: undosource show recording")
+	fmt.Fprintln(os.Stderr, "       undosource fetch [-o dir] recording")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "show":
+		err = show(os.Args[2:])
+	case "fetch":
+		err = fetch(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undosource:", err)
+		os.Exit(2)
+	}
+}
+
+func show(args []string) error {
+	flags := flag.NewFlagSet("show", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	source, err := readSource(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	printSource(os.Stdout, source)
+	return nil
+}
+
+func fetch(args []string) error {
+	flags := flag.NewFlagSet("fetch", flag.ExitOnError)
+	dir := flags.String("o", "", "`directory` to extract the source into (default recording.source)")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	recording := flags.Arg(0)
+	source, err := readSource(recording)
+	if err != nil {
+		return err
+	}
+	if source.ArchiveURL == "" {
+		return fmt.Errorf("%s: no source archive URL recorded", recording)
+	}
+	if source.Modified {
+		fmt.Fprintf(os.Stderr, "undosource: warning: %s was built with uncommitted changes\n", recording)
+	}
+
+	if *dir == "" {
+		*dir = recording + SourceSuffix
+	}
+	err = fetchArchive(source.ArchiveURL, *dir)
+	if err != nil {
+		return err
+	}
+	fmt.Println(*dir)
+	return nil
+}
+
+func readSource(recording string) (*undolr.SourceInfo, error) {
+	fingerprint, err := undolr.ReadFingerprint(recording)
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint.Source == nil {
+		return nil, fmt.Errorf("%s: no source information recorded", recording)
+	}
+	return fingerprint.Source, nil
+}
+
+func printSource(w io.Writer, source *undolr.SourceInfo) {
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%-9s %s\n", name+":", value)
+		}
+	}
+	field("module", source.Module)
+	field("version", source.Version)
+	field("vcs", source.VCS)
+	field("revision", source.Revision)
+	field("time", source.Time)
+	if source.Modified {
+		field("modified", "true")
+	}
+	field("archive", source.ArchiveURL)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// A Compression identifies a codec used to compress saved recordings.
+type Compression int
+
+// Values for Compression
+const (
+	// CompressionGzip compresses with gzip, producing files ending ".gz".
+	CompressionGzip Compression = iota + 1
+
+	// CompressionZstd compresses with Zstandard, producing files ending
+	// ".zst". No codec is built in; see RegisterCodec.
+	CompressionZstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return "unknown"
+	}
+}
+
+// Extension returns the suffix added to the names of recordings compressed with c.
+func (c Compression) Extension() string {
+	switch c {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// A Codec compresses and decompresses recordings.
+type Codec struct {
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+// ErrCompressionUnavailable indicates no codec is registered for a compression.
+var ErrCompressionUnavailable = errors.New("compression codec not available")
+
+var codecLock sync.Mutex
+var codecs = map[Compression]Codec{
+	CompressionGzip: {
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+}
+
+// RegisterCodec sets the codec used for a compression.
+//
+// The gzip codec is built in. To avoid external dependencies no zstd codec
+// is, so one must be registered before using CompressionZstd, for
+// instance with github.com/klauspost/compress/zstd:
+//
+//	undolr.RegisterCodec(undolr.CompressionZstd, undolr.Codec{
+//		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+//			return zstd.NewWriter(w)
+//		},
+//		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+//			d, err := zstd.NewReader(r)
+//			if err != nil {
+//				return nil, err
+//			}
+//			return d.IOReadCloser(), nil
+//		},
+//	})
+func RegisterCodec(c Compression, codec Codec) {
+	codecLock.Lock()
+	defer codecLock.Unlock()
+	codecs[c] = codec
+}
+
+func codecFor(c Compression) (Codec, error) {
+	codecLock.Lock()
+	defer codecLock.Unlock()
+	codec, ok := codecs[c]
+	if !ok || codec.NewWriter == nil || codec.NewReader == nil {
+		return Codec{}, fmt.Errorf("%s: %w", c, ErrCompressionUnavailable)
+	}
+	return codec, nil
+}
+
+// SaveCompressed behaves as SaveWithStats, compressing the recording.
+//
+// The recording is written to filename with the extension of c appended,
+// for instance "crash.undo.gz". A synchronous save must write a complete
+// file, so the recording is first saved uncompressed alongside it and then
+// compressed once all threads are running again; to avoid the temporary
+// file, stop recording and use RecordingContext.SaveCompressed. Stats
+// report the compressed size, and the Duration includes compression.
+func SaveCompressed(filename string, c Compression) (SaveStats, error) {
+	codec, err := codecFor(c)
+	if err != nil {
+		return SaveStats{}, err
+	}
+	return saveThrough(filename, c.Extension(), codec.NewWriter)
+}
+
+// SaveCompressed saves a stopped recording, compressing it as it is written.
+//
+// The recording is written to filename with the extension of c appended,
+// streamed through the codec by SaveToWriter so no uncompressed copy is
+// stored. Stats report the compressed size.
+func (context *RecordingContext) SaveCompressed(filename string, c Compression) (SaveStats, error) {
+	codec, err := codecFor(c)
+	if err != nil {
+		return SaveStats{}, err
+	}
+	return context.saveThrough(filename, c.Extension(), codec.NewWriter)
+}
+
+// Decompress writes the recording compressed in filename to output, so it can be loaded by UndoDB.
+//
+// The compression is identified by the extension of filename. If output
+// is empty, the extension is removed from filename to name it.
+func Decompress(filename, output string) error {
+	var c Compression
+	for _, candidate := range []Compression{CompressionGzip, CompressionZstd} {
+		if strings.HasSuffix(filename, candidate.Extension()) {
+			c = candidate
+		}
+	}
+	if c == 0 {
+		return fmt.Errorf("%s: %w", filename, ErrCompressionUnavailable)
+	}
+	codec, err := codecFor(c)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = strings.TrimSuffix(filename, c.Extension())
+	}
+
+	in, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r, err := codec.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	defer r.Close()
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
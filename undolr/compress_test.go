@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestCompressGzip(t *testing.T) {
+	filename, err := tmpnam(".gz")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	contents := bytes.Repeat([]byte("UndoDB recording"), 1024)
+	codec, _ := codecFor(CompressionGzip)
+	size, err := writeThrough(filename, codec.NewWriter, func(w io.Writer) error {
+		_, err := w.Write(contents)
+		return err
+	})
+	if err != nil {
+		t.Fatal("writeThrough:", err)
+	}
+	if size == 0 || size >= int64(len(contents)) {
+		t.Fatalf("Unexpected compressed size %d", size)
+	}
+
+	output := filename[:len(filename)-len(".gz")]
+	defer os.Remove(output)
+	err = Decompress(filename, "")
+	if err != nil {
+		t.Fatal("Decompress:", err)
+	}
+	data, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal("ReadFile:", err)
+	}
+	if !bytes.Equal(data, contents) {
+		t.Fatal("Decompressed contents don't match")
+	}
+}
+
+func TestCompressZstd(t *testing.T) {
+	_, err := SaveCompressed("unused", CompressionZstd)
+	if !errors.Is(err, ErrCompressionUnavailable) {
+		t.Fatal("Expected zstd to be unavailable:", err)
+	}
+
+	RegisterCodec(CompressionZstd, Codec{
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil },
+		NewReader: func(r io.Reader) (io.ReadCloser, error) { return ioutil.NopCloser(r), nil },
+	})
+	defer func() {
+		codecLock.Lock()
+		delete(codecs, CompressionZstd)
+		codecLock.Unlock()
+	}()
+
+	filename, err := tmpnam(".zst")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+	err = ioutil.WriteFile(filename, []byte("recording"), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	output := filename + ".out"
+	defer os.Remove(output)
+	err = Decompress(filename, output)
+	if err != nil {
+		t.Fatal("Decompress:", err)
+	}
+	data, _ := ioutil.ReadFile(output)
+	if string(data) != "recording" {
+		t.Fatalf("Unexpected contents %q", data)
+	}
+}
+
+func TestSaveCompressed(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	compressed := filename + CompressionGzip.Extension()
+	defer os.Remove(compressed)
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	stats, err := SaveCompressed(filename, CompressionGzip)
+	if err != nil {
+		t.Fatal("SaveCompressed:", err)
+	}
+	if stats.Filename != compressed {
+		t.Fatalf("Filename doesn't match (%s vs %s)", stats.Filename, compressed)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatal("Uncompressed recording not removed:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	os.Remove(compressed)
+	stats, err = context.SaveCompressed(filename, CompressionGzip)
+	if err != nil {
+		t.Fatal("SaveCompressed:", err)
+	}
+	size, _ := fileSize(compressed)
+	if stats.BytesWritten != size {
+		t.Fatalf("BytesWritten doesn't match (%d vs %d)", stats.BytesWritten, size)
+	}
+
+	err = Decompress(compressed, "")
+	if err != nil {
+		t.Fatal("Decompress:", err)
+	}
+	verifyRecording(t, filename)
+
+	uncompressed, _ := fileSize(filename)
+	if stats.CompressionRatio != float64(uncompressed)/float64(size) {
+		t.Fatalf("CompressionRatio doesn't match (%v vs %d/%d)", stats.CompressionRatio, uncompressed, size)
+	}
+}
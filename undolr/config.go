@@ -0,0 +1,250 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A set of error codes returned by LoadConfig and Config.Validate.
+var (
+	ErrConfigFormat  = errors.New("unsupported config file format")
+	ErrConfigInvalid = errors.New("invalid config")
+)
+
+// A ConfigSize is a size in bytes in a config file.
+//
+// It may be given as a number of bytes or as a string in the format
+// accepted by ParseSize, such as "512MB".
+type ConfigSize int64
+
+// UnmarshalJSON accepts a number of bytes or a size string.
+func (s *ConfigSize) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		size, err := ParseSize(text)
+		if err != nil {
+			return err
+		}
+		*s = ConfigSize(size)
+		return nil
+	}
+	var size int64
+	err := json.Unmarshal(data, &size)
+	if err != nil {
+		return fmt.Errorf("%s: %w", data, ErrSizeInvalid)
+	}
+	*s = ConfigSize(size)
+	return nil
+}
+
+// A ConfigDuration is a duration in a config file, given as a string such as "5m".
+type ConfigDuration time.Duration
+
+// UnmarshalJSON accepts a string in the format accepted by time.ParseDuration.
+func (d *ConfigDuration) UnmarshalJSON(data []byte) error {
+	var text string
+	err := json.Unmarshal(data, &text)
+	if err != nil {
+		return fmt.Errorf("duration %s must be a string such as \"5m\"", data)
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = ConfigDuration(duration)
+	return nil
+}
+
+// RotationConfig configures a Rotator from a config file. See RotationPolicy.
+type RotationConfig struct {
+	Dir      string         `json:"dir"`
+	Interval ConfigDuration `json:"interval"`
+	Prefix   string         `json:"prefix,omitempty"`
+	MaxCount int            `json:"max_count,omitempty"`
+	MaxBytes ConfigSize     `json:"max_bytes,omitempty"`
+	MaxAge   ConfigDuration `json:"max_age,omitempty"`
+}
+
+// UploadConfig configures uploads of saved recordings to S3 from a config file.
+//
+// Credentials are not read from the config file: see
+// upload.FromConfig.
+type UploadConfig struct {
+	Bucket   string         `json:"bucket"`
+	Region   string         `json:"region"`
+	Prefix   string         `json:"prefix,omitempty"`
+	Endpoint string         `json:"endpoint,omitempty"`
+	Timeout  ConfigDuration `json:"timeout,omitempty"`
+	Remove   bool           `json:"remove,omitempty"`
+}
+
+// Config is the recorder configuration read by LoadConfig.
+//
+// For instance:
+//
+//	{
+//		"event_log_size": "1GiB",
+//		"save_on_termination": "exit.undo",
+//		"recording_dir": "/var/lib/undo",
+//		"autostart": true,
+//		"rotation": {"dir": "/var/lib/undo/rolling", "interval": "10m", "max_count": 6},
+//		"upload": {"bucket": "recordings", "region": "eu-west-2"}
+//	}
+type Config struct {
+	EventLogSize       ConfigSize `json:"event_log_size,omitempty"`
+	ExcludeSymbolFiles bool       `json:"exclude_symbol_files,omitempty"`
+	ShmemLog           string     `json:"shmem_log,omitempty"`
+	ShmemLogSize       ConfigSize `json:"shmem_log_size,omitempty"`
+	SaveOnTermination  string     `json:"save_on_termination,omitempty"`
+
+	// RecordingDir is a directory in which to place recordings given by
+	// relative names, see DirectoryResolver.
+	RecordingDir string `json:"recording_dir,omitempty"`
+
+	// Autostart starts recording when the config is applied.
+	Autostart bool `json:"autostart,omitempty"`
+
+	Rotation *RotationConfig `json:"rotation,omitempty"`
+	Upload   *UploadConfig   `json:"upload,omitempty"`
+}
+
+// LoadConfig reads and validates a JSON config file.
+//
+// Unknown fields are rejected, so misspelt settings are not silently
+// ignored. YAML is not supported, as the package has no dependencies
+// beyond the standard library; files with a .yaml or .yml extension are
+// rejected with ErrConfigFormat. Use Apply to apply the configuration.
+func LoadConfig(path string) (*Config, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return nil, fmt.Errorf("%s: %w: YAML, use JSON", path, ErrConfigFormat)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", path, ErrConfigInvalid, err)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// StartOptions returns the StartOptions given by the configuration.
+func (c *Config) StartOptions() StartOptions {
+	return StartOptions{
+		EventLogSize:       int64(c.EventLogSize),
+		ExcludeSymbolFiles: c.ExcludeSymbolFiles,
+		ShmemLogFilename:   c.ShmemLog,
+		ShmemLogSize:       int64(c.ShmemLogSize),
+		SaveOnTermination:  c.SaveOnTermination,
+	}
+}
+
+// RotationPolicy returns the RotationPolicy given by the configuration's rotation settings.
+func (c *RotationConfig) RotationPolicy() RotationPolicy {
+	return RotationPolicy{
+		Interval: time.Duration(c.Interval),
+		Prefix:   c.Prefix,
+		MaxCount: c.MaxCount,
+		MaxBytes: int64(c.MaxBytes),
+		MaxAge:   time.Duration(c.MaxAge),
+	}
+}
+
+// Validate checks the configuration for errors which would otherwise only be reported part way through Apply.
+func (c *Config) Validate() error {
+	opts := c.StartOptions()
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	if r := c.Rotation; r != nil {
+		if r.Dir == "" {
+			return fmt.Errorf("%w: rotation requires a dir", ErrConfigInvalid)
+		}
+		if r.Interval <= 0 {
+			return fmt.Errorf("%w: rotation requires a positive interval", ErrConfigInvalid)
+		}
+		if r.MaxCount < 0 || r.MaxBytes < 0 || r.MaxAge < 0 {
+			return fmt.Errorf("%w: negative rotation limit", ErrConfigInvalid)
+		}
+		if strings.ContainsRune(r.Prefix, filepath.Separator) {
+			return fmt.Errorf("%w: rotation prefix %q contains a separator", ErrConfigInvalid, r.Prefix)
+		}
+	}
+
+	if u := c.Upload; u != nil {
+		if u.Bucket == "" || u.Region == "" {
+			return fmt.Errorf("%w: upload requires a bucket and region", ErrConfigInvalid)
+		}
+		if u.Timeout < 0 {
+			return fmt.Errorf("%w: negative upload timeout", ErrConfigInvalid)
+		}
+	}
+	return nil
+}
+
+// Apply applies the configuration, returning a Rotator if rotation is configured.
+//
+// The recording directory and settings are applied as by ConfigureFromEnv:
+// recording is started if Autostart is set, otherwise only the settings
+// which are made before recording starts are applied. The Rotator is
+// started if recording is; otherwise the caller starts it once recording
+// has started. Uploads are configured by upload.FromConfig, as this
+// package cannot depend on the upload package.
+func (c *Config) Apply() (*Rotator, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var rotator *Rotator
+	if c.Rotation != nil {
+		rotator, err = NewRotator(c.Rotation.Dir, c.Rotation.RotationPolicy())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.RecordingDir != "" {
+		SetPathResolver(DirectoryResolver(c.RecordingDir))
+	}
+
+	err = applyStartOptions(c.StartOptions(), c.Autostart)
+	if err != nil {
+		return nil, err
+	}
+
+	if rotator != nil && c.Autostart {
+		err = rotator.Start()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rotator, nil
+}
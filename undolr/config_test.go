@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file named name in a new directory, returning its path.
+func writeConfig(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	path := filepath.Join(dir, name)
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, "recorder.json", `{
+		"event_log_size": "1GiB",
+		"shmem_log": "/tmp/test.shmem",
+		"shmem_log_size": 16777216,
+		"save_on_termination": "exit.undo",
+		"recording_dir": "/var/lib/undo",
+		"autostart": true,
+		"rotation": {"dir": "/var/lib/undo/rolling", "interval": "10m", "max_count": 6, "max_bytes": "4GB", "max_age": "24h"},
+		"upload": {"bucket": "recordings", "region": "eu-west-2", "timeout": "1m", "remove": true}
+	}`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal("LoadConfig:", err)
+	}
+
+	opts := config.StartOptions()
+	expected := StartOptions{
+		EventLogSize:      1 << 30,
+		ShmemLogFilename:  "/tmp/test.shmem",
+		ShmemLogSize:      16 << 20,
+		SaveOnTermination: "exit.undo",
+	}
+	if opts != expected {
+		t.Fatalf("Options don't match (%+v vs %+v)", opts, expected)
+	}
+	if !config.Autostart || config.RecordingDir != "/var/lib/undo" {
+		t.Fatalf("Unexpected config: %+v", config)
+	}
+
+	policy := config.Rotation.RotationPolicy()
+	if config.Rotation.Dir != "/var/lib/undo/rolling" || policy.Interval != 10*time.Minute ||
+		policy.MaxCount != 6 || policy.MaxBytes != 4000000000 || policy.MaxAge != 24*time.Hour {
+		t.Fatalf("Unexpected rotation: %+v", policy)
+	}
+	if u := config.Upload; u == nil || u.Bucket != "recordings" || time.Duration(u.Timeout) != time.Minute || !u.Remove {
+		t.Fatalf("Unexpected upload: %+v", config.Upload)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for _, test := range []struct {
+		content string
+		err     error
+	}{
+		{`{"event_log_size": "lots"}`, ErrConfigInvalid},
+		{`{"event_log_sise": "1GiB"}`, ErrConfigInvalid},
+		{`{"shmem_log_size": "16M"}`, ErrStartOptionsInvalid},
+		{`{"rotation": {"interval": "10m"}}`, ErrConfigInvalid},
+		{`{"rotation": {"dir": "/tmp", "interval": 600}}`, ErrConfigInvalid},
+		{`{"rotation": {"dir": "/tmp", "interval": "10m", "max_count": -1}}`, ErrConfigInvalid},
+		{`{"upload": {"bucket": "recordings"}}`, ErrConfigInvalid},
+	} {
+		path := writeConfig(t, "recorder.json", test.content)
+		_, err := LoadConfig(path)
+		os.RemoveAll(filepath.Dir(path))
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v, got %v", test.content, test.err, err)
+		}
+	}
+
+	path := writeConfig(t, "recorder.yaml", "event_log_size: 1GiB\n")
+	defer os.RemoveAll(filepath.Dir(path))
+	if _, err := LoadConfig(path); !errors.Is(err, ErrConfigFormat) {
+		t.Error("Expected ErrConfigFormat for YAML, got", err)
+	}
+}
+
+func TestConfigApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+	defer SetPathResolver(nil)
+
+	config := Config{
+		RecordingDir: dir,
+		Rotation:     &RotationConfig{Dir: filepath.Join(dir, "rolling"), Interval: ConfigDuration(time.Minute)},
+	}
+	rotator, err := config.Apply()
+	if err != nil {
+		t.Fatal("Apply:", err)
+	}
+	if rotator == nil {
+		t.Fatal("Rotator not created")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rolling")); err != nil {
+		t.Fatal("Rotation directory not created:", err)
+	}
+
+	filename, err := resolvePath("recording.undo", SaveKindSync)
+	if err != nil {
+		t.Fatal("resolvePath:", err)
+	} else if filename != filepath.Join(dir, "recording.undo") {
+		t.Fatal("Recording directory not used:", filename)
+	}
+}
@@ -0,0 +1,332 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package ctlclient is a client for the recorder control endpoints served by package undohttp.
+//
+// It does not depend on the Live Recorder libraries, so it can be used by
+// controller services and command line tools running anywhere:
+//
+//	c := ctlclient.New("http://app:8080/debug/undo/")
+//	err := c.Start(ctx)
+//	...
+//	result, err := c.Snapshot(ctx, "incident.undo")
+package ctlclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrStreamEnded indicates a progress stream ended without reporting the outcome of the save.
+var ErrStreamEnded = errors.New("progress stream ended before save completed")
+
+// An Error is a failure reported by the control endpoints.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// QueuedSnapshot describes a snapshot in the server's queue.
+type QueuedSnapshot struct {
+	Source   string    `json:"source"`
+	Priority int       `json:"priority"`
+	Filename string    `json:"filename"`
+	Queued   time.Time `json:"queued"`
+	Running  bool      `json:"running"`
+}
+
+// EventLogStatistics describes the server's event log, as undolr.EventLogStatistics.
+type EventLogStatistics struct {
+	Size      int64         `json:"size"`
+	Recording bool          `json:"recording"`
+	Start     time.Time     `json:"start"`
+	Elapsed   time.Duration `json:"elapsed"`
+}
+
+// OverheadEstimate is the server's estimated overhead of recording, as undolr.OverheadEstimate.
+type OverheadEstimate struct {
+	Monitoring       bool          `json:"monitoring"`
+	Interval         time.Duration `json:"interval_ns"`
+	BaselineSamples  int           `json:"baseline_samples"`
+	RecordingSamples int           `json:"recording_samples"`
+	BaselineLatency  time.Duration `json:"baseline_latency_ns"`
+	RecordingLatency time.Duration `json:"recording_latency_ns"`
+	StallRatio       float64       `json:"stall_ratio"`
+}
+
+// Status is the recording state reported by the server.
+type Status struct {
+	Mode           string `json:"mode"`
+	DegradedReason string `json:"degraded_reason,omitempty"`
+	Version        string `json:"version,omitempty"`
+
+	// EventLogSize is the maximum size of the event log in bytes, also
+	// reported in EventLog.
+	EventLogSize int64               `json:"event_log_size,omitempty"`
+	EventLog     *EventLogStatistics `json:"event_log,omitempty"`
+
+	// EventLogFill is the estimated fraction of the event log in use, if
+	// the server's handler has an event log rate.
+	EventLogFill float64 `json:"event_log_fill,omitempty"`
+
+	Held     []string          `json:"held,omitempty"`
+	Dir      string            `json:"dir"`
+	Queue    []QueuedSnapshot  `json:"queue,omitempty"`
+	Overhead *OverheadEstimate `json:"overhead,omitempty"`
+}
+
+// SaveResult describes a recording saved by the server.
+type SaveResult struct {
+	// File is the path of the recording on the server.
+	File     string        `json:"file"`
+	Bytes    int64         `json:"bytes"`
+	Duration time.Duration `json:"duration_ns"`
+
+	// SHA256 is the checksum of the recording, if enabled on the server
+	// with undolr.SaveChecksums.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Profiles are the paths of profiles captured alongside the
+	// recording, if enabled on the server.
+	Profiles []string `json:"profiles,omitempty"`
+}
+
+// A Client makes requests to the control endpoints.
+type Client struct {
+	// BaseURL is the URL the undohttp handler is mounted at.
+	BaseURL string
+
+	// HTTPClient is used for requests, or http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// New returns a Client for the handler mounted at baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, query url.Values, accept string) (*http.Response, error) {
+	u, err := url.Parse(strings.TrimSuffix(c.BaseURL, "/") + "/" + endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+	return resp, nil
+}
+
+// call makes a request, decoding any JSON response in to result.
+func (c *Client) call(ctx context.Context, method, endpoint string, query url.Values, result interface{}) error {
+	resp, err := c.do(ctx, method, endpoint, query, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// Status returns the recording state.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	err := c.call(ctx, http.MethodGet, "status", nil, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Start starts recording.
+func (c *Client) Start(ctx context.Context) error {
+	return c.call(ctx, http.MethodPost, "start", nil, nil)
+}
+
+// Snapshot saves the recording so far to file in the server's directory.
+//
+// If file is empty the server chooses a name. A priority may be given for
+// servers with a snapshot queue; pass nil for the default.
+func (c *Client) Snapshot(ctx context.Context, file string, priority *int) (*SaveResult, error) {
+	query := url.Values{}
+	if file != "" {
+		query.Set("file", file)
+	}
+	if priority != nil {
+		query.Set("priority", strconv.Itoa(*priority))
+	}
+
+	var result SaveResult
+	err := c.call(ctx, http.MethodPost, "save", query, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Stop stops recording and discards it.
+func (c *Client) Stop(ctx context.Context) error {
+	return c.call(ctx, http.MethodPost, "stop", nil, nil)
+}
+
+// AnnotationLevels returns the annotation levels set on the server, by component.
+//
+// Levels are named as by undoex.AnnotationLevel, and the empty component
+// holds the default level if one has been set.
+func (c *Client) AnnotationLevels(ctx context.Context) (map[string]string, error) {
+	var levels map[string]string
+	err := c.call(ctx, http.MethodGet, "levels", nil, &levels)
+	if err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// SetAnnotationLevel sets the annotation level of a component on the server, returning the levels now set.
+//
+// level is "debug", "info" or "error", or empty to clear the component's
+// level so it inherits that of its parent.
+func (c *Client) SetAnnotationLevel(ctx context.Context, component, level string) (map[string]string, error) {
+	query := url.Values{"component": {component}}
+	if level != "" {
+		query.Set("level", level)
+	}
+
+	var levels map[string]string
+	err := c.call(ctx, http.MethodPost, "levels", query, &levels)
+	if err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// StopAndSave stops recording and saves it to file in the server's directory.
+func (c *Client) StopAndSave(ctx context.Context, file string) (*SaveResult, error) {
+	var result SaveResult
+	err := c.call(ctx, http.MethodPost, "stop", url.Values{"file": {file}}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StreamProgress stops recording and saves it to file, calling progress as the save proceeds.
+//
+// progress receives the percentage saved, or -1 if the server cannot
+// estimate it. It is called from the calling goroutine.
+func (c *Client) StreamProgress(ctx context.Context, file string, progress func(percent int)) (*SaveResult, error) {
+	resp, err := c.do(ctx, http.MethodPost, "stop", url.Values{"file": {file}}, "text/event-stream")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		// The server doesn't stream, so it has already saved.
+		var result SaveResult
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		if err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	var event string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data := []byte(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+			result, err := handleEvent(event, data, progress)
+			if result != nil || err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, ErrStreamEnded
+}
+
+// handleEvent handles one server-sent event, returning the outcome of the
+// save if it is complete.
+func handleEvent(event string, data []byte, progress func(percent int)) (*SaveResult, error) {
+	switch event {
+	case "progress":
+		var p struct {
+			Progress int `json:"progress"`
+		}
+		err := json.Unmarshal(data, &p)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(p.Progress)
+		}
+	case "complete":
+		var result SaveResult
+		err := json.Unmarshal(data, &result)
+		if err != nil {
+			return nil, err
+		}
+		return &result, nil
+	case "error":
+		var e struct {
+			Error string `json:"error"`
+		}
+		err := json.Unmarshal(data, &e)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New(e.Error)
+	}
+	return nil, nil
+}
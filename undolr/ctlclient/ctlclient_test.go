@@ -0,0 +1,207 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package ctlclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"go.undo.io/bindings/undoex"
+	"go.undo.io/bindings/undohttp"
+	"go.undo.io/bindings/undolr"
+)
+
+func TestStatusFromHandler(t *testing.T) {
+	server := httptest.NewServer(http.StripPrefix("/debug/undo", undohttp.NewHandler(os.TempDir())))
+	defer server.Close()
+
+	c := New(server.URL + "/debug/undo/")
+	status, err := c.Status(context.Background())
+	if err != nil {
+		t.Fatal("Status:", err)
+	}
+	if status.Dir != os.TempDir() || status.Mode == "" {
+		t.Fatalf("Unexpected status: %+v", status)
+	}
+
+	err = c.Stop(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Fatal("Expected Stop when not recording to conflict:", err)
+	}
+}
+
+func TestStatusOverheadFromHandler(t *testing.T) {
+	server := httptest.NewServer(undohttp.NewHandler(os.TempDir()))
+	defer server.Close()
+
+	err := undolr.StartOverheadMonitor(undolr.OverheadOptions{})
+	if err != nil {
+		t.Fatal("StartOverheadMonitor:", err)
+	}
+	defer undolr.StopOverheadMonitor()
+
+	status, err := New(server.URL).Status(context.Background())
+	if err != nil {
+		t.Fatal("Status:", err)
+	}
+	if status.Overhead == nil || !status.Overhead.Monitoring || status.Overhead.Interval != undolr.DefaultOverheadInterval {
+		t.Fatalf("Unexpected overhead: %+v", status.Overhead)
+	}
+}
+
+// TestStatusFields checks every field of the handler's status is decoded
+// by the client, with the same value.
+func TestStatusFields(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	full := undohttp.Status{
+		Mode:           "recording",
+		DegradedReason: "reason",
+		Version:        "8.0.0",
+		EventLogSize:   1 << 20,
+		EventLog: &undolr.EventLogStatistics{
+			Size: 1 << 20, Recording: true, Start: start, Elapsed: time.Minute,
+		},
+		EventLogFill: 0.5,
+		Held:         []string{"held"},
+		Dir:          "/recordings",
+		Queue: []undolr.QueuedSnapshot{
+			{
+				SnapshotRequest: undolr.SnapshotRequest{Source: "http", Priority: 1, Filename: "a.undo"},
+				Queued:          start,
+				Running:         true,
+			},
+		},
+		Overhead: &undolr.OverheadEstimate{
+			Monitoring: true, Interval: time.Second, BaselineSamples: 1, RecordingSamples: 2,
+			BaselineLatency: 3, RecordingLatency: 4, StallRatio: 0.25,
+		},
+	}
+	data, err := json.Marshal(full)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+
+	var status Status
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&status)
+	if err != nil {
+		t.Fatal("Status field not decoded by client:", err)
+	}
+
+	decoded, err := json.Marshal(status)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Fatalf("Status decoded differently:\n%s\n%s", decoded, data)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/undo/save" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"file":"/rec/%s","bytes":10,"duration_ns":5}`+"\n", r.FormValue("file")+r.FormValue("priority"))
+	}))
+	defer server.Close()
+
+	c := New(server.URL + "/undo")
+	priority := 5
+	result, err := c.Snapshot(context.Background(), "a.undo", &priority)
+	if err != nil {
+		t.Fatal("Snapshot:", err)
+	}
+	if result.File != "/rec/a.undo5" || result.Bytes != 10 || result.Duration != 5 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+}
+
+func TestStreamProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			http.Error(w, "not streaming", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch r.FormValue("file") {
+		case "ok.undo":
+			fmt.Fprint(w, "event: progress\ndata: {\"progress\":-1}\n\n"+
+				"event: progress\ndata: {\"progress\":50}\n\n"+
+				"event: complete\ndata: {\"file\":\"/rec/ok.undo\",\"bytes\":42}\n\n")
+		case "fail.undo":
+			fmt.Fprint(w, "event: error\ndata: {\"error\":\"no space left on device\"}\n\n")
+		default:
+			fmt.Fprint(w, "event: progress\ndata: {\"progress\":10}\n\n")
+		}
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+
+	var progress []int
+	result, err := c.StreamProgress(context.Background(), "ok.undo", func(percent int) {
+		progress = append(progress, percent)
+	})
+	if err != nil {
+		t.Fatal("StreamProgress:", err)
+	}
+	if result.Bytes != 42 || fmt.Sprint(progress) != "[-1 50]" {
+		t.Fatalf("Unexpected result %+v, progress %v", result, progress)
+	}
+
+	_, err = c.StreamProgress(context.Background(), "fail.undo", nil)
+	if err == nil || err.Error() != "no space left on device" {
+		t.Fatal("Expected error event to fail:", err)
+	}
+
+	_, err = c.StreamProgress(context.Background(), "truncated.undo", nil)
+	if err != ErrStreamEnded {
+		t.Fatal("Expected truncated stream to fail:", err)
+	}
+}
+
+func TestAnnotationLevels(t *testing.T) {
+	server := httptest.NewServer(undohttp.NewHandler(os.TempDir()))
+	defer server.Close()
+	defer undoex.ClearAnnotationLevel("ctlclient")
+
+	c := New(server.URL)
+	levels, err := c.SetAnnotationLevel(context.Background(), "ctlclient", "debug")
+	if err != nil {
+		t.Fatal("SetAnnotationLevel:", err)
+	}
+	if levels["ctlclient"] != "debug" {
+		t.Fatalf("Unexpected levels: %v", levels)
+	}
+
+	levels, err = c.SetAnnotationLevel(context.Background(), "ctlclient", "")
+	if err != nil {
+		t.Fatal("SetAnnotationLevel:", err)
+	}
+	if _, ok := levels["ctlclient"]; ok {
+		t.Fatalf("Level not cleared: %v", levels)
+	}
+
+	_, err = c.SetAnnotationLevel(context.Background(), "ctlclient", "verbose")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatal("Expected invalid level to be rejected:", err)
+	}
+}
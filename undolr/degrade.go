@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+)
+
+// A Mode describes whether the process is being recorded.
+type Mode int
+
+// Values for Mode
+const (
+	// ModeInactive means recording has not been started, or has been stopped.
+	ModeInactive Mode = iota
+
+	// ModeRecording means the process is being recorded.
+	ModeRecording
+
+	// ModeAnnotationOnly means recording was requested using
+	// StartOrDegrade but is not permitted on this host. Annotations made
+	// with the undoex package are accepted but not recorded.
+	ModeAnnotationOnly
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeInactive:
+		return "inactive"
+	case ModeRecording:
+		return "recording"
+	case ModeAnnotationOnly:
+		return "annotation-only"
+	default:
+		return "unknown"
+	}
+}
+
+// degraded holds the reason for the last fall back to ModeAnnotationOnly,
+// or nil if not degraded.
+var degraded error
+
+// StartOrDegrade starts recording, falling back to annotation-only mode where recording is not permitted.
+//
+// The degradation policy is as follows. If Start fails because the host
+// does not allow Live Recorder to attach to the process (for instance due
+// to /proc/sys/kernel/yama/ptrace_scope, or when running in a container
+// without ptrace permission) or because the process uses features which
+// cannot be recorded (Protection Keys), the process continues in
+// ModeAnnotationOnly and nil is returned. The reason for degrading is
+// available from DegradedReason. Any other failure is returned as from
+// Start, leaving the mode as ModeInactive.
+//
+// Calls to the undoex package are safe in every mode, so instrumented
+// applications need no further changes to run on such hosts.
+func StartOrDegrade() (Mode, error) {
+	err := Start()
+	if err == nil {
+		return ModeRecording, nil
+	}
+	if !degradable(err) {
+		return ModeInactive, err
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	degraded = err
+	return ModeAnnotationOnly, nil
+}
+
+func degradable(err error) bool {
+	var code ErrorCode
+	if !errors.As(err, &code) {
+		return false
+	}
+	switch code {
+	case ErrNoAttachYama, ErrCannotAttach, ErrPkeysInUse:
+		return true
+	default:
+		return false
+	}
+}
+
+// CurrentMode reports whether the process is being recorded.
+func CurrentMode() Mode {
+	lock.Lock()
+	defer lock.Unlock()
+	switch {
+	case recording:
+		return ModeRecording
+	case degraded != nil:
+		return ModeAnnotationOnly
+	default:
+		return ModeInactive
+	}
+}
+
+// DegradedReason returns the error which caused StartOrDegrade to fall back to
+// ModeAnnotationOnly, or nil if not in that mode.
+func DegradedReason() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if recording {
+		return nil
+	}
+	return degraded
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestModeString(t *testing.T) {
+	modes := map[Mode]string{
+		ModeInactive:       "inactive",
+		ModeRecording:      "recording",
+		ModeAnnotationOnly: "annotation-only",
+		Mode(42):           "unknown",
+	}
+	for mode, expected := range modes {
+		if mode.String() != expected {
+			t.Errorf("Mode %d string doesn't match (%s vs %s)",
+				int(mode), mode.String(), expected)
+		}
+	}
+}
+
+func TestDegradable(t *testing.T) {
+	// Codes as defined by undolr_error_t.
+	degradableErrors := []error{
+		startError(-1, syscall.EPERM, 1),
+		startError(-1, syscall.EPERM, 2),
+		startError(-1, syscall.EPERM, 6),
+	}
+	for _, err := range degradableErrors {
+		if !degradable(err) {
+			t.Errorf("Expected %v to be degradable", err)
+		}
+	}
+
+	otherErrors := []error{
+		startError(-1, syscall.EPERM, 3),
+		startError(-1, syscall.EPERM, 4),
+		startError(-1, syscall.EPERM, 5),
+		syscall.EINVAL,
+	}
+	for _, err := range otherErrors {
+		if degradable(err) {
+			t.Errorf("Unexpected degradable error %v", err)
+		}
+	}
+}
+
+func TestStartOrDegrade(t *testing.T) {
+	mode, err := StartOrDegrade()
+	if err != nil {
+		t.Fatal("StartOrDegrade:", err)
+	}
+
+	if CurrentMode() != mode {
+		t.Fatalf("CurrentMode doesn't match (%v vs %v)", CurrentMode(), mode)
+	}
+
+	switch mode {
+	case ModeRecording:
+		if DegradedReason() != nil {
+			t.Fatal("Unexpected DegradedReason while recording:", DegradedReason())
+		}
+		err = StopAndDiscard()
+		if err != nil {
+			t.Fatal("Stop:", err)
+		}
+		if CurrentMode() != ModeInactive {
+			t.Fatal("Expected inactive mode after stopping, got", CurrentMode())
+		}
+	case ModeAnnotationOnly:
+		if DegradedReason() == nil {
+			t.Fatal("Expected a DegradedReason in annotation-only mode")
+		}
+		t.Log("Recording degraded:", DegradedReason())
+	default:
+		t.Fatal("Unexpected mode", mode)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"sync"
+	"time"
+)
+
+// A DiscardReason identifies why recorded history was discarded.
+type DiscardReason int
+
+// Values for DiscardReason
+const (
+	// DiscardExplicit is a call to Discard or ReleaseRecording.
+	DiscardExplicit DiscardReason = iota
+
+	// DiscardEvicted is a held recording evicted to honour the limits set
+	// by SetHeldRecordingLimits.
+	DiscardEvicted
+
+	// DiscardStopped is a call to StopAndDiscard.
+	DiscardStopped
+
+	// DiscardLeaked is a RecordingContext found by the garbage collector
+	// without having been discarded.
+	DiscardLeaked
+)
+
+func (r DiscardReason) String() string {
+	switch r {
+	case DiscardExplicit:
+		return "explicit"
+	case DiscardEvicted:
+		return "evicted"
+	case DiscardStopped:
+		return "stopped"
+	case DiscardLeaked:
+		return "leaked"
+	default:
+		return "unknown"
+	}
+}
+
+// A DiscardEvent describes recorded history which has been discarded.
+type DiscardEvent struct {
+	Reason DiscardReason
+
+	// Saved is true if the history was saved before being discarded, so
+	// nothing was lost.
+	Saved bool
+
+	// Bytes is an estimate of the history discarded: the event log size
+	// when recording stopped.
+	Bytes int64
+
+	// Start and Stop are the times recording started and stopped.
+	Start time.Time
+	Stop  time.Time
+}
+
+// Span returns the period of execution covered by the discarded history.
+//
+// Where the event log has wrapped the history held will cover less than
+// this.
+func (e DiscardEvent) Span() time.Duration {
+	if e.Start.IsZero() {
+		return 0
+	}
+	return e.Stop.Sub(e.Start)
+}
+
+// DiscardTotals accumulates DiscardEvents for use as metrics.
+type DiscardTotals struct {
+	// Discards counts all discards, and Lost those without a save.
+	Discards int64
+	Lost     int64
+
+	// LostBytes and LostSpan total Bytes and Span over lost history.
+	LostBytes int64
+	LostSpan  time.Duration
+}
+
+var discardLock sync.Mutex
+var discardHook func(DiscardEvent)
+var discardTotals DiscardTotals
+
+// SetDiscardHook sets a function called whenever recorded history is discarded.
+//
+// This allows retention to be tuned against what is actually being lost.
+// The hook is called synchronously, after the history has been freed, and
+// without any locks held so it may call other functions in this package.
+// Passing nil removes the hook.
+func SetDiscardHook(hook func(DiscardEvent)) {
+	discardLock.Lock()
+	defer discardLock.Unlock()
+	discardHook = hook
+}
+
+// DiscardStats returns totals for all history discarded so far.
+func DiscardStats() DiscardTotals {
+	discardLock.Lock()
+	defer discardLock.Unlock()
+	return discardTotals
+}
+
+func (context *RecordingContext) notifyDiscard(reason DiscardReason) {
+	notifyDiscard(DiscardEvent{
+		Reason: reason,
+		Saved:  context.saveStats != nil,
+		Bytes:  context.historyBytes,
+		Start:  context.start,
+		Stop:   context.stop,
+	})
+}
+
+func notifyDiscard(event DiscardEvent) {
+	discardLock.Lock()
+	discardTotals.Discards++
+	if !event.Saved {
+		discardTotals.Lost++
+		discardTotals.LostBytes += event.Bytes
+		discardTotals.LostSpan += event.Span()
+	}
+	hook := discardHook
+	discardLock.Unlock()
+
+	if hook != nil {
+		hook(event)
+	}
+}
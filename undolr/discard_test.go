@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotifyDiscard(t *testing.T) {
+	var events []DiscardEvent
+	SetDiscardHook(func(event DiscardEvent) {
+		events = append(events, event)
+	})
+	defer SetDiscardHook(nil)
+
+	before := DiscardStats()
+
+	start := time.Now()
+	notifyDiscard(DiscardEvent{
+		Reason: DiscardEvicted,
+		Bytes:  1024,
+		Start:  start,
+		Stop:   start.Add(time.Second),
+	})
+	notifyDiscard(DiscardEvent{
+		Reason: DiscardExplicit,
+		Saved:  true,
+		Bytes:  2048,
+	})
+
+	if len(events) != 2 || events[0].Reason != DiscardEvicted || events[1].Reason != DiscardExplicit {
+		t.Fatal("Unexpected events", events)
+	}
+	if events[0].Span() != time.Second {
+		t.Fatal("Unexpected span", events[0].Span())
+	}
+
+	after := DiscardStats()
+	if after.Discards-before.Discards != 2 || after.Lost-before.Lost != 1 {
+		t.Fatalf("Unexpected discard counts %+v", after)
+	}
+	if after.LostBytes-before.LostBytes != 1024 || after.LostSpan-before.LostSpan != time.Second {
+		t.Fatalf("Unexpected lost history %+v", after)
+	}
+}
+
+func TestDiscardHook(t *testing.T) {
+	ch := make(chan DiscardEvent, 1)
+	SetDiscardHook(func(event DiscardEvent) {
+		ch <- event
+	})
+	defer SetDiscardHook(nil)
+
+	err := Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+
+	err = context.Discard()
+	if err != nil {
+		t.Fatal("Discard:", err)
+	}
+
+	event := <-ch
+	if event.Reason != DiscardExplicit || event.Saved {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+	if event.Bytes <= 0 || event.Span() <= 0 {
+		t.Fatalf("Expected history to be quantified %+v", event)
+	}
+}
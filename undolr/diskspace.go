@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ErrInsufficientDiskSpace indicates a save was not started as the target
+// filesystem does not have room for the recording.
+//
+// Errors returned by the disk space check are of type
+// *InsufficientDiskSpaceError and match this value with errors.Is.
+var ErrInsufficientDiskSpace = errors.New("insufficient disk space for recording")
+
+// An InsufficientDiskSpaceError reports the space required for a save and the space available.
+type InsufficientDiskSpaceError struct {
+	Path      string
+	Required  int64
+	Available int64
+}
+
+func (e *InsufficientDiskSpaceError) Error() string {
+	return fmt.Sprintf("%s: %v (%d bytes required, %d available)",
+		e.Path, ErrInsufficientDiskSpace, e.Required, e.Available)
+}
+
+// Is reports whether target is ErrInsufficientDiskSpace.
+func (e *InsufficientDiskSpaceError) Is(target error) bool {
+	return target == ErrInsufficientDiskSpace
+}
+
+// checkDiskSpace is set by CheckDiskSpace.
+var checkDiskSpace bool
+
+// CheckDiskSpace controls whether free space is checked before saving.
+//
+// When enabled, Save, SaveWithStats and SaveAsync fail immediately with an
+// *InsufficientDiskSpaceError if the filesystem holding the recording file
+// has less free space than SaveSizeEstimate. This avoids failing partway
+// through a long save. Disabled by default.
+func CheckDiskSpace(check bool) {
+	lock.Lock()
+	defer lock.Unlock()
+	checkDiskSpace = check
+}
+
+// SaveSizeEstimate returns an upper estimate of the size of a saved recording.
+//
+// The recording cannot hold more history than fits in the event log, so
+// the current maximum event log size is used.
+func SaveSizeEstimate() (size int64, err error) {
+	return EventLogSizeGet()
+}
+
+// preflightDiskSpace checks there is space to save a recording to filename
+// if enabled by CheckDiskSpace. It must be called without lock held.
+func preflightDiskSpace(filename string) error {
+	lock.Lock()
+	check := checkDiskSpace
+	lock.Unlock()
+	if !check {
+		return nil
+	}
+
+	required, err := SaveSizeEstimate()
+	if err != nil {
+		return err
+	}
+
+	available, err := diskSpaceAvailable(filepath.Dir(filename))
+	if err != nil {
+		return err
+	}
+
+	if available < required {
+		return &InsufficientDiskSpaceError{
+			Path:      filename,
+			Required:  required,
+			Available: available,
+		}
+	}
+	return nil
+}
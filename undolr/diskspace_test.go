@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestInsufficientDiskSpaceError(t *testing.T) {
+	var err error = &InsufficientDiskSpaceError{
+		Path:      "/tmp/recording.undolr",
+		Required:  2048,
+		Available: 1024,
+	}
+
+	if !errors.Is(err, ErrInsufficientDiskSpace) {
+		t.Fatal("Expected error to match ErrInsufficientDiskSpace")
+	}
+
+	var spaceErr *InsufficientDiskSpaceError
+	if !errors.As(err, &spaceErr) {
+		t.Fatal("Expected error to be an *InsufficientDiskSpaceError")
+	} else if spaceErr.Required != 2048 || spaceErr.Available != 1024 {
+		t.Fatal("Unexpected sizes:", spaceErr.Required, spaceErr.Available)
+	}
+}
+
+func TestDiskSpaceAvailable(t *testing.T) {
+	available, err := diskSpaceAvailable(os.TempDir())
+	if err != nil {
+		t.Fatal("diskSpaceAvailable:", err)
+	} else if available <= 0 {
+		t.Fatal("Expected some free space in", os.TempDir())
+	}
+
+	_, err = diskSpaceAvailable("/nonexistent/directory")
+	if err == nil {
+		t.Fatal("Unexpected success with nonexistent directory")
+	}
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	CheckDiskSpace(true)
+	defer CheckDiskSpace(false)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer StopAndDiscard()
+
+	// The temporary directory is expected to have room for a recording
+	// the size of the default event log.
+	err = Save(filename)
+	if err != nil {
+		t.Fatal("Save:", err)
+	}
+	verifyRecording(t, filename)
+}
@@ -0,0 +1,391 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EncryptedExtension is appended to the names of recordings encrypted on save.
+const EncryptedExtension = ".enc"
+
+// Errors returned when encrypting and decrypting recordings.
+var (
+	ErrEncryptionKeyInvalid = errors.New("encryption key must be 16, 24 or 32 bytes")
+	ErrEncryptionKeyUnknown = errors.New("encryption key not known")
+	ErrEncryptedFormat      = errors.New("not an encrypted recording")
+	ErrDecryptionFailed     = errors.New("recording decryption failed: wrong key, or corrupt or truncated file")
+)
+
+// encryptedMagic starts every encrypted recording.
+var encryptedMagic = []byte("UNDOENC\x01")
+
+// encryptedChunk is the size of the plaintext sealed in each chunk.
+const encryptedChunk = 64 * 1024
+
+// A KeyProvider supplies the keys used to encrypt recordings.
+//
+// A recording stores the ID of the key it was encrypted with, but never
+// the key, so a provider backed by a key management service can rotate
+// keys while still decrypting older recordings.
+type KeyProvider interface {
+	// EncryptionKey returns the key to encrypt a new recording with, and
+	// its ID. The key is used for AES-GCM, so must be 16, 24 or 32 bytes.
+	EncryptionKey() (id string, key []byte, err error)
+
+	// DecryptionKey returns the key with the given ID.
+	DecryptionKey(id string) ([]byte, error)
+}
+
+type staticKey struct {
+	id  string
+	key []byte
+}
+
+// StaticKey returns a KeyProvider always encrypting with key, identified by id.
+func StaticKey(id string, key []byte) KeyProvider {
+	return staticKey{id, append([]byte(nil), key...)}
+}
+
+func (k staticKey) EncryptionKey() (string, []byte, error) {
+	return k.id, k.key, nil
+}
+
+func (k staticKey) DecryptionKey(id string) ([]byte, error) {
+	if id != k.id {
+		return nil, fmt.Errorf("%q: %w", id, ErrEncryptionKeyUnknown)
+	}
+	return k.key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, ErrEncryptionKeyInvalid
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// An encryptWriter seals the recording in chunks. Each chunk's nonce is
+// the random prefix from the header followed by the chunk number, and
+// its additional data is the header followed by a byte marking the final
+// chunk, so chunks cannot be reordered, dropped or moved between files.
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	prefix []byte
+	seq    uint32
+	buf    []byte
+	err    error
+}
+
+// NewEncryptWriter returns a writer encrypting everything written to it with a key from keys, writing the result to w.
+//
+// The writer must be closed to write the final chunk. It may be combined
+// with SaveToWriter, and with compression by wrapping it in a compressing
+// writer.
+func NewEncryptWriter(w io.Writer, keys KeyProvider) (io.WriteCloser, error) {
+	id, key, err := keys.EncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(id) > 255 {
+		return nil, fmt.Errorf("key ID %q too long", id)
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, aead.NonceSize()-4)
+	_, err = rand.Read(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.Write(encryptedMagic)
+	header.WriteByte(byte(len(id)))
+	header.WriteString(id)
+	header.Write(prefix)
+
+	_, err = w.Write(header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		header: header.Bytes(),
+		prefix: prefix,
+		buf:    make([]byte, 0, encryptedChunk),
+	}, nil
+}
+
+func chunkNonce(prefix []byte, seq uint32) []byte {
+	nonce := make([]byte, len(prefix)+4)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[len(prefix):], seq)
+	return nonce
+}
+
+func chunkData(header []byte, final bool) []byte {
+	data := append([]byte(nil), header...)
+	if final {
+		return append(data, 1)
+	}
+	return append(data, 0)
+}
+
+func (e *encryptWriter) seal(final bool) error {
+	if e.seq == ^uint32(0) {
+		return errors.New("recording too large to encrypt")
+	}
+	sealed := e.aead.Seal(nil, chunkNonce(e.prefix, e.seq), e.buf, chunkData(e.header, final))
+	e.seq++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data arrives, as the last
+		// chunk must be sealed as final by Close.
+		if len(e.buf) == encryptedChunk {
+			e.err = e.seal(false)
+			if e.err != nil {
+				return n, e.err
+			}
+		}
+		k := copy(e.buf[len(e.buf):encryptedChunk], p)
+		e.buf = e.buf[:len(e.buf)+k]
+		p = p[k:]
+		n += k
+	}
+	return n, nil
+}
+
+func (e *encryptWriter) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.seal(true)
+	if e.err != nil {
+		return e.err
+	}
+	e.err = errors.New("write to closed encrypting writer")
+	return nil
+}
+
+type decryptReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	header []byte
+	prefix []byte
+	seq    uint32
+	chunk  []byte
+	plain  []byte
+	done   bool
+}
+
+// NewDecryptReader returns a reader decrypting a recording encrypted by NewEncryptWriter from r.
+//
+// The key is found by the ID stored in the recording. A read returns
+// ErrDecryptionFailed if the recording has been modified or truncated, so
+// the output must not be used unless io.EOF is reached.
+func NewDecryptReader(r io.Reader, keys KeyProvider) (io.Reader, error) {
+	fixed := make([]byte, len(encryptedMagic)+1)
+	_, err := io.ReadFull(r, fixed)
+	if err != nil || !bytes.Equal(fixed[:len(encryptedMagic)], encryptedMagic) {
+		return nil, ErrEncryptedFormat
+	}
+	id := make([]byte, fixed[len(encryptedMagic)])
+	_, err = io.ReadFull(r, id)
+	if err != nil {
+		return nil, ErrEncryptedFormat
+	}
+
+	key, err := keys.DecryptionKey(string(id))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, aead.NonceSize()-4)
+	_, err = io.ReadFull(r, prefix)
+	if err != nil {
+		return nil, ErrEncryptedFormat
+	}
+
+	header := append(append(fixed, id...), prefix...)
+	return &decryptReader{
+		r:      r,
+		aead:   aead,
+		header: header,
+		prefix: prefix,
+		// One byte beyond a full chunk detects whether it is the last.
+		chunk: make([]byte, 0, encryptedChunk+aead.Overhead()+1),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		err := d.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk.
+func (d *decryptReader) open() error {
+	full := encryptedChunk + d.aead.Overhead()
+
+	// The chunk buffer holds the byte read beyond the previous chunk.
+	n, err := io.ReadFull(d.r, d.chunk[len(d.chunk):cap(d.chunk)])
+	d.chunk = d.chunk[:len(d.chunk)+n]
+	final := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		return err
+	}
+	if final && len(d.chunk) > full {
+		return ErrDecryptionFailed
+	}
+
+	sealed := d.chunk
+	if !final {
+		sealed = d.chunk[:full]
+	}
+	plain, err := d.aead.Open(nil, chunkNonce(d.prefix, d.seq), sealed, chunkData(d.header, final))
+	if err != nil {
+		return ErrDecryptionFailed
+	}
+	d.seq++
+	d.plain = plain
+
+	if final {
+		d.done = true
+		d.chunk = d.chunk[:0]
+	} else {
+		d.chunk = append(d.chunk[:0], d.chunk[full])
+	}
+	return nil
+}
+
+// SaveEncrypted saves the recording so far, encrypting it with a key from keys.
+//
+// The recording is written to filename with EncryptedExtension appended.
+// A synchronous save must write a complete file, which would leave an
+// unencrypted copy on disk, so instead recording is stopped, restarted,
+// and the stopped recording streamed through the cipher as by
+// RecordingContext.SaveEncrypted. The recording continuing after the save
+// therefore starts afresh, as after SaveWithin. The key is obtained
+// before recording is stopped, so a failure to get one loses no history.
+func SaveEncrypted(filename string, keys KeyProvider) (SaveStats, error) {
+	id, key, err := keys.EncryptionKey()
+	if err == nil {
+		_, err = newGCM(key)
+	}
+	if err != nil {
+		return SaveStats{}, err
+	}
+
+	context, err := Stop()
+	if err != nil {
+		return SaveStats{}, err
+	}
+	restartErr := Start()
+
+	stats, err := context.SaveEncrypted(filename, StaticKey(id, key))
+	context.Discard()
+	if err == nil {
+		err = restartErr
+	}
+	return stats, err
+}
+
+// SaveEncrypted saves a stopped recording, encrypting it with a key from keys as it is written.
+//
+// The recording is written to filename with EncryptedExtension appended,
+// streamed through the cipher by SaveToWriter so no unencrypted copy is
+// written to disk.
+func (context *RecordingContext) SaveEncrypted(filename string, keys KeyProvider) (SaveStats, error) {
+	return context.saveThrough(filename, EncryptedExtension, func(w io.Writer) (io.WriteCloser, error) {
+		return NewEncryptWriter(w, keys)
+	})
+}
+
+// DecryptFile writes the recording encrypted in filename to output, so it can be loaded by UndoDB.
+//
+// If output is empty, EncryptedExtension is removed from filename to name
+// it. The output is removed if decryption fails.
+func DecryptFile(filename, output string, keys KeyProvider) error {
+	if output == "" {
+		if !strings.HasSuffix(filename, EncryptedExtension) {
+			return fmt.Errorf("%s: no output given for file without %s extension", filename, EncryptedExtension)
+		}
+		output = strings.TrimSuffix(filename, EncryptedExtension)
+	}
+
+	in, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r, err := NewDecryptReader(in, keys)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	out, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
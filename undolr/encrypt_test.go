@@ -0,0 +1,219 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, 32)
+
+func encrypt(t *testing.T, keys KeyProvider, plain []byte) []byte {
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, keys)
+	if err != nil {
+		t.Fatal("NewEncryptWriter:", err)
+	}
+	// Write in uneven pieces to cross chunk boundaries.
+	for len(plain) > 0 {
+		n := 1000
+		if n > len(plain) {
+			n = len(plain)
+		}
+		_, err = w.Write(plain[:n])
+		if err != nil {
+			t.Fatal("Write:", err)
+		}
+		plain = plain[n:]
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal("Close:", err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(keys KeyProvider, sealed []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(sealed), keys)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	keys := StaticKey("test", testKey)
+	for _, size := range []int{0, 1, encryptedChunk - 1, encryptedChunk, encryptedChunk + 1, 3*encryptedChunk + 5} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+
+		sealed := encrypt(t, keys, plain)
+		got, err := decrypt(keys, sealed)
+		if err != nil {
+			t.Fatalf("Decrypt %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("Decrypted %d bytes don't match", size)
+		}
+	}
+}
+
+func TestEncryptTampered(t *testing.T) {
+	keys := StaticKey("test", testKey)
+	plain := make([]byte, 2*encryptedChunk+10)
+	sealed := encrypt(t, keys, plain)
+
+	_, err := decrypt(StaticKey("other", testKey), sealed)
+	if !errors.Is(err, ErrEncryptionKeyUnknown) {
+		t.Error("Expected unknown key:", err)
+	}
+
+	_, err = decrypt(StaticKey("test", bytes.Repeat([]byte{1}, 32)), sealed)
+	if err != ErrDecryptionFailed {
+		t.Error("Expected wrong key to fail:", err)
+	}
+
+	// Dropping the final chunk leaves a full chunk which wasn't sealed as final.
+	header := len(encryptedMagic) + 1 + len("test") + 8
+	full := encryptedChunk + 16
+	_, err = decrypt(keys, sealed[:header+2*full])
+	if err != ErrDecryptionFailed {
+		t.Error("Expected truncated file to fail:", err)
+	}
+
+	modified := append([]byte(nil), sealed...)
+	modified[len(modified)-1] ^= 1
+	_, err = decrypt(keys, modified)
+	if err != ErrDecryptionFailed {
+		t.Error("Expected modified file to fail:", err)
+	}
+
+	_, err = decrypt(keys, []byte("HD\x10\x00\x00\x00UndoDB recording"))
+	if err != ErrEncryptedFormat {
+		t.Error("Expected plain recording to be rejected:", err)
+	}
+}
+
+func TestEncryptKeyInvalid(t *testing.T) {
+	_, err := NewEncryptWriter(ioutil.Discard, StaticKey("short", []byte("too short")))
+	if err != ErrEncryptionKeyInvalid {
+		t.Fatal("Expected invalid key:", err)
+	}
+}
+
+func TestDecryptFile(t *testing.T) {
+	keys := StaticKey("test", testKey)
+	filename, err := tmpnam(EncryptedExtension)
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+	err = ioutil.WriteFile(filename, encrypt(t, keys, []byte("recording")), 0600)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	output := filename[:len(filename)-len(EncryptedExtension)]
+	defer os.Remove(output)
+	err = DecryptFile(filename, "", keys)
+	if err != nil {
+		t.Fatal("DecryptFile:", err)
+	}
+	data, _ := ioutil.ReadFile(output)
+	if string(data) != "recording" {
+		t.Fatalf("Unexpected contents %q", data)
+	}
+}
+
+func TestSaveEncrypted(t *testing.T) {
+	keys := StaticKey("test", testKey)
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+	defer os.Remove(filename + EncryptedExtension)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	_, err = context.SaveEncrypted(filename, keys)
+	if err != nil {
+		t.Fatal("SaveEncrypted:", err)
+	}
+	err = DecryptFile(filename+EncryptedExtension, "", keys)
+	if err != nil {
+		t.Fatal("DecryptFile:", err)
+	}
+	verifyRecording(t, filename)
+}
+
+func TestSaveEncryptedInvalidKey(t *testing.T) {
+	_, err := SaveEncrypted("unused", StaticKey("test", []byte("short")))
+	if err != ErrEncryptionKeyInvalid {
+		t.Fatal("Expected SaveEncrypted() to fail with invalid key:", err)
+	}
+}
+
+func TestSaveEncryptedWhileRecording(t *testing.T) {
+	keys := StaticKey("test", testKey)
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	os.Remove(filename)
+	defer os.Remove(filename)
+	defer os.Remove(filename + EncryptedExtension)
+
+	var saved []string
+	SetSaveHook(func(event SaveEvent) {
+		saved = append(saved, event.Filename)
+	})
+	defer SetSaveHook(nil)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer StopAndDiscard()
+
+	_, err = SaveEncrypted(filename, keys)
+	if err != nil {
+		t.Fatal("SaveEncrypted:", err)
+	}
+	for _, name := range saved {
+		if name != filename+EncryptedExtension {
+			t.Fatal("Unencrypted save made:", saved)
+		}
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatal("Unencrypted recording left on disk:", err)
+	}
+	if CurrentMode() != ModeRecording {
+		t.Fatal("Recording not restarted after SaveEncrypted()")
+	}
+
+	err = DecryptFile(filename+EncryptedExtension, "", keys)
+	if err != nil {
+		t.Fatal("DecryptFile:", err)
+	}
+	verifyRecording(t, filename)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Environment variables read by StartOptionsFromEnv and ConfigureFromEnv.
+const (
+	// EnvEventLogSize is the maximum size of the event log, in the format
+	// accepted by ParseSize.
+	EnvEventLogSize = "UNDOLR_EVENT_LOG_SIZE"
+
+	// EnvExcludeSymbolFiles omits symbol files from saved recordings if
+	// true, in the format accepted by strconv.ParseBool.
+	EnvExcludeSymbolFiles = "UNDOLR_EXCLUDE_SYMBOL_FILES"
+
+	// EnvShmemLog is the file in which to log shared memory accesses.
+	EnvShmemLog = "UNDOLR_SHMEM_LOG"
+
+	// EnvShmemLogSize is the maximum size of the shared memory log, in
+	// the format accepted by ParseSize.
+	EnvShmemLogSize = "UNDOLR_SHMEM_LOG_SIZE"
+
+	// EnvSaveOnTermination is a recording file to save if the process
+	// terminates while being recorded.
+	EnvSaveOnTermination = "UNDOLR_SAVE_ON_TERMINATION"
+
+	// EnvRecordingDir is a directory in which to place recordings given
+	// by relative names, see DirectoryResolver.
+	EnvRecordingDir = "UNDOLR_RECORDING_DIR"
+
+	// EnvAutostart starts recording from ConfigureFromEnv if true, in the
+	// format accepted by strconv.ParseBool.
+	EnvAutostart = "UNDOLR_AUTOSTART"
+)
+
+// ErrEnvInvalid indicates an environment variable read by ConfigureFromEnv has an invalid value.
+var ErrEnvInvalid = errors.New("invalid environment variable")
+
+// StartOptionsFromEnv returns the StartOptions given by environment variables.
+//
+// Unset or empty variables leave the corresponding option as the zero
+// value. The options are validated, so errors are reported before
+// anything is applied.
+func StartOptionsFromEnv() (opts StartOptions, err error) {
+	opts.EventLogSize, err = envSize(EnvEventLogSize)
+	if err != nil {
+		return StartOptions{}, err
+	}
+	opts.ExcludeSymbolFiles, err = envBool(EnvExcludeSymbolFiles)
+	if err != nil {
+		return StartOptions{}, err
+	}
+	opts.ShmemLogFilename = os.Getenv(EnvShmemLog)
+	opts.ShmemLogSize, err = envSize(EnvShmemLogSize)
+	if err != nil {
+		return StartOptions{}, err
+	}
+	opts.SaveOnTermination = os.Getenv(EnvSaveOnTermination)
+
+	err = opts.Validate()
+	if err != nil {
+		return StartOptions{}, err
+	}
+	return opts, nil
+}
+
+// ConfigureFromEnv configures, and optionally starts, recording from environment variables.
+//
+// This allows recording to be enabled in a deployment without code
+// changes. If UNDOLR_RECORDING_DIR is set, recordings given by relative
+// names are placed in it. If UNDOLR_AUTOSTART is true recording is
+// started with the options from StartOptionsFromEnv, otherwise only the
+// settings which are made before recording starts are applied:
+// UNDOLR_SAVE_ON_TERMINATION requires recording, so is then ignored. Use
+// StartOptionsFromEnv with StartWithOptions to start recording later.
+//
+// Import go.undo.io/bindings/undolr/autoconfig to call ConfigureFromEnv
+// when the program starts.
+func ConfigureFromEnv() error {
+	opts, err := StartOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	autostart, err := envBool(EnvAutostart)
+	if err != nil {
+		return err
+	}
+
+	if dir := os.Getenv(EnvRecordingDir); dir != "" {
+		SetPathResolver(DirectoryResolver(dir))
+	}
+
+	return applyStartOptions(opts, autostart)
+}
+
+// applyStartOptions starts recording with opts if start is true, or
+// otherwise applies the settings from opts which are made before
+// recording starts.
+func applyStartOptions(opts StartOptions, start bool) error {
+	if start {
+		return StartWithOptions(opts)
+	}
+
+	var err error
+	if opts.EventLogSize != 0 {
+		err = EventLogSizeSet(opts.EventLogSize)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.ExcludeSymbolFiles {
+		err = IncludeSymbolFiles(false)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.ShmemLogFilename != "" {
+		err = ShmemLogFilenameSet(opts.ShmemLogFilename)
+		if err != nil {
+			return err
+		}
+		err = ShmemLogSizeSet(opts.ShmemLogSize)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envSize returns the size in the environment variable name, or zero if unset.
+func envSize(name string) (int64, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	size, err := ParseSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %v", ErrEnvInvalid, name, err)
+	}
+	return size, nil
+}
+
+// envBool returns the boolean in the environment variable name, or false if unset.
+func envBool(name string) (bool, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s=%q is not a boolean", ErrEnvInvalid, name, value)
+	}
+	return b, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setenv sets the environment variables in env, returning a function to unset them.
+func setenv(env map[string]string) func() {
+	for name, value := range env {
+		os.Setenv(name, value)
+	}
+	return func() {
+		for name := range env {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func TestStartOptionsFromEnv(t *testing.T) {
+	opts, err := StartOptionsFromEnv()
+	if err != nil {
+		t.Fatal("StartOptionsFromEnv:", err)
+	} else if opts != (StartOptions{}) {
+		t.Fatalf("Unexpected options with no environment: %+v", opts)
+	}
+
+	defer setenv(map[string]string{
+		EnvEventLogSize:       "512MiB",
+		EnvExcludeSymbolFiles: "true",
+		EnvShmemLog:           "/tmp/test.shmem",
+		EnvShmemLogSize:       "16M",
+		EnvSaveOnTermination:  "exit.undo",
+	})()
+
+	opts, err = StartOptionsFromEnv()
+	if err != nil {
+		t.Fatal("StartOptionsFromEnv:", err)
+	}
+	expected := StartOptions{
+		EventLogSize:       512 << 20,
+		ExcludeSymbolFiles: true,
+		ShmemLogFilename:   "/tmp/test.shmem",
+		ShmemLogSize:       16 << 20,
+		SaveOnTermination:  "exit.undo",
+	}
+	if opts != expected {
+		t.Fatalf("Options don't match (%+v vs %+v)", opts, expected)
+	}
+}
+
+func TestStartOptionsFromEnvInvalid(t *testing.T) {
+	for _, env := range []map[string]string{
+		{EnvEventLogSize: "512 bytes"},
+		{EnvExcludeSymbolFiles: "sometimes"},
+		{EnvShmemLogSize: "-1"},
+	} {
+		unset := setenv(env)
+		_, err := StartOptionsFromEnv()
+		unset()
+		if !errors.Is(err, ErrEnvInvalid) {
+			t.Errorf("Expected ErrEnvInvalid for %v, got %v", env, err)
+		}
+	}
+
+	defer setenv(map[string]string{EnvShmemLogSize: "16M"})()
+	if _, err := StartOptionsFromEnv(); !errors.Is(err, ErrStartOptionsInvalid) {
+		t.Error("Expected ErrStartOptionsInvalid for shmem size without filename, got", err)
+	}
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	err := ConfigureFromEnv()
+	if err != nil {
+		t.Fatal("ConfigureFromEnv:", err)
+	}
+
+	dir := filepath.Join(os.TempDir(), "undolr_recordings")
+	defer os.RemoveAll(dir)
+	defer SetPathResolver(nil)
+	unset := setenv(map[string]string{EnvRecordingDir: dir, EnvAutostart: "0"})
+	err = ConfigureFromEnv()
+	unset()
+	if err != nil {
+		t.Fatal("ConfigureFromEnv:", err)
+	}
+
+	filename, err := resolvePath("recording.undo", SaveKindSync)
+	if err != nil {
+		t.Fatal("resolvePath:", err)
+	} else if filename != filepath.Join(dir, "recording.undo") {
+		t.Fatal("Recording directory not used:", filename)
+	}
+
+	defer setenv(map[string]string{EnvAutostart: "maybe"})()
+	if err := ConfigureFromEnv(); !errors.Is(err, ErrEnvInvalid) {
+		t.Fatal("Expected ErrEnvInvalid, got", err)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// An Error reports a failed call to the Live Recorder library.
+//
+// Errors returned by library calls which fail are of this type, unless
+// they are reported by strict mode as a *StrictError. The fields can be
+// inspected with errors.As, and the errno and error code are also matched
+// by errors.Is:
+//
+//	var lrErr *undolr.Error
+//	if errors.As(err, &lrErr) && lrErr.Op == "undolr_save" && lrErr.Errno == syscall.ENOSPC {
+//		...
+//	}
+type Error struct {
+	// Op is the name of the library function which failed, such as
+	// "undolr_save".
+	Op string
+
+	// Code is the reason for failure given by undolr_start, or zero.
+	Code ErrorCode
+
+	// Errno is the errno reported by the call, or zero.
+	Errno syscall.Errno
+
+	// Message describes the failure: the description of Code if set, or
+	// otherwise of Errno.
+	Message string
+}
+
+// newError returns an *Error for a call to fn which failed with errno
+// and, for Start, code.
+func newError(fn libFunction, code ErrorCode, errno error) *Error {
+	e := &Error{Op: fn.String(), Code: code}
+	if errno != nil && !errors.As(errno, &e.Errno) {
+		e.Message = errno.Error()
+	}
+	switch {
+	case code != 0:
+		e.Message = code.Error()
+	case e.Errno != 0:
+		e.Message = e.Errno.Error()
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Code != 0 && e.Errno != 0 {
+		return fmt.Sprintf("%s: %v; %s", e.Op, e.Errno, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Op, e.Message)
+}
+
+// Is reports whether target is the error's Code, or ErrPtraceScope for
+// ErrNoAttachYama.
+func (e *Error) Is(target error) bool {
+	if e.Code == 0 {
+		return false
+	}
+	return target == e.Code || (target == ErrPtraceScope && e.Code == ErrNoAttachYama)
+}
+
+// As sets target to the error's Code if it is an *ErrorCode and the code is set.
+func (e *Error) As(target interface{}) bool {
+	code, ok := target.(*ErrorCode)
+	if ok && e.Code != 0 {
+		*code = e.Code
+		return true
+	}
+	return false
+}
+
+// Unwrap returns the error's Errno, or nil if none was reported.
+func (e *Error) Unwrap() error {
+	if e.Errno == 0 {
+		return nil
+	}
+	return e.Errno
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+)
+
+func TestErrorFields(t *testing.T) {
+	err := fmt.Errorf("saving: %w", checkResult(fnSave, -1, syscall.ENOSPC))
+
+	var lrErr *Error
+	if !errors.As(err, &lrErr) {
+		t.Fatalf("Expected *Error, got %T", errors.Unwrap(err))
+	}
+	if lrErr.Op != "undolr_save" || lrErr.Errno != syscall.ENOSPC || lrErr.Code != 0 {
+		t.Errorf("Unexpected fields: %+v", lrErr)
+	}
+	if lrErr.Error() != "undolr_save: "+syscall.ENOSPC.Error() {
+		t.Errorf("Unexpected message: %q", lrErr.Error())
+	}
+	if !errors.Is(err, syscall.ENOSPC) {
+		t.Error("Expected error to match its errno")
+	}
+
+	var code ErrorCode
+	if errors.As(err, &code) {
+		t.Error("Unexpected ErrorCode for a save failure:", code)
+	}
+	if errors.Is(err, ErrPtraceScope) {
+		t.Error("Unexpected match of ErrPtraceScope")
+	}
+}
+
+func TestErrorStart(t *testing.T) {
+	err := startError(-1, syscall.EPERM, 2)
+
+	var lrErr *Error
+	if !errors.As(err, &lrErr) {
+		t.Fatalf("Expected *Error, got %T", err)
+	}
+	if lrErr.Op != "undolr_start" || lrErr.Errno != syscall.EPERM || lrErr.Code != ErrCannotAttach {
+		t.Errorf("Unexpected fields: %+v", lrErr)
+	}
+	if lrErr.Message != ErrCannotAttach.Error() {
+		t.Errorf("Unexpected message: %q", lrErr.Message)
+	}
+
+	err = startError(-int(syscall.ENOMEM), nil, 0)
+	if !errors.As(err, &lrErr) || lrErr.Errno != syscall.ENOMEM || lrErr.Code != 0 {
+		t.Errorf("Unexpected error for return code: %+v", err)
+	}
+
+	if (&Error{Op: "undolr_start"}).Unwrap() != nil {
+		t.Error("Unexpected Unwrap result without errno")
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+// An ErrorCode is a reason given by the library for Start failing.
+//
+// Errors returned by Start are of type *Error, which match their ErrorCode
+// with errors.Is, and the code can be retrieved with errors.As:
+//
+//	err := undolr.Start()
+//	if errors.Is(err, undolr.ErrNoAttachYama) {
+//		log.Print(undolr.CheckPtraceScope())
+//	}
+//
+// The errno reported alongside the code is matched in the same way.
+type ErrorCode int
+
+// Values for ErrorCode, as reported by the library in undolr_error_t.
+const (
+	// ErrNoAttachYama means Live Recorder could not attach to the process
+	// due to /proc/sys/kernel/yama/ptrace_scope. Errors with this code
+	// also match ErrPtraceScope; see CheckPtraceScope and
+	// EnablePtraceAttach for remedies.
+	ErrNoAttachYama ErrorCode = 1
+
+	// ErrCannotAttach means Live Recorder could not attach to the
+	// process, for instance in a container without ptrace permission.
+	ErrCannotAttach ErrorCode = 2
+
+	// ErrLibrarySearchFailed means the dynamic libraries used by the
+	// process could not be found.
+	ErrLibrarySearchFailed ErrorCode = 3
+
+	// ErrCannotRecord is a general recording error.
+	ErrCannotRecord ErrorCode = 4
+
+	// ErrNoThreadInfo means information about the process's threads
+	// could not be found.
+	ErrNoThreadInfo ErrorCode = 5
+
+	// ErrPkeysInUse means the process uses Protection Keys, which cannot
+	// be recorded.
+	ErrPkeysInUse ErrorCode = 6
+)
+
+func (c ErrorCode) Error() string {
+	switch c {
+	case ErrNoAttachYama:
+		return "Failure to attach to the application process due to /proc/sys/kernel/yama/ptrace_scope."
+	case ErrCannotAttach:
+		return "Failure to attach to the application process."
+	case ErrLibrarySearchFailed:
+		return "Failed to find dynamic libraries used by application."
+	case ErrCannotRecord:
+		return "Recording error."
+	case ErrNoThreadInfo:
+		return "Live Recorder was unable to find information about threads."
+	case ErrPkeysInUse:
+		return "Use of Protection Keys was detected. This is not yet supported."
+	default:
+		return "Unknown error"
+	}
+}
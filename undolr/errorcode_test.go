@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+)
+
+func TestErrorCodeValues(t *testing.T) {
+	// Codes as defined by undolr_error_t.
+	codes := map[ErrorCode]int{
+		ErrNoAttachYama:        1,
+		ErrCannotAttach:        2,
+		ErrLibrarySearchFailed: 3,
+		ErrCannotRecord:        4,
+		ErrNoThreadInfo:        5,
+		ErrPkeysInUse:          6,
+	}
+	for code, expected := range codes {
+		if int(code) != expected {
+			t.Errorf("%v has value %d, expected %d", code, int(code), expected)
+		}
+		if code.Error() == ErrorCode(0).Error() {
+			t.Errorf("Code %d has no description", int(code))
+		}
+	}
+}
+
+func TestErrorCodeIs(t *testing.T) {
+	err := fmt.Errorf("starting: %w", startError(-1, syscall.EPERM, 1))
+
+	if !errors.Is(err, ErrNoAttachYama) {
+		t.Error("Expected error to match ErrNoAttachYama")
+	}
+	if !errors.Is(err, ErrPtraceScope) {
+		t.Error("Expected error to match ErrPtraceScope")
+	}
+	if !errors.Is(err, syscall.EPERM) {
+		t.Error("Expected error to match its errno")
+	}
+	if errors.Is(err, ErrCannotAttach) {
+		t.Error("Unexpected match of ErrCannotAttach")
+	}
+
+	var code ErrorCode
+	if !errors.As(err, &code) || code != ErrNoAttachYama {
+		t.Errorf("errors.As returned code %d, expected %d", int(code), int(ErrNoAttachYama))
+	}
+
+	err = startError(-1, syscall.EPERM, 4)
+	if errors.Is(err, ErrPtraceScope) {
+		t.Error("Unexpected match of ErrPtraceScope")
+	}
+
+	if errors.As(startError(-12, nil, 0), &code) {
+		t.Error("Unexpected ErrorCode for errno without a code")
+	}
+}
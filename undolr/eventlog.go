@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"time"
+)
+
+// EventLogStatistics describes the event log, which holds the recorded history.
+//
+// The UndoLR library does not report how much of the event log is in
+// use or how many times it has wrapped, only its maximum size. Until the
+// log fills, all history since Start is kept, so Elapsed is an upper bound
+// on how far back a save will reach; once it has filled, the oldest
+// history is discarded to make room.
+type EventLogStatistics struct {
+	// Size is the maximum size of the event log in bytes, see EventLogSizeGet.
+	Size int64 `json:"size"`
+
+	// Recording reports whether the process is being recorded.
+	Recording bool `json:"recording"`
+
+	// Start is the time recording started, or zero if not recording.
+	Start time.Time `json:"start"`
+
+	// Elapsed is the time since recording started, or zero if not recording.
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// EventLogStats reports the current state of the event log.
+//
+// Comparing Elapsed with the history reached by saves helps tune the size
+// passed to EventLogSizeSet.
+func EventLogStats() (stats EventLogStatistics, err error) {
+	err = require(fnEventLogSizeGet)
+	if err != nil {
+		return
+	}
+
+	size, rc, err := libEventLogSizeGet()
+	if rc != 0 {
+		return stats, checkResult(fnEventLogSizeGet, rc, err)
+	}
+
+	stats.Size = size
+
+	lock.Lock()
+	defer lock.Unlock()
+	if recording {
+		stats.Recording = true
+		stats.Start = recordingStart
+		stats.Elapsed = since(recordingStart)
+	}
+	return stats, nil
+}
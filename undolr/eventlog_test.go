@@ -0,0 +1,50 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventLogStats(t *testing.T) {
+	clock := newFakeClock(time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	stats, err := EventLogStats()
+	if err != nil {
+		t.Fatal("EventLogStats:", err)
+	}
+	if stats.Recording || !stats.Start.IsZero() || stats.Elapsed != 0 {
+		t.Fatal("Unexpected statistics when not recording:", stats)
+	}
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer StopAndDiscard()
+
+	clock.Advance(time.Minute)
+
+	stats, err = EventLogStats()
+	if err != nil {
+		t.Fatal("EventLogStats:", err)
+	}
+	size, err := EventLogSizeGet()
+	if err != nil {
+		t.Fatal("EventLogSizeGet:", err)
+	}
+	if stats.Size != size {
+		t.Errorf("Size doesn't match (%d vs %d)", stats.Size, size)
+	}
+	if !stats.Recording || stats.Elapsed != time.Minute {
+		t.Errorf("Unexpected statistics when recording: %+v", stats)
+	}
+}
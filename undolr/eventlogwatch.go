@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrEventLogWatchOptionsInvalid indicates EventLogWatchOptions with a missing rate, or values out of range.
+var ErrEventLogWatchOptionsInvalid = errors.New("event log watch options not valid")
+
+// Defaults for EventLogWatchOptions.
+const (
+	DefaultEventLogThreshold     = 0.8
+	DefaultEventLogWatchInterval = time.Second
+)
+
+// EventLogWatchOptions configures the watcher started by WatchEventLog.
+type EventLogWatchOptions struct {
+	// Rate is the rate at which history is added to the event log, in
+	// bytes per second. It must be given, as the library does not report
+	// how much of the event log is in use. Measure it for the workload,
+	// for instance by finding the smallest event log size for which a
+	// save reaches back a known time.
+	Rate int64
+
+	// Size is the size of the event log in bytes, or zero to read it with
+	// EventLogSizeGet at each check.
+	Size int64
+
+	// Threshold is the fraction of the event log, between 0 and 1, at
+	// which an EventLogThreshold notification is made, or zero for
+	// DefaultEventLogThreshold.
+	Threshold float64
+
+	// Interval is the time between checks, or zero for
+	// DefaultEventLogWatchInterval.
+	Interval time.Duration
+
+	// OnFill, if set, is called with each notification, from the
+	// watcher's goroutine.
+	OnFill func(EventLogFill)
+}
+
+// An EventLogFillPhase identifies the point in filling the event log an EventLogFill reports.
+type EventLogFillPhase int
+
+// Values for EventLogFillPhase.
+const (
+	// EventLogThreshold means the event log has reached the threshold,
+	// so the oldest history will soon be overwritten.
+	EventLogThreshold EventLogFillPhase = iota
+
+	// EventLogWrapped means the event log has filled, so the oldest
+	// history is being overwritten.
+	EventLogWrapped
+)
+
+func (p EventLogFillPhase) String() string {
+	switch p {
+	case EventLogThreshold:
+		return "threshold"
+	case EventLogWrapped:
+		return "wrapped"
+	default:
+		return "unknown"
+	}
+}
+
+// EventLogFill is a notification from WatchEventLog that the event log has reached a threshold or wrapped.
+//
+// The fill is estimated from the time since Start and the rate given in
+// EventLogWatchOptions, so is only as accurate as the rate.
+type EventLogFill struct {
+	Phase EventLogFillPhase `json:"phase"`
+
+	// Lap is the number of times the event log had wrapped before the
+	// point reported, so the first threshold and wrap have Lap zero.
+	Lap int `json:"lap"`
+
+	// Size is the size of the event log in bytes.
+	Size int64 `json:"size"`
+
+	// Estimated is the estimated number of bytes of history added to the
+	// event log since Start, including any overwritten.
+	Estimated int64 `json:"estimated"`
+
+	// Start is the time recording started, and Elapsed the time since.
+	Start   time.Time     `json:"start"`
+	Elapsed time.Duration `json:"elapsed_ns"`
+}
+
+// An EventLogWatcher notifies when the event log is estimated to reach a threshold or wrap.
+type EventLogWatcher struct {
+	opts EventLogWatchOptions
+	ch   chan EventLogFill
+	stop chan struct{}
+	done chan struct{}
+
+	stopOnce sync.Once
+
+	// start is the start of the recording being watched, and reached the
+	// number of thresholds and wraps passed in it. They are only used by
+	// the watcher's goroutine.
+	start   time.Time
+	reached int
+}
+
+// WatchEventLog starts a watcher notifying when the event log is estimated to reach a threshold or wrap.
+//
+// The event log is circular, so once it fills the oldest history is
+// overwritten. Notifications let a flight recorder save a snapshot before
+// history it needs is lost, or tune the event log size. Each recording,
+// from Start to Stop, is notified of the threshold and then the wrap of
+// each lap of the event log. If a check finds several have passed, only
+// the latest is notified.
+//
+// Notifications are passed to OnFill, and sent on the channel returned by
+// C, dropping them rather than blocking if it is not received from. The
+// watcher runs until Stop is called, checking every interval as timed by
+// the package Clock.
+func WatchEventLog(opts EventLogWatchOptions) (*EventLogWatcher, error) {
+	if opts.Threshold == 0 {
+		opts.Threshold = DefaultEventLogThreshold
+	}
+	if opts.Interval == 0 {
+		opts.Interval = DefaultEventLogWatchInterval
+	}
+	if opts.Rate <= 0 || opts.Size < 0 || opts.Threshold <= 0 || opts.Threshold >= 1 || opts.Interval < 0 {
+		return nil, ErrEventLogWatchOptionsInvalid
+	}
+
+	w := &EventLogWatcher{
+		opts: opts,
+		ch:   make(chan EventLogFill, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// C returns the channel notifications are sent on.
+func (w *EventLogWatcher) C() <-chan EventLogFill {
+	return w.ch
+}
+
+// Stop stops the watcher. No notifications are made once it returns.
+func (w *EventLogWatcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+func (w *EventLogWatcher) run() {
+	defer close(w.done)
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-currentClock().After(w.opts.Interval):
+			if fill, ok := w.check(); ok {
+				w.notify(fill)
+			}
+		}
+	}
+}
+
+// check returns the latest threshold or wrap passed since the last check,
+// if any.
+func (w *EventLogWatcher) check() (fill EventLogFill, ok bool) {
+	lock.Lock()
+	active, start := recording, recordingStart
+	lock.Unlock()
+	if !active {
+		w.start = time.Time{}
+		return fill, false
+	}
+	if !start.Equal(w.start) {
+		w.start = start
+		w.reached = 0
+	}
+
+	size := w.opts.Size
+	if size == 0 {
+		var err error
+		size, err = EventLogSizeGet()
+		if err != nil || size <= 0 {
+			return fill, false
+		}
+	}
+
+	elapsed := since(start)
+	estimated := int64(elapsed.Seconds() * float64(w.opts.Rate))
+	for {
+		lap := w.reached / 2
+		phase := EventLogFillPhase(w.reached % 2)
+		fraction := w.opts.Threshold
+		if phase == EventLogWrapped {
+			fraction = 1
+		}
+		if float64(estimated) < (float64(lap)+fraction)*float64(size) {
+			return fill, ok
+		}
+		w.reached++
+		fill = EventLogFill{
+			Phase:     phase,
+			Lap:       lap,
+			Size:      size,
+			Estimated: estimated,
+			Start:     start,
+			Elapsed:   elapsed,
+		}
+		ok = true
+	}
+}
+
+func (w *EventLogWatcher) notify(fill EventLogFill) {
+	if w.opts.OnFill != nil {
+		w.opts.OnFill(fill)
+	}
+	select {
+	case w.ch <- fill:
+	default:
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"testing"
+	"time"
+)
+
+// setRecording sets the recording state as Start and Stop would.
+func setRecording(active bool, start time.Time) {
+	lock.Lock()
+	defer lock.Unlock()
+	recording = active
+	recordingStart = start
+}
+
+func TestWatchEventLogInvalid(t *testing.T) {
+	for _, opts := range []EventLogWatchOptions{
+		{},
+		{Rate: -1},
+		{Rate: 1, Size: -1},
+		{Rate: 1, Threshold: 1},
+		{Rate: 1, Threshold: -0.5},
+		{Rate: 1, Interval: -time.Second},
+	} {
+		if _, err := WatchEventLog(opts); err != ErrEventLogWatchOptionsInvalid {
+			t.Errorf("Expected WatchEventLog(%+v) to fail with ErrEventLogWatchOptionsInvalid: %v", opts, err)
+		}
+	}
+}
+
+func TestEventLogWatcherCheck(t *testing.T) {
+	when := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	clock := newFakeClock(when)
+	SetClock(clock)
+	defer SetClock(nil)
+	defer setRecording(false, time.Time{})
+
+	w := &EventLogWatcher{opts: EventLogWatchOptions{Rate: 100, Size: 1000, Threshold: 0.5}}
+	expect := func(ok bool, phase EventLogFillPhase, lap int) {
+		t.Helper()
+		fill, gotOK := w.check()
+		if gotOK != ok || ok && (fill.Phase != phase || fill.Lap != lap || fill.Size != 1000 || !fill.Start.Equal(when)) {
+			t.Fatalf("Unexpected check %v %+v; expected %v %v %d", gotOK, fill, ok, phase, lap)
+		}
+	}
+
+	expect(false, 0, 0)
+	setRecording(true, when)
+	clock.Advance(4 * time.Second)
+	expect(false, 0, 0)
+	clock.Advance(time.Second)
+	expect(true, EventLogThreshold, 0)
+	expect(false, 0, 0)
+	clock.Advance(5 * time.Second)
+	expect(true, EventLogWrapped, 0)
+
+	// Only the latest of several passed is reported.
+	clock.Advance(10 * time.Second)
+	expect(true, EventLogWrapped, 1)
+	clock.Advance(5 * time.Second)
+	expect(true, EventLogThreshold, 2)
+
+	// A new recording starts afresh.
+	setRecording(true, clock.Now())
+	when = clock.Now()
+	clock.Advance(5 * time.Second)
+	expect(true, EventLogThreshold, 0)
+}
+
+func TestWatchEventLog(t *testing.T) {
+	when := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	clock := newFakeClock(when)
+	SetClock(clock)
+	defer SetClock(nil)
+	setRecording(true, when)
+	defer setRecording(false, time.Time{})
+
+	fills := make(chan EventLogFill, 1)
+	w, err := WatchEventLog(EventLogWatchOptions{
+		Rate:   1000,
+		Size:   1000,
+		OnFill: func(fill EventLogFill) { fills <- fill },
+	})
+	if err != nil {
+		t.Fatal("WatchEventLog:", err)
+	}
+	defer w.Stop()
+
+	clock.Advance(time.Second)
+	timer := <-clock.timers
+	timer <- clock.Now()
+
+	fill := <-fills
+	if fill.Phase != EventLogWrapped || fill.Estimated != 1000 || fill.Elapsed != time.Second {
+		t.Fatal("Unexpected fill", fill)
+	}
+	if fill := <-w.C(); fill.Phase != EventLogWrapped {
+		t.Fatal("Unexpected fill on channel", fill)
+	}
+}
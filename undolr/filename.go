@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxFilenameLength is the longest filename accepted, matching PATH_MAX
+// less the '\0' terminator.
+const maxFilenameLength = 4095
+
+// ErrFilenameInvalid indicates a filename was rejected before being passed to the UndoLR library.
+//
+// Errors returned for invalid filenames are of type *InvalidFilenameError
+// and match this value with errors.Is.
+var ErrFilenameInvalid = errors.New("invalid filename")
+
+// An InvalidFilenameError reports a filename which cannot be passed to the UndoLR library.
+//
+// Filenames are passed '\0' terminated, so one containing a '\0' would be
+// silently truncated and the recording written somewhere unexpected.
+// Filenames need not be valid UTF-8, and are passed to the library as
+// given, byte for byte, so names in any locale are written as named.
+type InvalidFilenameError struct {
+	Filename string
+	Reason   string
+}
+
+func (e *InvalidFilenameError) Error() string {
+	return fmt.Sprintf("%q: %v: %s", e.Filename, ErrFilenameInvalid, e.Reason)
+}
+
+// Is reports whether target is ErrFilenameInvalid.
+func (e *InvalidFilenameError) Is(target error) bool {
+	return target == ErrFilenameInvalid
+}
+
+// checkFilename returns an *InvalidFilenameError if filename cannot be
+// passed to the library.
+func checkFilename(filename string) error {
+	switch {
+	case filename == "":
+		return &InvalidFilenameError{filename, "empty"}
+	case len(filename) > maxFilenameLength:
+		return &InvalidFilenameError{filename[:64] + "...", fmt.Sprintf("longer than %d bytes", maxFilenameLength)}
+	case strings.IndexByte(filename, 0) >= 0:
+		return &InvalidFilenameError{filename, "contains '\\0'"}
+	}
+	return nil
+}
+
+// normalizeFilename checks filename and returns it as a clean absolute
+// path, so a relative name refers to the same file even if the working
+// directory changes before the library uses it, as it may for
+// SaveOnTermination. The directory must exist, which is checked following
+// any symbolic links, so failures are reported when the filename is given
+// rather than when the library first writes to it.
+func normalizeFilename(filename string) (string, error) {
+	err := checkFilename(filename)
+	if err != nil {
+		return "", err
+	}
+
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	err = checkFilename(abs)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Dir(abs)
+	fileinfo, err := os.Stat(dir)
+	if err != nil {
+		return "", &InvalidFilenameError{filename, fmt.Sprintf("directory %q: %v", dir, errors.Unwrap(err))}
+	}
+	if !fileinfo.IsDir() {
+		return "", &InvalidFilenameError{filename, fmt.Sprintf("%q is not a directory", dir)}
+	}
+	return abs, nil
+}
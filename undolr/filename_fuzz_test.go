@@ -0,0 +1,39 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func FuzzCheckFilename(f *testing.F) {
+	f.Add("recording.undo")
+	f.Add("")
+	f.Add("a\x00b.undo")
+	f.Add("/tmp/\xff\xfe.undo")
+	f.Add(strings.Repeat("a", maxFilenameLength+1))
+
+	f.Fuzz(func(t *testing.T, filename string) {
+		err := checkFilename(filename)
+		valid := filename != "" && len(filename) <= maxFilenameLength && strings.IndexByte(filename, 0) < 0
+		if valid && err != nil {
+			t.Fatalf("Unexpected error for %q: %v", filename, err)
+		}
+		if !valid && !errors.Is(err, ErrFilenameInvalid) {
+			t.Fatalf("Expected ErrFilenameInvalid for %q, got %v", filename, err)
+		}
+		if err != nil {
+			_ = err.Error()
+		}
+	})
+}
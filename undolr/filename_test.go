@@ -0,0 +1,179 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckFilename(t *testing.T) {
+	for _, filename := range []string{"recording.undo", "/tmp/\xff\xfe.undo", strings.Repeat("a", maxFilenameLength)} {
+		if err := checkFilename(filename); err != nil {
+			t.Errorf("Unexpected error for %q: %v", filename, err)
+		}
+	}
+
+	for _, filename := range []string{"", "a\x00b.undo", "\x00", strings.Repeat("a", maxFilenameLength+1)} {
+		err := checkFilename(filename)
+		if !errors.Is(err, ErrFilenameInvalid) {
+			t.Errorf("Expected ErrFilenameInvalid for %.20q, got %v", filename, err)
+		}
+		var filenameErr *InvalidFilenameError
+		if !errors.As(err, &filenameErr) {
+			t.Errorf("Expected InvalidFilenameError for %.20q, got %T", filename, err)
+		}
+	}
+}
+
+func TestInvalidFilenameRejected(t *testing.T) {
+	filename := "/tmp/a\x00b.undo"
+
+	if err := Save(filename); !errors.Is(err, ErrFilenameInvalid) {
+		t.Error("Save: expected ErrFilenameInvalid, got", err)
+	}
+	if err := SaveOnTermination(filename); !errors.Is(err, ErrFilenameInvalid) {
+		t.Error("SaveOnTermination: expected ErrFilenameInvalid, got", err)
+	}
+	if err := ShmemLogFilenameSet("a\x00b.shmem"); !errors.Is(err, ErrFilenameInvalid) {
+		t.Error("ShmemLogFilenameSet: expected ErrFilenameInvalid, got", err)
+	}
+	context := &RecordingContext{valid: true}
+	if err := context.SaveAsync(filename); !errors.Is(err, ErrFilenameInvalid) {
+		t.Error("SaveAsync: expected ErrFilenameInvalid, got", err)
+	}
+}
+
+func TestNormalizeFilename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	localized := filepath.Join(dir, "Записи с пробелами")
+	err = os.Mkdir(localized, 0755)
+	if err != nil {
+		t.Fatal("Mkdir:", err)
+	}
+	link := filepath.Join(dir, "récent")
+	err = os.Symlink(localized, link)
+	if err != nil {
+		t.Fatal("Symlink:", err)
+	}
+
+	for _, filename := range []string{
+		filepath.Join(localized, "enregistrement 1.undo"),
+		filepath.Join(localized, "録画.undo"),
+		filepath.Join(link, "recording.undo"),
+		filepath.Join(link, ".", "sub", "..", "recording.undo"),
+		filepath.Join(dir, "\xff\xfe latin1.undo"),
+	} {
+		normalized, err := normalizeFilename(filename)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", filename, err)
+			continue
+		}
+		if normalized != filepath.Clean(filename) {
+			t.Errorf("Filename doesn't match (%q vs %q)", normalized, filepath.Clean(filename))
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Getwd:", err)
+	}
+	err = os.Chdir(link)
+	if err != nil {
+		t.Fatal("Chdir:", err)
+	}
+	normalized, err := normalizeFilename("relative recording.undo")
+	os.Chdir(wd)
+	if err != nil {
+		t.Fatal("Unexpected error for relative filename:", err)
+	}
+	if !filepath.IsAbs(normalized) || filepath.Base(normalized) != "relative recording.undo" {
+		t.Fatal("Relative filename not made absolute:", normalized)
+	}
+
+	notDir := filepath.Join(localized, "enregistrement 1.undo")
+	f, err := os.Create(notDir)
+	if err != nil {
+		t.Fatal("Create:", err)
+	}
+	f.Close()
+
+	for _, filename := range []string{
+		filepath.Join(dir, "missing", "recording.undo"),
+		filepath.Join(notDir, "recording.undo"),
+	} {
+		if _, err := normalizeFilename(filename); !errors.Is(err, ErrFilenameInvalid) {
+			t.Errorf("Expected ErrFilenameInvalid for %q, got %v", filename, err)
+		}
+	}
+}
+
+func TestSaveLocalizedFilename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	localized := filepath.Join(dir, "Записи с пробелами")
+	err = os.Mkdir(localized, 0755)
+	if err != nil {
+		t.Fatal("Mkdir:", err)
+	}
+	link := filepath.Join(dir, "récent")
+	err = os.Symlink(localized, link)
+	if err != nil {
+		t.Fatal("Symlink:", err)
+	}
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	err = SaveOnTermination(filepath.Join(link, "à la fin.undo"))
+	if err != nil {
+		t.Fatal("SaveOnTermination:", err)
+	}
+	err = SaveOnTerminationCancel()
+	if err != nil {
+		t.Fatal("SaveOnTerminationCancel:", err)
+	}
+
+	syncFilename := filepath.Join(link, "enregistrement 1.undo")
+	err = Save(syncFilename)
+	if err != nil {
+		t.Fatal("Save:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	asyncFilename := filepath.Join(localized, "録画.undo")
+	complete := make(chan error)
+	go context.SaveBackground(asyncFilename, complete)
+	err = <-complete
+	if err != nil {
+		t.Fatal("SaveBackground:", err)
+	}
+
+	verifyRecording(t, filepath.Join(localized, "enregistrement 1.undo"))
+	verifyRecording(t, asyncFilename)
+}
@@ -0,0 +1,273 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Paths read for fingerprints, variables so tests can substitute their own files.
+var (
+	cpuinfoPath  = "/proc/cpuinfo"
+	procMapsPath = "/proc/self/maps"
+	procSysDir   = "/proc/sys"
+)
+
+// fingerprintSysctls are the sysctls recorded in a Fingerprint.
+var fingerprintSysctls = []string{
+	"kernel.yama.ptrace_scope",
+	"kernel.randomize_va_space",
+	"kernel.perf_event_paranoid",
+	"vm.mmap_min_addr",
+}
+
+// FingerprintSuffix is appended to a recording filename to name the file written by WriteFingerprint.
+const FingerprintSuffix = ".fingerprint.json"
+
+// A Fingerprint describes the environment a recording was made in.
+//
+// Recordings replay faithfully on a different machine only if it provides
+// the features the recorded program used, so the fingerprint captured at
+// Start can be stored alongside recordings with WriteFingerprint and
+// compared with the replay environment with Compare.
+type Fingerprint struct {
+	Time time.Time `json:"time"`
+
+	// Kernel is the kernel release, and Arch the architecture as named
+	// by GOARCH.
+	Kernel string `json:"kernel"`
+	Arch   string `json:"arch"`
+
+	CPUVendor string   `json:"cpu_vendor,omitempty"`
+	CPUModel  string   `json:"cpu_model,omitempty"`
+	CPUFlags  []string `json:"cpu_flags,omitempty"`
+
+	// LibraryVersion is the version of the UndoLR library, if loaded.
+	LibraryVersion string `json:"library_version,omitempty"`
+
+	// Libraries maps the path of each shared library loaded by the
+	// process to the version in its name, such as "6" for libc.so.6.
+	Libraries map[string]string `json:"libraries,omitempty"`
+
+	// Sysctls holds the values of kernel settings relevant to recording.
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+
+	// Source identifies the source the program was built from, so the
+	// matching source can be found when replaying.
+	Source *SourceInfo `json:"source,omitempty"`
+}
+
+// startFingerprint is the fingerprint taken by the last successful call to Start.
+var startFingerprint *Fingerprint
+
+// TakeFingerprint describes the current environment.
+//
+// Information which cannot be read, for instance because /proc is not
+// mounted, is left empty rather than failing.
+func TakeFingerprint() *Fingerprint {
+	f := &Fingerprint{
+		Time:           now(),
+		Arch:           runtime.GOARCH,
+		LibraryVersion: GetVersionString(),
+		Libraries:      make(map[string]string),
+		Sysctls:        make(map[string]string),
+		Source:         ReadSourceInfo(),
+	}
+
+	f.Kernel = readProcSys("kernel.osrelease")
+
+	readCPUInfo(f)
+	readLibraries(f)
+
+	for _, name := range fingerprintSysctls {
+		if value := readProcSys(name); value != "" {
+			f.Sysctls[name] = value
+		}
+	}
+	return f
+}
+
+// readProcSys returns the value of a sysctl, or "" if it cannot be read.
+func readProcSys(name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(procSysDir, strings.Replace(name, ".", "/", -1)))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// StartFingerprint returns the fingerprint taken when recording last started, or nil if it never has.
+func StartFingerprint() *Fingerprint {
+	lock.Lock()
+	defer lock.Unlock()
+	return startFingerprint
+}
+
+// readCPUInfo fills in the details of the first processor listed in /proc/cpuinfo.
+func readCPUInfo(f *Fingerprint) {
+	file, err := os.Open(cpuinfoPath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		colon := strings.IndexByte(line, ':')
+		if colon < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:colon])
+		value := strings.TrimSpace(line[colon+1:])
+		switch key {
+		case "vendor_id":
+			f.CPUVendor = value
+		case "model name":
+			f.CPUModel = value
+		case "flags", "Features":
+			f.CPUFlags = strings.Fields(value)
+			sort.Strings(f.CPUFlags)
+		}
+	}
+}
+
+// readLibraries fills in the shared libraries mapped by the process.
+func readLibraries(f *Fingerprint) {
+	file, err := os.Open(procMapsPath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		path := fields[5]
+		base := filepath.Base(path)
+		i := strings.Index(base, ".so")
+		if !strings.HasPrefix(path, "/") || i < 0 {
+			continue
+		}
+		f.Libraries[path] = strings.TrimPrefix(base[i+len(".so"):], ".")
+	}
+}
+
+func (f *Fingerprint) hasCPUFlag(flag string) bool {
+	i := sort.SearchStrings(f.CPUFlags, flag)
+	return i < len(f.CPUFlags) && f.CPUFlags[i] == flag
+}
+
+// Findings reports features of the environment which affect recording or replay.
+func (f *Fingerprint) Findings() []PreflightFinding {
+	var findings []PreflightFinding
+
+	if f.hasCPUFlag("ospke") {
+		findings = append(findings, PreflightFinding{
+			Check:   "pkeys",
+			Message: "memory protection keys are enabled, and Start fails if the process has allocated one",
+			Remedy:  "avoid pkey_alloc in the recorded process, or boot the kernel with nopku",
+		})
+	}
+	for _, flag := range []string{"rtm", "hle"} {
+		if f.hasCPUFlag(flag) {
+			findings = append(findings, PreflightFinding{
+				Check:   "tsx",
+				Message: "the CPU supports transactional memory, which recorded programs may use but other CPUs cannot replay",
+				Remedy:  "replay on a CPU with TSX, or disable it with the tsx=off kernel parameter",
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// Compare reports differences which may prevent a recording made in environment f from being replayed in environment other.
+func (f *Fingerprint) Compare(other *Fingerprint) []PreflightFinding {
+	var findings []PreflightFinding
+
+	if f.Arch != other.Arch {
+		findings = append(findings, PreflightFinding{
+			Check:    "arch",
+			Message:  fmt.Sprintf("recorded on %s, replaying on %s", f.Arch, other.Arch),
+			Remedy:   "replay on a machine of the same architecture",
+			Blocking: true,
+		})
+	}
+	if f.CPUVendor != other.CPUVendor {
+		findings = append(findings, PreflightFinding{
+			Check:   "cpu_vendor",
+			Message: fmt.Sprintf("recorded on a %s CPU, replaying on a %s CPU", f.CPUVendor, other.CPUVendor),
+			Remedy:  "replay on a CPU from the same vendor",
+		})
+	}
+
+	var missing []string
+	for _, flag := range f.CPUFlags {
+		if !other.hasCPUFlag(flag) {
+			missing = append(missing, flag)
+		}
+	}
+	if len(missing) > 0 {
+		findings = append(findings, PreflightFinding{
+			Check:   "cpu_flags",
+			Message: "the replay CPU lacks features of the recording CPU: " + strings.Join(missing, " "),
+			Remedy:  "replay on a CPU supporting the same features",
+		})
+	}
+	return findings
+}
+
+// WriteFingerprint writes the fingerprint taken when recording last started to a file alongside a recording.
+//
+// The file is named by appending FingerprintSuffix to recording. If
+// recording has never started, a fingerprint of the current environment is
+// written instead.
+func WriteFingerprint(recording string) error {
+	f := StartFingerprint()
+	if f == nil {
+		f = TakeFingerprint()
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(recording+FingerprintSuffix, append(data, '\n'), 0644)
+}
+
+// ReadFingerprint reads the fingerprint written alongside a recording by WriteFingerprint.
+func ReadFingerprint(recording string) (*Fingerprint, error) {
+	data, err := ioutil.ReadFile(recording + FingerprintSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fingerprint{}
+	err = json.Unmarshal(data, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", recording+FingerprintSuffix, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withFingerprintFiles(t *testing.T, cpuinfo, maps string, fn func()) {
+	dir, err := ioutil.TempDir("", "fingerprint")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, contents string) string {
+		filename := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(filename), 0755)
+		if err != nil {
+			t.Fatal("MkdirAll:", err)
+		}
+		err = ioutil.WriteFile(filename, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal("WriteFile:", err)
+		}
+		return filename
+	}
+
+	savedCPUInfo, savedMaps, savedSys := cpuinfoPath, procMapsPath, procSysDir
+	cpuinfoPath = write("cpuinfo", cpuinfo)
+	procMapsPath = write("maps", maps)
+	procSysDir = filepath.Join(dir, "sys")
+	write("sys/kernel/osrelease", "6.1.0-test\n")
+	write("sys/kernel/randomize_va_space", "2\n")
+	defer func() { cpuinfoPath, procMapsPath, procSysDir = savedCPUInfo, savedMaps, savedSys }()
+
+	fn()
+}
+
+const testCPUInfo = `processor	: 0
+vendor_id	: GenuineIntel
+model name	: Test CPU
+flags		: fpu sse2 avx2 ospke rtm
+
+processor	: 1
+vendor_id	: GenuineIntel
+flags		: fpu
+`
+
+const testMaps = `55d0a0000000-55d0a0001000 r--p 00000000 08:01 123 /usr/bin/app
+7f0000000000-7f0000001000 r--p 00000000 08:01 456 /usr/lib/x86_64-linux-gnu/libc.so.6
+7f0000002000-7f0000003000 r--p 00000000 08:01 789 /usr/lib/libundolr_pic_x64.so
+7ffd00000000-7ffd00001000 rw-p 00000000 00:00 0 [stack]
+`
+
+func TestTakeFingerprint(t *testing.T) {
+	withFingerprintFiles(t, testCPUInfo, testMaps, func() {
+		f := TakeFingerprint()
+
+		if f.Kernel != "6.1.0-test" {
+			t.Errorf("Kernel doesn't match: %q", f.Kernel)
+		}
+		if f.CPUVendor != "GenuineIntel" || f.CPUModel != "Test CPU" {
+			t.Errorf("CPU doesn't match: %q %q", f.CPUVendor, f.CPUModel)
+		}
+		if len(f.CPUFlags) != 5 || !f.hasCPUFlag("avx2") {
+			t.Errorf("Flags of first processor not read: %v", f.CPUFlags)
+		}
+		if len(f.Libraries) != 2 || f.Libraries["/usr/lib/x86_64-linux-gnu/libc.so.6"] != "6" {
+			t.Errorf("Libraries don't match: %v", f.Libraries)
+		}
+		if f.Sysctls["kernel.randomize_va_space"] != "2" {
+			t.Errorf("Sysctls don't match: %v", f.Sysctls)
+		}
+
+		checks := make(map[string]bool)
+		for _, finding := range f.Findings() {
+			checks[finding.Check] = true
+		}
+		if !checks["pkeys"] || !checks["tsx"] {
+			t.Error("Expected pkeys and tsx findings, got", f.Findings())
+		}
+	})
+}
+
+func TestFingerprintCompare(t *testing.T) {
+	recorded := &Fingerprint{Arch: "amd64", CPUVendor: "GenuineIntel", CPUFlags: []string{"avx2", "avx512f", "sse2"}}
+	replay := &Fingerprint{Arch: "amd64", CPUVendor: "GenuineIntel", CPUFlags: []string{"avx2", "sse2"}}
+
+	findings := recorded.Compare(replay)
+	if len(findings) != 1 || findings[0].Check != "cpu_flags" {
+		t.Fatal("Expected missing CPU flags finding, got", findings)
+	}
+	if len(replay.Compare(recorded)) != 0 {
+		t.Error("Unexpected findings replaying on a superset:", replay.Compare(recorded))
+	}
+
+	replay.Arch = "arm64"
+	findings = recorded.Compare(replay)
+	if len(findings) == 0 || !findings[0].Blocking {
+		t.Error("Expected blocking architecture finding, got", findings)
+	}
+}
+
+func TestWriteFingerprint(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename + FingerprintSuffix)
+
+	err = WriteFingerprint(filename)
+	if err != nil {
+		t.Fatal("WriteFingerprint:", err)
+	}
+
+	f, err := ReadFingerprint(filename)
+	if err != nil {
+		t.Fatal("ReadFingerprint:", err)
+	}
+	if f.Arch == "" || f.Time.IsZero() {
+		t.Errorf("Fingerprint not read back: %+v", f)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// A set of error codes returned by FlightRecorder.
+var (
+	ErrFlightRecorderClosed  = errors.New("flight recorder closed")
+	ErrFlightRecorderNoDir   = errors.New("flight recorder has no dump directory")
+	ErrFlightRecorderLimited = errors.New("flight recorder dump rate limited")
+)
+
+// FlightRecorderOptions configures a FlightRecorder.
+type FlightRecorderOptions struct {
+	// Start configures recording. Its EventLogSize bounds how much history
+	// is kept, and so how far back a dump reaches.
+	Start StartOptions
+
+	// Dir is the directory in which triggered dumps are saved. Triggers
+	// are not available if it is empty.
+	Dir string
+
+	// MinInterval is the minimum time between the starts of triggered
+	// dumps. Triggers arriving sooner are dropped.
+	MinInterval time.Duration
+
+	// Profiles selects profiles captured by CaptureProfiles alongside each
+	// triggered dump, once it is saved.
+	Profiles ProfileOptions
+
+	// Binaries selects binaries archived by CaptureBinaries alongside each
+	// triggered dump, once it is saved.
+	Binaries BinaryOptions
+
+	// OnDump, if set, is called when a triggered dump completes, including
+	// capturing its profiles and binaries. err is the error from the save,
+	// or else the first error capturing profiles or binaries.
+	OnDump func(filename string, stats SaveStats, err error)
+}
+
+// A FlightRecorder records continuously with a bounded event log, saving "what just happened" on demand.
+//
+// The event log is circular, so once it fills the oldest history is
+// dropped and a dump holds the most recent execution only. Dumps are
+// made with SaveWithStats, which stops the process for the duration of
+// the save.
+type FlightRecorder struct {
+	opts FlightRecorderOptions
+
+	mu       sync.Mutex
+	closed   bool
+	dumping  bool
+	lastDump time.Time
+	signals  chan os.Signal
+	wg       sync.WaitGroup
+}
+
+// NewFlightRecorder starts recording with the given options and returns a FlightRecorder for it.
+func NewFlightRecorder(opts FlightRecorderOptions) (*FlightRecorder, error) {
+	if opts.MinInterval < 0 {
+		return nil, ErrStartOptionsInvalid
+	}
+	if opts.Dir != "" {
+		dir, err := filepath.Abs(opts.Dir)
+		if err != nil {
+			return nil, err
+		}
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return nil, err
+		}
+		opts.Dir = dir
+	}
+
+	err := StartWithOptions(opts.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlightRecorder{opts: opts}, nil
+}
+
+// Dump saves the recorded history to filename.
+func (f *FlightRecorder) Dump(filename string) (SaveStats, error) {
+	f.mu.Lock()
+	closed := f.closed
+	f.mu.Unlock()
+	if closed {
+		return SaveStats{}, ErrFlightRecorderClosed
+	}
+	return SaveWithStats(filename)
+}
+
+// Trigger starts a dump to the dump directory in the background.
+//
+// The reason is included in the name of the recording file. The dump is
+// dropped, returning ErrFlightRecorderLimited, if another triggered dump
+// is in progress or started less than MinInterval ago. The outcome of the
+// dump is passed to OnDump.
+func (f *FlightRecorder) Trigger(reason string) error {
+	if f.opts.Dir == "" {
+		return ErrFlightRecorderNoDir
+	}
+
+	t := now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrFlightRecorderClosed
+	}
+	if f.dumping || (!f.lastDump.IsZero() && t.Sub(f.lastDump) < f.opts.MinInterval) {
+		return ErrFlightRecorderLimited
+	}
+	f.dumping = true
+	f.lastDump = t
+
+	filename := filepath.Join(f.opts.Dir, dumpName(t, reason))
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		stats, err := SaveWithStats(filename)
+		saved := err == nil
+		if saved && f.opts.Profiles.enabled() {
+			_, err = CaptureProfiles(filename, f.opts.Profiles)
+		}
+		if saved && f.opts.Binaries.enabled() {
+			_, binariesErr := CaptureBinaries(filename, f.opts.Binaries)
+			if err == nil {
+				err = binariesErr
+			}
+		}
+
+		f.mu.Lock()
+		f.dumping = false
+		f.mu.Unlock()
+
+		if f.opts.OnDump != nil {
+			f.opts.OnDump(filename, stats, err)
+		}
+	}()
+	return nil
+}
+
+// dumpName returns the recording file name for a dump triggered at t for reason.
+func dumpName(t time.Time, reason string) string {
+	name := "flight-" + t.UTC().Format(rotatorTimeFormat)
+	reason = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			return r
+		}
+		return '_'
+	}, reason)
+	if len(reason) > 64 {
+		reason = reason[:64]
+	}
+	if reason != "" {
+		name += "-" + reason
+	}
+	return name + rotatorSuffix
+}
+
+// DumpOnSignal triggers a dump whenever one of the given signals is received.
+//
+// The signal name is used as the reason. Signals are no longer handled
+// once the FlightRecorder is closed.
+func (f *FlightRecorder) DumpOnSignal(sig ...os.Signal) error {
+	if f.opts.Dir == "" {
+		return ErrFlightRecorderNoDir
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrFlightRecorderClosed
+	}
+	if f.signals == nil {
+		f.signals = make(chan os.Signal, 1)
+		f.wg.Add(1)
+		go func(signals <-chan os.Signal) {
+			defer f.wg.Done()
+			for s := range signals {
+				f.Trigger(s.String())
+			}
+		}(f.signals)
+	}
+	signal.Notify(f.signals, sig...)
+	return nil
+}
+
+// Close stops handling triggers, waits for any dump in progress and stops recording.
+func (f *FlightRecorder) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return ErrFlightRecorderClosed
+	}
+	f.closed = true
+	if f.signals != nil {
+		signal.Stop(f.signals)
+		close(f.signals)
+	}
+	f.mu.Unlock()
+
+	f.wg.Wait()
+	return StopAndDiscard()
+}
@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDumpName(t *testing.T) {
+	when := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	for _, test := range []struct {
+		reason   string
+		expected string
+	}{
+		{"", "flight-20260102T030405.000000006.undo"},
+		{"panic", "flight-20260102T030405.000000006-panic.undo"},
+		{"../x y", "flight-20260102T030405.000000006-___x_y.undo"},
+	} {
+		name := dumpName(when, test.reason)
+		if name != test.expected {
+			t.Errorf("dumpName(%q) = %q, expected %q", test.reason, name, test.expected)
+		}
+	}
+}
+
+func TestFlightRecorderTrigger(t *testing.T) {
+	f := &FlightRecorder{}
+	err := f.Trigger("test")
+	if err != ErrFlightRecorderNoDir {
+		t.Fatal("Expected Trigger without a directory to fail:", err)
+	}
+
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dumped := make(chan string, 1)
+	f = &FlightRecorder{opts: FlightRecorderOptions{
+		Dir:         dir,
+		MinInterval: time.Hour,
+		OnDump: func(filename string, stats SaveStats, err error) {
+			dumped <- filename
+		},
+	}}
+
+	err = f.Trigger("first")
+	if err != nil {
+		t.Fatal("Trigger:", err)
+	}
+	select {
+	case filename := <-dumped:
+		if filepath.Dir(filename) != dir {
+			t.Fatal("Dump not in directory:", filename)
+		}
+	case <-time.After(time.Second * 30):
+		t.Fatal("Dump hadn't completed after 30 seconds")
+	}
+
+	err = f.Trigger("second")
+	if err != ErrFlightRecorderLimited {
+		t.Fatal("Expected Trigger within MinInterval to fail:", err)
+	}
+}
+
+func TestFlightRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dumped := make(chan error, 1)
+	f, err := NewFlightRecorder(FlightRecorderOptions{
+		Start: StartOptions{EventLogSize: 64 * 1024 * 1024},
+		Dir:   dir,
+		OnDump: func(filename string, stats SaveStats, err error) {
+			if err == nil {
+				verifyRecording(t, filename)
+			}
+			dumped <- err
+		},
+	})
+	if err != nil {
+		t.Fatal("NewFlightRecorder:", err)
+	}
+
+	filename := filepath.Join(dir, "manual.undo")
+	_, err = f.Dump(filename)
+	if err != nil {
+		t.Fatal("Dump:", err)
+	}
+	verifyRecording(t, filename)
+
+	err = f.Trigger("test")
+	if err != nil {
+		t.Fatal("Trigger:", err)
+	}
+	select {
+	case err = <-dumped:
+		if err != nil {
+			t.Fatal("Triggered dump:", err)
+		}
+	case <-time.After(time.Second * 30):
+		t.Fatal("Dump hadn't completed after 30 seconds")
+	}
+
+	err = f.Close()
+	if err != nil {
+		t.Fatal("Close:", err)
+	}
+	err = f.Trigger("closed")
+	if err != ErrFlightRecorderClosed {
+		t.Fatal("Expected Trigger after Close to fail:", err)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// A set of error codes returned by functions handling held recordings.
+var (
+	ErrRecordingNotHeld     = errors.New("no recording held with that ID")
+	ErrRecordingAlreadyHeld = errors.New("a recording is already held with that ID")
+)
+
+// heldRecording is a stopped recording context held in memory.
+type heldRecording struct {
+	// mu serialises saves of the context.
+	mu      sync.Mutex
+	id      string
+	context *RecordingContext
+	used    uint64
+	size    int64
+}
+
+// heldLock protects heldRecordings and the limits. It is never held while
+// calling in to the library, so saving one held recording doesn't block
+// the others.
+var heldLock sync.Mutex
+var heldRecordings = make(map[string]*heldRecording)
+var heldMaxCount int
+var heldMaxBytes int64
+
+// heldUses orders uses of held recordings.
+var heldUses uint64
+
+// SetHeldRecordingLimits bounds the recordings held by HoldRecording.
+//
+// When holding a recording would exceed either limit, the least recently
+// used recordings (by HoldRecording or MaterializeRecording) are discarded
+// until it does not, giving a "keep the last few stop points" pattern
+// without further bookkeeping. The newly held recording is always kept.
+// A limit of zero means no limit, which is the default.
+//
+// The memory used by a recording is estimated as the event log size when
+// it was held, see EventLogSizeGet.
+func SetHeldRecordingLimits(maxCount int, maxBytes int64) {
+	heldLock.Lock()
+	defer heldLock.Unlock()
+	heldMaxCount = maxCount
+	heldMaxBytes = maxBytes
+}
+
+// heldEvictions returns, and removes from heldRecordings, the least
+// recently used recordings needing to be discarded to honour the limits.
+// It must be called with heldLock held.
+func heldEvictions(keep *heldRecording) (evicted []*heldRecording) {
+	var total int64
+	for _, h := range heldRecordings {
+		total += h.size
+	}
+
+	for _, h := range heldByUse() {
+		overCount := heldMaxCount > 0 && len(heldRecordings) > heldMaxCount
+		overBytes := heldMaxBytes > 0 && total > heldMaxBytes
+		if !overCount && !overBytes {
+			break
+		}
+		if h == keep {
+			continue
+		}
+		delete(heldRecordings, h.id)
+		total -= h.size
+		evicted = append(evicted, h)
+	}
+	return
+}
+
+// heldByUse returns the held recordings, least recently used first. It
+// must be called with heldLock held.
+func heldByUse() []*heldRecording {
+	held := make([]*heldRecording, 0, len(heldRecordings))
+	for _, h := range heldRecordings {
+		held = append(held, h)
+	}
+	sort.Slice(held, func(i, j int) bool {
+		return held[i].used < held[j].used
+	})
+	return held
+}
+
+// HoldRecording keeps a stopped recording in memory under id for later materialization.
+//
+// This allows a service to hold on to recent recordings and only write
+// the ones which turn out to be interesting, using MaterializeRecording.
+// Ownership of the context passes to the held recordings: it must not be
+// used or discarded by the caller, but is discarded by ReleaseRecording.
+func HoldRecording(id string, context *RecordingContext) error {
+	if !context.valid {
+		return ErrRecordingContextDiscarded
+	}
+
+	size, err := EventLogSizeGet()
+	if err != nil {
+		return err
+	}
+
+	heldLock.Lock()
+	if _, ok := heldRecordings[id]; ok {
+		heldLock.Unlock()
+		return ErrRecordingAlreadyHeld
+	}
+	h := &heldRecording{
+		id:      id,
+		context: context,
+		size:    size,
+	}
+	heldUses++
+	h.used = heldUses
+	heldRecordings[id] = h
+	evicted := heldEvictions(h)
+	heldLock.Unlock()
+
+	for _, h := range evicted {
+		h.discard(DiscardEvicted)
+	}
+	return nil
+}
+
+// MaterializeRecording saves the recording held under id to dest.
+//
+// This returns once the save is complete. The recording remains held, so
+// it may be materialized again, until released with ReleaseRecording.
+func MaterializeRecording(id, dest string) error {
+	heldLock.Lock()
+	h, ok := heldRecordings[id]
+	if ok {
+		heldUses++
+		h.used = heldUses
+	}
+	heldLock.Unlock()
+	if !ok {
+		return ErrRecordingNotHeld
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.context.valid {
+		// Evicted while waiting for another materialization.
+		return ErrRecordingNotHeld
+	}
+
+	ch := make(chan error, 1)
+	h.context.SaveBackground(dest, ch)
+	return <-ch
+}
+
+// ReleaseRecording discards the recording held under id.
+func ReleaseRecording(id string) error {
+	heldLock.Lock()
+	h, ok := heldRecordings[id]
+	if ok {
+		delete(heldRecordings, id)
+	}
+	heldLock.Unlock()
+	if !ok {
+		return ErrRecordingNotHeld
+	}
+
+	return h.discard(DiscardExplicit)
+}
+
+// discard discards the held recording, first waiting for any
+// materialization in progress.
+func (h *heldRecording) discard(reason DiscardReason) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.context.discard(reason)
+}
+
+// HeldRecordings returns the IDs of the recordings currently held, least recently used first.
+func HeldRecordings() []string {
+	heldLock.Lock()
+	defer heldLock.Unlock()
+
+	held := heldByUse()
+	ids := make([]string, len(held))
+	for i, h := range held {
+		ids[i] = h.id
+	}
+	return ids
+}
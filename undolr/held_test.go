@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"testing"
+)
+
+func TestHeldRecordingNotHeld(t *testing.T) {
+	err := MaterializeRecording("missing", "recording.undolr")
+	if err != ErrRecordingNotHeld {
+		t.Fatal("Expected MaterializeRecording() to fail with missing ID:", err)
+	}
+
+	err = ReleaseRecording("missing")
+	if err != ErrRecordingNotHeld {
+		t.Fatal("Expected ReleaseRecording() to fail with missing ID:", err)
+	}
+
+	err = HoldRecording("discarded", &RecordingContext{})
+	if err != ErrRecordingContextDiscarded {
+		t.Fatal("Expected HoldRecording() to fail with discarded context:", err)
+	}
+}
+
+func TestHeldRecording(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	for _, id := range []string{"first", "second"} {
+		err = Start()
+		if err != nil {
+			t.Fatal("Start:", err)
+		}
+
+		context, err := Stop()
+		if err != nil {
+			t.Fatal("Stop:", err)
+		}
+
+		err = HoldRecording(id, context)
+		if err != nil {
+			t.Fatal("HoldRecording:", err)
+		}
+	}
+
+	ids := HeldRecordings()
+	if len(ids) != 2 || ids[0] != "first" || ids[1] != "second" {
+		t.Fatal("Unexpected held recordings", ids)
+	}
+
+	err = MaterializeRecording("first", filename)
+	if err != nil {
+		t.Fatal("MaterializeRecording:", err)
+	}
+	verifyRecording(t, filename)
+
+	for _, id := range ids {
+		err = ReleaseRecording(id)
+		if err != nil {
+			t.Fatal("ReleaseRecording:", err)
+		}
+	}
+
+	if len(HeldRecordings()) != 0 {
+		t.Fatal("Unexpected held recordings after release", HeldRecordings())
+	}
+}
+
+func TestHeldEvictions(t *testing.T) {
+	saved := heldRecordings
+	defer func() {
+		heldRecordings = saved
+		SetHeldRecordingLimits(0, 0)
+	}()
+
+	heldRecordings = make(map[string]*heldRecording)
+	for i, id := range []string{"a", "b", "c", "d"} {
+		heldRecordings[id] = &heldRecording{id: id, used: uint64(i), size: 100}
+	}
+	newest := heldRecordings["d"]
+
+	SetHeldRecordingLimits(3, 0)
+	evicted := heldEvictions(newest)
+	if len(evicted) != 1 || evicted[0].id != "a" {
+		t.Fatal("Expected only a to be evicted by count")
+	}
+
+	SetHeldRecordingLimits(0, 150)
+	evicted = heldEvictions(newest)
+	if len(evicted) != 2 || evicted[0].id != "b" || evicted[1].id != "c" {
+		t.Fatal("Expected b and c to be evicted by size")
+	}
+
+	// The newest recording is kept even if it alone exceeds the limits.
+	SetHeldRecordingLimits(0, 50)
+	evicted = heldEvictions(newest)
+	if len(evicted) != 0 || len(heldRecordings) != 1 {
+		t.Fatal("Unexpected eviction of the newest recording")
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.undo.io/bindings/internal/uuid"
+)
+
+// An IDKind identifies what an ID is generated for.
+type IDKind int
+
+// Values for IDKind
+const (
+	// IDSession identifies the process's recording session, returned by
+	// SessionID.
+	IDSession IDKind = iota
+
+	// IDSnapshot names a saved recording, for the {id} recording name
+	// template token.
+	IDSnapshot
+
+	// IDTestRun disambiguates runs of the same test, see
+	// undoex.SetRunSuffixFunc.
+	IDTestRun
+)
+
+func (k IDKind) String() string {
+	switch k {
+	case IDSession:
+		return "session"
+	case IDSnapshot:
+		return "snapshot"
+	case IDTestRun:
+		return "test-run"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrIDInvalid indicates an IDGenerator returned an ID which cannot be used in a file name.
+var ErrIDInvalid = errors.New("invalid generated ID")
+
+// An IDGenerator generates the IDs used to name sessions, recordings and test runs.
+//
+// This allows an organization to apply its own numbering scheme, such as
+// naming recordings after the incident they belong to. IDs must be
+// non-empty and must not contain path separators or '\0'.
+type IDGenerator interface {
+	NewID(kind IDKind) (string, error)
+}
+
+// The IDGeneratorFunc type is an adapter to allow the use of ordinary functions as an IDGenerator.
+type IDGeneratorFunc func(kind IDKind) (string, error)
+
+// NewID calls f(kind).
+func (f IDGeneratorFunc) NewID(kind IDKind) (string, error) {
+	return f(kind)
+}
+
+// UUIDv7Generator generates time-ordered UUIDs as described in RFC 9562, the default IDGenerator.
+var UUIDv7Generator IDGenerator = IDGeneratorFunc(func(kind IDKind) (string, error) {
+	return uuid.NewV7(now())
+})
+
+var idLock sync.Mutex
+var idGenerator = UUIDv7Generator
+var sessionID string
+
+// SetIDGenerator sets the generator of IDs, or restores UUIDv7Generator if g is nil.
+//
+// For instance, to name recordings after an incident:
+//
+//	undolr.SetIDGenerator(undolr.IDGeneratorFunc(func(kind undolr.IDKind) (string, error) {
+//		id, err := undolr.UUIDv7Generator.NewID(kind)
+//		return incident + "-" + id, err
+//	}))
+//	err := undolr.Save("{id}.undo")
+//
+// The session ID is generated once, so is not affected by setting a
+// generator after SessionID is first called.
+func SetIDGenerator(g IDGenerator) {
+	idLock.Lock()
+	defer idLock.Unlock()
+	if g == nil {
+		g = UUIDv7Generator
+	}
+	idGenerator = g
+}
+
+// NewID returns a new ID of the given kind from the generator set by SetIDGenerator.
+func NewID(kind IDKind) (string, error) {
+	idLock.Lock()
+	g := idGenerator
+	idLock.Unlock()
+
+	id, err := g.NewID(kind)
+	if err != nil {
+		return "", err
+	}
+	if id == "" || strings.ContainsAny(id, "/\x00") {
+		return "", fmt.Errorf("%s ID %q: %w", kind, id, ErrIDInvalid)
+	}
+	return id, nil
+}
+
+// SessionID returns the ID of this process's recording session, generated on first use.
+//
+// It is available to recording name templates as {session}, so that all
+// the recordings saved by a process can be identified.
+func SessionID() (string, error) {
+	idLock.Lock()
+	id := sessionID
+	idLock.Unlock()
+	if id != "" {
+		return id, nil
+	}
+
+	id, err := NewID(IDSession)
+	if err != nil {
+		return "", err
+	}
+
+	idLock.Lock()
+	defer idLock.Unlock()
+	if sessionID == "" {
+		sessionID = id
+	}
+	return sessionID, nil
+}
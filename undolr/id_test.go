@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var uuidv7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestUUIDv7(t *testing.T) {
+	defer SetClock(nil)
+	SetClock(newFakeClock(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)))
+
+	first, err := NewID(IDSnapshot)
+	if err != nil {
+		t.Fatal("NewID:", err)
+	}
+	if !uuidv7Pattern.MatchString(first) {
+		t.Fatalf("ID %q is not a UUIDv7", first)
+	}
+	// 1772600767000 milliseconds since the epoch.
+	if first[:13] != "019cb73d-3218" {
+		t.Errorf("ID %q does not start with the time", first)
+	}
+
+	second, _ := NewID(IDSnapshot)
+	if first == second {
+		t.Error("IDs not unique:", first)
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	defer SetIDGenerator(nil)
+
+	var kinds []IDKind
+	SetIDGenerator(IDGeneratorFunc(func(kind IDKind) (string, error) {
+		kinds = append(kinds, kind)
+		return "INC-1234", nil
+	}))
+
+	name, err := ExpandTemplate("{id}.undo", PathMetadata{})
+	if err != nil {
+		t.Fatal("ExpandTemplate:", err)
+	}
+	if name != "INC-1234.undo" {
+		t.Errorf("Expanded to %q, expected INC-1234.undo", name)
+	}
+	if len(kinds) != 1 || kinds[0] != IDSnapshot {
+		t.Errorf("Generated IDs of kinds %v, expected [snapshot]", kinds)
+	}
+
+	for _, id := range []string{"", "INC/1234", "INC\x001234"} {
+		SetIDGenerator(IDGeneratorFunc(func(IDKind) (string, error) { return id, nil }))
+		_, err = NewID(IDTestRun)
+		if !errors.Is(err, ErrIDInvalid) {
+			t.Errorf("Expected ErrIDInvalid for %q, got %v", id, err)
+		}
+	}
+
+	SetIDGenerator(nil)
+	id, err := NewID(IDTestRun)
+	if err != nil || !uuidv7Pattern.MatchString(id) {
+		t.Errorf("Default generator not restored: %q, %v", id, err)
+	}
+}
+
+func TestSessionID(t *testing.T) {
+	first, err := SessionID()
+	if err != nil {
+		t.Fatal("SessionID:", err)
+	}
+
+	defer SetIDGenerator(nil)
+	SetIDGenerator(IDGeneratorFunc(func(IDKind) (string, error) { return "other", nil }))
+
+	second, err := ExpandTemplate("{session}", PathMetadata{})
+	if err != nil {
+		t.Fatal("ExpandTemplate:", err)
+	}
+	if second != first {
+		t.Errorf("Session ID changed from %q to %q", first, second)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// bindingsModule is the module path of these bindings.
+const bindingsModule = "go.undo.io/bindings"
+
+// InitInfo describes how the bindings initialized in the running program.
+//
+// It is intended to be attached to support requests as JSON, in place of
+// questions about the environment.
+type InitInfo struct {
+	// Library is the path of the UndoLR shared library mapped by the
+	// process. It is empty if the library is linked statically, or not
+	// at all.
+	Library string `json:"library,omitempty"`
+
+	// Version is the version string reported by the library.
+	Version string `json:"version,omitempty"`
+
+	// Stub is true if no library functions are available, so every call
+	// fails with ErrNotAvailable.
+	Stub bool `json:"stub"`
+
+	// Loader is how the library is found: "linked" when building, or
+	// "dlopen" when built with the undo_dlopen tag.
+	Loader string `json:"loader"`
+
+	// LoadError is why the library could not be loaded by dlopen.
+	LoadError string `json:"load_error,omitempty"`
+
+	// Missing lists the library functions not provided by the library
+	// in use, typically because it is older than the bindings.
+	Missing []string `json:"missing,omitempty"`
+
+	// BindingsVersion is the version of the bindings module the program
+	// was built with, if known.
+	BindingsVersion string `json:"bindings_version,omitempty"`
+
+	// GoVersion is the Go release the program was built with.
+	GoVersion string `json:"go_version"`
+
+	// BuildTags lists the tags the program was built with. They are
+	// only recorded by Go 1.18 and later.
+	BuildTags []string `json:"build_tags,omitempty"`
+}
+
+// InitReport describes how the bindings initialized in the running program.
+func InitReport() *InitInfo {
+	r := &InitInfo{
+		Library:   mappedLibrary("libundolr"),
+		Loader:    libLoader,
+		GoVersion: runtime.Version(),
+	}
+	libInit()
+	if libLoadErr != nil {
+		r.LoadError = libLoadErr.Error()
+	}
+	for fn := libFunction(0); fn < numLibFunctions; fn++ {
+		if require(fn) != nil {
+			r.Missing = append(r.Missing, fn.String())
+		}
+	}
+	r.Stub = !Available()
+	if !r.Stub {
+		r.Version = GetVersionString()
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		r.BindingsVersion = moduleVersion(info, bindingsModule)
+		if tags := buildSettings(info)["-tags"]; tags != "" {
+			r.BuildTags = strings.Split(tags, ",")
+		}
+	}
+	return r
+}
+
+// moduleVersion returns the version of module path in info, or "" if it
+// is not part of the build.
+func moduleVersion(info *debug.BuildInfo, path string) string {
+	if info.Main.Path == path {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != path {
+			continue
+		}
+		if dep.Replace != nil {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return ""
+}
+
+// mappedLibrary returns the path of the first shared library mapped by
+// the process whose name starts with prefix, or "" if there is none.
+func mappedLibrary(prefix string) string {
+	file, err := os.Open(procMapsPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		path := fields[5]
+		if strings.HasPrefix(filepath.Base(path), prefix) {
+			return path
+		}
+	}
+	return ""
+}
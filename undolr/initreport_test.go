@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestInitReport(t *testing.T) {
+	r := InitReport()
+	if r.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", r.GoVersion, runtime.Version())
+	}
+	if r.Stub != (len(r.Missing) == int(numLibFunctions)) {
+		t.Errorf("Stub = %v with %d of %d functions missing", r.Stub, len(r.Missing), numLibFunctions)
+	}
+	if r.Stub && r.Version != "" {
+		t.Errorf("Version = %q from a stub", r.Version)
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+	var decoded InitInfo
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if !reflect.DeepEqual(&decoded, r) {
+		t.Errorf("decoded report = %+v, want %+v", decoded, *r)
+	}
+}
+
+func TestMappedLibrary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr-initreport")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	maps := filepath.Join(dir, "maps")
+	err = ioutil.WriteFile(maps, []byte(
+		"7f0000000000-7f0000001000 r-xp 00000000 08:01 1 /usr/lib/libc.so.6\n"+
+			"7f0000002000-7f0000003000 r-xp 00000000 08:01 2 /opt/undo/libundolr_pic_x64.so\n"+
+			"7ffc00000000-7ffc00021000 rw-p 00000000 00:00 0 [stack]\n"), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	oldMaps := procMapsPath
+	procMapsPath = maps
+	defer func() { procMapsPath = oldMaps }()
+
+	if got := mappedLibrary("libundolr"); got != "/opt/undo/libundolr_pic_x64.so" {
+		t.Errorf("mappedLibrary = %q, want the UndoLR library", got)
+	}
+	if got := mappedLibrary("libundoex"); got != "" {
+		t.Errorf("mappedLibrary for a missing library = %q, want empty", got)
+	}
+
+	procMapsPath = filepath.Join(dir, "missing")
+	if got := mappedLibrary("libc"); got != "" {
+		t.Errorf("mappedLibrary without maps = %q, want empty", got)
+	}
+}
+
+func TestModuleVersion(t *testing.T) {
+	info := &debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "example.com/other", Version: "v1.0.0"},
+			{Path: bindingsModule, Version: "v0.3.0", Replace: &debug.Module{Path: "../bindings", Version: ""}},
+		},
+	}
+	if got := moduleVersion(info, "example.com/app"); got != "(devel)" {
+		t.Errorf("main module version = %q", got)
+	}
+	if got := moduleVersion(info, "example.com/other"); got != "v1.0.0" {
+		t.Errorf("dependency version = %q", got)
+	}
+	if got := moduleVersion(info, bindingsModule); got != "" {
+		t.Errorf("replaced dependency version = %q, want the replacement's", got)
+	}
+	if got := moduleVersion(info, "example.com/absent"); got != "" {
+		t.Errorf("absent module version = %q", got)
+	}
+}
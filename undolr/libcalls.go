@@ -0,0 +1,140 @@
+//go:build linux && cgo
+// +build linux,cgo
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+// #include <undolr.h>
+// #include <stdlib.h>
+// #include <errno.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// The functions below wrap the library calls, so the rest of the package
+// is independent of cgo and builds on other platforms against the stubs
+// in libcalls_stub.go. Each returns the library's result code, and errno
+// as an error as cgo reports it. Callers check for the function with
+// require first.
+
+// platformSupported is true as Live Recorder runs on this platform.
+const platformSupported = true
+
+// libContext is a recording context of the library.
+type libContext = C.undolr_recording_context_t
+
+func libStart() (rc int, code ErrorCode, err error) {
+	var undoError C.undolr_error_t
+	crc, err := C.undolr_start(&undoError)
+	return int(crc), ErrorCode(undoError), err
+}
+
+func libGetVersionString() string {
+	return C.GoString(C.undolr_get_version_string())
+}
+
+// libStop stops recording, storing the recording context in ctx, or
+// discarding it if ctx is nil.
+func libStop(ctx *libContext) (int, error) {
+	rc, err := C.undolr_stop(ctx)
+	return int(rc), err
+}
+
+func libSave(filename string) (int, error) {
+	cstring := C.CString(filename)
+	defer C.free(unsafe.Pointer(cstring))
+	rc, err := C.undolr_save(cstring)
+	return int(rc), err
+}
+
+func libSaveAsync(ctx libContext, filename string) (int, error) {
+	cstring := C.CString(filename)
+	defer C.free(unsafe.Pointer(cstring))
+	rc, err := C.undolr_save_async(ctx, cstring)
+	return int(rc), err
+}
+
+func libPollSavingProgress(ctx libContext) (complete bool, progress int, result int, rc int, err error) {
+	var cComplete, cProgress, cResult C.int
+	crc, err := C.undolr_poll_saving_progress(ctx, &cComplete, &cProgress, &cResult)
+	return cComplete != 0, int(cProgress), int(cResult), int(crc), err
+}
+
+func libGetSelectDescriptor(ctx libContext) (fd int, rc int, err error) {
+	var cFd C.int
+	crc, err := C.undolr_get_select_descriptor(ctx, &cFd)
+	return int(cFd), int(crc), err
+}
+
+func libDiscard(ctx libContext) (int, error) {
+	rc, err := C.undolr_discard(ctx)
+	return int(rc), err
+}
+
+func libSaveOnTermination(filename string) (int, error) {
+	cstring := C.CString(filename)
+	defer C.free(unsafe.Pointer(cstring))
+	rc, err := C.undolr_save_on_termination(cstring)
+	return int(rc), err
+}
+
+func libSaveOnTerminationCancel() (int, error) {
+	rc, err := C.undolr_save_on_termination_cancel()
+	return int(rc), err
+}
+
+func libEventLogSizeGet() (size int64, rc int, err error) {
+	var cBytes C.long
+	crc, err := C.undolr_event_log_size_get(&cBytes)
+	return int64(cBytes), int(crc), err
+}
+
+func libEventLogSizeSet(size int64) (int, error) {
+	rc, err := C.undolr_event_log_size_set(C.long(size))
+	return int(rc), err
+}
+
+func libIncludeSymbolFiles(include bool) (int, error) {
+	var cInclude C.int
+	if include {
+		cInclude = 1
+	}
+	rc, err := C.undolr_include_symbol_files(cInclude)
+	return int(rc), err
+}
+
+// libShmemLogFilenameSet sets the shared memory log, clearing it if
+// filename is empty.
+func libShmemLogFilenameSet(filename string) (int, error) {
+	var cstring *C.char
+	if filename != "" {
+		cstring = C.CString(filename)
+		defer C.free(unsafe.Pointer(cstring))
+	}
+	rc, err := C.undolr_shmem_log_filename_set(cstring)
+	return int(rc), err
+}
+
+func libShmemLogFilenameGet() (filename string, rc int, err error) {
+	var cFilename *C.char
+	crc, err := C.undolr_shmem_log_filename_get(&cFilename)
+	return C.GoString(cFilename), int(crc), err
+}
+
+func libShmemLogSizeSet(size uint64) (int, error) {
+	rc, err := C.undolr_shmem_log_size_set(C.ulong(size))
+	return int(rc), err
+}
+
+func libShmemLogSizeGet() (size uint64, rc int, err error) {
+	var cSize C.ulong
+	crc, err := C.undolr_shmem_log_size_get(&cSize)
+	return uint64(cSize), int(crc), err
+}
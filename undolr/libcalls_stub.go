@@ -0,0 +1,108 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"syscall"
+)
+
+// These stubs stand in for the library calls in libcalls.go where Live
+// Recorder cannot run. The library is never available, so they are called
+// only for functions which require permits to be no-ops once
+// SetUnsupportedPlatformNoOp is set; see noOp. They succeed without doing
+// anything, except those producing a recording, which fail.
+
+// platformSupported is false as Live Recorder does not run on this
+// platform, or cannot be called without cgo.
+const platformSupported = false
+
+// libLoader describes how the library was found, for InitReport.
+const libLoader = "stub"
+
+// loadLibrary always fails, as there is no library for this platform.
+func loadLibrary() error {
+	return ErrUnsupportedPlatform
+}
+
+func libFunctionPresent(fn libFunction) bool {
+	return false
+}
+
+// libContext stands in for a recording context of the library.
+type libContext uintptr
+
+func libStart() (rc int, code ErrorCode, err error) {
+	return 0, 0, nil
+}
+
+func libGetVersionString() string {
+	return ""
+}
+
+func libStop(ctx *libContext) (int, error) {
+	return 0, nil
+}
+
+func libSave(filename string) (int, error) {
+	return -1, syscall.ENOSYS
+}
+
+func libSaveAsync(ctx libContext, filename string) (int, error) {
+	return -1, syscall.ENOSYS
+}
+
+func libPollSavingProgress(ctx libContext) (complete bool, progress int, result int, rc int, err error) {
+	return false, 0, 0, -1, syscall.ENOSYS
+}
+
+func libGetSelectDescriptor(ctx libContext) (fd int, rc int, err error) {
+	return -1, -1, syscall.ENOSYS
+}
+
+func libDiscard(ctx libContext) (int, error) {
+	return 0, nil
+}
+
+func libSaveOnTermination(filename string) (int, error) {
+	return 0, nil
+}
+
+func libSaveOnTerminationCancel() (int, error) {
+	return 0, nil
+}
+
+func libEventLogSizeGet() (size int64, rc int, err error) {
+	return 0, 0, nil
+}
+
+func libEventLogSizeSet(size int64) (int, error) {
+	return 0, nil
+}
+
+func libIncludeSymbolFiles(include bool) (int, error) {
+	return 0, nil
+}
+
+func libShmemLogFilenameSet(filename string) (int, error) {
+	return 0, nil
+}
+
+func libShmemLogFilenameGet() (filename string, rc int, err error) {
+	return "", 0, nil
+}
+
+func libShmemLogSizeSet(size uint64) (int, error) {
+	return 0, nil
+}
+
+func libShmemLogSizeGet() (size uint64, rc int, err error) {
+	return 0, 0, nil
+}
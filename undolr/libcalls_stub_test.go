@@ -0,0 +1,53 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnsupportedPlatform(t *testing.T) {
+	err := Start()
+	if !errors.Is(err, ErrUnsupportedPlatform) || !errors.Is(err, ErrNotAvailable) {
+		t.Fatal("Expected Start() to fail with ErrUnsupportedPlatform:", err)
+	}
+	if err.Error() != "undolr_start: platform not supported by Live Recorder" {
+		t.Fatal("Unexpected error string", err)
+	}
+	if report := InitReport(); !report.Stub || report.Loader != "stub" {
+		t.Fatal("Unexpected report on an unsupported platform:", report)
+	}
+}
+
+func TestUnsupportedPlatformNoOp(t *testing.T) {
+	SetUnsupportedPlatformNoOp(true)
+	defer SetUnsupportedPlatformNoOp(false)
+
+	if err := Start(); err != nil {
+		t.Fatal("Start:", err)
+	}
+	if err := EventLogSizeSet(1024 * 1024); err != nil {
+		t.Fatal("EventLogSizeSet:", err)
+	}
+	if err := SaveOnTermination("undolr-stub.undo"); err != nil {
+		t.Fatal("SaveOnTermination:", err)
+	}
+	if err := SaveOnTerminationCancel(); err != nil {
+		t.Fatal("SaveOnTerminationCancel:", err)
+	}
+	if err := Save("undolr-stub.undo"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Fatal("Expected Save() to fail with ErrUnsupportedPlatform:", err)
+	}
+	if err := StopAndDiscard(); err != nil {
+		t.Fatal("StopAndDiscard:", err)
+	}
+}
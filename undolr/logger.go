@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"sync"
+)
+
+// A Logger receives reports of internal events, as key-value pairs following a message.
+//
+// The method set matches that of *slog.Logger, which can be passed to
+// SetLogger directly:
+//
+//	undolr.SetLogger(slog.Default())
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+var loggerLock sync.Mutex
+var logger Logger
+
+// SetLogger sets a Logger to report internal events which would otherwise only be visible in return values.
+//
+// The following are reported:
+//
+//   - saves starting, completing and failing, at the points reported to
+//     the save hook, see SetSaveHook;
+//   - library calls failing, with the function, return code and errno;
+//   - termination saves being armed, replaced and cancelled;
+//   - RecordingContexts found by the finalizer not to have been
+//     discarded, before the finalizer panics.
+//
+// The logger may be called with the package lock held, so must not call
+// functions in this package. Passing nil removes the logger.
+func SetLogger(l Logger) {
+	loggerLock.Lock()
+	defer loggerLock.Unlock()
+	logger = l
+}
+
+func currentLogger() Logger {
+	loggerLock.Lock()
+	defer loggerLock.Unlock()
+	return logger
+}
+
+func logInfo(msg string, args ...interface{}) {
+	if l := currentLogger(); l != nil {
+		l.Info(msg, args...)
+	}
+}
+
+func logWarn(msg string, args ...interface{}) {
+	if l := currentLogger(); l != nil {
+		l.Warn(msg, args...)
+	}
+}
+
+func logError(msg string, args ...interface{}) {
+	if l := currentLogger(); l != nil {
+		l.Error(msg, args...)
+	}
+}
+
+// logSave reports a save event.
+func logSave(event SaveEvent) {
+	switch event.Phase {
+	case SaveStarted:
+		logInfo("undolr: save started", "kind", event.Kind.String(), "filename", event.Filename)
+	case SaveCompleted:
+		logInfo("undolr: save completed", "kind", event.Kind.String(), "filename", event.Filename,
+			"bytes", event.Stats.BytesWritten, "duration", event.Stats.Duration)
+	case SaveFailed:
+		logError("undolr: save failed", "kind", event.Kind.String(), "filename", event.Filename,
+			"error", event.Err)
+	}
+}
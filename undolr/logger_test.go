@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+type testLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *testLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprint(level, " ", msg, " ", args))
+}
+
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("WARN", msg, args) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args) }
+
+func (l *testLogger) expect(t *testing.T, expected ...string) {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if fmt.Sprint(l.entries) != fmt.Sprint(expected) {
+		t.Errorf("Logged %q, expected %q", l.entries, expected)
+	}
+	l.entries = nil
+}
+
+func TestLoggerLibraryFailures(t *testing.T) {
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+
+	checkResult(fnSave, 0, nil)
+	l.expect(t)
+
+	checkResult(fnSave, -1, syscall.ENOSPC)
+	l.expect(t, "ERROR undolr: library call failed [function undolr_save rc -1 errno no space left on device]")
+
+	checkResult(fnSaveAsync, 1, nil)
+	l.expect(t, "WARN undolr: library call failed without setting errno [function undolr_save_async rc 1]")
+}
+
+func TestLoggerSaves(t *testing.T) {
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+
+	notifySave(SaveEvent{Phase: SaveStarted, Kind: SaveKindAsync, Filename: "a.undo"})
+	notifySave(SaveEvent{Phase: SaveCompleted, Kind: SaveKindAsync, Filename: "a.undo",
+		Stats: SaveStats{BytesWritten: 100}})
+	notifySave(SaveEvent{Phase: SaveFailed, Kind: SaveKindSync, Filename: "b.undo",
+		Err: errors.New("disk full")})
+	l.expect(t,
+		"INFO undolr: save started [kind async filename a.undo]",
+		"INFO undolr: save completed [kind async filename a.undo bytes 100 duration 0s]",
+		"ERROR undolr: save failed [kind sync filename b.undo error disk full]")
+
+	SetLogger(nil)
+	notifySave(SaveEvent{Phase: SaveStarted, Kind: SaveKindSync, Filename: "c.undo"})
+	l.expect(t)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+// MemoryStatistics describes the memory held by the recorder.
+//
+// The UndoLR library does not report its memory use, so this is an
+// estimate from the event log size, which dominates it: the event log is
+// counted in full while recording, and each stopped recording not yet
+// discarded is counted as the event log size when it was stopped. These
+// are upper bounds until the event log first fills. The memory is held
+// outside the Go heap, so is not included in runtime.MemStats.
+type MemoryStatistics struct {
+	// EventLog is the size of the event log if recording, or zero.
+	EventLog int64 `json:"event_log"`
+
+	// Stopped is the history held by StoppedContexts, the
+	// RecordingContexts returned by Stop and not yet discarded.
+	Stopped         int64 `json:"stopped"`
+	StoppedContexts int   `json:"stopped_contexts"`
+
+	// Total is EventLog plus Stopped.
+	Total int64 `json:"total"`
+}
+
+// MemoryStats reports an estimate of the memory held by the recorder.
+//
+// See MemoryStatistics for how it is estimated.
+func MemoryStats() MemoryStatistics {
+	lock.Lock()
+	active := recording
+	stats := MemoryStatistics{Stopped: stoppedBytes, StoppedContexts: stoppedContexts}
+	lock.Unlock()
+
+	if active {
+		stats.EventLog = currentEventLogSize()
+	}
+	stats.Total = stats.EventLog + stats.Stopped
+	return stats
+}
+
+// MemoryUsage returns the approximate number of bytes held by the recorder.
+//
+// This lets services with tight memory budgets account for the recorder,
+// whose memory is held outside the Go heap. It is MemoryStats().Total.
+func MemoryUsage() int64 {
+	return MemoryStats().Total
+}
+
+// untrack removes a discarded context from MemoryStats.
+func (context *RecordingContext) untrack() {
+	lock.Lock()
+	defer lock.Unlock()
+	if !context.tracked {
+		return
+	}
+	context.tracked = false
+	stoppedContexts--
+	stoppedBytes -= context.historyBytes
+}
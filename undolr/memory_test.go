@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"testing"
+)
+
+func TestMemoryStatsStopped(t *testing.T) {
+	context := &RecordingContext{historyBytes: 1000}
+	lock.Lock()
+	stoppedContexts++
+	stoppedBytes += context.historyBytes
+	context.tracked = true
+	lock.Unlock()
+
+	stats := MemoryStats()
+	if stats.StoppedContexts < 1 || stats.Stopped < 1000 || stats.Total != stats.EventLog+stats.Stopped {
+		t.Fatal("Unexpected stats with a stopped context", stats)
+	}
+
+	context.untrack()
+	context.untrack()
+	after := MemoryStats()
+	if after.StoppedContexts != stats.StoppedContexts-1 || after.Stopped != stats.Stopped-1000 {
+		t.Fatal("Unexpected stats after untracking", after, stats)
+	}
+	if MemoryUsage() != after.Total {
+		t.Fatal("Expected MemoryUsage() to be the total", MemoryUsage(), after)
+	}
+}
+
+func TestMemoryStats(t *testing.T) {
+	before := MemoryStats()
+
+	err := Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	recording := MemoryStats()
+	if recording.EventLog <= 0 {
+		t.Error("Expected the event log to be counted while recording", recording)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	stopped := MemoryStats()
+	if stopped.EventLog != 0 || stopped.StoppedContexts != before.StoppedContexts+1 || stopped.Stopped <= before.Stopped {
+		t.Error("Unexpected stats with a stopped context", stopped)
+	}
+
+	err = context.Discard()
+	if err != nil {
+		t.Fatal("Discard:", err)
+	}
+	if after := MemoryStats(); after != before {
+		t.Error("Unexpected stats after discarding", after, before)
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package metrics exposes the state of Live Recorder as metrics.
+//
+// A Metrics value observes saves through undolr.AddSaveHook and combines
+// them with the current recording state and discard totals. It serves the
+// Prometheus text exposition format, so it can be scraped without adding
+// a dependency on the Prometheus client library:
+//
+//	m := metrics.Install()
+//	http.Handle("/metrics/undo", m)
+//
+// The metrics are also available as a Snapshot for other systems. The
+// library reports the event log's capacity but not its occupancy, so only
+// the former is exported.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// DurationBuckets are the upper bounds, in seconds, of the save duration histogram.
+var DurationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+// Counts holds the save counters for one kind of save.
+type Counts struct {
+	Started      int64
+	Completed    int64
+	Failed       int64
+	BytesWritten int64
+
+	// DurationBuckets counts completed saves by duration, with an entry
+	// for each of DurationBuckets and a final entry for longer saves.
+	DurationBuckets []int64
+	DurationSum     float64
+}
+
+// A Snapshot holds the values of the metrics at a point in time.
+type Snapshot struct {
+	Recording      bool
+	AnnotationOnly bool
+
+	// EventLogSize is the event log capacity in bytes, or -1 if unknown.
+	EventLogSize int64
+
+	// Saves holds counters for each kind of save.
+	Saves map[undolr.SaveKind]Counts
+
+	Discards undolr.DiscardTotals
+
+	// Memory is the estimated memory held by the recorder.
+	Memory undolr.MemoryStatistics
+
+	// Overhead is the estimated overhead of recording, if
+	// undolr.StartOverheadMonitor has been called.
+	Overhead undolr.OverheadEstimate
+}
+
+// Metrics accumulates save events.
+type Metrics struct {
+	mu    sync.Mutex
+	saves map[undolr.SaveKind]*Counts
+}
+
+// New returns Metrics with no saves observed.
+//
+// Pass its Observe method to undolr.AddSaveHook, or use Install.
+func New() *Metrics {
+	return &Metrics{saves: make(map[undolr.SaveKind]*Counts)}
+}
+
+// Install returns new Metrics observing all saves.
+//
+// The metrics are added as a save hook with undolr.AddSaveHook, so other
+// save hooks, such as those uploading recordings, are kept.
+func Install() *Metrics {
+	m := New()
+	undolr.AddSaveHook(m.Observe)
+	return m
+}
+
+// Observe records a save event.
+func (m *Metrics) Observe(event undolr.SaveEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts, ok := m.saves[event.Kind]
+	if !ok {
+		counts = &Counts{DurationBuckets: make([]int64, len(DurationBuckets)+1)}
+		m.saves[event.Kind] = counts
+	}
+
+	switch event.Phase {
+	case undolr.SaveStarted:
+		counts.Started++
+	case undolr.SaveFailed:
+		counts.Failed++
+	case undolr.SaveCompleted:
+		counts.Completed++
+		counts.BytesWritten += event.Stats.BytesWritten
+		seconds := event.Stats.Duration.Seconds()
+		counts.DurationSum += seconds
+		i := 0
+		for i < len(DurationBuckets) && seconds > DurationBuckets[i] {
+			i++
+		}
+		counts.DurationBuckets[i]++
+	}
+}
+
+// Snapshot returns the current values of the metrics.
+func (m *Metrics) Snapshot() Snapshot {
+	mode := undolr.CurrentMode()
+	snapshot := Snapshot{
+		Recording:      mode == undolr.ModeRecording,
+		AnnotationOnly: mode == undolr.ModeAnnotationOnly,
+		EventLogSize:   -1,
+		Saves:          make(map[undolr.SaveKind]Counts),
+		Discards:       undolr.DiscardStats(),
+		Memory:         undolr.MemoryStats(),
+		Overhead:       undolr.Overhead(),
+	}
+	if size, err := undolr.EventLogSizeGet(); err == nil {
+		snapshot.EventLogSize = size
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for kind, counts := range m.saves {
+		c := *counts
+		c.DurationBuckets = append([]int64(nil), counts.DurationBuckets...)
+		snapshot.Saves[kind] = c
+	}
+	return snapshot
+}
+
+// kinds lists the save kinds in the order they are written.
+var kinds = []undolr.SaveKind{undolr.SaveKindSync, undolr.SaveKindAsync, undolr.SaveKindTermination}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	s := m.Snapshot()
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(cw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("undolr_recording", "gauge", "Whether the process is being recorded.")
+	fmt.Fprintf(cw, "undolr_recording %d\n", boolValue(s.Recording))
+	metric("undolr_annotation_only", "gauge", "Whether recording fell back to annotation-only mode.")
+	fmt.Fprintf(cw, "undolr_annotation_only %d\n", boolValue(s.AnnotationOnly))
+	if s.EventLogSize >= 0 {
+		metric("undolr_event_log_size_bytes", "gauge", "Maximum size of the event log.")
+		fmt.Fprintf(cw, "undolr_event_log_size_bytes %d\n", s.EventLogSize)
+	}
+	metric("undolr_memory_bytes", "gauge", "Estimated memory held by the recorder, outside the Go heap.")
+	fmt.Fprintf(cw, "undolr_memory_bytes{state=\"recording\"} %d\n", s.Memory.EventLog)
+	fmt.Fprintf(cw, "undolr_memory_bytes{state=\"stopped\"} %d\n", s.Memory.Stopped)
+	metric("undolr_stopped_contexts", "gauge", "Stopped recordings held in memory and not yet discarded.")
+	fmt.Fprintf(cw, "undolr_stopped_contexts %d\n", s.Memory.StoppedContexts)
+
+	counters := []struct {
+		name, help string
+		value      func(Counts) int64
+	}{
+		{"undolr_saves_started_total", "Saves started.", func(c Counts) int64 { return c.Started }},
+		{"undolr_saves_completed_total", "Saves completed.", func(c Counts) int64 { return c.Completed }},
+		{"undolr_saves_failed_total", "Saves which failed after starting.", func(c Counts) int64 { return c.Failed }},
+		{"undolr_save_written_bytes_total", "Bytes written by completed saves.", func(c Counts) int64 { return c.BytesWritten }},
+	}
+	for _, counter := range counters {
+		metric(counter.name, "counter", counter.help)
+		for _, kind := range kinds {
+			if c, ok := s.Saves[kind]; ok {
+				fmt.Fprintf(cw, "%s{kind=%q} %d\n", counter.name, kind, counter.value(c))
+			}
+		}
+	}
+
+	metric("undolr_save_duration_seconds", "histogram", "Duration of completed saves.")
+	for _, kind := range kinds {
+		c, ok := s.Saves[kind]
+		if !ok {
+			continue
+		}
+		var cumulative int64
+		for i, bound := range DurationBuckets {
+			cumulative += c.DurationBuckets[i]
+			fmt.Fprintf(cw, "undolr_save_duration_seconds_bucket{kind=%q,le=\"%g\"} %d\n", kind, bound, cumulative)
+		}
+		cumulative += c.DurationBuckets[len(DurationBuckets)]
+		fmt.Fprintf(cw, "undolr_save_duration_seconds_bucket{kind=%q,le=\"+Inf\"} %d\n", kind, cumulative)
+		fmt.Fprintf(cw, "undolr_save_duration_seconds_sum{kind=%q} %g\n", kind, c.DurationSum)
+		fmt.Fprintf(cw, "undolr_save_duration_seconds_count{kind=%q} %d\n", kind, cumulative)
+	}
+
+	metric("undolr_discards_total", "counter", "Recordings discarded.")
+	fmt.Fprintf(cw, "undolr_discards_total %d\n", s.Discards.Discards)
+	metric("undolr_discards_lost_total", "counter", "Recordings discarded without being saved.")
+	fmt.Fprintf(cw, "undolr_discards_lost_total %d\n", s.Discards.Lost)
+	metric("undolr_discards_lost_bytes_total", "counter", "Estimated history discarded without being saved.")
+	fmt.Fprintf(cw, "undolr_discards_lost_bytes_total %d\n", s.Discards.LostBytes)
+
+	if s.Overhead.Monitoring {
+		metric("undolr_scheduling_latency_seconds", "gauge", "Mean scheduling latency sampled by the overhead monitor.")
+		fmt.Fprintf(cw, "undolr_scheduling_latency_seconds{state=\"baseline\"} %g\n", s.Overhead.BaselineLatency.Seconds())
+		fmt.Fprintf(cw, "undolr_scheduling_latency_seconds{state=\"recording\"} %g\n", s.Overhead.RecordingLatency.Seconds())
+		metric("undolr_overhead_stall_ratio", "gauge", "Estimated fraction of wall time stalled by the recorder.")
+		fmt.Fprintf(cw, "undolr_overhead_stall_ratio %g\n", s.Overhead.StallRatio)
+	}
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
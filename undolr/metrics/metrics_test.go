@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.undo.io/bindings/undolr"
+)
+
+func TestObserve(t *testing.T) {
+	m := New()
+	m.Observe(undolr.SaveEvent{Phase: undolr.SaveStarted, Kind: undolr.SaveKindSync})
+	m.Observe(undolr.SaveEvent{Phase: undolr.SaveCompleted, Kind: undolr.SaveKindSync,
+		Stats: undolr.SaveStats{BytesWritten: 100, Duration: time.Second * 2}})
+	m.Observe(undolr.SaveEvent{Phase: undolr.SaveStarted, Kind: undolr.SaveKindAsync})
+	m.Observe(undolr.SaveEvent{Phase: undolr.SaveFailed, Kind: undolr.SaveKindAsync, Err: syscall.ENOSPC})
+
+	s := m.Snapshot()
+	sync := s.Saves[undolr.SaveKindSync]
+	if sync.Started != 1 || sync.Completed != 1 || sync.BytesWritten != 100 || sync.DurationSum != 2 {
+		t.Fatalf("Unexpected sync counts: %+v", sync)
+	}
+	if sync.DurationBuckets[5] != 1 {
+		t.Fatalf("Duration not in 5s bucket: %v", sync.DurationBuckets)
+	}
+	async := s.Saves[undolr.SaveKindAsync]
+	if async.Started != 1 || async.Failed != 1 || async.Completed != 0 {
+		t.Fatalf("Unexpected async counts: %+v", async)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	m := New()
+	m.Observe(undolr.SaveEvent{Phase: undolr.SaveStarted, Kind: undolr.SaveKindSync})
+	m.Observe(undolr.SaveEvent{Phase: undolr.SaveCompleted, Kind: undolr.SaveKindSync,
+		Stats: undolr.SaveStats{BytesWritten: 100, Duration: time.Millisecond * 200}})
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatal("Unexpected content type:", w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, expected := range []string{
+		"# TYPE undolr_recording gauge\nundolr_recording 0\n",
+		"undolr_saves_started_total{kind=\"sync\"} 1\n",
+		"undolr_save_written_bytes_total{kind=\"sync\"} 100\n",
+		"undolr_save_duration_seconds_bucket{kind=\"sync\",le=\"0.1\"} 0\n",
+		"undolr_save_duration_seconds_bucket{kind=\"sync\",le=\"0.5\"} 1\n",
+		"undolr_save_duration_seconds_bucket{kind=\"sync\",le=\"+Inf\"} 1\n",
+		"undolr_save_duration_seconds_count{kind=\"sync\"} 1\n",
+		"# TYPE undolr_discards_total counter\n",
+		"undolr_memory_bytes{state=\"recording\"} 0\n",
+		"undolr_memory_bytes{state=\"stopped\"} 0\n",
+		"undolr_stopped_contexts 0\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("Output doesn't contain %q:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, "kind=\"async\"") {
+		t.Fatalf("Unexpected async metrics:\n%s", body)
+	}
+}
+
+func TestOverheadMetrics(t *testing.T) {
+	var buf strings.Builder
+	New().WriteTo(&buf)
+	if strings.Contains(buf.String(), "undolr_overhead_stall_ratio") {
+		t.Fatalf("Unexpected overhead metrics without monitor:\n%s", buf.String())
+	}
+
+	err := undolr.StartOverheadMonitor(undolr.OverheadOptions{})
+	if err != nil {
+		t.Fatal("StartOverheadMonitor:", err)
+	}
+	defer undolr.StopOverheadMonitor()
+
+	buf.Reset()
+	New().WriteTo(&buf)
+	for _, expected := range []string{
+		"# TYPE undolr_overhead_stall_ratio gauge\nundolr_overhead_stall_ratio ",
+		"undolr_scheduling_latency_seconds{state=\"baseline\"} ",
+		"undolr_scheduling_latency_seconds{state=\"recording\"} ",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Fatalf("Output doesn't contain %q:\n%s", expected, buf.String())
+		}
+	}
+}
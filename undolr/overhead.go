@@ -0,0 +1,222 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOverheadMonitorRunning indicates StartOverheadMonitor was called while the monitor is running.
+var ErrOverheadMonitorRunning = errors.New("overhead monitor already running")
+
+// Defaults for OverheadOptions.
+const (
+	DefaultOverheadInterval = 10 * time.Millisecond
+	DefaultOverheadWindow   = 500
+)
+
+// OverheadOptions configures the overhead monitor started by StartOverheadMonitor.
+type OverheadOptions struct {
+	// Interval is the time the sampling goroutine sleeps for between
+	// samples, or zero for DefaultOverheadInterval.
+	Interval time.Duration
+
+	// Window is the number of recent samples averaged for each estimate,
+	// or zero for DefaultOverheadWindow.
+	Window int
+}
+
+// OverheadEstimate is an estimate of the overhead of recording.
+//
+// It is derived from scheduling latency: the time by which a goroutine
+// sleeping for Interval oversleeps. Samples taken while not recording
+// form the baseline, so the monitor should be started before Start, and
+// the increase in latency while recording is attributed to the recorder.
+type OverheadEstimate struct {
+	// Monitoring reports whether the overhead monitor is running.
+	Monitoring bool `json:"monitoring"`
+
+	Interval time.Duration `json:"interval_ns"`
+
+	// BaselineSamples and RecordingSamples count the samples in the
+	// current windows while not recording and while recording.
+	BaselineSamples  int `json:"baseline_samples"`
+	RecordingSamples int `json:"recording_samples"`
+
+	// BaselineLatency and RecordingLatency are the mean scheduling
+	// latencies over the windows.
+	BaselineLatency  time.Duration `json:"baseline_latency_ns"`
+	RecordingLatency time.Duration `json:"recording_latency_ns"`
+
+	// StallRatio is the estimated fraction of wall time stalled by the
+	// recorder, from 0 to 1. It is only meaningful when there are both
+	// baseline and recording samples.
+	StallRatio float64 `json:"stall_ratio"`
+}
+
+// latencyWindow holds the most recent scheduling latency samples.
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+	full    bool
+	sum     time.Duration
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	if w.full {
+		w.sum -= w.samples[w.next]
+	}
+	w.samples[w.next] = latency
+	w.sum += latency
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+func (w *latencyWindow) count() int {
+	if w.full {
+		return len(w.samples)
+	}
+	return w.next
+}
+
+func (w *latencyWindow) mean() time.Duration {
+	n := w.count()
+	if n == 0 {
+		return 0
+	}
+	return w.sum / time.Duration(n)
+}
+
+// overheadMonitor accumulates latency samples. Its fields are protected
+// by overheadLock.
+type overheadMonitor struct {
+	interval  time.Duration
+	baseline  latencyWindow
+	recording latencyWindow
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+var overheadLock sync.Mutex
+var overhead *overheadMonitor
+
+func newOverheadMonitor(opts OverheadOptions) *overheadMonitor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultOverheadInterval
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultOverheadWindow
+	}
+	return &overheadMonitor{
+		interval:  opts.Interval,
+		baseline:  latencyWindow{samples: make([]time.Duration, opts.Window)},
+		recording: latencyWindow{samples: make([]time.Duration, opts.Window)},
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// add records a sample. It must be called with overheadLock held.
+func (m *overheadMonitor) add(latency time.Duration, recording bool) {
+	if latency < 0 {
+		latency = 0
+	}
+	if recording {
+		m.recording.add(latency)
+	} else {
+		m.baseline.add(latency)
+	}
+}
+
+// estimate returns the current estimate. It must be called with
+// overheadLock held.
+func (m *overheadMonitor) estimate() OverheadEstimate {
+	estimate := OverheadEstimate{
+		Monitoring:       true,
+		Interval:         m.interval,
+		BaselineSamples:  m.baseline.count(),
+		RecordingSamples: m.recording.count(),
+		BaselineLatency:  m.baseline.mean(),
+		RecordingLatency: m.recording.mean(),
+	}
+	if estimate.BaselineSamples > 0 && estimate.RecordingLatency > estimate.BaselineLatency {
+		stalled := estimate.RecordingLatency - estimate.BaselineLatency
+		estimate.StallRatio = float64(stalled) / float64(m.interval+estimate.RecordingLatency)
+	}
+	return estimate
+}
+
+// run samples scheduling latency until stopped. The latency is measured
+// with the system clock, as it is a property of the real process.
+func (m *overheadMonitor) run() {
+	defer close(m.done)
+	timer := time.NewTimer(m.interval)
+	defer timer.Stop()
+	for {
+		start := time.Now()
+		timer.Reset(m.interval)
+		select {
+		case <-m.stop:
+			return
+		case <-timer.C:
+		}
+		latency := time.Since(start) - m.interval
+		recording := CurrentMode() == ModeRecording
+
+		overheadLock.Lock()
+		m.add(latency, recording)
+		overheadLock.Unlock()
+	}
+}
+
+// StartOverheadMonitor starts estimating the overhead of recording, reported by Overhead.
+//
+// A goroutine repeatedly sleeps for the interval and measures how late it
+// wakes. This costs a wakeup per interval, so the interval should not be
+// much shorter than the default.
+func StartOverheadMonitor(opts OverheadOptions) error {
+	overheadLock.Lock()
+	defer overheadLock.Unlock()
+	if overhead != nil {
+		return ErrOverheadMonitorRunning
+	}
+	overhead = newOverheadMonitor(opts)
+	go overhead.run()
+	return nil
+}
+
+// StopOverheadMonitor stops the monitor started by StartOverheadMonitor, discarding its samples.
+func StopOverheadMonitor() {
+	overheadLock.Lock()
+	m := overhead
+	overhead = nil
+	overheadLock.Unlock()
+
+	if m != nil {
+		close(m.stop)
+		<-m.done
+	}
+}
+
+// Overhead returns the current estimate of the overhead of recording.
+//
+// Monitoring is false, and the other fields zero, unless the monitor has
+// been started by StartOverheadMonitor.
+func Overhead() OverheadEstimate {
+	overheadLock.Lock()
+	defer overheadLock.Unlock()
+	if overhead == nil {
+		return OverheadEstimate{}
+	}
+	return overhead.estimate()
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverheadEstimate(t *testing.T) {
+	m := newOverheadMonitor(OverheadOptions{Interval: 10 * time.Millisecond, Window: 4})
+
+	estimate := m.estimate()
+	if !estimate.Monitoring || estimate.StallRatio != 0 || estimate.BaselineSamples != 0 {
+		t.Fatalf("Unexpected estimate with no samples: %+v", estimate)
+	}
+
+	for _, latency := range []time.Duration{time.Millisecond, time.Millisecond, -time.Millisecond} {
+		m.add(latency, false)
+	}
+	// Only the last four samples are kept.
+	for _, latency := range []time.Duration{time.Hour, 10 * time.Millisecond, 10 * time.Millisecond, 2 * time.Millisecond, 2 * time.Millisecond} {
+		m.add(latency, true)
+	}
+
+	estimate = m.estimate()
+	if estimate.BaselineSamples != 3 || estimate.RecordingSamples != 4 {
+		t.Fatalf("Unexpected sample counts: %+v", estimate)
+	}
+	if estimate.BaselineLatency != 2*time.Millisecond/3 || estimate.RecordingLatency != 6*time.Millisecond {
+		t.Fatalf("Unexpected latencies: %+v", estimate)
+	}
+	expected := float64(6*time.Millisecond-2*time.Millisecond/3) / float64(16*time.Millisecond)
+	if estimate.StallRatio != expected {
+		t.Fatalf("Stall ratio doesn't match (%g vs %g)", estimate.StallRatio, expected)
+	}
+}
+
+func TestOverheadMonitor(t *testing.T) {
+	if Overhead().Monitoring {
+		t.Fatal("Unexpected monitoring before start")
+	}
+
+	err := StartOverheadMonitor(OverheadOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal("StartOverheadMonitor:", err)
+	}
+	defer StopOverheadMonitor()
+	if err := StartOverheadMonitor(OverheadOptions{}); err != ErrOverheadMonitorRunning {
+		t.Fatal("Expected ErrOverheadMonitorRunning, got", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for Overhead().BaselineSamples < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("No samples taken:", Overhead())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	estimate := Overhead()
+	if !estimate.Monitoring || estimate.Interval != time.Millisecond || estimate.RecordingSamples != 0 {
+		t.Fatalf("Unexpected estimate: %+v", estimate)
+	}
+
+	StopOverheadMonitor()
+	if Overhead().Monitoring {
+		t.Fatal("Unexpected monitoring after stop")
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A SaveKind identifies the kind of save a recording path is resolved for.
+type SaveKind int
+
+// Values for SaveKind
+const (
+	// SaveKindSync is a save while recording, by Save or SaveWithStats.
+	SaveKindSync SaveKind = iota
+
+	// SaveKindAsync is a save of a stopped recording, by SaveAsync or
+	// SaveBackground.
+	SaveKindAsync
+
+	// SaveKindTermination is a save when the process exits, armed by
+	// SaveOnTermination.
+	SaveKindTermination
+)
+
+func (k SaveKind) String() string {
+	switch k {
+	case SaveKindSync:
+		return "sync"
+	case SaveKindAsync:
+		return "async"
+	case SaveKindTermination:
+		return "termination"
+	default:
+		return "unknown"
+	}
+}
+
+// PathMetadata describes the save a recording path is being resolved for.
+type PathMetadata struct {
+	Kind SaveKind
+	Time time.Time
+	PID  int
+}
+
+// A PathResolver maps the filename passed to a save function to the path the recording is written to.
+//
+// This allows a platform to apply its own layout for recordings in one
+// place rather than at every call site.
+type PathResolver interface {
+	ResolvePath(name string, meta PathMetadata) (string, error)
+}
+
+// The PathResolverFunc type is an adapter to allow the use of ordinary functions as a PathResolver.
+type PathResolverFunc func(name string, meta PathMetadata) (string, error)
+
+// ResolvePath calls f(name, meta).
+func (f PathResolverFunc) ResolvePath(name string, meta PathMetadata) (string, error) {
+	return f(name, meta)
+}
+
+// DirectoryResolver returns a PathResolver placing recordings given by relative names in dir.
+//
+// Absolute names are left unchanged. The directory is created if needed.
+func DirectoryResolver(dir string) PathResolver {
+	return PathResolverFunc(func(name string, meta PathMetadata) (string, error) {
+		if filepath.IsAbs(name) {
+			return name, nil
+		}
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, name), nil
+	})
+}
+
+var pathResolver PathResolver
+
+// SetPathResolver sets the PathResolver used by all functions which save recordings.
+//
+// This applies to Save, SaveWithStats, SaveAsync, SaveBackground and
+// SaveOnTermination. Passing nil restores the default of using filenames
+// as given. In either case relative filenames are made absolute, and the
+// directory must exist.
+func SetPathResolver(r PathResolver) {
+	lock.Lock()
+	defer lock.Unlock()
+	pathResolver = r
+}
+
+// resolvePath applies the current PathResolver to filename, and returns
+// the result normalized by normalizeFilename. It must be called without
+// lock held.
+func resolvePath(filename string, kind SaveKind) (string, error) {
+	lock.Lock()
+	r := pathResolver
+	lock.Unlock()
+
+	if r != nil {
+		var err error
+		filename, err = r.ResolvePath(filename, PathMetadata{
+			Kind: kind,
+			Time: now(),
+			PID:  os.Getpid(),
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return normalizeFilename(filename)
+}
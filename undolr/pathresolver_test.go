@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePathDefault(t *testing.T) {
+	filename, err := resolvePath("recording.undolr", SaveKindSync)
+	if err != nil {
+		t.Fatal("resolvePath:", err)
+	}
+
+	expected, err := filepath.Abs("recording.undolr")
+	if err != nil {
+		t.Fatal("Abs:", err)
+	} else if filename != expected {
+		t.Fatal("Unexpected filename", filename)
+	}
+}
+
+func TestPathResolverFunc(t *testing.T) {
+	SetPathResolver(PathResolverFunc(func(name string, meta PathMetadata) (string, error) {
+		return fmt.Sprintf("%s-%s-%d", name, meta.Kind, meta.PID), nil
+	}))
+	defer SetPathResolver(nil)
+
+	filename, err := resolvePath("recording", SaveKindTermination)
+	if err != nil {
+		t.Fatal("resolvePath:", err)
+	}
+
+	expected, err := filepath.Abs(fmt.Sprintf("recording-termination-%d", os.Getpid()))
+	if err != nil {
+		t.Fatal("Abs:", err)
+	} else if filename != expected {
+		t.Fatalf("Filename doesn't match (%s vs %s)", filename, expected)
+	}
+}
+
+func TestDirectoryResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	subdir := filepath.Join(dir, "recordings")
+	resolver := DirectoryResolver(subdir)
+
+	filename, err := resolver.ResolvePath("recording.undolr", PathMetadata{})
+	if err != nil {
+		t.Fatal("ResolvePath:", err)
+	} else if filename != filepath.Join(subdir, "recording.undolr") {
+		t.Fatal("Unexpected filename", filename)
+	}
+
+	if _, err = os.Stat(subdir); err != nil {
+		t.Fatal("Directory not created:", err)
+	}
+
+	filename, err = resolver.ResolvePath("/tmp/recording.undolr", PathMetadata{})
+	if err != nil {
+		t.Fatal("ResolvePath:", err)
+	} else if filename != "/tmp/recording.undolr" {
+		t.Fatal("Absolute filename changed:", filename)
+	}
+}
+
+func TestPathResolverSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	SetPathResolver(DirectoryResolver(dir))
+	defer SetPathResolver(nil)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	stats, err := SaveWithStats("recording.undolr")
+	if err != nil {
+		t.Fatal("SaveWithStats:", err)
+	}
+
+	err = StopAndDiscard()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+
+	filename := filepath.Join(dir, "recording.undolr")
+	if stats.Filename != filename {
+		t.Fatalf("Filename doesn't match (%s vs %s)", stats.Filename, filename)
+	}
+	verifyRecording(t, filename)
+}
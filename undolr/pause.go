@@ -0,0 +1,30 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+// Pause suspends recording without discarding the history recorded so far.
+//
+// No version of the UndoLR library provides pausing, so Pause always
+// returns a *NotSupportedError matching ErrNotSupportedByLibrary. It is
+// provided so callers can be written against it and fall back to
+// continuing to record, rather than calling Stop which loses the history:
+//
+//	if err := undolr.Pause(); err == nil {
+//		defer undolr.Resume()
+//	}
+func Pause() error {
+	return &NotSupportedError{"undolr_pause"}
+}
+
+// Resume continues recording suspended by Pause.
+//
+// As Pause is never supported, Resume always returns a *NotSupportedError
+// matching ErrNotSupportedByLibrary.
+func Resume() error {
+	return &NotSupportedError{"undolr_resume"}
+}
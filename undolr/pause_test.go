@@ -0,0 +1,22 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPauseResume(t *testing.T) {
+	if err := Pause(); !errors.Is(err, ErrNotSupportedByLibrary) {
+		t.Error("Pause: expected ErrNotSupportedByLibrary, got", err)
+	}
+	if err := Resume(); !errors.Is(err, ErrNotSupportedByLibrary) {
+		t.Error("Resume: expected ErrNotSupportedByLibrary, got", err)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// SaveResultCode is the result of a completed asynchronous save.
+//
+// The library reports zero for success, or otherwise an errno value
+// describing the failure.
+type SaveResultCode int
+
+// Result codes for completed asynchronous saves.
+const (
+	SaveResultSuccess  SaveResultCode = 0
+	SaveResultIOError  SaveResultCode = SaveResultCode(syscall.EIO)
+	SaveResultDiskFull SaveResultCode = SaveResultCode(syscall.ENOSPC)
+)
+
+// ProgressUnknown is the progress reported for a save in progress when the
+// library cannot estimate how far through it is.
+const ProgressUnknown = -1
+
+// Err returns nil for SaveResultSuccess, or the corresponding syscall.Errno.
+func (code SaveResultCode) Err() error {
+	if code == SaveResultSuccess {
+		return nil
+	}
+	return syscall.Errno(code)
+}
+
+func (code SaveResultCode) String() string {
+	switch code {
+	case SaveResultSuccess:
+		return "success"
+	case SaveResultIOError:
+		return "I/O error"
+	case SaveResultDiskFull:
+		return "disk full"
+	default:
+		return fmt.Sprintf("error %d (%v)", int(code), syscall.Errno(code))
+	}
+}
+
+// SaveStatus describes the status of an asynchronous save.
+type SaveStatus struct {
+	// Complete reports whether the save has finished.
+	Complete bool
+
+	// Progress is the percentage of the save completed, from 0 to 100, or
+	// ProgressUnknown. It is only valid while the save is incomplete.
+	Progress int
+
+	// Result is the result of the save. It is only valid once the save
+	// is complete.
+	Result SaveResultCode
+
+	// Err is set if the status could not be determined, or if the save
+	// completed unsuccessfully, in which case it is Result.Err().
+	Err error
+}
+
+// PollStatus reports the status of the current SaveAsync operation.
+//
+// This is equivalent to Poll, but with the result typed.
+func (context *RecordingContext) PollStatus() SaveStatus {
+	complete, progress, result, err := context.Poll()
+	if err != nil {
+		return SaveStatus{Err: err}
+	}
+
+	status := SaveStatus{Complete: complete}
+	if complete {
+		status.Result = SaveResultCode(result)
+		status.Err = status.Result.Err()
+	} else {
+		status.Progress = progress
+	}
+	return status
+}
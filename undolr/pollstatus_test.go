@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSaveResultCode(t *testing.T) {
+	if SaveResultSuccess.Err() != nil {
+		t.Fatal("Unexpected error for success:", SaveResultSuccess.Err())
+	}
+	if !errors.Is(SaveResultDiskFull.Err(), syscall.ENOSPC) {
+		t.Fatal("Disk full not reported as ENOSPC:", SaveResultDiskFull.Err())
+	}
+	if SaveResultIOError.String() != "I/O error" {
+		t.Fatal("Unexpected string:", SaveResultIOError.String())
+	}
+	if SaveResultCode(syscall.EACCES).String() == "" {
+		t.Fatal("Empty string for other error")
+	}
+}
+
+func TestPollStatus(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	status := context.PollStatus()
+	if status.Err != ErrRecordingContextSaveNotStarted {
+		t.Fatal("Expected PollStatus() to fail before saving:", status.Err)
+	}
+
+	err = context.SaveAsync(filename)
+	if err != nil {
+		t.Fatal("SaveAsync:", err)
+	}
+
+	deadline := time.Now().Add(time.Second * 30)
+	for {
+		status = context.PollStatus()
+		if status.Err != nil {
+			t.Fatal("PollStatus:", status.Err)
+		}
+		if status.Complete {
+			break
+		}
+		if status.Progress != ProgressUnknown && (status.Progress < 0 || status.Progress > 100) {
+			t.Fatal("Unexpected progress:", status.Progress)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Save hadn't completed after 30 seconds")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if status.Result != SaveResultSuccess {
+		t.Fatal("Unexpected result:", status.Result)
+	}
+	verifyRecording(t, filename)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A SeccompMode is the seccomp mode of the process, as reported by /proc/self/status.
+type SeccompMode int
+
+// Values for SeccompMode
+const (
+	SeccompDisabled SeccompMode = 0
+	SeccompStrict   SeccompMode = 1
+	SeccompFilter   SeccompMode = 2
+)
+
+func (m SeccompMode) String() string {
+	switch m {
+	case SeccompDisabled:
+		return "disabled"
+	case SeccompStrict:
+		return "strict"
+	case SeccompFilter:
+		return "filter"
+	default:
+		return "unknown"
+	}
+}
+
+// capSysPtrace is the bit number of CAP_SYS_PTRACE in capability sets.
+const capSysPtrace = 19
+
+// procStatusPath is a variable so tests can substitute their own file.
+var procStatusPath = "/proc/self/status"
+
+// A PreflightFinding describes a condition which may prevent recording.
+type PreflightFinding struct {
+	// Check names the check which produced the finding.
+	Check string
+
+	// Message describes the condition found.
+	Message string
+
+	// Remedy describes the change needed, for containers in terms of the
+	// Kubernetes securityContext where applicable.
+	Remedy string
+
+	// Blocking is true if recording is known to fail, rather than
+	// possibly fail, in this condition.
+	Blocking bool
+}
+
+func (f PreflightFinding) String() string {
+	return fmt.Sprintf("%s: %s; %s", f.Check, f.Message, f.Remedy)
+}
+
+// A PreflightReport describes the environment recording would take place in.
+type PreflightReport struct {
+	PtraceScope  PtraceScope
+	Seccomp      SeccompMode
+	NoNewPrivs   bool
+	CapSysPtrace bool
+	Fingerprint  *Fingerprint
+	Findings     []PreflightFinding
+}
+
+// OK reports whether no blocking findings were made.
+func (r *PreflightReport) OK() bool {
+	for _, finding := range r.Findings {
+		if finding.Blocking {
+			return false
+		}
+	}
+	return true
+}
+
+// Preflight examines the current process and host for conditions which prevent Live Recorder attaching.
+//
+// This covers the Yama ptrace scope (see CheckPtraceScope), seccomp
+// filtering, whether the process holds CAP_SYS_PTRACE and the features of
+// the environment reported by Fingerprint.Findings, such as protection
+// keys. It changes nothing, so may be used to diagnose a Start failure
+// inside a container where the reason would otherwise be unclear.
+func Preflight() (*PreflightReport, error) {
+	report := &PreflightReport{}
+
+	scope, err := ReadPtraceScope()
+	if err != nil {
+		return nil, err
+	}
+	report.PtraceScope = scope
+
+	err = readProcStatus(report)
+	if err != nil {
+		return nil, err
+	}
+
+	if scopeErr, ok := CheckPtraceScope().(*PtraceScopeError); ok {
+		finding := PreflightFinding{
+			Check:    "ptrace_scope",
+			Message:  fmt.Sprintf("Yama ptrace scope is %d (%s)", int(scope), scope),
+			Remedy:   scopeErr.Remedy,
+			Blocking: true,
+		}
+		if scope == PtraceScopeAdminOnly && report.CapSysPtrace {
+			finding.Blocking = false
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+
+	switch report.Seccomp {
+	case SeccompStrict:
+		report.Findings = append(report.Findings, PreflightFinding{
+			Check:    "seccomp",
+			Message:  "process is in seccomp strict mode",
+			Remedy:   "run the process without seccomp strict mode",
+			Blocking: true,
+		})
+	case SeccompFilter:
+		report.Findings = append(report.Findings, PreflightFinding{
+			Check:   "seccomp",
+			Message: "a seccomp filter is applied which may block ptrace and related system calls",
+			Remedy:  "set securityContext.seccompProfile.type to Unconfined, or use a profile allowing ptrace, process_vm_readv and process_vm_writev",
+		})
+	}
+
+	if !report.CapSysPtrace {
+		report.Findings = append(report.Findings, PreflightFinding{
+			Check:    "capabilities",
+			Message:  "CAP_SYS_PTRACE is not in the effective capability set",
+			Remedy:   "add SYS_PTRACE to securityContext.capabilities.add",
+			Blocking: scope == PtraceScopeAdminOnly,
+		})
+	}
+
+	report.Fingerprint = TakeFingerprint()
+	report.Findings = append(report.Findings, report.Fingerprint.Findings()...)
+
+	return report, nil
+}
+
+// readProcStatus fills in the details of the report taken from /proc/self/status.
+func readProcStatus(report *PreflightReport) error {
+	file, err := os.Open(procStatusPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "Seccomp:":
+			mode, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return fmt.Errorf("%s: Seccomp: %v", procStatusPath, err)
+			}
+			report.Seccomp = SeccompMode(mode)
+		case "NoNewPrivs:":
+			report.NoNewPrivs = fields[1] == "1"
+		case "CapEff:":
+			caps, err := strconv.ParseUint(fields[1], 16, 64)
+			if err != nil {
+				return fmt.Errorf("%s: CapEff: %v", procStatusPath, err)
+			}
+			report.CapSysPtrace = caps&(1<<capSysPtrace) != 0
+		}
+	}
+	return scanner.Err()
+}
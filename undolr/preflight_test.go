@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func withProcStatus(t *testing.T, contents string, fn func()) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = ioutil.WriteFile(filename, []byte(contents), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	saved := procStatusPath
+	procStatusPath = filename
+	defer func() { procStatusPath = saved }()
+
+	fn()
+}
+
+func TestPreflight(t *testing.T) {
+	report, err := Preflight()
+	if err != nil {
+		t.Fatal("Preflight:", err)
+	}
+	if testing.Verbose() {
+		for _, finding := range report.Findings {
+			t.Log(finding)
+		}
+	}
+}
+
+func TestPreflightContainer(t *testing.T) {
+	status := "Name:\ttest\nNoNewPrivs:\t1\nSeccomp:\t2\nCapEff:\t00000000a80425fb\n"
+
+	withPtraceScope(t, "1\n", func() {
+		withProcStatus(t, status, func() {
+			report, err := Preflight()
+			if err != nil {
+				t.Fatal("Preflight:", err)
+			}
+
+			if report.Seccomp != SeccompFilter {
+				t.Errorf("Seccomp doesn't match (%v vs %v)", report.Seccomp, SeccompFilter)
+			}
+			if !report.NoNewPrivs {
+				t.Error("Expected NoNewPrivs")
+			}
+			if report.CapSysPtrace {
+				t.Error("Unexpected CAP_SYS_PTRACE")
+			}
+
+			checks := make(map[string]bool)
+			for _, finding := range report.Findings {
+				checks[finding.Check] = true
+			}
+			if !checks["seccomp"] || !checks["capabilities"] {
+				t.Error("Expected seccomp and capabilities findings, got", report.Findings)
+			}
+		})
+	})
+}
+
+func TestPreflightAdminOnly(t *testing.T) {
+	withPtraceScope(t, "2\n", func() {
+		withProcStatus(t, "Seccomp:\t0\nCapEff:\t0000000000000000\n", func() {
+			report, err := Preflight()
+			if err != nil {
+				t.Fatal("Preflight:", err)
+			}
+			if report.OK() {
+				t.Error("Expected admin-only scope without CAP_SYS_PTRACE to block recording")
+			}
+		})
+
+		withProcStatus(t, "Seccomp:\t0\nCapEff:\t0000000000080000\n", func() {
+			report, err := Preflight()
+			if err != nil {
+				t.Fatal("Preflight:", err)
+			}
+			if !report.CapSysPtrace {
+				t.Error("Expected CAP_SYS_PTRACE")
+			}
+			if !report.OK() {
+				t.Error("Unexpected blocking finding with CAP_SYS_PTRACE:", report.Findings)
+			}
+		})
+	})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"runtime/pprof"
+	"time"
+)
+
+// Suffixes appended to a recording file name to name the profiles written by CaptureProfiles.
+const (
+	CPUProfileSuffix       = ".cpu.pprof"
+	HeapProfileSuffix      = ".heap.pprof"
+	GoroutineProfileSuffix = ".goroutine.pprof"
+)
+
+// ProfileOptions selects the profiles captured alongside a recording.
+type ProfileOptions struct {
+	// CPU is how long to profile CPU usage for. Zero means no CPU profile.
+	CPU time.Duration
+
+	// Heap captures a heap profile. It includes allocations since the
+	// program started, so covers the history in the recording.
+	Heap bool
+
+	// Goroutine captures the stacks of all goroutines.
+	Goroutine bool
+}
+
+// enabled reports whether any profile is selected.
+func (opts ProfileOptions) enabled() bool {
+	return opts.CPU > 0 || opts.Heap || opts.Goroutine
+}
+
+// CaptureProfiles writes the selected profiles alongside a recording, for viewing with "go tool pprof".
+//
+// Each profile is named by appending its suffix, such as HeapProfileSuffix,
+// to recording. Heap and goroutine profiles are written immediately. Go
+// cannot profile CPU usage retrospectively, so the CPU profile covers the
+// opts.CPU following the call, which returns once it is complete; it
+// fails if CPU profiling is already in progress, for instance through
+// net/http/pprof. The names of the files written are returned, even if
+// another profile failed.
+func CaptureProfiles(recording string, opts ProfileOptions) ([]string, error) {
+	var written []string
+	var firstErr error
+	record := func(filename string, err error) {
+		if err == nil {
+			written = append(written, filename)
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if opts.Heap {
+		filename := recording + HeapProfileSuffix
+		record(filename, writeProfile(filename, "heap"))
+	}
+	if opts.Goroutine {
+		filename := recording + GoroutineProfileSuffix
+		record(filename, writeProfile(filename, "goroutine"))
+	}
+	if opts.CPU > 0 {
+		filename := recording + CPUProfileSuffix
+		record(filename, writeCPUProfile(filename, opts.CPU))
+	}
+	return written, firstErr
+}
+
+func writeProfile(filename, name string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = pprof.Lookup(name).WriteTo(f, 0)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filename)
+	}
+	return err
+}
+
+func writeCPUProfile(filename string, duration time.Duration) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = pprof.StartCPUProfile(f)
+	if err == nil {
+		<-currentClock().After(duration)
+		pprof.StopCPUProfile()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filename)
+	}
+	return err
+}
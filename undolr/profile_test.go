@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"runtime/pprof"
+	"testing"
+	"time"
+)
+
+func TestCaptureProfiles(t *testing.T) {
+	recording, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+
+	written, err := CaptureProfiles(recording, ProfileOptions{})
+	if err != nil || len(written) != 0 {
+		t.Fatal("Unexpected profiles with none selected:", written, err)
+	}
+
+	written, err = CaptureProfiles(recording, ProfileOptions{CPU: 10 * time.Millisecond, Heap: true, Goroutine: true})
+	for _, filename := range written {
+		defer os.Remove(filename)
+	}
+	if err != nil {
+		t.Fatal("CaptureProfiles:", err)
+	}
+
+	want := []string{recording + HeapProfileSuffix, recording + GoroutineProfileSuffix, recording + CPUProfileSuffix}
+	if len(written) != len(want) {
+		t.Fatalf("Unexpected profiles written: %v", written)
+	}
+	for i, filename := range want {
+		if written[i] != filename {
+			t.Errorf("Profile %d doesn't match (%s vs %s)", i, written[i], filename)
+		}
+		size, err := fileSize(filename)
+		if err != nil || size == 0 {
+			t.Errorf("Profile %s not written: %v", filename, err)
+		}
+	}
+}
+
+func TestCaptureProfilesCPUBusy(t *testing.T) {
+	recording, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+
+	f, err := os.Create(recording + ".busy")
+	if err != nil {
+		t.Fatal("Create:", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	err = pprof.StartCPUProfile(f)
+	if err != nil {
+		t.Fatal("StartCPUProfile:", err)
+	}
+	defer pprof.StopCPUProfile()
+
+	written, err := CaptureProfiles(recording, ProfileOptions{CPU: time.Millisecond, Heap: true})
+	for _, filename := range written {
+		defer os.Remove(filename)
+	}
+	if err == nil {
+		t.Fatal("Expected CPU profile to fail while profiling")
+	}
+	if len(written) != 1 || written[0] != recording+HeapProfileSuffix {
+		t.Fatal("Expected heap profile despite CPU failure, got", written)
+	}
+	if _, err := os.Stat(recording + CPUProfileSuffix); !os.IsNotExist(err) {
+		t.Fatal("Failed CPU profile not removed:", err)
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package progress
+
+import (
+	"os"
+	"time"
+
+	"go.undo.io/bindings/undolr"
+)
+
+func ExampleBar() {
+	err := undolr.Start()
+	if err != nil {
+		panic(err)
+	}
+
+	recContext, err := undolr.Stop()
+	if err != nil {
+		panic(err)
+	}
+	defer recContext.Discard()
+
+	ch, err := recContext.SaveProgress("recording.undolr", 20*time.Millisecond)
+	if err != nil {
+		panic(err)
+	}
+
+	err = Bar(os.Stdout, "recording.undolr", ch)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package progress
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.undo.io/bindings/undoex"
+	"go.undo.io/bindings/undolr"
+)
+
+// HeartbeatAnnotationName is the name of the annotations added by Heartbeat.
+const HeartbeatAnnotationName = "undolr-save"
+
+// Details of the annotations added by Heartbeat.
+const (
+	HeartbeatInProgressDetail = "in-progress"
+	HeartbeatCompleteDetail   = "complete"
+	HeartbeatFailedDetail     = "failed"
+)
+
+// HeartbeatOptions selects what Heartbeat reports while a save is in progress.
+type HeartbeatOptions struct {
+	// Interval is the minimum time between reports of progress. The
+	// outcome is always reported.
+	Interval time.Duration
+
+	// Annotate adds annotations to the current recording, so analysis of
+	// a recording made while a previous one was saving shows when, and
+	// for how long, the save was running.
+	Annotate bool
+
+	// Logf, if set, is passed log lines in the format described for Log.
+	Logf func(format string, v ...interface{})
+}
+
+// A HeartbeatAnnotation is the JSON content of the annotations added by Heartbeat.
+type HeartbeatAnnotation struct {
+	File string `json:"file"`
+
+	// Progress is the percentage of the save completed, or
+	// undolr.ProgressUnknown.
+	Progress int `json:"progress"`
+
+	ElapsedMillis int64  `json:"elapsed_ms"`
+	Error         string `json:"error,omitempty"`
+}
+
+// annotate adds the annotations for Heartbeat. Tests replace it, as
+// annotations cannot be added without the annotation library.
+var annotate = undoex.AnnotationAddText
+
+// Heartbeat reports the progress of the save reporting on ch until it completes.
+//
+// Each report is an annotation named HeartbeatAnnotationName, with detail
+// HeartbeatInProgressDetail, HeartbeatCompleteDetail or
+// HeartbeatFailedDetail and a HeartbeatAnnotation as JSON content, and a
+// log line, as selected by opts. Failures to annotate are ignored, as
+// they are expected when the process is not being recorded. It returns
+// the error from the final status, if any.
+func Heartbeat(filename string, opts HeartbeatOptions, ch <-chan undolr.SaveStatus) error {
+	start := time.Now()
+	var last time.Time
+	var final undolr.SaveStatus
+	for status := range ch {
+		final = status
+		if status.Complete || status.Err != nil {
+			break
+		}
+		if !last.IsZero() && time.Since(last) < opts.Interval {
+			continue
+		}
+		last = time.Now()
+		if opts.Annotate {
+			heartbeatAnnotate(HeartbeatInProgressDetail, HeartbeatAnnotation{
+				File:          filename,
+				Progress:      status.Progress,
+				ElapsedMillis: time.Since(start).Milliseconds(),
+			})
+		}
+		if opts.Logf == nil {
+			continue
+		}
+		if status.Progress == undolr.ProgressUnknown {
+			opts.Logf("event=undolr_save file=%q progress=unknown", filename)
+		} else {
+			opts.Logf("event=undolr_save file=%q progress=%d", filename, status.Progress)
+		}
+	}
+
+	elapsed := time.Since(start)
+	duration := elapsed.Round(time.Millisecond)
+	switch {
+	case final.Err != nil:
+		if opts.Annotate {
+			heartbeatAnnotate(HeartbeatFailedDetail, HeartbeatAnnotation{
+				File:          filename,
+				Progress:      undolr.ProgressUnknown,
+				ElapsedMillis: elapsed.Milliseconds(),
+				Error:         final.Err.Error(),
+			})
+		}
+		if opts.Logf != nil {
+			opts.Logf("event=undolr_save file=%q complete=%t error=%q duration=%v",
+				filename, final.Complete, final.Err.Error(), duration)
+		}
+	case final.Complete:
+		if opts.Annotate {
+			heartbeatAnnotate(HeartbeatCompleteDetail, HeartbeatAnnotation{
+				File:          filename,
+				Progress:      100,
+				ElapsedMillis: elapsed.Milliseconds(),
+			})
+		}
+		if opts.Logf != nil {
+			opts.Logf("event=undolr_save file=%q complete=true duration=%v", filename, duration)
+		}
+	default:
+		return undolr.ErrRecordingContextSaveIncomplete
+	}
+	return final.Err
+}
+
+func heartbeatAnnotate(detail string, annotation HeartbeatAnnotation) {
+	data, err := json.Marshal(annotation)
+	if err != nil {
+		return
+	}
+	annotate(HeartbeatAnnotationName, detail, undoex.JSON, string(data))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"go.undo.io/bindings/undoex"
+	"go.undo.io/bindings/undolr"
+)
+
+type heartbeatRecord struct {
+	detail     string
+	annotation HeartbeatAnnotation
+}
+
+// recordAnnotations replaces annotate, returning the annotations added.
+func recordAnnotations(t *testing.T) *[]heartbeatRecord {
+	var records []heartbeatRecord
+	annotate = func(name, detail string, contentType undoex.AnnotationContentType, text string) error {
+		if name != HeartbeatAnnotationName || contentType != undoex.JSON {
+			t.Errorf("Unexpected annotation %q (%v)", name, contentType)
+		}
+		var annotation HeartbeatAnnotation
+		if err := json.Unmarshal([]byte(text), &annotation); err != nil {
+			t.Errorf("Invalid annotation %q: %v", text, err)
+		}
+		records = append(records, heartbeatRecord{detail, annotation})
+		return nil
+	}
+	return &records
+}
+
+func TestHeartbeat(t *testing.T) {
+	records := recordAnnotations(t)
+	defer func() { annotate = undoex.AnnotationAddText }()
+
+	var lines []string
+	logf := func(format string, v ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, v...))
+	}
+
+	err := Heartbeat("test.undo", HeartbeatOptions{Annotate: true, Logf: logf}, statuses(
+		undolr.SaveStatus{Progress: undolr.ProgressUnknown},
+		undolr.SaveStatus{Progress: 42},
+		undolr.SaveStatus{Complete: true},
+	))
+	if err != nil {
+		t.Fatal("Heartbeat:", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Unexpected log lines: %q", lines)
+	}
+
+	expected := []heartbeatRecord{
+		{HeartbeatInProgressDetail, HeartbeatAnnotation{File: "test.undo", Progress: undolr.ProgressUnknown}},
+		{HeartbeatInProgressDetail, HeartbeatAnnotation{File: "test.undo", Progress: 42}},
+		{HeartbeatCompleteDetail, HeartbeatAnnotation{File: "test.undo", Progress: 100}},
+	}
+	if len(*records) != len(expected) {
+		t.Fatalf("Unexpected annotations: %+v", *records)
+	}
+	for i, record := range *records {
+		record.annotation.ElapsedMillis = 0
+		if record != expected[i] {
+			t.Errorf("Annotation %d doesn't match (%+v vs %+v)", i, record, expected[i])
+		}
+	}
+}
+
+func TestHeartbeatFailed(t *testing.T) {
+	records := recordAnnotations(t)
+	defer func() { annotate = undoex.AnnotationAddText }()
+
+	err := Heartbeat("test.undo", HeartbeatOptions{Interval: 1 << 62, Annotate: true}, statuses(
+		undolr.SaveStatus{Progress: 1},
+		undolr.SaveStatus{Progress: 2},
+		undolr.SaveStatus{Complete: true, Err: syscall.EIO},
+	))
+	if err != syscall.EIO {
+		t.Fatal("Expected error from final status:", err)
+	}
+	if len(*records) != 2 || (*records)[0].annotation.Progress != 1 {
+		t.Fatalf("Unexpected annotations: %+v", *records)
+	}
+	failed := (*records)[1]
+	if failed.detail != HeartbeatFailedDetail || failed.annotation.Error != syscall.EIO.Error() {
+		t.Fatalf("Unexpected failure annotation: %+v", failed)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package progress renders the progress of asynchronous saves.
+//
+// The functions consume the status channel returned by
+// undolr.RecordingContext.SaveProgress, either drawing a progress bar for
+// command line tools, or writing periodic log lines and annotations for
+// services:
+//
+//	ch, err := context.SaveProgress("recording.undo", 100*time.Millisecond)
+//	if err != nil {
+//		return err
+//	}
+//	err = progress.Bar(os.Stderr, "recording.undo", ch)
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// barWidth is the number of characters in the bar drawn by Bar.
+const barWidth = 40
+
+// Bar draws a progress bar to w until the save reporting on ch completes.
+//
+// The bar is redrawn in place using carriage returns, so w should be a
+// terminal. It returns the error from the final status, if any.
+func Bar(w io.Writer, label string, ch <-chan undolr.SaveStatus) error {
+	var final undolr.SaveStatus
+	for status := range ch {
+		final = status
+		if status.Complete || status.Err != nil {
+			break
+		}
+		fmt.Fprintf(w, "\r%s %s", bar(status.Progress), label)
+	}
+
+	switch {
+	case final.Err != nil:
+		fmt.Fprintf(w, "\r%s %s: %v\n", bar(-1), label, final.Err)
+	case final.Complete:
+		fmt.Fprintf(w, "\r%s %s\n", bar(100), label)
+	default:
+		return undolr.ErrRecordingContextSaveIncomplete
+	}
+	return final.Err
+}
+
+// bar returns a bar showing progress percent complete.
+func bar(percent int) string {
+	if percent == undolr.ProgressUnknown || percent < 0 {
+		return "[" + strings.Repeat("?", barWidth) + "]  ?? %"
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	filled := percent * barWidth / 100
+	return fmt.Sprintf("[%s%s] %3d %%",
+		strings.Repeat("#", filled), strings.Repeat(" ", barWidth-filled), percent)
+}
+
+// Log writes structured log lines with logf until the save reporting on ch completes.
+//
+// Progress is logged at most once per interval, and the outcome always,
+// as space separated key=value pairs, for instance:
+//
+//	event=undolr_save file="recording.undo" progress=42
+//	event=undolr_save file="recording.undo" complete=true duration=1.5s
+//
+// logf is typically log.Printf, or the Printf method of a *log.Logger. It
+// returns the error from the final status, if any.
+func Log(logf func(format string, v ...interface{}), filename string, interval time.Duration, ch <-chan undolr.SaveStatus) error {
+	return Heartbeat(filename, HeartbeatOptions{Interval: interval, Logf: logf}, ch)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// statuses returns a closed channel holding the given statuses.
+func statuses(s ...undolr.SaveStatus) <-chan undolr.SaveStatus {
+	ch := make(chan undolr.SaveStatus, len(s))
+	for _, status := range s {
+		ch <- status
+	}
+	close(ch)
+	return ch
+}
+
+func TestBar(t *testing.T) {
+	var buf bytes.Buffer
+	err := Bar(&buf, "test.undo", statuses(
+		undolr.SaveStatus{Progress: undolr.ProgressUnknown},
+		undolr.SaveStatus{Progress: 50},
+		undolr.SaveStatus{Complete: true},
+	))
+	if err != nil {
+		t.Fatal("Bar:", err)
+	}
+
+	lines := strings.Split(buf.String(), "\r")
+	expected := []string{
+		"",
+		"[" + strings.Repeat("?", barWidth) + "]  ?? % test.undo",
+		"[" + strings.Repeat("#", barWidth/2) + strings.Repeat(" ", barWidth/2) + "]  50 % test.undo",
+		"[" + strings.Repeat("#", barWidth) + "] 100 % test.undo\n",
+	}
+	if fmt.Sprintf("%q", lines) != fmt.Sprintf("%q", expected) {
+		t.Fatalf("Unexpected output:\n %q\n vs\n %q", lines, expected)
+	}
+}
+
+func TestBarError(t *testing.T) {
+	var buf bytes.Buffer
+	err := Bar(&buf, "test.undo", statuses(
+		undolr.SaveStatus{Complete: true, Result: undolr.SaveResultDiskFull, Err: syscall.ENOSPC},
+	))
+	if err != syscall.ENOSPC {
+		t.Fatal("Expected error from final status:", err)
+	}
+	if !strings.HasSuffix(buf.String(), "test.undo: "+syscall.ENOSPC.Error()+"\n") {
+		t.Fatalf("Unexpected output: %q", buf.String())
+	}
+
+	err = Bar(&buf, "test.undo", statuses(undolr.SaveStatus{Progress: 10}))
+	if err != undolr.ErrRecordingContextSaveIncomplete {
+		t.Fatal("Expected incomplete save to fail:", err)
+	}
+}
+
+func TestLog(t *testing.T) {
+	var lines []string
+	logf := func(format string, v ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, v...))
+	}
+
+	err := Log(logf, "test.undo", 0, statuses(
+		undolr.SaveStatus{Progress: undolr.ProgressUnknown},
+		undolr.SaveStatus{Progress: 42},
+		undolr.SaveStatus{Complete: true},
+	))
+	if err != nil {
+		t.Fatal("Log:", err)
+	}
+	if len(lines) != 3 ||
+		lines[0] != `event=undolr_save file="test.undo" progress=unknown` ||
+		lines[1] != `event=undolr_save file="test.undo" progress=42` ||
+		!strings.HasPrefix(lines[2], `event=undolr_save file="test.undo" complete=true duration=`) {
+		t.Fatalf("Unexpected log lines: %q", lines)
+	}
+
+	lines = nil
+	err = Log(logf, "test.undo", 1<<62, statuses(
+		undolr.SaveStatus{Progress: 1},
+		undolr.SaveStatus{Progress: 2},
+		undolr.SaveStatus{Complete: true, Err: syscall.EIO},
+	))
+	if err != syscall.EIO {
+		t.Fatal("Expected error from final status:", err)
+	}
+	if len(lines) != 2 || !strings.Contains(lines[1], `error="input/output error"`) {
+		t.Fatalf("Unexpected log lines: %q", lines)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A PtraceScope is a setting of /proc/sys/kernel/yama/ptrace_scope.
+//
+// Live Recorder attaches to the process using ptrace, so restrictive
+// settings are the most common reason for Start failing, particularly
+// inside containers.
+type PtraceScope int
+
+// Values for PtraceScope
+const (
+	// PtraceScopeNone means the Yama security module is not active.
+	PtraceScopeNone PtraceScope = -1
+
+	// PtraceScopeClassic allows any process to be traced by another
+	// process running as the same user.
+	PtraceScopeClassic PtraceScope = 0
+
+	// PtraceScopeRestricted only allows a process to be traced by its
+	// ancestors, or by a tracer it declared using prctl(PR_SET_PTRACER).
+	PtraceScopeRestricted PtraceScope = 1
+
+	// PtraceScopeAdminOnly only allows processes with CAP_SYS_PTRACE to
+	// trace.
+	PtraceScopeAdminOnly PtraceScope = 2
+
+	// PtraceScopeNoAttach disallows tracing entirely. It cannot be lowered
+	// without rebooting.
+	PtraceScopeNoAttach PtraceScope = 3
+)
+
+func (s PtraceScope) String() string {
+	switch s {
+	case PtraceScopeNone:
+		return "none"
+	case PtraceScopeClassic:
+		return "classic"
+	case PtraceScopeRestricted:
+		return "restricted"
+	case PtraceScopeAdminOnly:
+		return "admin-only"
+	case PtraceScopeNoAttach:
+		return "no-attach"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrPtraceScope indicates the Yama ptrace scope will prevent Live Recorder attaching.
+//
+// Errors returned by CheckPtraceScope and EnablePtraceAttach are of type
+// *PtraceScopeError and match this value with errors.Is.
+var ErrPtraceScope = errors.New("ptrace_scope prevents recording")
+
+// A PtraceScopeError reports a ptrace scope which prevents recording and how to remedy it.
+type PtraceScopeError struct {
+	Scope  PtraceScope
+	Remedy string
+}
+
+func (e *PtraceScopeError) Error() string {
+	return fmt.Sprintf("%v (%d, %s): %s", ErrPtraceScope, int(e.Scope), e.Scope, e.Remedy)
+}
+
+// Is reports whether target is ErrPtraceScope.
+func (e *PtraceScopeError) Is(target error) bool {
+	return target == ErrPtraceScope
+}
+
+// ptraceScopePath is a variable so tests can substitute their own file.
+var ptraceScopePath = "/proc/sys/kernel/yama/ptrace_scope"
+
+// Constants for prctl(PR_SET_PTRACER) from <linux/prctl.h>.
+const (
+	prSetPtracer    = 0x59616d61
+	prSetPtracerAny = ^uintptr(0)
+)
+
+// ptracerAny is set once PR_SET_PTRACER_ANY has been applied.
+var ptracerAny bool
+
+// ReadPtraceScope returns the current Yama ptrace scope.
+//
+// PtraceScopeNone is returned if Yama is not active.
+func ReadPtraceScope() (PtraceScope, error) {
+	data, err := ioutil.ReadFile(ptraceScopePath)
+	if os.IsNotExist(err) {
+		return PtraceScopeNone, nil
+	} else if err != nil {
+		return PtraceScopeNone, err
+	}
+
+	value, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return PtraceScopeNone, fmt.Errorf("%s: %v", ptraceScopePath, err)
+	}
+	return PtraceScope(value), nil
+}
+
+// CheckPtraceScope checks, without changing anything, whether the ptrace scope allows recording.
+//
+// If it does not, a *PtraceScopeError describing the remedy is returned.
+func CheckPtraceScope() error {
+	scope, err := ReadPtraceScope()
+	if err != nil {
+		return err
+	}
+
+	switch scope {
+	case PtraceScopeNone, PtraceScopeClassic:
+		return nil
+	case PtraceScopeRestricted:
+		if ptracerAny {
+			return nil
+		}
+		return &PtraceScopeError{scope,
+			"call EnablePtraceAttach before Start, or set " + ptraceScopePath + " to 0"}
+	case PtraceScopeAdminOnly:
+		return &PtraceScopeError{scope,
+			"set " + ptraceScopePath + " to 1 (or call EnablePtraceAttach(true) with privileges)"}
+	default:
+		return &PtraceScopeError{scope,
+			"attaching is disabled until reboot; boot with a lower " + ptraceScopePath}
+	}
+}
+
+// EnablePtraceAttach adjusts the process and, optionally, the system so Live Recorder can attach.
+//
+// With PtraceScopeRestricted this declares that any process may trace this
+// one using prctl(PR_SET_PTRACER), which needs no privileges and only
+// affects the calling process.
+//
+// With PtraceScopeAdminOnly and allowSysctl set, the system-wide setting is
+// first lowered to PtraceScopeRestricted. This affects every process on the
+// host and requires CAP_SYS_ADMIN; it should only be used where that is
+// acceptable, such as in dedicated test environments.
+//
+// This must be called before Start. Any remaining problem is returned as a
+// *PtraceScopeError.
+func EnablePtraceAttach(allowSysctl bool) error {
+	scope, err := ReadPtraceScope()
+	if err != nil {
+		return err
+	}
+
+	if scope == PtraceScopeAdminOnly && allowSysctl {
+		err = ioutil.WriteFile(ptraceScopePath, []byte("1\n"), 0644)
+		if err != nil {
+			return err
+		}
+		scope = PtraceScopeRestricted
+	}
+
+	if scope == PtraceScopeRestricted && !ptracerAny {
+		err = setPtracerAny()
+		if err != nil {
+			return err
+		}
+		ptracerAny = true
+	}
+
+	return CheckPtraceScope()
+}
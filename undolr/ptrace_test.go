@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func withPtraceScope(t *testing.T, contents string, fn func()) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = ioutil.WriteFile(filename, []byte(contents), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	saved := ptraceScopePath
+	ptraceScopePath = filename
+	defer func() { ptraceScopePath = saved }()
+
+	fn()
+}
+
+func TestReadPtraceScope(t *testing.T) {
+	for contents, expected := range map[string]PtraceScope{
+		"0\n": PtraceScopeClassic,
+		"1\n": PtraceScopeRestricted,
+		"2\n": PtraceScopeAdminOnly,
+		"3\n": PtraceScopeNoAttach,
+	} {
+		withPtraceScope(t, contents, func() {
+			scope, err := ReadPtraceScope()
+			if err != nil {
+				t.Fatal("ReadPtraceScope:", err)
+			} else if scope != expected {
+				t.Errorf("Scope doesn't match (%v vs %v)", scope, expected)
+			}
+		})
+	}
+
+	withPtraceScope(t, "junk", func() {
+		_, err := ReadPtraceScope()
+		if err == nil {
+			t.Error("Unexpected success with invalid ptrace_scope")
+		}
+	})
+
+	saved := ptraceScopePath
+	ptraceScopePath = "/nonexistent/ptrace_scope"
+	defer func() { ptraceScopePath = saved }()
+
+	scope, err := ReadPtraceScope()
+	if err != nil {
+		t.Fatal("ReadPtraceScope:", err)
+	} else if scope != PtraceScopeNone {
+		t.Errorf("Expected PtraceScopeNone without Yama, got %v", scope)
+	}
+}
+
+func TestCheckPtraceScope(t *testing.T) {
+	withPtraceScope(t, "0\n", func() {
+		err := CheckPtraceScope()
+		if err != nil {
+			t.Error("CheckPtraceScope:", err)
+		}
+	})
+
+	for _, contents := range []string{"2\n", "3\n"} {
+		withPtraceScope(t, contents, func() {
+			err := CheckPtraceScope()
+			if !errors.Is(err, ErrPtraceScope) {
+				t.Errorf("Expected ErrPtraceScope for %q, got %v", contents, err)
+			}
+		})
+	}
+}
+
+func TestEnablePtraceAttach(t *testing.T) {
+	withPtraceScope(t, "3\n", func() {
+		err := EnablePtraceAttach(true)
+		if !errors.Is(err, ErrPtraceScope) {
+			t.Fatal("Expected ErrPtraceScope with no-attach scope, got", err)
+		}
+	})
+
+	withPtraceScope(t, "1\n", func() {
+		err := EnablePtraceAttach(false)
+		if err == syscall.EINVAL {
+			// The kernel rejects PR_SET_PTRACER when Yama isn't active.
+			t.Skip("Yama not active")
+		} else if err != nil {
+			t.Fatal("EnablePtraceAttach:", err)
+		}
+
+		err = CheckPtraceScope()
+		if err != nil {
+			t.Fatal("CheckPtraceScope:", err)
+		}
+	})
+}
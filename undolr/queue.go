@@ -0,0 +1,213 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSnapshotQueueClosed indicates a snapshot was submitted to, or left queued in, a closed SnapshotQueue.
+var ErrSnapshotQueueClosed = errors.New("snapshot queue closed")
+
+// Priorities for common sources of snapshot requests. Any int may be used;
+// higher priorities run first.
+const (
+	PrioritySchedule = 0
+	PriorityTrigger  = 10
+	PriorityOperator = 20
+)
+
+// A SnapshotRequest asks for the recording so far to be saved.
+type SnapshotRequest struct {
+	// Source identifies who made the request, for introspection.
+	Source string `json:"source"`
+
+	// Priority orders requests in the queue. Requests of equal priority
+	// run in the order submitted.
+	Priority int `json:"priority"`
+
+	// Filename is the recording file to save to.
+	Filename string `json:"filename"`
+}
+
+// A QueuedSnapshot describes a request in a SnapshotQueue.
+type QueuedSnapshot struct {
+	SnapshotRequest
+	Queued  time.Time `json:"queued"`
+	Running bool      `json:"running"`
+}
+
+type snapshotEntry struct {
+	QueuedSnapshot
+	seq   uint64
+	index int
+	done  chan struct{}
+	stats SaveStats
+	err   error
+}
+
+type snapshotHeap []*snapshotEntry
+
+func (h snapshotHeap) Len() int { return len(h) }
+func (h snapshotHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h snapshotHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *snapshotHeap) Push(x interface{}) {
+	e := x.(*snapshotEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *snapshotHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	e.index = -1
+	return e
+}
+
+// A SnapshotQueue runs snapshot requests from several sources one at a time, highest priority first.
+//
+// As the process has a single recorder, saves made through a queue never
+// overlap. Each snapshot is saved with SaveWithStats, so recording must be
+// running when it reaches the head of the queue.
+type SnapshotQueue struct {
+	mu      sync.Mutex
+	queue   snapshotHeap
+	running *snapshotEntry
+	seq     uint64
+	closed  bool
+	wake    chan struct{}
+	stopped chan struct{}
+}
+
+// NewSnapshotQueue creates a SnapshotQueue and starts running its requests.
+func NewSnapshotQueue() *SnapshotQueue {
+	q := &SnapshotQueue{
+		wake:    make(chan struct{}, 1),
+		stopped: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Submit queues a snapshot and waits for it to be saved.
+//
+// If ctx is done while the request is still queued it is removed from the
+// queue and ctx.Err() is returned. A save already running cannot be
+// interrupted, so Submit then waits for it to finish.
+func (q *SnapshotQueue) Submit(ctx context.Context, req SnapshotRequest) (SaveStats, error) {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return SaveStats{}, ErrSnapshotQueueClosed
+	}
+	q.seq++
+	e := &snapshotEntry{
+		QueuedSnapshot: QueuedSnapshot{SnapshotRequest: req, Queued: now()},
+		seq:            q.seq,
+		done:           make(chan struct{}),
+	}
+	heap.Push(&q.queue, e)
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return e.stats, e.err
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	if e.index >= 0 {
+		heap.Remove(&q.queue, e.index)
+		q.mu.Unlock()
+		return SaveStats{}, ctx.Err()
+	}
+	q.mu.Unlock()
+
+	<-e.done
+	return e.stats, e.err
+}
+
+// Pending returns the running request, if any, followed by those queued in the order they will run.
+func (q *SnapshotQueue) Pending() []QueuedSnapshot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var pending []QueuedSnapshot
+	if q.running != nil {
+		pending = append(pending, q.running.QueuedSnapshot)
+	}
+	queued := append(snapshotHeap(nil), q.queue...)
+	sort.Slice(queued, queued.Less)
+	for _, e := range queued {
+		pending = append(pending, e.QueuedSnapshot)
+	}
+	return pending
+}
+
+// Close stops the queue, failing queued requests with ErrSnapshotQueueClosed.
+//
+// Close waits for any running save to finish.
+func (q *SnapshotQueue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	for q.queue.Len() > 0 {
+		e := heap.Pop(&q.queue).(*snapshotEntry)
+		e.err = ErrSnapshotQueueClosed
+		close(e.done)
+	}
+	close(q.wake)
+	q.mu.Unlock()
+
+	<-q.stopped
+}
+
+func (q *SnapshotQueue) run() {
+	defer close(q.stopped)
+	for range q.wake {
+		for {
+			q.mu.Lock()
+			if q.queue.Len() == 0 {
+				q.mu.Unlock()
+				break
+			}
+			e := heap.Pop(&q.queue).(*snapshotEntry)
+			e.Running = true
+			q.running = e
+			q.mu.Unlock()
+
+			e.stats, e.err = SaveWithStats(e.Filename)
+
+			q.mu.Lock()
+			q.running = nil
+			q.mu.Unlock()
+			close(e.done)
+		}
+	}
+}
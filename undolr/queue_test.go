@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// waitQueued waits for n requests to be queued in q.
+func waitQueued(q *SnapshotQueue, n int) {
+	for {
+		q.mu.Lock()
+		queued := q.queue.Len()
+		q.mu.Unlock()
+		if queued >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSnapshotQueueOrder(t *testing.T) {
+	q := &SnapshotQueue{wake: make(chan struct{}, 1), stopped: make(chan struct{})}
+
+	// Queue requests without a worker running, so they stay queued.
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan error, 4)
+	for _, req := range []SnapshotRequest{
+		{Source: "schedule", Priority: PrioritySchedule, Filename: "a"},
+		{Source: "operator", Priority: PriorityOperator, Filename: "b"},
+		{Source: "trigger", Priority: PriorityTrigger, Filename: "c"},
+		{Source: "schedule", Priority: PrioritySchedule, Filename: "d"},
+	} {
+		req := req
+		c := context.Background()
+		if req.Filename == "c" {
+			c = ctx
+		}
+		go func() {
+			_, err := q.Submit(c, req)
+			results <- err
+		}()
+		waitQueued(q, int(req.Filename[0]-'a')+1)
+	}
+
+	pending := q.Pending()
+	var order string
+	for _, p := range pending {
+		order += p.Filename
+	}
+	if order != "bcad" {
+		t.Fatalf("Unexpected order %q: %+v", order, pending)
+	}
+
+	cancel()
+	if err := <-results; err != context.Canceled {
+		t.Fatal("Expected cancelled request to fail:", err)
+	}
+	order = ""
+	for _, p := range q.Pending() {
+		order += p.Filename
+	}
+	if order != "bad" {
+		t.Fatalf("Cancelled request not removed: %q", order)
+	}
+
+	go q.run()
+	q.Close()
+	for i := 0; i < 3; i++ {
+		if err := <-results; err != ErrSnapshotQueueClosed {
+			t.Fatal("Expected queued request to fail on Close:", err)
+		}
+	}
+
+	_, err := q.Submit(context.Background(), SnapshotRequest{Filename: "e"})
+	if err != ErrSnapshotQueueClosed {
+		t.Fatal("Expected Submit after Close to fail:", err)
+	}
+}
+
+func TestSnapshotQueue(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer StopAndDiscard()
+
+	q := NewSnapshotQueue()
+	defer q.Close()
+
+	stats, err := q.Submit(context.Background(), SnapshotRequest{
+		Source:   "test",
+		Priority: PriorityOperator,
+		Filename: filename,
+	})
+	if err != nil {
+		t.Fatal("Submit:", err)
+	}
+	if stats.Filename != filename {
+		t.Fatal("Unexpected filename:", stats.Filename)
+	}
+	verifyRecording(t, filename)
+}
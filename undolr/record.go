@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+// Record records fn running, saving the recording to filename.
+//
+// Recording is started, fn is called, and recording is then stopped and
+// the recording saved and discarded, whether fn returns an error,
+// panics or exits its goroutine with runtime.Goexit, as t.FailNow does.
+// A panic is continued once the recording is saved. fn is not called if
+// recording cannot be started.
+//
+// The error from fn is returned if there is one, otherwise any error
+// from stopping, saving or discarding the recording:
+//
+//	err := undolr.Record("flaky.undo", func() error {
+//		return runFlakyOperation()
+//	})
+//
+// Recording covers the whole process while fn runs, not just fn, and the
+// process must not already be recorded.
+func Record(filename string, fn func() error) (err error) {
+	err = checkFilename(filename)
+	if err != nil {
+		return err
+	}
+
+	err = Start()
+	if err != nil {
+		return err
+	}
+
+	returned := false
+	defer func() {
+		if returned {
+			return
+		}
+		p := recover()
+		finishRecord(filename)
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	err = fn()
+	returned = true
+
+	finishErr := finishRecord(filename)
+	if err == nil {
+		err = finishErr
+	}
+	return err
+}
+
+// finishRecord stops recording and saves the recording to filename,
+// discarding it afterwards whether or not it was saved.
+func finishRecord(filename string) error {
+	context, err := Stop()
+	if err != nil {
+		return err
+	}
+	err = context.Save(filename)
+	discardErr := context.Discard()
+	if err == nil {
+		err = discardErr
+	}
+	return err
+}
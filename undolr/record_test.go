@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestRecordInvalidFilename(t *testing.T) {
+	called := false
+	err := Record("", func() error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrFilenameInvalid) || called {
+		t.Fatal("Expected Record() to fail with ErrFilenameInvalid without calling fn:", err, called)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(filename)
+
+	fnErr := errors.New("fn failed")
+	err = Record(filename, func() error {
+		return fnErr
+	})
+	if err != fnErr {
+		t.Fatal("Expected Record() to return the error from fn:", err)
+	}
+	verifyRecording(t, filename)
+
+	if CurrentMode() == ModeRecording {
+		t.Fatal("Expected recording to be stopped")
+	}
+}
+
+func TestRecordPanic(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(filename)
+
+	var p interface{}
+	func() {
+		defer func() {
+			p = recover()
+		}()
+		err = Record(filename, func() error {
+			panic("fn panicked")
+		})
+	}()
+	if err != nil {
+		t.Fatal("Record:", err)
+	}
+	if p != "fn panicked" {
+		t.Fatal("Expected the panic to continue:", p)
+	}
+	verifyRecording(t, filename)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+// A Recorder starts, stops and saves recordings of the process.
+//
+// Code which takes a Recorder, rather than calling the package functions
+// directly, can be tested without the UndoLR library or ptrace
+// permission by passing a fake, such as undolrtest.FakeRecorder.
+// LiveRecorder uses Live Recorder itself.
+type Recorder interface {
+	// Start starts recording the process, as Start.
+	Start() error
+
+	// Save saves the history recorded so far while still recording, as Save.
+	Save(filename string) error
+
+	// Stop stops recording, keeping the history to be saved, as Stop.
+	Stop() (Recording, error)
+
+	// StopAndDiscard stops recording and discards the history, as StopAndDiscard.
+	StopAndDiscard() error
+}
+
+// A Recording is the history kept when a Recorder is stopped.
+//
+// It must eventually be discarded, whether or not it is saved.
+type Recording interface {
+	// Save saves the recording to a file, returning once complete.
+	Save(filename string) error
+
+	// Discard frees the recording, which may no longer be saved.
+	Discard() error
+}
+
+// LiveRecorder is a Recorder using Live Recorder, by calling the package functions.
+//
+// Its Recordings are *RecordingContext.
+type LiveRecorder struct{}
+
+// Start calls Start.
+func (LiveRecorder) Start() error {
+	return Start()
+}
+
+// Save calls Save.
+func (LiveRecorder) Save(filename string) error {
+	return Save(filename)
+}
+
+// Stop calls Stop, returning the *RecordingContext.
+func (LiveRecorder) Stop() (Recording, error) {
+	context, err := Stop()
+	if err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+// StopAndDiscard calls StopAndDiscard.
+func (LiveRecorder) StopAndDiscard() error {
+	return StopAndDiscard()
+}
+
+// Save saves a stopped recording to a named recording file, returning once complete.
+//
+// This is SaveNotify, waiting for the result. It lets *RecordingContext
+// serve as a Recording.
+func (context *RecordingContext) Save(filename string) error {
+	ch, err := context.SaveNotify(filename)
+	if err != nil {
+		return err
+	}
+	return (<-ch).Err
+}
@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"testing"
+)
+
+var _ Recorder = LiveRecorder{}
+var _ Recording = (*RecordingContext)(nil)
+
+func TestLiveRecorder(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(filename)
+
+	var recorder Recorder = LiveRecorder{}
+	err = recorder.Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	recording, err := recorder.Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer recording.Discard()
+
+	err = recording.Save(filename)
+	if err != nil {
+		t.Fatal("Save:", err)
+	}
+	verifyRecording(t, filename)
+}
+
+func TestLiveRecorderStopFailed(t *testing.T) {
+	recording, err := LiveRecorder{}.Stop()
+	if err == nil || recording != nil {
+		t.Fatal("Expected Stop() without Start() to fail with a nil Recording:", recording, err)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+// SavePanic saves the recording to filename if p, a value returned by recover, is not nil.
+//
+// It returns p so that a custom panic handler can re-raise it once the
+// recording is saved:
+//
+//	defer func() {
+//		p, _ := undolr.SavePanic(recover(), "crash.undo")
+//		if p != nil {
+//			cleanup()
+//			panic(p)
+//		}
+//	}()
+//
+// Nothing is saved if the process is not being recorded.
+func SavePanic(p interface{}, filename string) (interface{}, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	lock.Lock()
+	active := recording
+	lock.Unlock()
+	if !active {
+		return p, nil
+	}
+
+	return p, Save(filename)
+}
+
+// RecoverAndSave saves the recording to filename if the calling goroutine is panicking, then continues the panic.
+//
+// It must be deferred directly, typically at the top of main and of
+// long-running goroutines:
+//
+//	defer undolr.RecoverAndSave("crash.undo")
+//
+// Errors saving the recording are ignored so the original panic is what
+// terminates the process. Use SavePanic to handle them.
+func RecoverAndSave(filename string) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	SavePanic(p, filename)
+	panic(p)
+}
+
+// RecoverAndSaveNamed is as RecoverAndSave, but names the recording file by calling name with the panic value.
+func RecoverAndSaveNamed(name func(p interface{}) string) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	SavePanic(p, name(p))
+	panic(p)
+}
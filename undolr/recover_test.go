@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestSavePanicNotRecording(t *testing.T) {
+	p, err := SavePanic(nil, "unused.undo")
+	if p != nil || err != nil {
+		t.Fatal("Unexpected result without a panic:", p, err)
+	}
+
+	p, err = SavePanic("boom", "unused.undo")
+	if p != "boom" || err != nil {
+		t.Fatal("Unexpected result when not recording:", p, err)
+	}
+}
+
+func TestRecoverAndSave(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer StopAndDiscard()
+
+	recovered := func() (p interface{}) {
+		defer func() {
+			p = recover()
+		}()
+		defer RecoverAndSaveNamed(func(p interface{}) string {
+			if p != "boom" {
+				t.Errorf("Unexpected panic value: %v", p)
+			}
+			return filename
+		})
+		panic("boom")
+	}()
+
+	if fmt.Sprint(recovered) != "boom" {
+		t.Fatal("Panic not continued:", recovered)
+	}
+	verifyRecording(t, filename)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRetentionPolicyInvalid indicates a retention policy with no limits, or a negative limit.
+var ErrRetentionPolicyInvalid = errors.New("retention policy not valid")
+
+// splitPartPattern matches the names of parts written by SplitRecording.
+var splitPartPattern = regexp.MustCompile(`\.part[0-9]{3,}$`)
+
+// RetentionPolicy is a disk budget for a directory of recordings.
+type RetentionPolicy struct {
+	// MaxBytes is the total size of recordings, including their sidecar
+	// files, to keep. Zero means no limit.
+	MaxBytes int64
+
+	// MaxCount is the number of recordings to keep. Zero means no limit.
+	MaxCount int
+
+	// Match, if set, selects the recordings managed by name. Files it
+	// rejects, such as a checksum manifest kept in the directory, are
+	// never deleted and don't count towards the budget.
+	Match func(name string) bool
+}
+
+// A RetainedRecording is a recording in a directory managed by a Retention, together with its sidecar files.
+type RetainedRecording struct {
+	// Path is the path of the recording file. The file may not exist, for
+	// instance if only its split parts were kept.
+	Path string
+
+	// Sidecars are the paths of files written alongside the recording,
+	// such as its fingerprint and split manifest and parts.
+	Sidecars []string
+
+	// Size is the total size of the recording and its sidecars.
+	Size int64
+
+	// ModTime is the latest modification time of any of its files.
+	ModTime time.Time
+}
+
+// A Retention keeps a directory of recordings within a disk budget.
+//
+// Recordings are deleted oldest first, together with the sidecar files
+// written by WriteFingerprint, SplitRecording, CaptureProfiles and
+// CaptureBinaries, so that a new save will fit. Use Resolver to enforce
+// the budget before every save, so flight recorder dumps can never fill
+// the disk.
+type Retention struct {
+	dir    string
+	policy RetentionPolicy
+	mu     sync.Mutex
+}
+
+// NewRetention creates a Retention managing the recordings in dir.
+func NewRetention(dir string, policy RetentionPolicy) (*Retention, error) {
+	if policy.MaxBytes < 0 || policy.MaxCount < 0 || (policy.MaxBytes == 0 && policy.MaxCount == 0) {
+		return nil, ErrRetentionPolicyInvalid
+	}
+	return &Retention{dir: dir, policy: policy}, nil
+}
+
+// recordingName returns the name of the recording a file in the directory
+// belongs to.
+func recordingName(name string) string {
+	for _, suffix := range []string{FingerprintSuffix, SplitManifestSuffix,
+		CPUProfileSuffix, HeapProfileSuffix, GoroutineProfileSuffix, BinariesSuffix} {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	if loc := splitPartPattern.FindStringIndex(name); loc != nil {
+		return name[:loc[0]]
+	}
+	return name
+}
+
+// Recordings returns the recordings in the directory, oldest first.
+func (r *Retention) Recordings() ([]RetainedRecording, error) {
+	files, err := ioutil.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*RetainedRecording)
+	for _, file := range files {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		name := recordingName(file.Name())
+		if r.policy.Match != nil && !r.policy.Match(name) {
+			continue
+		}
+
+		recording := byName[name]
+		if recording == nil {
+			recording = &RetainedRecording{Path: filepath.Join(r.dir, name)}
+			byName[name] = recording
+		}
+		if file.Name() != name {
+			recording.Sidecars = append(recording.Sidecars, filepath.Join(r.dir, file.Name()))
+		}
+		recording.Size += file.Size()
+		if file.ModTime().After(recording.ModTime) {
+			recording.ModTime = file.ModTime()
+		}
+	}
+
+	recordings := make([]RetainedRecording, 0, len(byName))
+	for _, recording := range byName {
+		recordings = append(recordings, *recording)
+	}
+	sort.Slice(recordings, func(i, j int) bool {
+		if !recordings[i].ModTime.Equal(recordings[j].ModTime) {
+			return recordings[i].ModTime.Before(recordings[j].ModTime)
+		}
+		return recordings[i].Path < recordings[j].Path
+	})
+	return recordings, nil
+}
+
+// Enforce deletes the oldest recordings until a new recording of reserve bytes fits within the budget.
+//
+// If reserve is zero, recordings are only deleted until the directory
+// itself is within the budget. The paths of the recordings deleted are
+// returned. A recording which cannot be deleted stops enforcement, so
+// the budget may not be met if an error is returned.
+func (r *Retention) Enforce(reserve int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recordings, err := r.Recordings()
+	if err != nil {
+		return nil, err
+	}
+
+	count := len(recordings)
+	var total int64
+	for _, recording := range recordings {
+		total += recording.Size
+	}
+	if reserve > 0 {
+		count++
+		total += reserve
+	}
+
+	var removed []string
+	for _, recording := range recordings {
+		if !(r.policy.MaxCount > 0 && count > r.policy.MaxCount) &&
+			!(r.policy.MaxBytes > 0 && total > r.policy.MaxBytes) {
+			break
+		}
+		for _, path := range append(recording.Sidecars, recording.Path) {
+			err = os.Remove(path)
+			if err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+		}
+		removed = append(removed, recording.Path)
+		count--
+		total -= recording.Size
+	}
+	return removed, nil
+}
+
+// Resolver returns a PathResolver enforcing the budget before each save to the directory.
+//
+// Paths are first resolved by next, if not nil. Saves to other
+// directories are not affected. Room is made for a recording of
+// SaveSizeEstimate bytes; if no estimate is available, the directory is
+// brought within the budget with room for one more recording by count.
+func (r *Retention) Resolver(next PathResolver) PathResolver {
+	return PathResolverFunc(func(name string, meta PathMetadata) (string, error) {
+		var err error
+		if next != nil {
+			name, err = next.ResolvePath(name, meta)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return "", err
+		}
+		dir, err := filepath.Abs(r.dir)
+		if err != nil {
+			return "", err
+		}
+		if filepath.Dir(abs) != dir {
+			return name, nil
+		}
+
+		reserve, err := SaveSizeEstimate()
+		if err != nil || reserve <= 0 {
+			reserve = 1
+		}
+		_, err = r.Enforce(reserve)
+		if err != nil {
+			return "", err
+		}
+		return name, nil
+	})
+}
@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAged writes size bytes to name in dir, with a modification time
+// age before base.
+func writeAged(t *testing.T, dir, name string, size int, base time.Time, age time.Duration) {
+	filename := filepath.Join(dir, name)
+	err := ioutil.WriteFile(filename, make([]byte, size), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+	err = os.Chtimes(filename, base.Add(-age), base.Add(-age))
+	if err != nil {
+		t.Fatal("Chtimes:", err)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "retention")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := time.Now()
+	writeAged(t, dir, "old.undo", 100, base, 3*time.Hour)
+	writeAged(t, dir, "old.undo"+FingerprintSuffix, 10, base, 3*time.Hour)
+	writeAged(t, dir, "split.undo.part000", 100, base, 2*time.Hour)
+	writeAged(t, dir, "split.undo.part001", 50, base, 2*time.Hour)
+	writeAged(t, dir, "split.undo"+SplitManifestSuffix, 10, base, 2*time.Hour)
+	writeAged(t, dir, "new.undo", 100, base, time.Hour)
+	writeAged(t, dir, "checksums.sha256", 1000, base, 4*time.Hour)
+
+	_, err = NewRetention(dir, RetentionPolicy{})
+	if err != ErrRetentionPolicyInvalid {
+		t.Fatal("Expected policy without limits to be invalid:", err)
+	}
+
+	retention, err := NewRetention(dir, RetentionPolicy{
+		MaxBytes: 400,
+		Match:    func(name string) bool { return strings.HasSuffix(name, ".undo") },
+	})
+	if err != nil {
+		t.Fatal("NewRetention:", err)
+	}
+
+	recordings, err := retention.Recordings()
+	if err != nil {
+		t.Fatal("Recordings:", err)
+	}
+	if len(recordings) != 3 {
+		t.Fatalf("Found %d recordings, expected 3: %+v", len(recordings), recordings)
+	}
+	if filepath.Base(recordings[1].Path) != "split.undo" || recordings[1].Size != 160 || len(recordings[1].Sidecars) != 3 {
+		t.Fatalf("Unexpected split recording: %+v", recordings[1])
+	}
+
+	removed, err := retention.Enforce(0)
+	if err != nil || len(removed) != 0 {
+		t.Fatal("Unexpected removal within budget:", removed, err)
+	}
+
+	removed, err = retention.Enforce(100)
+	if err != nil {
+		t.Fatal("Enforce:", err)
+	}
+	if len(removed) != 1 || filepath.Base(removed[0]) != "old.undo" {
+		t.Fatal("Expected oldest recording removed, got", removed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.undo"+FingerprintSuffix)); !os.IsNotExist(err) {
+		t.Fatal("Sidecar not removed:", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "checksums.sha256")); err != nil {
+		t.Fatal("Unmatched file removed:", err)
+	}
+
+	retention.policy.MaxBytes = 0
+	retention.policy.MaxCount = 1
+	removed, err = retention.Enforce(1)
+	if err != nil {
+		t.Fatal("Enforce:", err)
+	}
+	if len(removed) != 2 {
+		t.Fatal("Expected both remaining recordings removed for one more, got", removed)
+	}
+}
+
+func TestRetentionResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "retention")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := time.Now()
+	writeAged(t, dir, "a.undo", 10, base, 2*time.Hour)
+	writeAged(t, dir, "b.undo", 10, base, time.Hour)
+
+	retention, err := NewRetention(dir, RetentionPolicy{MaxCount: 2})
+	if err != nil {
+		t.Fatal("NewRetention:", err)
+	}
+	resolver := retention.Resolver(DirectoryResolver(dir))
+
+	path, err := resolver.ResolvePath("c.undo", PathMetadata{Kind: SaveKindSync})
+	if err != nil {
+		t.Fatal("ResolvePath:", err)
+	}
+	if path != filepath.Join(dir, "c.undo") {
+		t.Fatal("Unexpected path:", path)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.undo")); !os.IsNotExist(err) {
+		t.Fatal("Oldest recording not removed before save:", err)
+	}
+
+	_, err = resolver.ResolvePath("/elsewhere/d.undo", PathMetadata{Kind: SaveKindSync})
+	if err != nil {
+		t.Fatal("ResolvePath:", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.undo")); err != nil {
+		t.Fatal("Recording removed for save to another directory:", err)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A set of error codes returned by Rotator.
+var (
+	ErrRotatorRunning        = errors.New("rotator already running")
+	ErrRotationPolicyInvalid = errors.New("rotation policy not valid")
+)
+
+// rotatorSuffix is the extension of recordings written by a Rotator.
+const rotatorSuffix = ".undo"
+
+// rotatorTimeFormat names recordings so they sort in the order written.
+const rotatorTimeFormat = "20060102T150405.000000000"
+
+// RotationPolicy controls when a Rotator saves recordings and which it keeps.
+type RotationPolicy struct {
+	// Interval is the time between saves by a running Rotator.
+	Interval time.Duration
+
+	// Prefix starts the names of the recording files. It defaults to
+	// "recording-". Only files with the prefix are considered for deletion.
+	Prefix string
+
+	// MaxCount is the number of recordings to keep. Zero means no limit.
+	MaxCount int
+
+	// MaxBytes is the total size of recordings to keep. Zero means no
+	// limit.
+	MaxBytes int64
+
+	// MaxAge is the age beyond which recordings are deleted. Zero means
+	// no limit.
+	MaxAge time.Duration
+
+	// OnError, if set, is called with errors from saves and deletions by
+	// a running Rotator.
+	OnError func(err error)
+}
+
+// A Rotator periodically saves recordings to a directory, keeping a rolling set.
+//
+// Each save is made with SaveWithStats while recording, so recording must
+// have been started. After each save the oldest recordings in the directory
+// are deleted until the policy's limits are met; the newest recording is
+// always kept. Recordings are saved directly in the directory: a
+// PathResolver which moves them elsewhere prevents them being deleted.
+type Rotator struct {
+	dir    string
+	policy RotationPolicy
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRotator creates a Rotator saving recordings to dir, which is created if needed.
+func NewRotator(dir string, policy RotationPolicy) (*Rotator, error) {
+	if policy.Interval < 0 || policy.MaxCount < 0 || policy.MaxBytes < 0 || policy.MaxAge < 0 {
+		return nil, ErrRotationPolicyInvalid
+	}
+	if policy.Prefix == "" {
+		policy.Prefix = "recording-"
+	}
+	if strings.ContainsRune(policy.Prefix, filepath.Separator) {
+		return nil, ErrRotationPolicyInvalid
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rotator{dir: dir, policy: policy}, nil
+}
+
+// Rotate saves a recording now and deletes old recordings, returning the name of the file saved.
+func (r *Rotator) Rotate() (filename string, err error) {
+	t := now()
+	filename = filepath.Join(r.dir, r.policy.Prefix+t.UTC().Format(rotatorTimeFormat)+rotatorSuffix)
+
+	stats, err := SaveWithStats(filename)
+	if err != nil {
+		return "", err
+	}
+
+	return stats.Filename, r.prune(t)
+}
+
+// Recordings returns the recordings in the directory, oldest first.
+func (r *Rotator) Recordings() ([]string, error) {
+	files, err := r.recordings()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = filepath.Join(r.dir, file.Name())
+	}
+	return names, nil
+}
+
+func (r *Rotator) recordings() ([]os.FileInfo, error) {
+	entries, err := ioutil.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []os.FileInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Mode().IsRegular() &&
+			strings.HasPrefix(name, r.policy.Prefix) &&
+			strings.HasSuffix(name, rotatorSuffix) {
+			files = append(files, entry)
+		}
+	}
+
+	// ReadDir sorts by name, which is the order written.
+	return files, nil
+}
+
+// prune deletes the oldest recordings until the policy's limits are met.
+func (r *Rotator) prune(now time.Time) error {
+	files, err := r.recordings()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, file := range files {
+		total += file.Size()
+	}
+
+	var firstErr error
+	for i := 0; i < len(files)-1; i++ {
+		file := files[i]
+		count := len(files) - i
+		if !(r.policy.MaxCount > 0 && count > r.policy.MaxCount) &&
+			!(r.policy.MaxBytes > 0 && total > r.policy.MaxBytes) &&
+			!(r.policy.MaxAge > 0 && now.Sub(file.ModTime()) > r.policy.MaxAge) {
+			break
+		}
+		err = os.Remove(filepath.Join(r.dir, file.Name()))
+		if err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+		total -= file.Size()
+	}
+	return firstErr
+}
+
+// Start saves recordings every policy interval until Stop is called.
+//
+// The first save is made after one interval, and each later save one
+// interval after the previous one completes. Intervals are timed by the
+// package Clock.
+func (r *Rotator) Start() error {
+	if r.policy.Interval <= 0 {
+		return ErrRotationPolicyInvalid
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return ErrRotatorRunning
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go r.run(r.stop, r.done)
+	return nil
+}
+
+func (r *Rotator) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-currentClock().After(r.policy.Interval):
+			_, err := r.Rotate()
+			if err != nil && r.policy.OnError != nil {
+				r.policy.OnError(err)
+			}
+		}
+	}
+}
+
+// Stop stops a running Rotator, waiting for any save in progress to finish.
+//
+// Stop has no effect if the Rotator isn't running.
+func (r *Rotator) Stop() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
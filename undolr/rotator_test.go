@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeRotated creates a fake recording of size bytes in dir with the given age.
+func writeRotated(t *testing.T, dir, name string, size int, age time.Duration) {
+	filename := filepath.Join(dir, name)
+	err := ioutil.WriteFile(filename, make([]byte, size), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+	mtime := time.Now().Add(-age)
+	err = os.Chtimes(filename, mtime, mtime)
+	if err != nil {
+		t.Fatal("Chtimes:", err)
+	}
+}
+
+func TestRotatorPrune(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		policy   RotationPolicy
+		expected []string
+	}{
+		{"none", RotationPolicy{}, []string{"r-1.undo", "r-2.undo", "r-3.undo", "r-4.undo"}},
+		{"count", RotationPolicy{MaxCount: 2}, []string{"r-3.undo", "r-4.undo"}},
+		{"bytes", RotationPolicy{MaxBytes: 250}, []string{"r-3.undo", "r-4.undo"}},
+		{"age", RotationPolicy{MaxAge: time.Minute * 90}, []string{"r-3.undo", "r-4.undo"}},
+		{"newest", RotationPolicy{MaxBytes: 1}, []string{"r-4.undo"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "undolr_test_")
+			if err != nil {
+				t.Fatal("TempDir:", err)
+			}
+			defer os.RemoveAll(dir)
+
+			writeRotated(t, dir, "r-1.undo", 100, time.Hour*3)
+			writeRotated(t, dir, "r-2.undo", 100, time.Hour*2)
+			writeRotated(t, dir, "r-3.undo", 100, time.Hour)
+			writeRotated(t, dir, "r-4.undo", 100, 0)
+			writeRotated(t, dir, "other.undo", 100, time.Hour*4)
+			writeRotated(t, dir, "r-5.txt", 100, time.Hour*4)
+
+			test.policy.Prefix = "r-"
+			r, err := NewRotator(dir, test.policy)
+			if err != nil {
+				t.Fatal("NewRotator:", err)
+			}
+
+			err = r.prune(time.Now())
+			if err != nil {
+				t.Fatal("prune:", err)
+			}
+
+			names, err := r.Recordings()
+			if err != nil {
+				t.Fatal("Recordings:", err)
+			}
+			if len(names) != len(test.expected) {
+				t.Fatalf("Unexpected recordings kept: %v", names)
+			}
+			for i, name := range names {
+				if filepath.Base(name) != test.expected[i] {
+					t.Fatalf("Unexpected recordings kept: %v", names)
+				}
+			}
+
+			for _, name := range []string{"other.undo", "r-5.txt"} {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Fatalf("%s removed: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRotatorInvalid(t *testing.T) {
+	_, err := NewRotator(os.TempDir(), RotationPolicy{MaxCount: -1})
+	if err != ErrRotationPolicyInvalid {
+		t.Fatal("Expected negative count to fail:", err)
+	}
+	_, err = NewRotator(os.TempDir(), RotationPolicy{Prefix: "a/b"})
+	if err != ErrRotationPolicyInvalid {
+		t.Fatal("Expected prefix with separator to fail:", err)
+	}
+
+	r, err := NewRotator(os.TempDir(), RotationPolicy{})
+	if err != nil {
+		t.Fatal("NewRotator:", err)
+	}
+	err = r.Start()
+	if err != ErrRotationPolicyInvalid {
+		t.Fatal("Expected Start without an interval to fail:", err)
+	}
+	r.Stop()
+}
+
+func TestRotator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := NewRotator(dir, RotationPolicy{Interval: time.Millisecond * 100, MaxCount: 2})
+	if err != nil {
+		t.Fatal("NewRotator:", err)
+	}
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer StopAndDiscard()
+
+	for i := 0; i < 3; i++ {
+		filename, err := r.Rotate()
+		if err != nil {
+			t.Fatal("Rotate:", err)
+		}
+		verifyRecording(t, filename)
+	}
+
+	names, err := r.Recordings()
+	if err != nil {
+		t.Fatal("Recordings:", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("Expected 2 recordings kept: %v", names)
+	}
+
+	err = r.Start()
+	if err != nil {
+		t.Fatal("Rotator Start:", err)
+	}
+	err = r.Start()
+	if err != ErrRotatorRunning {
+		t.Fatal("Expected second Start to fail:", err)
+	}
+	time.Sleep(time.Millisecond * 250)
+	r.Stop()
+}
@@ -0,0 +1,154 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// SaveContext saves recorded program history to a named recording file unless ctx is done.
+//
+// A synchronous save stops every thread in the process, including the
+// caller's, so once started it cannot be interrupted: ctx is only checked
+// before the save begins. To bound the time taken by a save, Stop the
+// recording and use SaveAsyncContext instead.
+func SaveContext(ctx context.Context, filename string) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	return Save(filename)
+}
+
+// SaveAsyncContext saves a stopped recording to a named recording file, waiting for completion or for ctx to be done.
+//
+// The outcome of the save is returned, as Wait would report. If ctx is
+// done before the save completes, ctx.Err() is returned instead. The
+// library has no way to abort a save in progress, so it is abandoned
+// instead: it continues in the background and the partially written file
+// is removed once it completes. Until then the recording context may not
+// be saved again, and Discard waits for the abandoned save to finish.
+func (context *RecordingContext) SaveAsyncContext(ctx context.Context, filename string) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	fd, err := context.GetSelectDescriptor()
+	if err != nil {
+		return err
+	}
+
+	err = context.SaveAsync(filename)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- waitSelectDescriptor(fd)
+	}()
+
+	select {
+	case err = <-done:
+		return context.saveOutcome(err)
+	case <-ctx.Done():
+		abandoned := make(chan struct{})
+		context.abandoned = abandoned
+		filename := context.saveFilename
+		go func() {
+			<-done
+			os.Remove(filename)
+			close(abandoned)
+		}()
+		return ctx.Err()
+	}
+}
+
+// Wait blocks until the save started by SaveAsync completes or ctx is done.
+//
+// It returns the outcome of the save, as Poll would report once complete,
+// or ctx.Err() if ctx is done first. The save continues regardless, and
+// Wait may be called again to resume waiting. A save which has already
+// been observed to complete returns its outcome immediately.
+func (context *RecordingContext) Wait(ctx context.Context) error {
+	if !context.valid {
+		return ErrRecordingContextDiscarded
+	}
+	if !context.saving {
+		return ErrRecordingContextSaveNotStarted
+	}
+	if context.abandonedPending() {
+		return ErrRecordingContextSaveAbandoned
+	}
+	if context.saveStats != nil || context.saveErr != nil {
+		return context.saveErr
+	}
+
+	if context.waiting == nil {
+		fd, err := context.GetSelectDescriptor()
+		if err != nil {
+			return err
+		}
+		waiting := make(chan error, 1)
+		go func() {
+			waiting <- waitSelectDescriptor(fd)
+		}()
+		context.waiting = waiting
+	}
+
+	select {
+	case err := <-context.waiting:
+		context.waiting = nil
+		return context.saveOutcome(err)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// A ContextSave is a stopped recording to save to a file with SaveAll.
+type ContextSave struct {
+	Context  *RecordingContext
+	Filename string
+}
+
+// SaveAll saves stopped recordings in parallel, waiting for every save to complete or for ctx to be done.
+//
+// Each recording is saved as by SaveAsyncContext, so the time taken is
+// that of the longest save rather than their sum. The returned errors
+// correspond to saves, and are nil for saves which succeeded. The
+// contexts must not be used by other goroutines until SaveAll returns.
+func SaveAll(ctx context.Context, saves []ContextSave) []error {
+	errs := make([]error, len(saves))
+	var wg sync.WaitGroup
+	for i, save := range saves {
+		wg.Add(1)
+		go func(i int, save ContextSave) {
+			defer wg.Done()
+			errs[i] = save.Context.SaveAsyncContext(ctx, save.Filename)
+		}(i, save)
+	}
+	wg.Wait()
+	return errs
+}
+
+// abandonedPending reports whether a save abandoned by SaveAsyncContext is
+// still in progress.
+func (context *RecordingContext) abandonedPending() bool {
+	if context.abandoned == nil {
+		return false
+	}
+	select {
+	case <-context.abandoned:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,344 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSaveContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := SaveContext(ctx, "recording.undolr")
+	if err != context.Canceled {
+		t.Fatal("Expected SaveContext() to fail with cancelled context:", err)
+	}
+
+	err = (&RecordingContext{valid: true}).SaveAsyncContext(ctx, "recording.undolr")
+	if err != context.Canceled {
+		t.Fatal("Expected SaveAsyncContext() to fail with cancelled context:", err)
+	}
+}
+
+func TestAbandonedPending(t *testing.T) {
+	context := &RecordingContext{}
+	if context.abandonedPending() {
+		t.Fatal("Unexpected abandoned save")
+	}
+
+	context.abandoned = make(chan struct{})
+	if !context.abandonedPending() {
+		t.Fatal("Expected abandoned save to be pending")
+	}
+
+	close(context.abandoned)
+	if context.abandonedPending() {
+		t.Fatal("Unexpected abandoned save after completion")
+	}
+}
+
+func TestSaveContext(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	err = SaveContext(context.Background(), filename)
+	if err != nil {
+		t.Fatal("SaveContext:", err)
+	}
+
+	err = StopAndDiscard()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+
+	verifyRecording(t, filename)
+}
+
+func TestSaveAsyncContext(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	recContext, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer recContext.Discard()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = recContext.SaveAsyncContext(ctx, filename)
+	if err != nil {
+		t.Fatal("SaveAsyncContext:", err)
+	}
+
+	verifyRecording(t, filename)
+}
+
+func TestSaveAsyncContextTimeout(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	recContext, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+
+	os.Remove(filename)
+
+	// Short enough that the save is expected to be abandoned.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	err = recContext.SaveAsyncContext(ctx, filename)
+	if err == nil {
+		recContext.Discard()
+		t.Skip("Save completed before the timeout")
+	} else if err != context.DeadlineExceeded {
+		t.Fatal("Expected SaveAsyncContext() to time out:", err)
+	}
+
+	// Discard waits for the abandoned save, which removes the file.
+	err = recContext.Discard()
+	if err != nil {
+		t.Fatal("Discard:", err)
+	}
+	if _, err = os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatal("Expected abandoned recording to be removed:", err)
+	}
+}
+
+func TestSaveAsyncContextFailed(t *testing.T) {
+	filename := unwritableFilename(t)
+
+	var phases []SavePhase
+	SetSaveHook(func(event SaveEvent) {
+		phases = append(phases, event.Phase)
+	})
+	defer SetSaveHook(nil)
+
+	err := Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	recContext, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer recContext.Discard()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = recContext.SaveAsyncContext(ctx, filename)
+	if err == nil || err == context.DeadlineExceeded {
+		t.Fatal("Expected SaveAsyncContext() to fail saving to a missing directory:", err)
+	}
+	if _, err = recContext.SaveStats(); err == nil {
+		t.Fatal("Expected SaveStats() to fail after a failed save")
+	}
+	for _, phase := range phases {
+		if phase == SaveCompleted {
+			t.Fatal("Failed save reported as completed:", phases)
+		}
+	}
+}
+
+func TestSaveAllErrors(t *testing.T) {
+	if errs := SaveAll(context.Background(), nil); len(errs) != 0 {
+		t.Fatal("Unexpected errors for no saves:", errs)
+	}
+
+	saves := []ContextSave{
+		{&RecordingContext{}, "first.undo"},
+		{&RecordingContext{}, "second.undo"},
+	}
+	errs := SaveAll(context.Background(), saves)
+	if len(errs) != 2 || errs[0] != ErrRecordingContextDiscarded || errs[1] != ErrRecordingContextDiscarded {
+		t.Fatal("Unexpected errors for discarded contexts:", errs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs = SaveAll(ctx, saves)
+	if len(errs) != 2 || errs[0] != context.Canceled || errs[1] != context.Canceled {
+		t.Fatal("Unexpected errors for cancelled saves:", errs)
+	}
+}
+
+func TestSaveAll(t *testing.T) {
+	var saves []ContextSave
+	for i := 0; i < 3; i++ {
+		err := Start()
+		if err != nil {
+			t.Fatal("Start:", err)
+		}
+		context, err := Stop()
+		if err != nil {
+			t.Fatal("Stop:", err)
+		}
+		defer context.Discard()
+
+		filename, err := tmpnam("")
+		if err != nil {
+			t.Fatal("Filename:", err)
+		}
+		defer os.Remove(filename)
+		saves = append(saves, ContextSave{context, filename})
+	}
+
+	for i, err := range SaveAll(context.Background(), saves) {
+		if err != nil {
+			t.Fatalf("Save %d: %v", i, err)
+		}
+		verifyRecording(t, saves[i].Filename)
+	}
+}
+
+func TestSaveAllFailed(t *testing.T) {
+	var saves []ContextSave
+	for i := 0; i < 2; i++ {
+		err := Start()
+		if err != nil {
+			t.Fatal("Start:", err)
+		}
+		context, err := Stop()
+		if err != nil {
+			t.Fatal("Stop:", err)
+		}
+		defer context.Discard()
+		saves = append(saves, ContextSave{Context: context})
+	}
+
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+	saves[0].Filename = filename
+	saves[1].Filename = unwritableFilename(t)
+
+	errs := SaveAll(context.Background(), saves)
+	if errs[0] != nil {
+		t.Fatal("Save 0:", errs[0])
+	}
+	verifyRecording(t, filename)
+	if errs[1] == nil {
+		t.Fatal("Expected save 1 to fail saving to a missing directory")
+	}
+}
+
+func TestWaitErrors(t *testing.T) {
+	ctx := context.Background()
+	if err := (&RecordingContext{}).Wait(ctx); err != ErrRecordingContextDiscarded {
+		t.Fatal("Expected Wait() to fail for a discarded context:", err)
+	}
+	if err := (&RecordingContext{valid: true}).Wait(ctx); err != ErrRecordingContextSaveNotStarted {
+		t.Fatal("Expected Wait() to fail without a save:", err)
+	}
+
+	recContext := &RecordingContext{valid: true, saving: true, abandoned: make(chan struct{})}
+	if err := recContext.Wait(ctx); err != ErrRecordingContextSaveAbandoned {
+		t.Fatal("Expected Wait() to fail with an abandoned save:", err)
+	}
+
+	saveErr := SaveResultCode(28).Err()
+	recContext = &RecordingContext{valid: true, saving: true, saveErr: saveErr}
+	if err := recContext.Wait(ctx); err != saveErr {
+		t.Fatal("Expected Wait() to return the outcome of a failed save:", err)
+	}
+	recContext = &RecordingContext{valid: true, saving: true, saveStats: &SaveStats{}}
+	if err := recContext.Wait(ctx); err != nil {
+		t.Fatal("Expected Wait() to succeed for a completed save:", err)
+	}
+}
+
+func TestWaitResume(t *testing.T) {
+	waiting := make(chan error, 1)
+	recContext := &RecordingContext{valid: true, saving: true, saveReported: true, waiting: waiting}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := recContext.Wait(ctx); err != context.Canceled {
+		t.Fatal("Expected Wait() to fail with cancelled context:", err)
+	}
+	if recContext.waiting != waiting {
+		t.Fatal("Expected cancelled Wait() to keep waiting for the save")
+	}
+
+	waiting <- ErrSaveBackgroundReadFailed
+	if err := recContext.Wait(context.Background()); err != ErrSaveBackgroundReadFailed {
+		t.Fatal("Expected resumed Wait() to return the read error:", err)
+	}
+	if err := recContext.Wait(context.Background()); err != ErrSaveBackgroundReadFailed {
+		t.Fatal("Expected Wait() to return the outcome again:", err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	recContext, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer recContext.Discard()
+
+	err = recContext.SaveAsync(filename)
+	if err != nil {
+		t.Fatal("SaveAsync:", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = recContext.Wait(ctx)
+	if err != nil {
+		t.Fatal("Wait:", err)
+	}
+
+	verifyRecording(t, filename)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"sync"
+)
+
+// A SavePhase identifies the point in a save a SaveEvent reports.
+type SavePhase int
+
+// Values for SavePhase
+const (
+	// SaveStarted is reported once a save has been accepted by the library.
+	SaveStarted SavePhase = iota
+
+	// SaveCompleted is reported once a save has written its recording.
+	SaveCompleted
+
+	// SaveFailed is reported when a save started but did not complete.
+	SaveFailed
+)
+
+func (p SavePhase) String() string {
+	switch p {
+	case SaveStarted:
+		return "started"
+	case SaveCompleted:
+		return "completed"
+	case SaveFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// A SaveEvent describes progress of a save.
+type SaveEvent struct {
+	Phase SavePhase
+	Kind  SaveKind
+
+	// Filename is the recording file, or empty for a save streamed to a
+	// writer by SaveToWriter.
+	Filename string
+
+	// Stats holds statistics for a completed save.
+	Stats SaveStats
+
+	// Err is the reason a save failed.
+	Err error
+}
+
+var saveHookLock sync.Mutex
+var saveHook func(SaveEvent)
+
+// saveHooks are the hooks added by AddSaveHook. The slice is replaced
+// rather than modified on removal, so notifySave may use a copy without
+// the lock held.
+var saveHooks []*addedSaveHook
+
+type addedSaveHook struct {
+	hook func(SaveEvent)
+}
+
+// SetSaveHook sets a function called as saves start and finish.
+//
+// Synchronous saves are reported when Save or SaveWithStats return. The
+// completion of asynchronous saves is reported when it is first observed,
+// by Poll, SaveBackground or any of the functions built on them. The hook
+// is called synchronously without any locks held, so it may call other
+// functions in this package. Passing nil removes the hook.
+//
+// There is one hook set by SetSaveHook, which is replaced by each call.
+// Hooks added by AddSaveHook are unaffected.
+func SetSaveHook(hook func(SaveEvent)) {
+	saveHookLock.Lock()
+	defer saveHookLock.Unlock()
+	saveHook = hook
+}
+
+// AddSaveHook adds a function called as saves start and finish, returning a function to remove it.
+//
+// Hooks added are called as described for SetSaveHook, after the hook it
+// sets and in the order they were added. Any number may be added, so
+// independent observers of saves, such as the metrics and upload
+// packages, do not replace one another.
+func AddSaveHook(hook func(SaveEvent)) (remove func()) {
+	added := &addedSaveHook{hook}
+
+	saveHookLock.Lock()
+	defer saveHookLock.Unlock()
+	saveHooks = append(saveHooks, added)
+
+	return func() {
+		saveHookLock.Lock()
+		defer saveHookLock.Unlock()
+		for i, h := range saveHooks {
+			if h == added {
+				saveHooks = append(saveHooks[:i:i], saveHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func notifySave(event SaveEvent) {
+	logSave(event)
+	observeSaveRate(event)
+
+	saveHookLock.Lock()
+	hook := saveHook
+	hooks := saveHooks
+	saveHookLock.Unlock()
+
+	if hook != nil {
+		hook(event)
+	}
+	for _, h := range hooks {
+		h.hook(event)
+	}
+}
+
+// reportSave notifies the save hook of the outcome of an asynchronous
+// save the first time it is known. It must be called without lock held.
+func (context *RecordingContext) reportSave() {
+	if context.saveReported {
+		return
+	}
+	switch {
+	case context.saveStats != nil:
+		context.saveReported = true
+		addChecksum(context.saveStats)
+		notifySave(SaveEvent{
+			Phase:    SaveCompleted,
+			Kind:     SaveKindAsync,
+			Filename: context.saveFilename,
+			Stats:    *context.saveStats,
+		})
+	case context.saveErr != nil:
+		context.saveReported = true
+		notifySave(SaveEvent{
+			Phase:    SaveFailed,
+			Kind:     SaveKindAsync,
+			Filename: context.saveFilename,
+			Err:      context.saveErr,
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestReportSave(t *testing.T) {
+	var events []SaveEvent
+	SetSaveHook(func(event SaveEvent) {
+		events = append(events, event)
+	})
+	defer SetSaveHook(nil)
+
+	context := &RecordingContext{saveFilename: "test.undo"}
+	context.reportSave()
+	if len(events) != 0 {
+		t.Fatal("Save reported before completion:", events)
+	}
+
+	context.saveStats = &SaveStats{Filename: "test.undo", BytesWritten: 42}
+	context.reportSave()
+	context.reportSave()
+	if len(events) != 1 || events[0].Phase != SaveCompleted || events[0].Kind != SaveKindAsync ||
+		events[0].Stats.BytesWritten != 42 {
+		t.Fatalf("Unexpected events: %+v", events)
+	}
+
+	events = nil
+	context = &RecordingContext{saveFilename: "test.undo", saveErr: syscall.ENOSPC}
+	context.reportSave()
+	if len(events) != 1 || events[0].Phase != SaveFailed || events[0].Err != syscall.ENOSPC {
+		t.Fatalf("Unexpected events: %+v", events)
+	}
+}
+
+func TestAddSaveHook(t *testing.T) {
+	var calls []string
+	SetSaveHook(func(SaveEvent) { calls = append(calls, "set") })
+	defer SetSaveHook(nil)
+	removeFirst := AddSaveHook(func(SaveEvent) { calls = append(calls, "first") })
+	removeSecond := AddSaveHook(func(SaveEvent) { calls = append(calls, "second") })
+	defer removeSecond()
+
+	notifySave(SaveEvent{Phase: SaveStarted, Kind: SaveKindSync})
+	if fmt.Sprint(calls) != "[set first second]" {
+		t.Fatal("Unexpected hook calls:", calls)
+	}
+
+	calls = nil
+	removeFirst()
+	removeFirst()
+	SetSaveHook(nil)
+	notifySave(SaveEvent{Phase: SaveStarted, Kind: SaveKindSync})
+	if fmt.Sprint(calls) != "[second]" {
+		t.Fatal("Unexpected hook calls after removal:", calls)
+	}
+}
+
+func TestSaveHook(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	var phases []SavePhase
+	SetSaveHook(func(event SaveEvent) {
+		phases = append(phases, event.Phase)
+	})
+	defer SetSaveHook(nil)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	err = Save(filename)
+	if err != nil {
+		t.Fatal("Save:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	ch := make(chan error, 1)
+	context.SaveBackground(filename, ch)
+	err = <-ch
+	if err != nil {
+		t.Fatal("SaveBackground:", err)
+	}
+
+	expected := []SavePhase{SaveStarted, SaveCompleted, SaveStarted, SaveCompleted}
+	if len(phases) != len(expected) {
+		t.Fatalf("Unexpected phases: %v", phases)
+	}
+	for i := range expected {
+		if phases[i] != expected[i] {
+			t.Fatalf("Unexpected phases: %v", phases)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrProgressIntervalInvalid indicates a progress interval which isn't positive.
+var ErrProgressIntervalInvalid = errors.New("progress interval not valid")
+
+// SaveResult describes the outcome of a save started by SaveNotify.
+type SaveResult struct {
+	// Filename is the name of the recording file.
+	Filename string
+
+	// Result is the result code reported by the library for the save.
+	Result SaveResultCode
+
+	// Stats holds statistics for the save. It is only valid if Err is nil.
+	Stats SaveStats
+
+	// Err is nil if the save succeeded.
+	Err error
+}
+
+// SaveNotify starts saving a stopped recording to a named recording file, returning a channel which receives the outcome.
+//
+// Errors starting the save are returned directly. Otherwise exactly one
+// SaveResult is sent on the returned channel once the save completes, and
+// the channel is then closed. The channel is buffered, so the result is not
+// lost if it is never received.
+func (context *RecordingContext) SaveNotify(filename string) (<-chan SaveResult, error) {
+	fd, err := context.GetSelectDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	err = context.SaveAsync(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	filename = context.saveFilename
+	ch := make(chan SaveResult, 1)
+	go func() {
+		defer close(ch)
+		ch <- context.waitSaveResult(fd, filename)
+	}()
+	return ch, nil
+}
+
+// waitSaveResult waits for the save associated with fd to complete and
+// collects its result.
+func (context *RecordingContext) waitSaveResult(fd int, filename string) SaveResult {
+	res := SaveResult{Filename: filename}
+
+	res.Err = waitSelectDescriptor(fd)
+	if res.Err != nil {
+		return res
+	}
+
+	status := context.PollStatus()
+	res.Result = status.Result
+	switch {
+	case status.Err != nil && status.Complete:
+		res.Err = fmt.Errorf("save to %s failed: %w", filename, status.Err)
+	case status.Err != nil:
+		res.Err = status.Err
+	case !status.Complete:
+		res.Err = ErrRecordingContextSaveIncomplete
+	default:
+		res.Stats, res.Err = context.SaveStats()
+	}
+	return res
+}
+
+// SaveProgress starts saving a stopped recording to a named recording file, returning a channel of its status.
+//
+// The status is polled every interval and sent on the channel whenever it
+// changes. The final status sent has Complete set, or Err set if the
+// status could not be determined, and the channel is then closed. Status
+// updates are dropped rather than block the save if the receiver falls
+// behind, but the final status is always delivered, so the channel
+// should be received from until it is closed.
+func (context *RecordingContext) SaveProgress(filename string, interval time.Duration) (<-chan SaveStatus, error) {
+	if interval <= 0 {
+		return nil, ErrProgressIntervalInvalid
+	}
+
+	err := context.SaveAsync(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan SaveStatus, 1)
+	go func() {
+		defer close(ch)
+		last := SaveStatus{Progress: -2}
+		for {
+			status := context.PollStatus()
+			if status.Complete || status.Err != nil {
+				ch <- status
+				return
+			}
+			if status != last {
+				select {
+				case ch <- status:
+					last = status
+				default:
+				}
+			}
+			<-currentClock().After(interval)
+		}
+	}()
+	return ch, nil
+}
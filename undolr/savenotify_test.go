@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSaveNotify(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	ch, err := context.SaveNotify(filename)
+	if err != nil {
+		t.Fatal("SaveNotify:", err)
+	}
+
+	var res SaveResult
+	select {
+	case res = <-ch:
+	case <-time.After(time.Second * 30):
+		t.Fatal("Save hadn't completed after 30 seconds")
+	}
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if res.Result != SaveResultSuccess {
+		t.Fatal("Unexpected result:", res.Result)
+	}
+
+	size, _ := fileSize(filename)
+	if res.Stats.BytesWritten != size {
+		t.Fatalf("BytesWritten doesn't match (%d vs %d)", res.Stats.BytesWritten, size)
+	}
+
+	if _, ok := <-ch; ok {
+		t.Fatal("Channel not closed after result")
+	}
+
+	verifyRecording(t, filename)
+}
+
+func TestSaveProgress(t *testing.T) {
+	_, err := (&RecordingContext{}).SaveProgress("unused", 0)
+	if err != ErrProgressIntervalInvalid {
+		t.Fatal("Expected zero interval to fail:", err)
+	}
+
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	ch, err := context.SaveProgress(filename, time.Millisecond*10)
+	if err != nil {
+		t.Fatal("SaveProgress:", err)
+	}
+
+	var last SaveStatus
+	for status := range ch {
+		last = status
+	}
+	if !last.Complete || last.Err != nil {
+		t.Fatalf("Unexpected final status: %+v", last)
+	}
+	verifyRecording(t, filename)
+}
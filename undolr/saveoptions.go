@@ -0,0 +1,159 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrSaveOptionsInvalid indicates SaveOptions failed validation.
+//
+// Errors returned by SaveOptions.Validate wrap this value.
+var ErrSaveOptionsInvalid = errors.New("invalid save options")
+
+// ErrSaveTimeout indicates a save did not complete within SaveOptions.Timeout.
+var ErrSaveTimeout = errors.New("save timed out")
+
+// throttleChunks is the number of pieces a second's worth of data is
+// written in when throttled, so the rate is smooth rather than bursty.
+const throttleChunks = 10
+
+// SaveOptions bundles the limits applied by SaveWithOptions.
+//
+// The zero value saves without limits.
+type SaveOptions struct {
+	// Timeout is the longest the save may take, or zero for no limit.
+	Timeout time.Duration
+
+	// RateLimit is the most bytes per second the recording may be
+	// written at, or zero for no limit.
+	RateLimit int64
+}
+
+// Validate checks the options for errors before any save is started.
+func (opts *SaveOptions) Validate() error {
+	if opts.Timeout < 0 {
+		return fmt.Errorf("%w: negative timeout %v", ErrSaveOptionsInvalid, opts.Timeout)
+	}
+	if opts.RateLimit < 0 {
+		return fmt.Errorf("%w: negative rate limit %d", ErrSaveOptionsInvalid, opts.RateLimit)
+	}
+	return nil
+}
+
+// SaveWithOptions saves a stopped recording to a named recording file within the limits of opts.
+//
+// The recording is streamed to the file as by SaveToWriter. As the
+// library writes through a FIFO, throttling the copy to RateLimit also
+// slows the library, so the save does not saturate the disk of a loaded
+// host; the recording is held in memory meanwhile, as for any stopped
+// recording.
+//
+// If the save has not completed after Timeout, ErrSaveTimeout is returned.
+// The library has no way to abort a save, so the rest of the recording is
+// read and dropped in the background without throttling, and the partial
+// file removed. Until then the recording context may not be saved again,
+// and Discard waits for the save to finish, as for SaveAsyncContext.
+func (context *RecordingContext) SaveWithOptions(filename string, opts SaveOptions) (SaveStats, error) {
+	err := opts.Validate()
+	if err != nil {
+		return SaveStats{}, err
+	}
+	if !context.valid {
+		return SaveStats{}, ErrRecordingContextDiscarded
+	}
+
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = now().Add(opts.Timeout)
+	}
+	newWriter := func(w io.Writer) (io.WriteCloser, error) {
+		return &throttledWriter{w: w, rate: opts.RateLimit, deadline: deadline, start: now()}, nil
+	}
+	if opts.Timeout == 0 {
+		return context.saveThrough(filename, "", newWriter)
+	}
+
+	type saveResult struct {
+		stats SaveStats
+		err   error
+	}
+	done := make(chan saveResult, 1)
+	go func() {
+		stats, err := context.saveThrough(filename, "", newWriter)
+		done <- saveResult{stats, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.stats, res.err
+	case <-currentClock().After(opts.Timeout):
+		abandoned := make(chan struct{})
+		context.abandoned = abandoned
+		go func() {
+			<-done
+			close(abandoned)
+		}()
+		return SaveStats{}, ErrSaveTimeout
+	}
+}
+
+// throttledWriter writes to w at no more than rate bytes per second, if
+// rate is non-zero, failing with ErrSaveTimeout once deadline has passed,
+// if it is non-zero.
+type throttledWriter struct {
+	w        io.Writer
+	rate     int64
+	deadline time.Time
+	start    time.Time
+	written  int64
+}
+
+func (t *throttledWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		if !t.deadline.IsZero() && !now().Before(t.deadline) {
+			return n, ErrSaveTimeout
+		}
+		chunk := p
+		if limit := t.chunkSize(); int64(len(chunk)) > limit {
+			chunk = chunk[:limit]
+		}
+		m, err := t.w.Write(chunk)
+		n += m
+		t.written += int64(m)
+		p = p[m:]
+		if err != nil {
+			return n, err
+		}
+		if t.rate > 0 {
+			due := t.start.Add(time.Duration(float64(t.written) / float64(t.rate) * float64(time.Second)))
+			if wait := due.Sub(now()); wait > 0 {
+				<-currentClock().After(wait)
+			}
+		}
+	}
+	return n, nil
+}
+
+// chunkSize returns the most bytes to write at once.
+func (t *throttledWriter) chunkSize() int64 {
+	if t.rate <= 0 {
+		return 1 << 62
+	}
+	if chunk := t.rate / throttleChunks; chunk > 0 {
+		return chunk
+	}
+	return 1
+}
+
+func (t *throttledWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// advancingClock is a Clock whose timers fire at once, advancing the time
+// by their duration.
+type advancingClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *advancingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *advancingClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestSaveOptionsValidate(t *testing.T) {
+	for _, opts := range []SaveOptions{{Timeout: -time.Second}, {RateLimit: -1}} {
+		if err := opts.Validate(); !errors.Is(err, ErrSaveOptionsInvalid) {
+			t.Errorf("Validate(%+v) = %v, want ErrSaveOptionsInvalid", opts, err)
+		}
+	}
+	opts := SaveOptions{Timeout: time.Minute, RateLimit: 1 << 20}
+	if err := opts.Validate(); err != nil {
+		t.Error("Validate:", err)
+	}
+
+	_, err := (&RecordingContext{valid: true}).SaveWithOptions("recording.undo", SaveOptions{RateLimit: -1})
+	if !errors.Is(err, ErrSaveOptionsInvalid) {
+		t.Error("Expected SaveWithOptions() to validate options:", err)
+	}
+	_, err = (&RecordingContext{}).SaveWithOptions("recording.undo", SaveOptions{})
+	if err != ErrRecordingContextDiscarded {
+		t.Error("Expected SaveWithOptions() to fail for a discarded context:", err)
+	}
+}
+
+func TestThrottledWriter(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := &advancingClock{now: start}
+	SetClock(clock)
+	defer SetClock(nil)
+
+	var buf bytes.Buffer
+	w := &throttledWriter{w: &buf, rate: 100, start: start}
+	n, err := w.Write(make([]byte, 1000))
+	if n != 1000 || err != nil {
+		t.Fatal("Write:", n, err)
+	}
+	if elapsed := clock.Now().Sub(start); elapsed != 10*time.Second {
+		t.Fatal("Expected writing 1000 bytes at 100 bytes/s to take 10s, took", elapsed)
+	}
+	if buf.Len() != 1000 {
+		t.Fatal("Unexpected bytes written:", buf.Len())
+	}
+
+	w = &throttledWriter{w: &buf, rate: 100, start: clock.Now(), deadline: clock.Now().Add(time.Second)}
+	n, err = w.Write(make([]byte, 1000))
+	if err != ErrSaveTimeout {
+		t.Fatal("Expected write to time out:", err)
+	}
+	if n != 100 {
+		t.Fatal("Expected a second's worth of bytes before the timeout, wrote", n)
+	}
+
+	w = &throttledWriter{w: &buf, start: clock.Now()}
+	before := clock.Now()
+	if n, err := w.Write(make([]byte, 1<<20)); n != 1<<20 || err != nil {
+		t.Fatal("Unthrottled write:", n, err)
+	}
+	if !clock.Now().Equal(before) {
+		t.Fatal("Unthrottled write waited")
+	}
+}
+
+func TestSaveWithOptions(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	recContext, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer recContext.Discard()
+
+	stats, err := recContext.SaveWithOptions(filename, SaveOptions{Timeout: time.Minute, RateLimit: 1 << 30})
+	if err != nil {
+		t.Fatal("SaveWithOptions:", err)
+	}
+	if stats.Filename != filename || stats.BytesWritten == 0 {
+		t.Fatal("Unexpected stats:", stats)
+	}
+
+	verifyRecording(t, filename)
+}
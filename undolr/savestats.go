@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"time"
+)
+
+// SaveStats describes a completed save of a recording.
+type SaveStats struct {
+	// Filename is the name of the recording file written.
+	Filename string
+
+	// BytesWritten is the size of the recording file once saved.
+	BytesWritten int64
+
+	// Duration is the wall time taken by the save.
+	Duration time.Duration
+
+	// StopTheWorld is the time for which all threads of the process
+	// were stopped by the save. This is zero for asynchronous saves.
+	StopTheWorld time.Duration
+
+	// SymbolsIncluded reports whether symbol files were included in the
+	// recording (see IncludeSymbolFiles).
+	SymbolsIncluded bool
+
+	// CompressionRatio is the size of the recording divided by
+	// BytesWritten for a compressed or encrypted save, so is greater
+	// than one if compression reduced its size. It is zero for other
+	// saves.
+	CompressionRatio float64
+
+	// SHA256 is the hex encoded SHA-256 checksum of the recording file
+	// written, if enabled by SaveChecksums. For a compressed or encrypted
+	// save it is the checksum of the compressed or encrypted file, and
+	// for SaveToWriter that of the data written.
+	SHA256 string
+}
+
+func newSaveStats(filename string, start time.Time, symbols bool) SaveStats {
+	stats := SaveStats{
+		Filename:        filename,
+		Duration:        since(start),
+		SymbolsIncluded: symbols,
+	}
+	if fileinfo, err := os.Stat(filename); err == nil {
+		stats.BytesWritten = fileinfo.Size()
+	}
+	return stats
+}
+
+// saveComplete records the statistics for an asynchronous save the first
+// time its completion is observed.
+func (context *RecordingContext) saveComplete() {
+	if context.saveStats != nil {
+		return
+	}
+	stats := newSaveStats(context.saveFilename, context.saveStart, context.saveSymbols)
+	context.saveStats = &stats
+}
+
+// SaveStats returns statistics for the last asynchronous save of the recording context.
+//
+// The save must have been observed to complete, either by Poll reporting
+// completion or by SaveBackground returning.
+func (context *RecordingContext) SaveStats() (stats SaveStats, err error) {
+	if !context.valid {
+		err = ErrRecordingContextDiscarded
+		return
+	}
+	if !context.saving {
+		err = ErrRecordingContextSaveNotStarted
+		return
+	}
+	if context.saveStats == nil {
+		err = ErrRecordingContextSaveIncomplete
+		return
+	}
+	return *context.saveStats, nil
+}
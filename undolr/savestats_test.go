@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSaveWithStats(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	stats, err := SaveWithStats(filename)
+	if err != nil {
+		t.Fatal("SaveWithStats:", err)
+	}
+
+	err = StopAndDiscard()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+
+	verifyRecording(t, filename)
+
+	size, _ := fileSize(filename)
+	if stats.BytesWritten != size {
+		t.Fatalf("BytesWritten doesn't match (%d vs %d)", stats.BytesWritten, size)
+	}
+	if stats.StopTheWorld != stats.Duration {
+		t.Fatalf("StopTheWorld doesn't match Duration (%v vs %v)",
+			stats.StopTheWorld, stats.Duration)
+	}
+	if !stats.SymbolsIncluded {
+		t.Fatal("Symbols not reported as included by default")
+	}
+}
+
+func TestAsyncSaveStats(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	_, err = context.SaveStats()
+	if err != ErrRecordingContextSaveNotStarted {
+		t.Fatal("Expected SaveStats() to fail before saving:", err)
+	}
+
+	ch := make(chan error, 1)
+
+	go context.SaveBackground(filename, ch)
+	select {
+	case err = <-ch:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second * 30):
+		t.Fatal("Save hadn't completed after 30 seconds")
+	}
+
+	stats, err := context.SaveStats()
+	if err != nil {
+		t.Fatal("SaveStats:", err)
+	}
+
+	size, _ := fileSize(filename)
+	if stats.BytesWritten != size {
+		t.Fatalf("BytesWritten doesn't match (%d vs %d)", stats.BytesWritten, size)
+	}
+	if stats.StopTheWorld != 0 {
+		t.Fatal("Unexpected StopTheWorld time for asynchronous save:", stats.StopTheWorld)
+	}
+}
+
+func TestSaveBackgroundFailed(t *testing.T) {
+	filename := unwritableFilename(t)
+
+	var phases []SavePhase
+	SetSaveHook(func(event SaveEvent) {
+		phases = append(phases, event.Phase)
+	})
+	defer SetSaveHook(nil)
+
+	err := Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	ch := make(chan error, 1)
+	go context.SaveBackground(filename, ch)
+	select {
+	case err = <-ch:
+		if err == nil {
+			t.Fatal("Expected SaveBackground() to fail saving to a missing directory")
+		}
+	case <-time.After(time.Second * 30):
+		t.Fatal("Save hadn't completed after 30 seconds")
+	}
+
+	_, err = context.SaveStats()
+	if err == nil {
+		t.Fatal("Expected SaveStats() to fail after a failed save")
+	}
+	for _, phase := range phases {
+		if phase == SaveCompleted {
+			t.Fatal("Failed save reported as completed:", phases)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io"
+	"os"
+)
+
+// A newWriterFunc wraps a writer in one transforming the recording, such
+// as a compressor.
+type newWriterFunc func(w io.Writer) (io.WriteCloser, error)
+
+// writeThrough creates filename and writes to it through the writer
+// returned by newWriter using write, returning the size of the file. The
+// file is removed if any step fails. The file is written atomically if
+// enabled by SetAtomicSave.
+func writeThrough(filename string, newWriter newWriterFunc, write func(w io.Writer) error) (int64, error) {
+	target, mode := atomicTarget(filename)
+	f, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+	tw, err := newWriter(f)
+	if err == nil {
+		err = write(tw)
+		if closeErr := tw.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	var size int64
+	if err == nil {
+		var fileinfo os.FileInfo
+		fileinfo, err = f.Stat()
+		if err == nil {
+			size = fileinfo.Size()
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return 0, err
+	}
+	err = commitSave(target, filename, mode)
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// saveThrough saves synchronously to filename, then writes the recording
+// through newWriter to filename with extension appended, and removes the
+// original.
+func saveThrough(filename, extension string, newWriter newWriterFunc) (stats SaveStats, err error) {
+	filename, err = resolvePath(filename, SaveKindSync)
+	if err != nil {
+		return
+	}
+	output := filename + extension
+
+	start := now()
+	stats, started, err := saveSync(filename)
+	if started {
+		notifySave(SaveEvent{Phase: SaveStarted, Kind: SaveKindSync, Filename: output})
+		defer func() {
+			if err == nil {
+				notifySave(SaveEvent{Phase: SaveCompleted, Kind: SaveKindSync, Filename: output, Stats: stats})
+			} else {
+				notifySave(SaveEvent{Phase: SaveFailed, Kind: SaveKindSync, Filename: output, Err: err})
+			}
+		}()
+	}
+	if err != nil {
+		return
+	}
+	defer os.Remove(filename)
+
+	size, err := writeThrough(output, newWriter, func(w io.Writer) error {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	stats.Filename = output
+	stats.CompressionRatio = compressionRatio(stats.BytesWritten, size)
+	stats.BytesWritten = size
+	stats.Duration = since(start)
+	addChecksum(&stats)
+	return stats, nil
+}
+
+// saveThrough saves a stopped recording, streaming it through newWriter
+// to filename with extension appended.
+func (context *RecordingContext) saveThrough(filename, extension string, newWriter newWriterFunc) (stats SaveStats, err error) {
+	filename, err = resolvePath(filename, SaveKindAsync)
+	if err != nil {
+		return
+	}
+	output := filename + extension
+
+	started := false
+	size, err := writeThrough(output, newWriter, func(w io.Writer) error {
+		var err error
+		stats, started, err = context.saveToWriter(w, output)
+		return err
+	})
+	if err == nil {
+		stats.CompressionRatio = compressionRatio(stats.BytesWritten, size)
+		stats.BytesWritten = size
+		stats.SHA256 = ""
+		addChecksum(&stats)
+	}
+	if started {
+		context.finishStreamSave(stats, err)
+	}
+	return stats, err
+}
+
+// compressionRatio returns the ratio of the size of a recording to the
+// size written for it, or zero if nothing was written.
+func compressionRatio(size, written int64) float64 {
+	if written == 0 {
+		return 0
+	}
+	return float64(size) / float64(written)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestWriteThrough(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	identity := func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil }
+	size, err := writeThrough(filename, identity, func(w io.Writer) error {
+		_, err := io.WriteString(w, "recording")
+		return err
+	})
+	if err != nil {
+		t.Fatal("writeThrough:", err)
+	}
+	data, _ := ioutil.ReadFile(filename)
+	if size != 9 || string(data) != "recording" {
+		t.Fatalf("Unexpected contents %q (size %d)", data, size)
+	}
+
+	failed := errors.New("write failed")
+	_, err = writeThrough(filename, identity, func(w io.Writer) error { return failed })
+	if err != failed {
+		t.Fatal("Expected write error:", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatal("Partial file not removed:", err)
+	}
+}
+
+func TestCompressionRatio(t *testing.T) {
+	if ratio := compressionRatio(1000, 250); ratio != 4 {
+		t.Fatal("Unexpected ratio:", ratio)
+	}
+	if ratio := compressionRatio(1000, 0); ratio != 0 {
+		t.Fatal("Unexpected ratio for empty file:", ratio)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"sync"
+	"time"
+)
+
+// saveWithinMargin is the factor by which the estimated duration of a
+// synchronous save must fit within the deadline given to SaveWithin.
+const saveWithinMargin = 2
+
+var saveRateLock sync.Mutex
+
+// saveRate is the throughput of the last completed save in bytes per
+// second, or zero if none has completed.
+var saveRate float64
+
+// observeSaveRate records the throughput of a completed save.
+func observeSaveRate(event SaveEvent) {
+	if event.Phase != SaveCompleted || event.Stats.Duration <= 0 || event.Stats.BytesWritten <= 0 {
+		return
+	}
+	saveRateLock.Lock()
+	defer saveRateLock.Unlock()
+	saveRate = float64(event.Stats.BytesWritten) / event.Stats.Duration.Seconds()
+}
+
+// fitsWithin reports whether a synchronous save of up to size bytes is
+// expected to complete comfortably within d.
+func fitsWithin(d time.Duration, size int64) bool {
+	saveRateLock.Lock()
+	rate := saveRate
+	saveRateLock.Unlock()
+
+	if rate <= 0 || size <= 0 {
+		return false
+	}
+	estimate := time.Duration(float64(size) / rate * float64(time.Second))
+	return estimate*saveWithinMargin <= d
+}
+
+// SaveWithin saves the recording so far, returning within d.
+//
+// A synchronous save stops every thread in the process and cannot be
+// interrupted, so SaveWithin first estimates how long one would take from
+// the throughput of the last save and the size of the event log. If it
+// fits comfortably within d, the recording is saved as by Save.
+//
+// Otherwise, or if there is no previous save to estimate from, the save
+// is downgraded to an asynchronous one: recording is stopped, the history
+// saved with SaveAsync, and recording started again once the save has
+// begun, so the process only pauses briefly. History before the restart
+// is not available to later saves. If the save has not completed after d,
+// partial is true and the save continues in the background, the file
+// being complete once it finishes; its outcome is reported to the save
+// hook, see SetSaveHook. This suits request handlers which capture a
+// recording on error but must respond within a deadline.
+func SaveWithin(d time.Duration, filename string) (partial bool, err error) {
+	deadline := currentClock().After(d)
+
+	size, err := EventLogSizeGet()
+	if err == nil && fitsWithin(d, size) {
+		return false, Save(filename)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		return false, err
+	}
+
+	fd, err := context.GetSelectDescriptor()
+	if err == nil {
+		err = context.SaveAsync(filename)
+	}
+	if err != nil {
+		context.Discard()
+		Start()
+		return false, err
+	}
+	restartErr := Start()
+
+	done := make(chan error, 1)
+	go func() {
+		err := waitSelectDescriptor(fd)
+		if err == nil {
+			status := context.PollStatus()
+			err = status.Err
+			if err == nil && !status.Complete {
+				err = ErrRecordingContextSaveIncomplete
+			}
+		} else {
+			context.saveErr = err
+			context.reportSave()
+		}
+		context.Discard()
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+	case <-deadline:
+		partial = true
+	}
+	if err == nil {
+		err = restartErr
+	}
+	return partial, err
+}
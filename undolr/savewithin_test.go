@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFitsWithin(t *testing.T) {
+	defer func() { saveRate = 0 }()
+
+	saveRate = 0
+	if fitsWithin(time.Hour, 1) {
+		t.Error("Expected no estimate without a previous save")
+	}
+
+	// 100MB/s.
+	notifySave(SaveEvent{Phase: SaveCompleted, Stats: SaveStats{BytesWritten: 100e6, Duration: time.Second}})
+	for _, test := range []struct {
+		d        time.Duration
+		size     int64
+		expected bool
+	}{
+		{time.Second, 50e6, true},
+		{time.Second, 60e6, false},
+		{10 * time.Second, 500e6, true},
+		{time.Second, 0, false},
+	} {
+		if fits := fitsWithin(test.d, test.size); fits != test.expected {
+			t.Errorf("fitsWithin(%v, %d) = %v, expected %v", test.d, test.size, fits, test.expected)
+		}
+	}
+
+	notifySave(SaveEvent{Phase: SaveFailed})
+	notifySave(SaveEvent{Phase: SaveCompleted})
+	if !fitsWithin(time.Second, 50e6) {
+		t.Error("Save rate changed by save without statistics")
+	}
+}
+
+func TestSaveWithin(t *testing.T) {
+	err := Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+	defer StopAndDiscard()
+
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	partial, err := SaveWithin(time.Minute, filename)
+	if err != nil {
+		t.Fatal("SaveWithin:", err)
+	}
+	if partial {
+		t.Fatal("Save incomplete after a minute")
+	}
+	verifyRecording(t, filename)
+
+	if CurrentMode() != ModeRecording {
+		t.Fatal("Recording not restarted")
+	}
+}
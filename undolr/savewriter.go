@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+type copyResult struct {
+	n   int64
+	err error
+}
+
+// SaveToWriter saves a stopped recording, streaming it to w rather than to a named file.
+//
+// The library can only save to a named file, so the recording is written to
+// a FIFO in a temporary directory and copied to w as it is written. Nothing
+// is stored on disk, so recordings may be streamed to object storage, a
+// network connection or a compressor from hosts with little writable
+// storage. A synchronous save would stop the goroutine copying to w as
+// well as the library writing the FIFO, so only stopped recordings can be
+// saved this way.
+//
+// The number of bytes written to w is returned. If w returns an error the
+// rest of the recording is discarded so that the save can finish, and the
+// error is returned. The save hook is notified with an empty Filename.
+func (context *RecordingContext) SaveToWriter(w io.Writer) (n int64, err error) {
+	stats, started, err := context.saveToWriter(w, "")
+	if started {
+		context.finishStreamSave(stats, err)
+	}
+	return stats.BytesWritten, err
+}
+
+// saveToWriter streams a save to w, notifying the save hook that a save
+// to filename has started. If the save started, the caller reports its
+// outcome with finishStreamSave. BytesWritten is the number of bytes
+// written to w.
+func (context *RecordingContext) saveToWriter(w io.Writer, filename string) (stats SaveStats, started bool, err error) {
+	if !context.valid {
+		return stats, false, ErrRecordingContextDiscarded
+	}
+	if context.abandonedPending() {
+		return stats, false, ErrRecordingContextSaveAbandoned
+	}
+
+	dir, err := ioutil.TempDir("", "undolr")
+	if err != nil {
+		return stats, false, err
+	}
+	defer os.RemoveAll(dir)
+
+	fifo := filepath.Join(dir, "recording.undo")
+	err = mkfifo(fifo, 0600)
+	if err != nil {
+		return stats, false, &os.PathError{Op: "mkfifo", Path: fifo, Err: err}
+	}
+
+	// The read end is opened without blocking, and a write end held open
+	// until the save completes, so that the copy neither waits for the
+	// library to open the FIFO nor sees end of file before it does.
+	r, err := os.OpenFile(fifo, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return stats, false, err
+	}
+	defer r.Close()
+	hold, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+	if err != nil {
+		return stats, false, err
+	}
+	defer hold.Close()
+
+	h := sha256.New()
+	if checksumsEnabled() {
+		w = io.MultiWriter(w, h)
+	}
+
+	copied := make(chan copyResult, 1)
+	go func() {
+		n, err := io.Copy(w, r)
+		if err != nil {
+			io.Copy(ioutil.Discard, r)
+		}
+		copied <- copyResult{n, err}
+	}()
+
+	fd, err := context.GetSelectDescriptor()
+	if err == nil {
+		err = context.startSaveAsync(fifo)
+	}
+	if err != nil {
+		hold.Close()
+		<-copied
+		return stats, false, err
+	}
+	// The completion of the save is reported by finishStreamSave, once
+	// the size of the recording is known.
+	started = true
+	context.saveReported = true
+	notifySave(SaveEvent{Phase: SaveStarted, Kind: SaveKindAsync, Filename: filename})
+
+	res := context.waitSaveResult(fd, fifo)
+	hold.Close()
+	result := <-copied
+
+	err = res.Err
+	if err == nil {
+		err = result.err
+	}
+	stats = res.Stats
+	stats.Filename = filename
+	stats.BytesWritten = result.n
+	if checksumsEnabled() {
+		stats.SHA256 = hex.EncodeToString(h.Sum(nil))
+	}
+	return stats, true, err
+}
+
+// finishStreamSave records and reports the outcome of a save by saveToWriter.
+func (context *RecordingContext) finishStreamSave(stats SaveStats, err error) {
+	if err != nil {
+		context.saveErr = err
+		notifySave(SaveEvent{Phase: SaveFailed, Kind: SaveKindAsync, Filename: stats.Filename, Err: err})
+		return
+	}
+	context.saveStats = &stats
+	notifySave(SaveEvent{Phase: SaveCompleted, Kind: SaveKindAsync, Filename: stats.Filename, Stats: stats})
+}
@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return 0, errors.New("write failed")
+}
+
+func TestSaveToWriter(t *testing.T) {
+	_, err := (&RecordingContext{}).SaveToWriter(&bytes.Buffer{})
+	if err != ErrRecordingContextDiscarded {
+		t.Fatal("Expected discarded context to fail:", err)
+	}
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	context, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	defer context.Discard()
+
+	var buf bytes.Buffer
+	n, err := context.SaveToWriter(&buf)
+	if err != nil {
+		t.Fatal("SaveToWriter:", err)
+	}
+	if n != int64(buf.Len()) || n == 0 {
+		t.Fatalf("Unexpected size %d for %d bytes written", n, buf.Len())
+	}
+
+	stats, err := context.SaveStats()
+	if err != nil {
+		t.Fatal("SaveStats:", err)
+	}
+	if stats.BytesWritten != n || stats.Filename != "" {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+
+	var w failingWriter
+	_, err = context.SaveToWriter(&w)
+	if err == nil || err.Error() != "write failed" {
+		t.Fatal("Expected writer error:", err)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"strconv"
+)
+
+// procSelfFD is the directory through which descriptors of the process
+// are reopened.
+var procSelfFD = "/proc/self/fd"
+
+// SelectFile returns the select descriptor of the recording context as an *os.File.
+//
+// The file becomes readable when the associated save completes, as
+// described for GetSelectDescriptor. Unlike the raw descriptor it is
+// non-blocking and managed by the Go runtime, so it supports
+// SetReadDeadline and can be read from a goroutine without holding an
+// operating system thread:
+//
+//	f, err := context.SelectFile()
+//	...
+//	f.SetReadDeadline(time.Now().Add(30 * time.Second))
+//	_, err = f.Read(make([]byte, 1))
+//
+// The file refers to the same pipe as the descriptor, so reading from it
+// consumes the notification: do not also use SaveBackground or Wait for the
+// same save. The file is closed by Discard, and may be closed earlier by
+// the caller.
+func (context *RecordingContext) SelectFile() (*os.File, error) {
+	fd, err := context.GetSelectDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	f, err := openSelectFile(fd)
+	if err != nil {
+		return nil, err
+	}
+	context.selectFiles = append(context.selectFiles, f)
+	return f, nil
+}
+
+// openSelectFile opens fd afresh through /proc rather than duplicating it,
+// so the non-blocking mode the runtime sets on the file does not affect
+// the descriptor shared with the library.
+func openSelectFile(fd int) (*os.File, error) {
+	return os.OpenFile(procSelfFD+"/"+strconv.Itoa(fd), os.O_RDONLY, 0)
+}
+
+// closeSelectFiles closes the files returned by SelectFile.
+func (context *RecordingContext) closeSelectFiles() {
+	for _, f := range context.selectFiles {
+		f.Close()
+	}
+	context.selectFiles = nil
+}
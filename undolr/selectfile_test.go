@@ -0,0 +1,111 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestOpenSelectFile(t *testing.T) {
+	var fds [2]int
+	if err := syscall.Pipe(fds[:]); err != nil {
+		t.Fatal("Pipe:", err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+
+	f, err := openSelectFile(fds[0])
+	if err != nil {
+		t.Fatal("openSelectFile:", err)
+	}
+	defer f.Close()
+
+	f.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = f.Read(make([]byte, 1))
+	if !os.IsTimeout(err) {
+		t.Fatal("Expected read of an empty pipe to time out:", err)
+	}
+
+	if _, err := syscall.Write(fds[1], []byte{1}); err != nil {
+		t.Fatal("Write:", err)
+	}
+	f.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if n, err := f.Read(make([]byte, 1)); n != 1 || err != nil {
+		t.Fatal("Expected to read the notification:", n, err)
+	}
+
+	flags, _, errno := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fds[0]), syscall.F_GETFL, 0)
+	if errno != 0 {
+		t.Fatal("fcntl:", errno)
+	}
+	if flags&syscall.O_NONBLOCK != 0 {
+		t.Fatal("Expected the original descriptor to remain blocking")
+	}
+}
+
+func TestSelectFileDiscarded(t *testing.T) {
+	if _, err := (&RecordingContext{}).SelectFile(); err != ErrRecordingContextDiscarded {
+		t.Fatal("Expected SelectFile() to fail for a discarded context:", err)
+	}
+}
+
+func TestSelectFile(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	err = Start()
+	if err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	recContext, err := Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+
+	f, err := recContext.SelectFile()
+	if err != nil {
+		recContext.Discard()
+		t.Fatal("SelectFile:", err)
+	}
+
+	err = recContext.SaveAsync(filename)
+	if err != nil {
+		recContext.Discard()
+		t.Fatal("SaveAsync:", err)
+	}
+
+	f.SetReadDeadline(time.Now().Add(30 * time.Second))
+	if _, err := f.Read(make([]byte, 1)); err != nil {
+		recContext.Discard()
+		t.Fatal("Read:", err)
+	}
+	if status := recContext.PollStatus(); !status.Complete || status.Err != nil {
+		recContext.Discard()
+		t.Fatal("Expected save to be complete:", status)
+	}
+
+	err = recContext.Discard()
+	if err != nil {
+		t.Fatal("Discard:", err)
+	}
+	if _, err := f.Read(make([]byte, 1)); err == nil {
+		t.Fatal("Expected select file to be closed by Discard")
+	}
+
+	verifyRecording(t, filename)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// ErrShmemSessionNotFound indicates JoinShmemSession found no shared memory log in the environment.
+var ErrShmemSessionNotFound = errors.New("no shared memory log session in environment")
+
+// ErrShmemSessionClosed indicates a ShmemSession was used after Close.
+var ErrShmemSessionClosed = errors.New("shared memory log session closed")
+
+// A ShmemSession logs the shared memory accesses of a process and the workers it starts to a common log.
+//
+// The library requires the shared memory log to be set in each process
+// before it starts recording, or fails with EINVAL. A ShmemSession applies
+// the settings in the order required, and passes them to workers in the
+// environment, so the documented sequence becomes:
+//
+//	session, err := undolr.NewShmemSession("workers.shmem", 0)
+//	...
+//	// Create the shared maps, then start each worker:
+//	cmd := session.Command("worker")
+//	...
+//	err = session.Start()
+//
+// with each worker calling:
+//
+//	session, err := undolr.JoinShmemSession()
+//	...
+//	err = session.Start()
+//
+// Go programs cannot fork without exec, so maps must be ones each worker
+// can map again, such as files or descriptors inherited through
+// exec.Cmd.ExtraFiles. Workers may instead use StartOptionsFromEnv, which
+// reads the same variables.
+type ShmemSession struct {
+	filename string
+	size     int64
+	owner    bool
+	closed   bool
+}
+
+// NewShmemSession sets a new shared memory log for this process and the workers it starts.
+//
+// The filename must have a .shmem extension; a relative filename is made
+// absolute. Any existing log of that name is removed, as a log is not
+// discarded between runs. A size of zero selects the library default.
+// The process must not be recording yet.
+func NewShmemSession(filename string, size int64) (*ShmemSession, error) {
+	opts := StartOptions{ShmemLogFilename: filename, ShmemLogSize: size}
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+	filename, err = normalizeFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Remove(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	session := &ShmemSession{filename: filename, size: size, owner: true}
+	err = session.apply()
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// JoinShmemSession sets the shared memory log passed by the process which started this one.
+//
+// The log is read from EnvShmemLog and EnvShmemLogSize, as set by
+// ShmemSession.Env, and ErrShmemSessionNotFound returned if none is set.
+// The process must not be recording yet.
+func JoinShmemSession() (*ShmemSession, error) {
+	opts, err := StartOptionsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if opts.ShmemLogFilename == "" {
+		return nil, ErrShmemSessionNotFound
+	}
+
+	session := &ShmemSession{filename: opts.ShmemLogFilename, size: opts.ShmemLogSize}
+	err = session.apply()
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// apply sets the shared memory log of the session, which must happen
+// before recording starts.
+func (s *ShmemSession) apply() error {
+	lock.Lock()
+	alreadyRecording := recording
+	lock.Unlock()
+	if alreadyRecording {
+		return ErrAlreadyRecording
+	}
+
+	err := ShmemLogFilenameSet(s.filename)
+	if err != nil {
+		return err
+	}
+	return ShmemLogSizeSet(s.size)
+}
+
+// Filename returns the absolute path of the shared memory log.
+func (s *ShmemSession) Filename() string {
+	return s.filename
+}
+
+// Env returns the environment variables passing the session to a worker.
+func (s *ShmemSession) Env() []string {
+	return []string{
+		EnvShmemLog + "=" + s.filename,
+		EnvShmemLogSize + "=" + strconv.FormatInt(s.size, 10),
+	}
+}
+
+// Command returns a command to run a worker joining the session, as by exec.Command.
+//
+// The worker inherits the environment of this process, with the session
+// added.
+func (s *ShmemSession) Command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), s.Env()...)
+	return cmd
+}
+
+// Start starts recording this process, logging its shared memory accesses to the session's log.
+func (s *ShmemSession) Start() error {
+	if s.closed {
+		return ErrShmemSessionClosed
+	}
+	return Start()
+}
+
+// Close stops logging shared memory accesses when this process next starts recording.
+//
+// The process must not be recording. The log is kept, as recordings made
+// in the session refer to it; see Remove.
+func (s *ShmemSession) Close() error {
+	if s.closed {
+		return ErrShmemSessionClosed
+	}
+	lock.Lock()
+	alreadyRecording := recording
+	lock.Unlock()
+	if alreadyRecording {
+		return ErrAlreadyRecording
+	}
+
+	err := ShmemLogFilenameClear()
+	if err != nil {
+		return err
+	}
+	s.closed = true
+	return nil
+}
+
+// Remove closes the session if needed, and removes the log if this process created it.
+//
+// Recordings made in the session no longer show shared memory accesses by
+// other processes once the log is removed, so it should only be called
+// when they are no longer needed, or have been archived with the log.
+func (s *ShmemSession) Remove() error {
+	if !s.closed {
+		err := s.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if !s.owner {
+		return nil
+	}
+	err := os.Remove(s.filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewShmemSessionInvalid(t *testing.T) {
+	if _, err := NewShmemSession("accesses.log", 0); !errors.Is(err, ErrStartOptionsInvalid) {
+		t.Error("Expected a filename without .shmem extension to be rejected:", err)
+	}
+	if _, err := NewShmemSession("accesses.shmem", -1); !errors.Is(err, ErrStartOptionsInvalid) {
+		t.Error("Expected a negative size to be rejected:", err)
+	}
+}
+
+func TestShmemSessionEnv(t *testing.T) {
+	session := &ShmemSession{filename: "/var/tmp/workers.shmem", size: 1 << 20}
+
+	cmd := session.Command("worker")
+	env := cmd.Env[len(cmd.Env)-2:]
+	if env[0] != EnvShmemLog+"=/var/tmp/workers.shmem" || env[1] != EnvShmemLogSize+"=1048576" {
+		t.Fatal("Unexpected worker environment:", env)
+	}
+
+	defer os.Unsetenv(EnvShmemLog)
+	defer os.Unsetenv(EnvShmemLogSize)
+	for _, kv := range session.Env() {
+		i := strings.IndexByte(kv, '=')
+		os.Setenv(kv[:i], kv[i+1:])
+	}
+	opts, err := StartOptionsFromEnv()
+	if err != nil {
+		t.Fatal("StartOptionsFromEnv:", err)
+	}
+	if opts.ShmemLogFilename != session.filename || opts.ShmemLogSize != session.size {
+		t.Fatal("Worker would see a different session:", opts)
+	}
+}
+
+func TestJoinShmemSessionNotFound(t *testing.T) {
+	os.Unsetenv(EnvShmemLog)
+	if _, err := JoinShmemSession(); err != ErrShmemSessionNotFound {
+		t.Fatal("Expected no session without the environment:", err)
+	}
+}
+
+func TestShmemSessionClosed(t *testing.T) {
+	session := &ShmemSession{filename: "/var/tmp/workers.shmem", closed: true}
+	if err := session.Start(); err != ErrShmemSessionClosed {
+		t.Fatal("Expected Start() to fail after Close:", err)
+	}
+	if err := session.Close(); err != ErrShmemSessionClosed {
+		t.Fatal("Expected Close() to fail after Close:", err)
+	}
+}
+
+func TestShmemSession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr-shmem")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "workers.shmem")
+	if err := ioutil.WriteFile(filename, []byte("stale"), 0644); err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	session, err := NewShmemSession(filename, 0)
+	if err != nil {
+		t.Fatal("NewShmemSession:", err)
+	}
+	if got, err := ShmemLogFilenameGet(); err != nil || got != filename {
+		t.Fatal("Unexpected shmem log filename:", got, err)
+	}
+
+	err = session.Start()
+	if err != nil {
+		session.Remove()
+		t.Fatal("Start:", err)
+	}
+	if err := session.Close(); err != ErrAlreadyRecording {
+		t.Error("Expected Close() to fail while recording:", err)
+	}
+	err = StopAndDiscard()
+	if err != nil {
+		t.Fatal("StopAndDiscard:", err)
+	}
+
+	err = session.Remove()
+	if err != nil {
+		t.Fatal("Remove:", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatal("Expected the log to be removed:", err)
+	}
+}
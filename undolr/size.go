@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// A set of error codes returned when parsing sizes.
+var (
+	ErrSizeInvalid    = errors.New("invalid size")
+	ErrSizeOutOfRange = errors.New("size out of range")
+)
+
+// sizeUnits maps lower case unit suffixes to their multipliers.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a size in bytes, such as "512MB" or "2GiB".
+//
+// The number may have a fractional part, and be followed by an optional
+// unit: B, or KB, MB, GB and TB for powers of 1000, or KiB, MiB, GiB and
+// TiB for powers of 1024. A single letter K, M, G or T is taken as a
+// power of 1024. Units are case-insensitive and may be separated from the
+// number by spaces. The size must be a whole number of bytes.
+//
+// Errors wrap ErrSizeInvalid if s cannot be parsed, or ErrSizeOutOfRange
+// if the size is negative or too large.
+func ParseSize(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	split := strings.IndexFunc(trimmed, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+')
+	})
+	if split < 0 {
+		split = len(trimmed)
+	}
+	number := trimmed[:split]
+	unit := strings.ToLower(strings.TrimSpace(trimmed[split:]))
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("%q: %w", s, ErrSizeInvalid)
+	}
+	value, ok := new(big.Rat).SetString(number)
+	if !ok || strings.ContainsAny(number, "/eE") {
+		return 0, fmt.Errorf("%q: %w", s, ErrSizeInvalid)
+	}
+	if value.Sign() < 0 {
+		return 0, fmt.Errorf("%q: %w: negative", s, ErrSizeOutOfRange)
+	}
+
+	value.Mul(value, new(big.Rat).SetInt64(multiplier))
+	if !value.IsInt() {
+		return 0, fmt.Errorf("%q: %w: not a whole number of bytes", s, ErrSizeInvalid)
+	}
+	if value.Num().Cmp(big.NewInt(math.MaxInt64)) > 0 {
+		return 0, fmt.Errorf("%q: %w: too large", s, ErrSizeOutOfRange)
+	}
+	return value.Num().Int64(), nil
+}
+
+// EventLogSizeSetString sets the maximum size for the event log from a size such as "512MB".
+//
+// See ParseSize for the format. The size must not be zero.
+func EventLogSizeSetString(s string) error {
+	size, err := ParseSize(s)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return fmt.Errorf("%q: %w: event log size must not be zero", s, ErrSizeOutOfRange)
+	}
+	return EventLogSizeSet(size)
+}
+
+// ShmemLogSizeSetString sets the maximum shared memory log access size from a size such as "16MiB".
+//
+// See ParseSize for the format. A size of zero selects the default.
+func ShmemLogSizeSetString(s string) error {
+	size, err := ParseSize(s)
+	if err != nil {
+		return err
+	}
+	return ShmemLogSizeSet(size)
+}
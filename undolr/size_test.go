@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		size int64
+	}{
+		{"0", 0},
+		{"1048576", 1048576},
+		{"100B", 100},
+		{"512MB", 512000000},
+		{"512mb", 512000000},
+		{"2GiB", 2 << 30},
+		{"2 GiB", 2 << 30},
+		{" 16M ", 16 << 20},
+		{"1.5KiB", 1536},
+		{"0.5k", 512},
+		{"8TiB", 8 << 40},
+		{"9223372036854775807", 1<<63 - 1},
+	} {
+		size, err := ParseSize(test.s)
+		if err != nil {
+			t.Errorf("ParseSize(%q): %v", test.s, err)
+		} else if size != test.size {
+			t.Errorf("ParseSize(%q) doesn't match (%d vs %d)", test.s, size, test.size)
+		}
+	}
+
+	for _, s := range []string{"", "MB", "12XB", "1..5MB", "1/2GB", "1e6", "0.1B", "1.0001KB", "1 2MB"} {
+		if _, err := ParseSize(s); !errors.Is(err, ErrSizeInvalid) {
+			t.Errorf("ParseSize(%q): expected ErrSizeInvalid, got %v", s, err)
+		}
+	}
+
+	for _, s := range []string{"-1", "-2GiB", "8388608TiB", "9223372036854775808"} {
+		if _, err := ParseSize(s); !errors.Is(err, ErrSizeOutOfRange) {
+			t.Errorf("ParseSize(%q): expected ErrSizeOutOfRange, got %v", s, err)
+		}
+	}
+}
+
+func TestEventLogSizeSetString(t *testing.T) {
+	if err := EventLogSizeSetString("0MB"); !errors.Is(err, ErrSizeOutOfRange) {
+		t.Error("Expected ErrSizeOutOfRange for zero size, got", err)
+	}
+	if err := EventLogSizeSetString("lots"); !errors.Is(err, ErrSizeInvalid) {
+		t.Error("Expected ErrSizeInvalid, got", err)
+	}
+	if err := ShmemLogSizeSetString("16 MiB!"); !errors.Is(err, ErrSizeInvalid) {
+		t.Error("Expected ErrSizeInvalid, got", err)
+	}
+
+	size, err := EventLogSizeGet()
+	if err != nil {
+		t.Fatal("EventLogSizeGet:", err)
+	}
+	defer EventLogSizeSet(size)
+
+	err = EventLogSizeSetString("64MiB")
+	if err != nil {
+		t.Fatal("EventLogSizeSetString:", err)
+	}
+	newSize, err := EventLogSizeGet()
+	if err != nil {
+		t.Fatal("EventLogSizeGet:", err)
+	} else if newSize != 64<<20 {
+		t.Fatalf("Size doesn't match (%d vs %d)", newSize, 64<<20)
+	}
+}
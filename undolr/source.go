@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// SourceInfo identifies the source the recorded program was built from.
+//
+// It is read from the build information embedded by the Go toolchain. The
+// version control fields are only recorded by Go 1.18 and later, when
+// building in a checkout; see "go help buildvcs".
+type SourceInfo struct {
+	// Module and Version are the path and version of the main module.
+	Module  string `json:"module,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// VCS is the version control system, such as "git", and Revision the
+	// commit built. Modified reports uncommitted changes in the checkout,
+	// in which case the source at Revision is not exactly that built.
+	VCS      string `json:"vcs,omitempty"`
+	Revision string `json:"revision,omitempty"`
+	Time     string `json:"time,omitempty"`
+	Modified bool   `json:"modified,omitempty"`
+
+	// ArchiveURL locates an archive of the source, set with
+	// SetSourceArchiveURL.
+	ArchiveURL string `json:"archive_url,omitempty"`
+}
+
+var sourceLock sync.Mutex
+var sourceArchiveURL string
+
+// SetSourceArchiveURL sets the URL of an archive of the program's source, recorded in SourceInfo.
+//
+// The URL may contain "{revision}" and "{module}", replaced by the values
+// from the build information, for instance
+// "https://github.com/org/service/archive/{revision}.tar.gz". The
+// undosource command fetches the archive next to a recording. Archives
+// must be gzipped tar or zip files.
+func SetSourceArchiveURL(url string) {
+	sourceLock.Lock()
+	defer sourceLock.Unlock()
+	sourceArchiveURL = url
+}
+
+// ReadSourceInfo returns the source information for the running program, or nil if it has no build information.
+func ReadSourceInfo() *SourceInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	s := &SourceInfo{
+		Module:  info.Main.Path,
+		Version: info.Main.Version,
+	}
+	applyBuildSettings(s, buildSettings(info))
+
+	sourceLock.Lock()
+	url := sourceArchiveURL
+	sourceLock.Unlock()
+	if url != "" {
+		s.ArchiveURL = strings.NewReplacer("{revision}", s.Revision, "{module}", s.Module).Replace(url)
+	}
+	return s
+}
+
+// applyBuildSettings fills in the version control fields from the build settings.
+func applyBuildSettings(s *SourceInfo, settings map[string]string) {
+	s.VCS = settings["vcs"]
+	s.Revision = settings["vcs.revision"]
+	s.Time = settings["vcs.time"]
+	s.Modified = settings["vcs.modified"] == "true"
+}
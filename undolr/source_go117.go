@@ -0,0 +1,21 @@
+//go:build !go1.18
+// +build !go1.18
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"runtime/debug"
+)
+
+// buildSettings returns the settings recorded in the build information,
+// which are only available from Go 1.18.
+func buildSettings(info *debug.BuildInfo) map[string]string {
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"runtime/debug"
+)
+
+// buildSettings returns the settings recorded in the build information.
+func buildSettings(info *debug.BuildInfo) map[string]string {
+	settings := make(map[string]string, len(info.Settings))
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+	return settings
+}
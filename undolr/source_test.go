@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"testing"
+)
+
+func TestApplyBuildSettings(t *testing.T) {
+	var s SourceInfo
+	applyBuildSettings(&s, map[string]string{
+		"vcs":          "git",
+		"vcs.revision": "0123456789abcdef",
+		"vcs.time":     "2026-01-02T03:04:05Z",
+		"vcs.modified": "true",
+	})
+	want := SourceInfo{VCS: "git", Revision: "0123456789abcdef", Time: "2026-01-02T03:04:05Z", Modified: true}
+	if s != want {
+		t.Errorf("source = %+v, want %+v", s, want)
+	}
+
+	s = SourceInfo{}
+	applyBuildSettings(&s, nil)
+	if s != (SourceInfo{}) {
+		t.Errorf("source without settings = %+v, want empty", s)
+	}
+}
+
+func TestReadSourceInfo(t *testing.T) {
+	defer SetSourceArchiveURL("")
+
+	s := ReadSourceInfo()
+	if s == nil {
+		t.Skip("no build information")
+	}
+	if s.ArchiveURL != "" {
+		t.Errorf("archive URL = %q, want none", s.ArchiveURL)
+	}
+
+	SetSourceArchiveURL("https://example.com/{module}/archive/{revision}.tar.gz")
+	s = ReadSourceInfo()
+	want := "https://example.com/" + s.Module + "/archive/" + s.Revision + ".tar.gz"
+	if s.ArchiveURL != want {
+		t.Errorf("archive URL = %q, want %q", s.ArchiveURL, want)
+	}
+}
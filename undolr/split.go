@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// A set of error codes returned by SplitRecording and JoinRecording.
+var (
+	ErrSplitPartSizeInvalid = errors.New("part size not valid")
+	ErrSplitChecksum        = errors.New("checksum mismatch")
+	ErrSplitManifestInvalid = errors.New("split manifest not valid")
+)
+
+// SplitManifestSuffix is appended to a recording file name to name the manifest written by SplitRecording.
+const SplitManifestSuffix = ".parts.json"
+
+// SplitPart describes one part of a split recording.
+type SplitPart struct {
+	// File is the part's file name, relative to the manifest.
+	File   string `json:"file"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// SplitManifest describes how to reassemble a split recording.
+type SplitManifest struct {
+	// Name is the base name of the original recording file.
+	Name   string      `json:"name"`
+	Size   int64       `json:"size"`
+	SHA256 string      `json:"sha256"`
+	Parts  []SplitPart `json:"parts"`
+}
+
+// SplitRecording splits a saved recording in to parts of at most partSize bytes, returning the manifest path.
+//
+// The parts are written alongside the recording, named by appending
+// ".part000", ".part001" and so on, together with a JSON manifest named by
+// appending SplitManifestSuffix. The manifest records the size and
+// SHA-256 checksum of each part and of the whole recording so
+// JoinRecording can verify the reassembly. The original recording is left
+// in place.
+func SplitRecording(filename string, partSize int64) (manifestFile string, err error) {
+	if partSize <= 0 {
+		return "", ErrSplitPartSizeInvalid
+	}
+
+	in, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	manifest := SplitManifest{Name: filepath.Base(filename)}
+	whole := sha256.New()
+
+	for i := 0; ; i++ {
+		part := SplitPart{File: fmt.Sprintf("%s.part%03d", manifest.Name, i)}
+		part.Size, part.SHA256, err = writePart(filepath.Join(filepath.Dir(filename), part.File),
+			io.TeeReader(io.LimitReader(in, partSize), whole))
+		if err != nil {
+			return "", err
+		}
+		if part.Size == 0 && i > 0 {
+			os.Remove(filepath.Join(filepath.Dir(filename), part.File))
+			break
+		}
+		manifest.Parts = append(manifest.Parts, part)
+		manifest.Size += part.Size
+		if part.Size < partSize {
+			break
+		}
+	}
+	manifest.SHA256 = hex.EncodeToString(whole.Sum(nil))
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	manifestFile = filename + SplitManifestSuffix
+	err = ioutil.WriteFile(manifestFile, append(data, '\n'), 0644)
+	if err != nil {
+		return "", err
+	}
+	return manifestFile, nil
+}
+
+func writePart(filename string, r io.Reader) (size int64, sum string, err error) {
+	out, err := os.Create(filename)
+	if err != nil {
+		return 0, "", err
+	}
+	h := sha256.New()
+	size, err = io.Copy(io.MultiWriter(out, h), r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filename)
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadSplitManifest reads a manifest written by SplitRecording.
+func ReadSplitManifest(manifestFile string) (*SplitManifest, error) {
+	data, err := ioutil.ReadFile(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var manifest SplitManifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", manifestFile, ErrSplitManifestInvalid, err)
+	}
+	for _, part := range manifest.Parts {
+		if part.File != filepath.Base(part.File) {
+			return nil, fmt.Errorf("%s: %w: part %q outside manifest directory",
+				manifestFile, ErrSplitManifestInvalid, part.File)
+		}
+	}
+	return &manifest, nil
+}
+
+// JoinRecording reassembles a split recording described by manifestFile in to output.
+//
+// The parts are read from the manifest's directory. Every part, and the
+// whole recording, is checked against the manifest; on any mismatch
+// ErrSplitChecksum is returned and output is removed.
+func JoinRecording(manifestFile, output string) (err error) {
+	manifest, err := ReadSplitManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+		}
+	}()
+
+	dir := filepath.Dir(manifestFile)
+	whole := sha256.New()
+	var size int64
+	for _, part := range manifest.Parts {
+		err = joinPart(io.MultiWriter(out, whole), filepath.Join(dir, part.File), part)
+		if err != nil {
+			return err
+		}
+		size += part.Size
+	}
+
+	if size != manifest.Size || hex.EncodeToString(whole.Sum(nil)) != manifest.SHA256 {
+		return fmt.Errorf("%s: %w", manifest.Name, ErrSplitChecksum)
+	}
+	return nil
+}
+
+func joinPart(w io.Writer, filename string, part SplitPart) error {
+	in, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, h), in)
+	if err != nil {
+		return err
+	}
+	if size != part.Size || hex.EncodeToString(h.Sum(nil)) != part.SHA256 {
+		return fmt.Errorf("%s: %w", part.File, ErrSplitChecksum)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitRecording(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		size  int
+		parts int
+	}{
+		{"empty", 0, 1},
+		{"small", 10, 1},
+		{"exact", 300, 3},
+		{"partial", 301, 4},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "undolr_test_")
+			if err != nil {
+				t.Fatal("TempDir:", err)
+			}
+			defer os.RemoveAll(dir)
+
+			data := make([]byte, test.size)
+			rand.Read(data)
+			filename := filepath.Join(dir, "recording.undo")
+			err = ioutil.WriteFile(filename, data, 0644)
+			if err != nil {
+				t.Fatal("WriteFile:", err)
+			}
+
+			manifestFile, err := SplitRecording(filename, 100)
+			if err != nil {
+				t.Fatal("SplitRecording:", err)
+			}
+
+			manifest, err := ReadSplitManifest(manifestFile)
+			if err != nil {
+				t.Fatal("ReadSplitManifest:", err)
+			}
+			if len(manifest.Parts) != test.parts || manifest.Size != int64(test.size) {
+				t.Fatalf("Unexpected manifest: %+v", manifest)
+			}
+			for _, part := range manifest.Parts {
+				if part.Size > 100 {
+					t.Fatalf("Part too large: %+v", part)
+				}
+			}
+
+			joined := filepath.Join(dir, "joined.undo")
+			err = JoinRecording(manifestFile, joined)
+			if err != nil {
+				t.Fatal("JoinRecording:", err)
+			}
+			result, err := ioutil.ReadFile(joined)
+			if err != nil {
+				t.Fatal("ReadFile:", err)
+			}
+			if !bytes.Equal(result, data) {
+				t.Fatal("Joined recording differs from original")
+			}
+		})
+	}
+}
+
+func TestJoinRecordingCorrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "recording.undo")
+	err = ioutil.WriteFile(filename, bytes.Repeat([]byte("x"), 250), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	_, err = SplitRecording(filename, 0)
+	if err != ErrSplitPartSizeInvalid {
+		t.Fatal("Expected zero part size to fail:", err)
+	}
+
+	manifestFile, err := SplitRecording(filename, 100)
+	if err != nil {
+		t.Fatal("SplitRecording:", err)
+	}
+
+	err = ioutil.WriteFile(filename+".part001", bytes.Repeat([]byte("y"), 100), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	joined := filepath.Join(dir, "joined.undo")
+	err = JoinRecording(manifestFile, joined)
+	if !errors.Is(err, ErrSplitChecksum) {
+		t.Fatal("Expected corrupt part to fail:", err)
+	}
+	if _, err := os.Stat(joined); !os.IsNotExist(err) {
+		t.Fatal("Output not removed after failure:", err)
+	}
+
+	err = ioutil.WriteFile(manifestFile, []byte(`{"parts":[{"file":"../x"}]}`), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+	err = JoinRecording(manifestFile, joined)
+	if !errors.Is(err, ErrSplitManifestInvalid) {
+		t.Fatal("Expected part outside directory to fail:", err)
+	}
+}
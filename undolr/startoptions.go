@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ErrStartOptionsInvalid indicates StartOptions failed validation.
+//
+// Errors returned by StartOptions.Validate wrap this value.
+var ErrStartOptionsInvalid = errors.New("invalid start options")
+
+// ErrAlreadyRecording indicates recording was requested while the process is already being recorded.
+var ErrAlreadyRecording = errors.New("already recording")
+
+// StartOptions bundles the settings applied by StartWithOptions.
+//
+// The zero value starts recording with the library defaults.
+type StartOptions struct {
+	// EventLogSize is the maximum size of the event log in bytes, or zero
+	// for the current setting. See EventLogSizeSet.
+	EventLogSize int64
+
+	// ExcludeSymbolFiles omits symbol files from saved recordings. See
+	// IncludeSymbolFiles.
+	ExcludeSymbolFiles bool
+
+	// ShmemLogFilename is the file in which to log shared memory accesses,
+	// or empty to not log them. It must have a .shmem extension. See
+	// ShmemLogFilenameSet.
+	ShmemLogFilename string
+
+	// ShmemLogSize is the maximum size of the shared memory log in bytes,
+	// or zero for the default. See ShmemLogSizeSet.
+	ShmemLogSize int64
+
+	// SaveOnTermination is a recording file to save if the process
+	// terminates while being recorded, or empty for none. See
+	// SaveOnTermination.
+	SaveOnTermination string
+}
+
+// Validate checks the options for errors which would otherwise only be reported part way through StartWithOptions.
+func (opts *StartOptions) Validate() error {
+	if opts.EventLogSize < 0 {
+		return fmt.Errorf("%w: negative event log size %d", ErrStartOptionsInvalid, opts.EventLogSize)
+	}
+	if opts.ShmemLogSize < 0 {
+		return fmt.Errorf("%w: negative shmem log size %d", ErrStartOptionsInvalid, opts.ShmemLogSize)
+	}
+	if opts.ShmemLogFilename != "" && filepath.Ext(opts.ShmemLogFilename) != ".shmem" {
+		return fmt.Errorf("%w: shmem log filename %q must have a .shmem extension",
+			ErrStartOptionsInvalid, opts.ShmemLogFilename)
+	}
+	if opts.ShmemLogSize != 0 && opts.ShmemLogFilename == "" {
+		return fmt.Errorf("%w: shmem log size given without a shmem log filename", ErrStartOptionsInvalid)
+	}
+	return nil
+}
+
+// StartWithOptions validates and applies the options, then starts recording the process.
+//
+// The settings are applied in the order the library requires: those which
+// must be made before recording starts, such as the shared memory log,
+// then Start, then SaveOnTermination. If any step fails recording is not
+// left running.
+func StartWithOptions(opts StartOptions) error {
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	lock.Lock()
+	alreadyRecording := recording
+	lock.Unlock()
+	if alreadyRecording {
+		return ErrAlreadyRecording
+	}
+
+	if opts.EventLogSize != 0 {
+		err = EventLogSizeSet(opts.EventLogSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = IncludeSymbolFiles(!opts.ExcludeSymbolFiles)
+	if err != nil {
+		return err
+	}
+
+	if opts.ShmemLogFilename != "" {
+		err = ShmemLogFilenameSet(opts.ShmemLogFilename)
+		if err != nil {
+			return err
+		}
+		err = ShmemLogSizeSet(opts.ShmemLogSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = Start()
+	if err != nil {
+		return err
+	}
+
+	if opts.SaveOnTermination != "" {
+		err = SaveOnTermination(opts.SaveOnTermination)
+		if err != nil {
+			StopAndDiscard()
+			return err
+		}
+	}
+
+	return nil
+}
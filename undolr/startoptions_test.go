@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestStartOptionsValidate(t *testing.T) {
+	valid := []StartOptions{
+		{},
+		{EventLogSize: 1 << 30, ExcludeSymbolFiles: true},
+		{ShmemLogFilename: "/tmp/log.shmem", ShmemLogSize: 16777216},
+		{SaveOnTermination: "recording.undolr"},
+	}
+	for _, opts := range valid {
+		err := opts.Validate()
+		if err != nil {
+			t.Errorf("Validate(%+v): %v", opts, err)
+		}
+	}
+
+	invalid := []StartOptions{
+		{EventLogSize: -1},
+		{ShmemLogFilename: "/tmp/log.shmem", ShmemLogSize: -1},
+		{ShmemLogFilename: "/tmp/log"},
+		{ShmemLogSize: 16777216},
+	}
+	for _, opts := range invalid {
+		err := opts.Validate()
+		if !errors.Is(err, ErrStartOptionsInvalid) {
+			t.Errorf("Expected Validate(%+v) to fail, got %v", opts, err)
+		}
+	}
+}
+
+func TestStartWithOptions(t *testing.T) {
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	defer os.Remove(filename)
+
+	size, err := EventLogSizeGet()
+	if err != nil {
+		t.Fatal("EventLogSizeGet:", err)
+	}
+	defer EventLogSizeSet(size)
+	defer IncludeSymbolFiles(true)
+
+	err = StartWithOptions(StartOptions{
+		EventLogSize:       size * 2,
+		ExcludeSymbolFiles: true,
+	})
+	if err != nil {
+		t.Fatal("StartWithOptions:", err)
+	}
+
+	err = StartWithOptions(StartOptions{})
+	if err != ErrAlreadyRecording {
+		t.Fatal("Expected StartWithOptions() to fail while recording:", err)
+	}
+
+	newSize, err := EventLogSizeGet()
+	if err != nil {
+		t.Fatal("EventLogSizeGet:", err)
+	} else if newSize != size*2 {
+		t.Fatalf("Size doesn't match (%d vs %d)", newSize, size*2)
+	}
+
+	stats, err := SaveWithStats(filename)
+	if err != nil {
+		t.Fatal("SaveWithStats:", err)
+	}
+	if stats.SymbolsIncluded {
+		t.Fatal("Symbols included despite ExcludeSymbolFiles")
+	}
+
+	err = StopAndDiscard()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	verifyRecording(t, filename)
+}
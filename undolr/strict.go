@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var strictLock sync.Mutex
+var strictMode bool
+
+// ErrStrict indicates strict mode detected an inconsistency which would otherwise go unreported.
+//
+// Errors returned for inconsistencies are of type *StrictError and match
+// this value with errors.Is.
+var ErrStrict = errors.New("inconsistent result in strict mode")
+
+// A StrictError reports the library function whose result was found inconsistent in strict mode.
+type StrictError struct {
+	Function string
+	Detail   string
+}
+
+func (e *StrictError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Function, e.Detail, ErrStrict)
+}
+
+// Is reports whether target is ErrStrict.
+func (e *StrictError) Is(target error) bool {
+	return target == ErrStrict
+}
+
+// SetStrict controls whether inconsistent library results are reported as errors.
+//
+// By default a library call which fails without setting errno returns a
+// nil error, as do setters whose value does not take effect. In strict
+// mode:
+//
+//   - a failing library call always returns an error, a *StrictError if
+//     errno was not set;
+//   - EventLogSizeSet, ShmemLogFilenameSet, ShmemLogFilenameClear and
+//     ShmemLogSizeSet read the value back and return a *StrictError if it
+//     does not match, or the getter's error if it cannot be read. Sizes of
+//     zero select the library default, so are not read back;
+//   - saves which report success return a *StrictError if the recording
+//     file does not exist.
+//
+// IncludeSymbolFiles and SaveOnTermination have no getter so cannot be
+// verified. Strict mode is intended for bring-up, where failing fast
+// matters more than tolerating quirks of the library in use.
+func SetStrict(enable bool) {
+	strictLock.Lock()
+	defer strictLock.Unlock()
+	strictMode = enable
+}
+
+func strictEnabled() bool {
+	strictLock.Lock()
+	defer strictLock.Unlock()
+	return strictMode
+}
+
+// checkResult returns the error for a call to fn which returned rc, with
+// errno reported as err. A failure without errno is only an error in
+// strict mode.
+func checkResult(fn libFunction, rc int, err error) error {
+	if rc == 0 {
+		return nil
+	}
+	if err != nil {
+		logError("undolr: library call failed", "function", fn.String(), "rc", rc, "errno", err)
+		return newError(fn, 0, err)
+	}
+	logWarn("undolr: library call failed without setting errno", "function", fn.String(), "rc", rc)
+	if strictEnabled() {
+		return &StrictError{fn.String(), fmt.Sprintf("returned %d without setting errno", rc)}
+	}
+	return nil
+}
+
+// checkSaved returns a *StrictError in strict mode if the recording
+// reported saved by fn does not exist.
+func checkSaved(fn libFunction, filename string) error {
+	if !strictEnabled() {
+		return nil
+	}
+	if _, err := os.Stat(filename); err != nil {
+		return &StrictError{fn.String(), fmt.Sprintf("recording not found after save: %v", err)}
+	}
+	return nil
+}
+
+// checkReadBack returns a *StrictError in strict mode if the value read
+// back by getter, using get, differs from the value set by fn. It must
+// be called with libLock held.
+func checkReadBack(fn libFunction, getter libFunction, set interface{}, get func() (interface{}, error)) error {
+	if !strictEnabled() {
+		return nil
+	}
+	got, err := get()
+	if err != nil {
+		return err
+	}
+	if got != set {
+		return &StrictError{fn.String(), fmt.Sprintf("set %v but %s returned %v", set, getter, got)}
+	}
+	return nil
+}
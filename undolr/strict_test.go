@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestCheckResult(t *testing.T) {
+	defer SetStrict(false)
+
+	if err := checkResult(fnSave, 0, syscall.EINTR); err != nil {
+		t.Fatal("Unexpected error for success:", err)
+	}
+	if err := checkResult(fnSave, -1, syscall.ENOSPC); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatal("Expected errno, got", err)
+	}
+	if err := checkResult(fnSave, -1, nil); err != nil {
+		t.Fatal("Unexpected error outside strict mode:", err)
+	}
+
+	SetStrict(true)
+	if err := checkResult(fnSave, -1, syscall.ENOSPC); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatal("Expected errno in strict mode, got", err)
+	}
+	err := checkResult(fnSave, -1, nil)
+	if !errors.Is(err, ErrStrict) {
+		t.Fatal("Expected ErrStrict, got", err)
+	}
+	var strictErr *StrictError
+	if !errors.As(err, &strictErr) || strictErr.Function != "undolr_save" {
+		t.Fatal("Expected StrictError for undolr_save, got", err)
+	}
+}
+
+func TestCheckSaved(t *testing.T) {
+	defer SetStrict(false)
+
+	filename, err := tmpnam("")
+	if err != nil {
+		t.Fatal("Filename:", err)
+	}
+	os.Remove(filename)
+
+	if err := checkSaved(fnSave, filename); err != nil {
+		t.Fatal("Unexpected error outside strict mode:", err)
+	}
+
+	SetStrict(true)
+	if err := checkSaved(fnSave, filename); !errors.Is(err, ErrStrict) {
+		t.Fatal("Expected ErrStrict for missing recording, got", err)
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal("Create:", err)
+	}
+	f.Close()
+	defer os.Remove(filename)
+	if err := checkSaved(fnSave, filename); err != nil {
+		t.Fatal("Unexpected error for saved recording:", err)
+	}
+}
+
+func TestCheckReadBack(t *testing.T) {
+	defer SetStrict(false)
+
+	got := func(value interface{}, err error) func() (interface{}, error) {
+		return func() (interface{}, error) { return value, err }
+	}
+
+	if err := checkReadBack(fnEventLogSizeSet, fnEventLogSizeGet, int64(1024), got(int64(2048), nil)); err != nil {
+		t.Fatal("Unexpected error outside strict mode:", err)
+	}
+
+	SetStrict(true)
+	if err := checkReadBack(fnEventLogSizeSet, fnEventLogSizeGet, int64(1024), got(int64(1024), nil)); err != nil {
+		t.Fatal("Unexpected error for matching value:", err)
+	}
+	if err := checkReadBack(fnEventLogSizeSet, fnEventLogSizeGet, int64(1024), got(int64(2048), nil)); !errors.Is(err, ErrStrict) {
+		t.Fatal("Expected ErrStrict for mismatched value, got", err)
+	}
+	if err := checkReadBack(fnShmemLogFilenameSet, fnShmemLogFilenameGet, "a.shmem", got(nil, syscall.EINVAL)); err != syscall.EINVAL {
+		t.Fatal("Expected getter error, got", err)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// libFunction identifies a function of the UndoLR library. The values
+// must match the order of the functions in the C code behind
+// libFunctionPresent.
+type libFunction int
+
+const (
+	fnStart libFunction = iota
+	fnGetVersionString
+	fnStop
+	fnSave
+	fnSaveAsync
+	fnPollSavingProgress
+	fnGetSelectDescriptor
+	fnDiscard
+	fnSaveOnTermination
+	fnSaveOnTerminationCancel
+	fnEventLogSizeGet
+	fnEventLogSizeSet
+	fnIncludeSymbolFiles
+	fnShmemLogFilenameSet
+	fnShmemLogFilenameGet
+	fnShmemLogSizeSet
+	fnShmemLogSizeGet
+	numLibFunctions
+)
+
+var libFunctionNames = [numLibFunctions]string{
+	"undolr_start",
+	"undolr_get_version_string",
+	"undolr_stop",
+	"undolr_save",
+	"undolr_save_async",
+	"undolr_poll_saving_progress",
+	"undolr_get_select_descriptor",
+	"undolr_discard",
+	"undolr_save_on_termination",
+	"undolr_save_on_termination_cancel",
+	"undolr_event_log_size_get",
+	"undolr_event_log_size_set",
+	"undolr_include_symbol_files",
+	"undolr_shmem_log_filename_set",
+	"undolr_shmem_log_filename_get",
+	"undolr_shmem_log_size_set",
+	"undolr_shmem_log_size_get",
+}
+
+func (fn libFunction) String() string {
+	return libFunctionNames[fn]
+}
+
+// ErrNotSupportedByLibrary indicates the UndoLR library in use is too old to provide a function.
+//
+// Errors returned for missing functions are of type *NotSupportedError and
+// match this value with errors.Is.
+var ErrNotSupportedByLibrary = errors.New("not supported by the UndoLR library")
+
+// A NotSupportedError reports the library function missing from the UndoLR library in use.
+type NotSupportedError struct {
+	Function string
+}
+
+func (e *NotSupportedError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Function, ErrNotSupportedByLibrary)
+}
+
+// Is reports whether target is ErrNotSupportedByLibrary.
+func (e *NotSupportedError) Is(target error) bool {
+	return target == ErrNotSupportedByLibrary
+}
+
+// EnvLibrary is the UndoLR shared library to load when built with the undo_dlopen tag.
+//
+// It may be a path, or a name searched for as by dlopen. If unset, the
+// names in libraryNames are tried in turn.
+const EnvLibrary = "UNDOLR_LIBRARY"
+
+// libraryNames are the names of the UndoLR shared library tried in turn
+// when built with the undo_dlopen tag.
+var libraryNames = []string{"libundolr_pic_x64.so", "libundolr.so"}
+
+// ErrNotAvailable indicates the UndoLR library is not available to the process at all.
+//
+// Errors returned for an unavailable library are of type
+// *NotAvailableError, and also match ErrNotSupportedByLibrary.
+var ErrNotAvailable = errors.New("UndoLR library not available")
+
+// ErrUnsupportedPlatform indicates the package was built for a platform Live Recorder does not support.
+//
+// Live Recorder only runs on Linux, and the package needs cgo to call it.
+// Elsewhere the package builds with stubs, and functions which need the
+// library fail with a *NotAvailableError which also matches this value,
+// unless SetUnsupportedPlatformNoOp is used.
+var ErrUnsupportedPlatform = errors.New("platform not supported by Live Recorder")
+
+// A NotAvailableError reports a library function called while the UndoLR library is not available.
+type NotAvailableError struct {
+	Function string
+}
+
+func (e *NotAvailableError) Error() string {
+	if !platformSupported {
+		return fmt.Sprintf("%s: %v", e.Function, ErrUnsupportedPlatform)
+	}
+	return fmt.Sprintf("%s: %v", e.Function, ErrNotAvailable)
+}
+
+// Is reports whether target is ErrNotAvailable or ErrNotSupportedByLibrary,
+// or ErrUnsupportedPlatform when built for an unsupported platform.
+func (e *NotAvailableError) Is(target error) bool {
+	if target == ErrUnsupportedPlatform {
+		return !platformSupported
+	}
+	return target == ErrNotAvailable || target == ErrNotSupportedByLibrary
+}
+
+// libHas records which library functions are available, and libAvailable
+// whether any are. They are filled in on first use, loading the library
+// if built with the undo_dlopen tag, as the library cannot change once
+// loaded.
+var libHas [numLibFunctions]bool
+var libAvailable bool
+var libLoadErr error
+var libHasOnce sync.Once
+
+func libInit() {
+	libHasOnce.Do(func() {
+		libLoadErr = loadLibrary()
+		for i := range libHas {
+			libHas[i] = libFunctionPresent(libFunction(i))
+			libAvailable = libAvailable || libHas[i]
+		}
+	})
+}
+
+// unsupportedNoOp is set by SetUnsupportedPlatformNoOp.
+var unsupportedNoOp int32
+
+// SetUnsupportedPlatformNoOp controls whether functions silently do nothing on an unsupported platform.
+//
+// By default, functions which need the library fail with an error
+// matching ErrUnsupportedPlatform when the package is built for a
+// platform other than Linux, or without cgo. With noop set, those which
+// only control or query the recorder, such as Start, StopAndDiscard,
+// SaveOnTermination and the settings, succeed without doing anything, so
+// a program can call them unconditionally. Saving still fails, as there
+// is no recording to save. This has no effect on Linux, where a missing
+// library is reported as ErrNotAvailable.
+func SetUnsupportedPlatformNoOp(noop bool) {
+	var value int32
+	if noop {
+		value = 1
+	}
+	atomic.StoreInt32(&unsupportedNoOp, value)
+}
+
+// noOp reports whether fn should silently do nothing, rather than fail,
+// as the platform is unsupported. Functions producing a recording always
+// fail.
+func noOp(fn libFunction) bool {
+	if platformSupported || atomic.LoadInt32(&unsupportedNoOp) == 0 {
+		return false
+	}
+	switch fn {
+	case fnSave, fnSaveAsync, fnPollSavingProgress, fnGetSelectDescriptor:
+		return false
+	}
+	return true
+}
+
+// require returns a *NotAvailableError if the library is not available,
+// or a *NotSupportedError if fn is missing from it. On an unsupported
+// platform it returns nil if fn should do nothing, in which case the stub
+// called in its place succeeds.
+func require(fn libFunction) error {
+	libInit()
+	if !libAvailable {
+		if noOp(fn) {
+			return nil
+		}
+		return &NotAvailableError{fn.String()}
+	}
+	if !libHas[fn] {
+		return &NotSupportedError{fn.String()}
+	}
+	return nil
+}
+
+// Available reports whether the UndoLR library is available to the process.
+//
+// If it is not, every function which needs the library fails with an
+// error matching ErrNotAvailable. By default the library is linked when
+// building, so is only missing if it was not linked. Built with the
+// undo_dlopen tag, the library is instead loaded when first needed, so
+// the same binary runs on hosts with and without it installed; see
+// EnvLibrary.
+func Available() bool {
+	libInit()
+	return libAvailable
+}
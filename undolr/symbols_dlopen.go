@@ -0,0 +1,148 @@
+//go:build linux && cgo && undo_dlopen
+// +build linux,cgo,undo_dlopen
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+/*
+#cgo LDFLAGS: -ldl
+
+#include <dlfcn.h>
+#include <errno.h>
+#include <stddef.h>
+#include <stdlib.h>
+#include <undolr.h>
+
+// The library functions are looked up when the library is loaded, in the
+// order of libFunction, and defined here to call through the pointers
+// found, so the library is not needed when linking.
+static const char *undolr_go_names[] = {
+	"undolr_start",
+	"undolr_get_version_string",
+	"undolr_stop",
+	"undolr_save",
+	"undolr_save_async",
+	"undolr_poll_saving_progress",
+	"undolr_get_select_descriptor",
+	"undolr_discard",
+	"undolr_save_on_termination",
+	"undolr_save_on_termination_cancel",
+	"undolr_event_log_size_get",
+	"undolr_event_log_size_set",
+	"undolr_include_symbol_files",
+	"undolr_shmem_log_filename_set",
+	"undolr_shmem_log_filename_get",
+	"undolr_shmem_log_size_set",
+	"undolr_shmem_log_size_get",
+};
+
+#define UNDOLR_GO_NUM_FNS (sizeof(undolr_go_names) / sizeof(undolr_go_names[0]))
+
+static void *undolr_go_fns[UNDOLR_GO_NUM_FNS];
+
+static int undolr_go_dlopen(const char *path)
+{
+	void *handle = dlopen(path, RTLD_NOW | RTLD_LOCAL);
+	size_t i;
+
+	if (handle == NULL) {
+		return -1;
+	}
+	for (i = 0; i < UNDOLR_GO_NUM_FNS; i++) {
+		undolr_go_fns[i] = dlsym(handle, undolr_go_names[i]);
+	}
+	return 0;
+}
+
+static const char *undolr_go_dlerror(void)
+{
+	return dlerror();
+}
+
+static int undolr_go_has(int fn)
+{
+	return undolr_go_fns[fn] != NULL;
+}
+
+#define UNDOLR_GO_FORWARD(index, name, params, args) \
+	int name params \
+	{ \
+		int (*f) params = (int (*) params)undolr_go_fns[index]; \
+		if (f == NULL) { \
+			errno = ENOSYS; \
+			return -1; \
+		} \
+		return f args; \
+	}
+
+UNDOLR_GO_FORWARD(0, undolr_start, (undolr_error_t *error), (error))
+UNDOLR_GO_FORWARD(2, undolr_stop, (undolr_recording_context_t *context), (context))
+UNDOLR_GO_FORWARD(3, undolr_save, (const char *filename), (filename))
+UNDOLR_GO_FORWARD(4, undolr_save_async,
+	(undolr_recording_context_t context, const char *filename), (context, filename))
+UNDOLR_GO_FORWARD(5, undolr_poll_saving_progress,
+	(undolr_recording_context_t context, int *complete, int *progress, int *result),
+	(context, complete, progress, result))
+UNDOLR_GO_FORWARD(6, undolr_get_select_descriptor,
+	(undolr_recording_context_t context, int *fd), (context, fd))
+UNDOLR_GO_FORWARD(7, undolr_discard, (undolr_recording_context_t context), (context))
+UNDOLR_GO_FORWARD(8, undolr_save_on_termination, (const char *filename), (filename))
+UNDOLR_GO_FORWARD(9, undolr_save_on_termination_cancel, (void), ())
+UNDOLR_GO_FORWARD(10, undolr_event_log_size_get, (long *bytes), (bytes))
+UNDOLR_GO_FORWARD(11, undolr_event_log_size_set, (long bytes), (bytes))
+UNDOLR_GO_FORWARD(12, undolr_include_symbol_files, (int include), (include))
+UNDOLR_GO_FORWARD(13, undolr_shmem_log_filename_set, (const char *filename), (filename))
+UNDOLR_GO_FORWARD(14, undolr_shmem_log_filename_get, (const char **o_filename), (o_filename))
+UNDOLR_GO_FORWARD(15, undolr_shmem_log_size_set, (unsigned long max_size), (max_size))
+UNDOLR_GO_FORWARD(16, undolr_shmem_log_size_get, (unsigned long *o_max_size), (o_max_size))
+
+const char *undolr_get_version_string(void)
+{
+	const char *(*f)(void) = (const char *(*)(void))undolr_go_fns[1];
+
+	if (f == NULL) {
+		return NULL;
+	}
+	return f();
+}
+*/
+import "C"
+import (
+	"errors"
+	"os"
+	"unsafe"
+)
+
+// libLoader describes how the library was found, for InitReport.
+const libLoader = "dlopen"
+
+// loadLibrary loads the library named by EnvLibrary, or the first of
+// libraryNames found.
+func loadLibrary() error {
+	names := libraryNames
+	if name := os.Getenv(EnvLibrary); name != "" {
+		names = []string{name}
+	}
+
+	var err error
+	for _, name := range names {
+		cname := C.CString(name)
+		rc := C.undolr_go_dlopen(cname)
+		C.free(unsafe.Pointer(cname))
+		if rc == 0 {
+			return nil
+		}
+		err = errors.New(C.GoString(C.undolr_go_dlerror()))
+	}
+	return err
+}
+
+func libFunctionPresent(fn libFunction) bool {
+	return C.undolr_go_has(C.int(fn)) != 0
+}
@@ -0,0 +1,57 @@
+//go:build linux && cgo && !undo_dlopen
+// +build linux,cgo,!undo_dlopen
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+/*
+#include <stddef.h>
+#include <undolr.h>
+
+// The library functions are declared weak, so those missing from the
+// library in use resolve to NULL rather than failing to link.
+static int undolr_go_has(int fn)
+{
+	void *p = NULL;
+
+	switch (fn) {
+	case 0: p = (void *)undolr_start; break;
+	case 1: p = (void *)undolr_get_version_string; break;
+	case 2: p = (void *)undolr_stop; break;
+	case 3: p = (void *)undolr_save; break;
+	case 4: p = (void *)undolr_save_async; break;
+	case 5: p = (void *)undolr_poll_saving_progress; break;
+	case 6: p = (void *)undolr_get_select_descriptor; break;
+	case 7: p = (void *)undolr_discard; break;
+	case 8: p = (void *)undolr_save_on_termination; break;
+	case 9: p = (void *)undolr_save_on_termination_cancel; break;
+	case 10: p = (void *)undolr_event_log_size_get; break;
+	case 11: p = (void *)undolr_event_log_size_set; break;
+	case 12: p = (void *)undolr_include_symbol_files; break;
+	case 13: p = (void *)undolr_shmem_log_filename_set; break;
+	case 14: p = (void *)undolr_shmem_log_filename_get; break;
+	case 15: p = (void *)undolr_shmem_log_size_set; break;
+	case 16: p = (void *)undolr_shmem_log_size_get; break;
+	}
+	return p != NULL;
+}
+*/
+import "C"
+
+// libLoader describes how the library was found, for InitReport.
+const libLoader = "linked"
+
+// loadLibrary does nothing, as the library is linked when building.
+func loadLibrary() error {
+	return nil
+}
+
+func libFunctionPresent(fn libFunction) bool {
+	return C.undolr_go_has(C.int(fn)) != 0
+}
@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNotSupportedError(t *testing.T) {
+	var err error = &NotSupportedError{fnShmemLogSizeGet.String()}
+	if !errors.Is(err, ErrNotSupportedByLibrary) {
+		t.Fatal("Expected error to match ErrNotSupportedByLibrary")
+	}
+	if err.Error() != "undolr_shmem_log_size_get: not supported by the UndoLR library" {
+		t.Fatal("Unexpected error string", err)
+	}
+}
+
+func TestLibraryFunctions(t *testing.T) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	// The library the tests run against is expected to be complete.
+	for fn := libFunction(0); fn < numLibFunctions; fn++ {
+		err := require(fn)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNotAvailableError(t *testing.T) {
+	var err error = &NotAvailableError{fnStart.String()}
+	if !errors.Is(err, ErrNotAvailable) || !errors.Is(err, ErrNotSupportedByLibrary) {
+		t.Fatal("Expected error to match ErrNotAvailable and ErrNotSupportedByLibrary")
+	}
+	if errors.Is(err, ErrUnsupportedPlatform) != !platformSupported {
+		t.Fatal("Unexpected match of ErrUnsupportedPlatform", err)
+	}
+	if platformSupported && err.Error() != "undolr_start: UndoLR library not available" {
+		t.Fatal("Unexpected error string", err)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	if Available() {
+		t.Skip("UndoLR library available")
+	}
+	if err := Start(); !errors.Is(err, ErrNotAvailable) {
+		t.Fatal("Expected Start() to fail with ErrNotAvailable:", err)
+	}
+	if report := InitReport(); !report.Stub || report.Loader != libLoader {
+		t.Fatal("Unexpected report without the library:", report)
+	}
+}
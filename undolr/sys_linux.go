@@ -0,0 +1,41 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"syscall"
+)
+
+// setPtracerAny allows any process to ptrace this one, with
+// prctl(PR_SET_PTRACER, PR_SET_PTRACER_ANY).
+func setPtracerAny() error {
+	_, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetPtracer, prSetPtracerAny, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// diskSpaceAvailable returns the number of bytes available to unprivileged
+// users on the filesystem containing dir.
+func diskSpaceAvailable(dir string) (int64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(dir, &stat)
+	if err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+func mkfifo(path string, mode uint32) error {
+	return syscall.Mkfifo(path, mode)
+}
+
+func readFd(fd int, p []byte) (int, error) {
+	return syscall.Read(fd, p)
+}
@@ -0,0 +1,30 @@
+//go:build !linux
+// +build !linux
+
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+// The system calls in sys_linux.go are not available elsewhere. They are
+// only needed around recording, which cannot happen on other platforms.
+
+func setPtracerAny() error {
+	return ErrUnsupportedPlatform
+}
+
+func diskSpaceAvailable(dir string) (int64, error) {
+	return 0, ErrUnsupportedPlatform
+}
+
+func mkfifo(path string, mode uint32) error {
+	return ErrUnsupportedPlatform
+}
+
+func readFd(fd int, p []byte) (int, error) {
+	return 0, ErrUnsupportedPlatform
+}
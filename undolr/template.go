@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// A set of error codes returned when expanding recording name templates.
+var (
+	ErrTemplateUnknownToken = errors.New("unknown token in recording name template")
+	ErrTemplateUnterminated = errors.New("unterminated token in recording name template")
+)
+
+// templateSeq numbers the names expanded by ExpandTemplate.
+var templateSeq uint64
+
+// ExpandTemplate expands the tokens in a recording name template.
+//
+// The following tokens are replaced:
+//
+//	{hostname}   the host name
+//	{pid}        the process ID
+//	{timestamp}  the time of the save, in UTC, as 20060102T150405Z
+//	{unix}       the time of the save in seconds since the Unix epoch
+//	{date}       the date of the save, in UTC, as 2006-01-02
+//	{seq}        a sequence number, incremented for each expansion using it
+//	{kind}       the kind of save: sync, async or termination
+//	{id}         a new ID from the IDGenerator, see SetIDGenerator
+//	{session}    the session ID, see SessionID
+//
+// "{{" and "}}" produce literal braces. Names without tokens are returned
+// unchanged.
+func ExpandTemplate(name string, meta PathMetadata) (string, error) {
+	if !strings.ContainsAny(name, "{}") {
+		return name, nil
+	}
+
+	var b strings.Builder
+	for len(name) > 0 {
+		i := strings.IndexAny(name, "{}")
+		if i < 0 {
+			b.WriteString(name)
+			break
+		}
+		b.WriteString(name[:i])
+		name = name[i:]
+
+		switch {
+		case strings.HasPrefix(name, "{{"), strings.HasPrefix(name, "}}"):
+			b.WriteByte(name[0])
+			name = name[2:]
+			continue
+		case name[0] == '}':
+			return "", fmt.Errorf("%w: unmatched '}'", ErrTemplateUnterminated)
+		}
+
+		end := strings.IndexByte(name, '}')
+		if end < 0 {
+			return "", ErrTemplateUnterminated
+		}
+		value, err := expandToken(name[1:end], meta)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+		name = name[end+1:]
+	}
+	return b.String(), nil
+}
+
+func expandToken(token string, meta PathMetadata) (string, error) {
+	switch token {
+	case "hostname":
+		return os.Hostname()
+	case "pid":
+		return strconv.Itoa(meta.PID), nil
+	case "timestamp":
+		return meta.Time.UTC().Format("20060102T150405Z"), nil
+	case "unix":
+		return strconv.FormatInt(meta.Time.Unix(), 10), nil
+	case "date":
+		return meta.Time.UTC().Format("2006-01-02"), nil
+	case "seq":
+		return strconv.FormatUint(atomic.AddUint64(&templateSeq, 1), 10), nil
+	case "kind":
+		return meta.Kind.String(), nil
+	case "id":
+		return NewID(IDSnapshot)
+	case "session":
+		return SessionID()
+	default:
+		return "", fmt.Errorf("%w: {%s}", ErrTemplateUnknownToken, token)
+	}
+}
+
+// TemplateResolver returns a PathResolver expanding templates with ExpandTemplate.
+//
+// The expanded name is passed on to next, if it is not nil. Install it to
+// allow templates in every save, including SaveOnTermination:
+//
+//	undolr.SetPathResolver(undolr.TemplateResolver(undolr.DirectoryResolver("/var/lib/recordings")))
+//	err := undolr.Save("app-{hostname}-{pid}-{timestamp}.undo")
+func TemplateResolver(next PathResolver) PathResolver {
+	return PathResolverFunc(func(name string, meta PathMetadata) (string, error) {
+		name, err := ExpandTemplate(name, meta)
+		if err != nil || next == nil {
+			return name, err
+		}
+		return next.ResolvePath(name, meta)
+	})
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal("Hostname:", err)
+	}
+	meta := PathMetadata{
+		Kind: SaveKindTermination,
+		Time: time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		PID:  1234,
+	}
+
+	for _, test := range []struct {
+		template string
+		expected string
+	}{
+		{"plain.undo", "plain.undo"},
+		{"app-{hostname}-{pid}.undo", "app-" + hostname + "-1234.undo"},
+		{"{timestamp}-{unix}-{date}-{kind}", "20260304T050607Z-1772600767-2026-03-04-termination"},
+		{"{{literal}}", "{literal}"},
+	} {
+		name, err := ExpandTemplate(test.template, meta)
+		if err != nil {
+			t.Fatalf("ExpandTemplate(%q): %v", test.template, err)
+		}
+		if name != test.expected {
+			t.Errorf("ExpandTemplate(%q) = %q, expected %q", test.template, name, test.expected)
+		}
+	}
+
+	first, _ := ExpandTemplate("{seq}", meta)
+	second, _ := ExpandTemplate("{seq}", meta)
+	if first == second {
+		t.Fatal("Sequence number not incremented:", first, second)
+	}
+
+	for _, template := range []string{"{unknown}", "{pid", "pid}"} {
+		_, err = ExpandTemplate(template, meta)
+		if !errors.Is(err, ErrTemplateUnknownToken) && !errors.Is(err, ErrTemplateUnterminated) {
+			t.Errorf("Expected ExpandTemplate(%q) to fail: %v", template, err)
+		}
+	}
+}
+
+func TestTemplateResolver(t *testing.T) {
+	r := TemplateResolver(DirectoryResolver(os.TempDir()))
+	name, err := r.ResolvePath("rec-{pid}.undo", PathMetadata{PID: 42})
+	if err != nil {
+		t.Fatal("ResolvePath:", err)
+	}
+	if name != os.TempDir()+"/rec-42.undo" {
+		t.Fatal("Unexpected path:", name)
+	}
+}
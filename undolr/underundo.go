@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// procDir is read by UnderUndo, a variable so tests can substitute their
+// own files.
+var procDir = "/proc"
+
+// Prefixes of the names of the Undo processes which trace the process
+// when it is recorded by the standalone Live Recorder tool, and when it is
+// run in an UndoDB debugging session.
+var (
+	liveTracerNames   = []string{"live-record"}
+	replayTracerNames = []string{"udb", "undodb"}
+)
+
+// UnderUndo reports whether the process is already being recorded, or is running under UndoDB.
+//
+// live is true if the process is being recorded, either by this package
+// after Start, or externally by the Live Recorder tool, as with
+// "live-record ./program". Starting recording again would fail, so code
+// which records itself can check this first. replay is true if the
+// process is running in an UndoDB session, such as "udb ./program",
+// where its execution can be replayed.
+//
+// External recorders are detected by the name of the process tracing
+// this one, found from /proc, so neither is reported on other platforms.
+// Replayed execution sees what the program saw when first run, so the
+// result is the same whenever it is replayed.
+func UnderUndo() (live bool, replay bool) {
+	lock.Lock()
+	live = recording
+	lock.Unlock()
+
+	tracer := tracerName()
+	switch {
+	case hasAnyPrefix(tracer, liveTracerNames):
+		live = true
+	case hasAnyPrefix(tracer, replayTracerNames):
+		replay = true
+	}
+	return live, replay
+}
+
+// tracerName returns the name of the process tracing this one, or "" if
+// there is none or it cannot be found.
+func tracerName() string {
+	file, err := os.Open(procDir + "/self/status")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	pid := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "TracerPid:") {
+			pid = strings.TrimSpace(strings.TrimPrefix(line, "TracerPid:"))
+			break
+		}
+	}
+	if pid == "" || pid == "0" {
+		return ""
+	}
+
+	comm, err := ioutil.ReadFile(procDir + "/" + pid + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	if s == "" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
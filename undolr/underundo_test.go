@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUnderUndo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(path string) { procDir = path }(procDir)
+	procDir = dir
+
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := func(live, replay bool) {
+		t.Helper()
+		gotLive, gotReplay := UnderUndo()
+		if gotLive != live || gotReplay != replay {
+			t.Fatalf("UnderUndo() = %v, %v; expected %v, %v", gotLive, gotReplay, live, replay)
+		}
+	}
+
+	expect(false, false)
+	write("self/status", "Name:\tprogram\nState:\tR (running)\nTracerPid:\t0\n")
+	expect(false, false)
+
+	write("self/status", "Name:\tprogram\nTracerPid:\t1234\n")
+	write("1234/comm", "gdb\n")
+	expect(false, false)
+	write("1234/comm", "live-record\n")
+	expect(true, false)
+	write("1234/comm", "undodb-server_x\n")
+	expect(false, true)
+
+	setRecording(true, time.Now())
+	defer setRecording(false, time.Time{})
+	expect(true, true)
+}
@@ -9,30 +9,94 @@ SPDX-License-Identifier: BSD-3-Clause
 //
 // This allows an application to create an Undo Recording of itself running,
 // which can then be opened using the Undo Debugger (UndoDB).
+//
+// A library call which fails returns an *Error giving the function called,
+// its errno and, for Start, the reason for failure. This is a breaking
+// change from earlier versions, which returned the bare syscall.Errno:
+// comparisons such as err == syscall.EINVAL no longer match, and must be
+// written errors.Is(err, syscall.EINVAL), which matches both.
 package undolr
 
-// #include <undolr.h>
-// #include <stdlib.h>
-// #include <errno.h>
-import "C"
 import (
 	"errors"
 	"fmt"
+	"os"
 	"runtime"
 	"sync"
 	"syscall"
-	"unsafe"
+	"time"
 )
 
+// lock protects the package state, and is only held briefly: never
+// across a call to the library which may block.
 var lock sync.Mutex
 
+// libLock serializes library calls which change the state of the
+// recorder, such as starting, stopping, saving and changing settings.
+// Calls which only read state, such as GetVersionString and
+// EventLogSizeGet, do not take it, so are not held up by a long save. If
+// both are needed libLock is acquired first.
+var libLock sync.Mutex
+
+// includeSymbols mirrors the last value successfully passed to
+// IncludeSymbolFiles, so it can be reported in SaveStats.
+var includeSymbols = true
+
+// terminationFilename mirrors the filename armed by the last successful
+// call to SaveOnTermination, or "" if none is armed, as the library has
+// no getter.
+var terminationFilename string
+
+// recording is true between successful calls to Start and Stop.
+var recording bool
+
+// recordingStart is the time of the last successful call to Start.
+var recordingStart time.Time
+
+// stoppedContexts counts the RecordingContexts returned by Stop which
+// have not been discarded, and stoppedBytes totals their historyBytes.
+var stoppedContexts int
+var stoppedBytes int64
+
 // A RecordingContext provides access to a recording after recording has been stopped.
+//
+// Each RecordingContext must only be used by one goroutine at a time, but
+// independent contexts may be saved concurrently: only starting a save
+// is serialized with other library calls, and each save is waited for
+// separately. See SaveAll.
 type RecordingContext struct {
-	ctx    C.undolr_recording_context_t
+	ctx    libContext
 	valid  bool
 	saving bool
 	file   string
 	line   int
+
+	saveFilename string
+	saveStart    time.Time
+	saveSymbols  bool
+	saveStats    *SaveStats
+	saveErr      error
+	saveReported bool
+
+	start        time.Time
+	stop         time.Time
+	historyBytes int64
+
+	// tracked is true while the context is counted by MemoryStats.
+	tracked bool
+
+	// abandoned is closed once a save abandoned by SaveAsyncContext
+	// has completed in the background.
+	abandoned chan struct{}
+
+	// waiting receives the result of reading the select descriptor for
+	// a save being waited for by Wait, kept so a Wait cancelled by its
+	// context can be resumed.
+	waiting chan error
+
+	// selectFiles are the files returned by SelectFile, closed by
+	// Discard.
+	selectFiles []*os.File
 }
 
 // A set of error codes returned by methods handling recording contexts.
@@ -41,48 +105,17 @@ var (
 	ErrRecordingContextDiscarded      = errors.New("recording context already discarded")
 	ErrRecordingContextSaveNotStarted = errors.New("saving not yet started")
 	ErrSaveBackgroundReadFailed       = errors.New("failed to read when waiting for save")
+	ErrRecordingContextSaveIncomplete = errors.New("saving not yet complete")
+	ErrRecordingContextSaveAbandoned  = errors.New("abandoned save still in progress")
 )
 
-type undoLrError struct {
-	code  C.undolr_error_t
-	text  string
-	errno error
-	rc    int
-}
-
-func (e undoLrError) Error() string {
-	return fmt.Sprintf("%v; %s", e.errno, e.text)
-}
-
-func undoLrErrorWrap(rc int, errno error, code C.undolr_error_t) error {
+// startError returns the error for a call to undolr_start which returned
+// rc, with errno and code as reported.
+func startError(rc int, errno error, code ErrorCode) error {
 	if code == 0 && rc < 0 {
-		return syscall.Errno(-rc)
-	}
-
-	wrapped := undoLrError{
-		code:  code,
-		errno: errno,
-		rc:    rc,
-	}
-
-	switch code {
-	case C.undolr_error_NO_ATTACH_YAMA:
-		wrapped.text = "Failure to attach to the application process due to /proc/sys/kernel/yama/ptrace_scope."
-	case C.undolr_error_CANNOT_ATTACH:
-		wrapped.text = "Failure to attach to the application process."
-	case C.undolr_error_LIBRARY_SEARCH_FAILED:
-		wrapped.text = "Failed to find dynamic libraries used by application."
-	case C.undolr_error_CANNOT_RECORD:
-		wrapped.text = "Recording error."
-	case C.undolr_error_NO_THREAD_INFO:
-		wrapped.text = "Live Recorder was unable to find information about threads."
-	case C.undolr_error_PKEYS_IN_USE:
-		wrapped.text = "Use of Protection Keys was detected. This is not yet supported."
-	default:
-		wrapped.text = "Unknown error"
+		errno = syscall.Errno(-rc)
 	}
-
-	return wrapped
+	return newError(fnStart, code, errno)
 }
 
 // Start recording the process.
@@ -91,25 +124,39 @@ func undoLrErrorWrap(rc int, errno error, code C.undolr_error_t) error {
 //
 // The process must not already be being recorded, i.e. <Stop>
 // must have been called since any previous call to <Start>.
+//
+// A fingerprint of the environment is taken, available from
+// StartFingerprint, so it can be stored alongside recordings.
 func Start() error {
-	var undoError C.undolr_error_t
+	fingerprint := TakeFingerprint()
 
-	lock.Lock()
-	defer lock.Unlock()
+	libLock.Lock()
+	defer libLock.Unlock()
 
-	rc, errno := C.undolr_start(&undoError)
+	if err := require(fnStart); err != nil {
+		return err
+	}
+
+	rc, code, errno := libStart()
 	if rc != 0 {
-		return undoLrErrorWrap(int(rc), errno, undoError)
+		return startError(rc, errno, code)
 	}
 
+	lock.Lock()
+	defer lock.Unlock()
+	recording = true
+	recordingStart = now()
+	startFingerprint = fingerprint
+	degraded = nil
 	return nil
 }
 
 // GetVersionString returns the version string for the underlying UndoLR library.
 func GetVersionString() string {
-	lock.Lock()
-	defer lock.Unlock()
-	return C.GoString(C.undolr_get_version_string())
+	if require(fnGetVersionString) != nil {
+		return ""
+	}
+	return libGetVersionString()
 }
 
 // Stop recording the process, keeping it for later saving.
@@ -120,15 +167,29 @@ func GetVersionString() string {
 //
 // The returned RecordingContext must be later freed using Discard.
 func Stop() (context *RecordingContext, err error) {
-	var rc C.int
+	var rc int
 
 	context = &RecordingContext{}
 
-	lock.Lock()
-	defer lock.Unlock()
+	libLock.Lock()
+	defer libLock.Unlock()
+
+	err = require(fnStop)
+	if err != nil {
+		return nil, err
+	}
 
-	rc, err = C.undolr_stop(&context.ctx)
+	context.historyBytes = currentEventLogSize()
+	rc, err = libStop(&context.ctx)
 	if rc == 0 {
+		lock.Lock()
+		recording = false
+		context.start = recordingStart
+		stoppedContexts++
+		stoppedBytes += context.historyBytes
+		context.tracked = true
+		lock.Unlock()
+		context.stop = now()
 		context.valid = true
 		_, context.file, context.line, _ = runtime.Caller(1)
 		runtime.SetFinalizer(context, recordingContextFinalizer)
@@ -142,9 +203,12 @@ func Stop() (context *RecordingContext, err error) {
 
 func recordingContextFinalizer(context *RecordingContext) {
 	if context.valid {
-		lock.Lock()
-		defer lock.Unlock()
-		C.undolr_discard(context.ctx)
+		context.untrack()
+		libLock.Lock()
+		libDiscard(context.ctx)
+		libLock.Unlock()
+		context.notifyDiscard(DiscardLeaked)
+		logError("undolr: RecordingContext has not been Discarded", "file", context.file, "line", context.line)
 		panic(fmt.Sprintf("%s:%d: RecordingContext has not been Discarded",
 			context.file, context.line))
 	}
@@ -152,13 +216,32 @@ func recordingContextFinalizer(context *RecordingContext) {
 
 // StopAndDiscard stops the recording and immediately discards it.
 func StopAndDiscard() (err error) {
-	lock.Lock()
-	defer lock.Unlock()
-	rc, err := C.undolr_stop((*C.undolr_recording_context_t)(nil))
-	if rc == 0 {
-		err = nil
+	libLock.Lock()
+	err = require(fnStop)
+	if err != nil {
+		libLock.Unlock()
+		return
 	}
-	return
+
+	bytes := currentEventLogSize()
+	rc, err := libStop(nil)
+	if rc != 0 {
+		libLock.Unlock()
+		return checkResult(fnStop, rc, err)
+	}
+	lock.Lock()
+	recording = false
+	start := recordingStart
+	lock.Unlock()
+	libLock.Unlock()
+
+	notifyDiscard(DiscardEvent{
+		Reason: DiscardStopped,
+		Bytes:  bytes,
+		Start:  start,
+		Stop:   now(),
+	})
+	return nil
 }
 
 // Save recorded program history to a named recording file.
@@ -180,18 +263,90 @@ func StopAndDiscard() (err error) {
 // but may also overlap with previous recordings depending on the
 // size of the event log and how long the caller runs between calls.
 func Save(filename string) (err error) {
-	cstring := C.CString(filename)
-	defer C.free(unsafe.Pointer(cstring))
+	_, err = SaveWithStats(filename)
+	return
+}
 
-	lock.Lock()
-	defer lock.Unlock()
+// SaveWithStats behaves as Save, additionally returning statistics about the save.
+//
+// As all threads are stopped for the duration of a synchronous save the
+// StopTheWorld time reported is the same as the wall time taken.
+func SaveWithStats(filename string) (stats SaveStats, err error) {
+	filename, err = resolvePath(filename, SaveKindSync)
+	if err != nil {
+		return
+	}
+
+	started := false
+	defer func() {
+		if !started {
+			return
+		}
+		notifySave(SaveEvent{Phase: SaveStarted, Kind: SaveKindSync, Filename: filename})
+		if err == nil {
+			notifySave(SaveEvent{Phase: SaveCompleted, Kind: SaveKindSync, Filename: filename, Stats: stats})
+		} else {
+			notifySave(SaveEvent{Phase: SaveFailed, Kind: SaveKindSync, Filename: filename, Err: err})
+		}
+	}()
 
-	rc, err := C.undolr_save(cstring)
+	stats, started, err = saveSync(filename)
+	if err == nil {
+		addChecksum(&stats)
+	}
+	return
+}
 
+// saveSync saves to filename without resolving the path or notifying the
+// save hook. started reports whether the library was asked to save.
+func saveSync(filename string) (stats SaveStats, started bool, err error) {
+	err = preflightDiskSpace(filename)
+	if err != nil {
+		return
+	}
+
+	target, mode := atomicTarget(filename)
+
+	err = require(fnSave)
+	if err != nil {
+		return
+	}
+
+	lock.Lock()
+	symbols := includeSymbols
+	lock.Unlock()
+
+	// All threads, including any running a save hook, are stopped while
+	// saving, so SaveStarted is reported together with the outcome. Only
+	// libLock is held, so that Start and Stop wait for the save.
+	libLock.Lock()
+	start := now()
+	started = true
+	rc, err := libSave(target)
+	libLock.Unlock()
 	if rc != 0 {
+		err = checkResult(fnSave, rc, err)
+		if target != filename {
+			os.Remove(target)
+		}
 		return
 	}
-	return nil
+	err = checkSaved(fnSave, target)
+	if err != nil {
+		if target != filename {
+			os.Remove(target)
+		}
+		return
+	}
+
+	stats = newSaveStats(target, start, symbols)
+	stats.Filename = filename
+	stats.StopTheWorld = stats.Duration
+	err = commitSave(target, filename, mode)
+	if err != nil {
+		return
+	}
+	return stats, true, nil
 }
 
 // SaveAsync will save recorded program history to a named recording file.
@@ -206,22 +361,69 @@ func (context *RecordingContext) SaveAsync(filename string) (err error) {
 	if !context.valid {
 		return ErrRecordingContextDiscarded
 	}
+	if context.abandonedPending() {
+		return ErrRecordingContextSaveAbandoned
+	}
 
-	cstring := C.CString(filename)
-	defer C.free(unsafe.Pointer(cstring))
+	filename, err = resolvePath(filename, SaveKindAsync)
+	if err != nil {
+		return
+	}
 
-	lock.Lock()
-	defer lock.Unlock()
+	err = preflightDiskSpace(filename)
+	if err != nil {
+		return
+	}
 
-	rc, err := C.undolr_save_async(context.ctx, cstring)
-	if rc != 0 {
+	err = context.startSaveAsync(filename)
+	if err != nil {
+		return
+	}
+	notifySave(SaveEvent{Phase: SaveStarted, Kind: SaveKindAsync, Filename: filename})
+	return nil
+}
+
+// startSaveAsync starts an asynchronous save to filename, without
+// resolving the path or notifying the save hook.
+func (context *RecordingContext) startSaveAsync(filename string) (err error) {
+	err = checkFilename(filename)
+	if err != nil {
+		return
+	}
+
+	err = require(fnSaveAsync)
+	if err != nil {
 		return
 	}
+
+	libLock.Lock()
+	start := now()
+	rc, err := libSaveAsync(context.ctx, filename)
+	libLock.Unlock()
+	if rc != 0 {
+		return checkResult(fnSaveAsync, rc, err)
+	}
+
+	lock.Lock()
+	symbols := includeSymbols
+	lock.Unlock()
+
 	context.saving = true
+	context.saveFilename = filename
+	context.saveStart = start
+	context.saveSymbols = symbols
+	context.saveStats = nil
+	context.saveErr = nil
+	context.saveReported = false
+	context.waiting = nil
 	return nil
 }
 
 // Poll reports the status of the current SaveAsync operation.
+//
+// While the save is in progress, progress is the percentage completed or
+// ProgressUnknown. Once complete, result is zero on success or an errno
+// value otherwise. PollStatus reports the same with typed results.
 func (context *RecordingContext) Poll() (complete bool, progress int, result int, err error) {
 	if !context.valid {
 		err = ErrRecordingContextDiscarded
@@ -232,21 +434,34 @@ func (context *RecordingContext) Poll() (complete bool, progress int, result int
 		return
 	}
 
-	var cComplete, cProgress, cResult C.int
+	defer context.reportSave()
 
-	lock.Lock()
-	defer lock.Unlock()
-	rc, err := C.undolr_poll_saving_progress(context.ctx, &cComplete, &cProgress, &cResult)
+	err = require(fnPollSavingProgress)
+	if err != nil {
+		return
+	}
+
+	complete, progress, result, rc, err := libPollSavingProgress(context.ctx)
 
 	if rc != 0 {
+		err = checkResult(fnPollSavingProgress, rc, err)
 		return
 	}
 
-	complete = cComplete != 0
-	progress = int(cProgress)
-	result = int(cResult)
 	err = nil
 
+	if complete && result == 0 {
+		err = checkSaved(fnSaveAsync, context.saveFilename)
+		if err != nil {
+			os.Remove(context.saveFilename)
+			context.saveErr = err
+			return
+		}
+		context.saveComplete()
+	} else if complete {
+		context.saveErr = SaveResultCode(result).Err()
+	}
+
 	return
 }
 
@@ -256,23 +471,25 @@ func (context *RecordingContext) Poll() (complete bool, progress int, result int
 // be selected for read to wake up a thread.
 //
 // The file descriptor is closed and therefore becomes invalid when the corresponding
-// recording context is freed up via Discard.
+// recording context is freed up via Discard. SelectFile returns it as an
+// *os.File instead.
 func (context *RecordingContext) GetSelectDescriptor() (fd int, err error) {
 	if !context.valid {
 		err = ErrRecordingContextDiscarded
 		return
 	}
 
-	var cFd C.int
+	err = require(fnGetSelectDescriptor)
+	if err != nil {
+		return
+	}
 
-	lock.Lock()
-	defer lock.Unlock()
-	rc, err := C.undolr_get_select_descriptor(context.ctx, &cFd)
+	fd, rc, err := libGetSelectDescriptor(context.ctx)
 	if rc != 0 {
+		err = checkResult(fnGetSelectDescriptor, rc, err)
 		return
 	}
 
-	fd = int(cFd)
 	err = nil
 
 	return
@@ -280,7 +497,8 @@ func (context *RecordingContext) GetSelectDescriptor() (fd int, err error) {
 
 // SaveBackground saves a recording in the background.
 //
-// This writes an error code (or nil) to a channel upon completion.
+// This writes an error code (or nil) to a channel upon completion. To
+// start a save and wait for it separately, use SaveAsync and Wait.
 func (context *RecordingContext) SaveBackground(filename string, complete chan<- error) {
 	fd, err := context.GetSelectDescriptor()
 	if err != nil {
@@ -294,18 +512,37 @@ func (context *RecordingContext) SaveBackground(filename string, complete chan<-
 		return
 	}
 
+	complete <- context.saveOutcome(waitSelectDescriptor(fd))
+}
+
+// saveOutcome collects the outcome of an asynchronous save once waiting for
+// its select descriptor has returned err, recording it and notifying the
+// save hook. Only a save the library reports successful is complete.
+func (context *RecordingContext) saveOutcome(err error) error {
+	if err != nil {
+		context.saveErr = err
+		context.reportSave()
+		return err
+	}
+	status := context.PollStatus()
+	if status.Err == nil && !status.Complete {
+		return ErrRecordingContextSaveIncomplete
+	}
+	return status.Err
+}
+
+// waitSelectDescriptor blocks until the save associated with the select
+// descriptor fd completes.
+func waitSelectDescriptor(fd int) error {
 	data := make([]byte, 1, 1)
-	n, err := syscall.Read(fd, data)
+	n, err := readFd(fd, data)
 	if err != nil {
-		complete <- err
-		return
+		return err
 	}
 	if n != 1 {
-		complete <- ErrSaveBackgroundReadFailed
-		return
+		return ErrSaveBackgroundReadFailed
 	}
-
-	complete <- nil
+	return nil
 }
 
 // Discard recorded program history from memory.
@@ -313,18 +550,28 @@ func (context *RecordingContext) SaveBackground(filename string, complete chan<-
 // Recording state that is currently held in memory is freed, and may no
 // longer be saved.
 func (context *RecordingContext) Discard() (err error) {
+	return context.discard(DiscardExplicit)
+}
+
+func (context *RecordingContext) discard(reason DiscardReason) (err error) {
 	if !context.valid {
 		return ErrRecordingContextDiscarded
 	}
+	if context.abandoned != nil {
+		<-context.abandoned
+	}
 	context.valid = false
+	context.untrack()
+	context.closeSelectFiles()
 
-	lock.Lock()
-	defer lock.Unlock()
-
-	rc, err := C.undolr_discard(context.ctx)
+	libLock.Lock()
+	rc, err := libDiscard(context.ctx)
+	libLock.Unlock()
 	if rc != 0 {
-		return
+		return checkResult(fnDiscard, rc, err)
 	}
+
+	context.notifyDiscard(reason)
 	return nil
 }
 
@@ -332,71 +579,140 @@ func (context *RecordingContext) Discard() (err error) {
 //
 // If the program terminates in between calls to Start and Stop
 // the recorded history up to that time will be saved to a recording.
+// A relative filename is made absolute when this is called, so the
+// recording is saved there even if the working directory changes.
 func SaveOnTermination(filename string) (err error) {
-	cstring := C.CString(filename)
-	defer C.free(unsafe.Pointer(cstring))
+	filename, err = resolvePath(filename, SaveKindTermination)
+	if err != nil {
+		return
+	}
 
-	lock.Lock()
-	defer lock.Unlock()
+	libLock.Lock()
+	defer libLock.Unlock()
 
-	rc, err := C.undolr_save_on_termination(cstring)
-	if rc != 0 {
+	err = require(fnSaveOnTermination)
+	if err != nil {
 		return
 	}
+
+	rc, err := libSaveOnTermination(filename)
+	if rc != 0 {
+		return checkResult(fnSaveOnTermination, rc, err)
+	}
+
+	lock.Lock()
+	previous := terminationFilename
+	terminationFilename = filename
+	lock.Unlock()
+	if previous != "" && previous != filename {
+		logWarn("undolr: termination save replaced", "filename", filename, "previous", previous)
+	}
+	logInfo("undolr: termination save armed", "filename", filename)
 	return nil
 }
 
-// SaveOnTerminationCancel sancels any previous call to SaveOnTermination.
-func SaveOnTerminationCancel() (err error) {
+// SaveOnTerminationGet returns the recording filename armed by SaveOnTermination, or "" if none is armed.
+//
+// Components sharing a process can check this before arming their own
+// termination save, rather than replacing another's. Only calls made
+// through this package are known: a filename armed by other users of the
+// library in the process is not reported.
+func SaveOnTerminationGet() string {
 	lock.Lock()
 	defer lock.Unlock()
-	rc, err := C.undolr_save_on_termination_cancel()
-	if rc != 0 {
+	return terminationFilename
+}
+
+// SaveOnTerminationCancel cancels any previous call to SaveOnTermination.
+func SaveOnTerminationCancel() (err error) {
+	libLock.Lock()
+	defer libLock.Unlock()
+	err = require(fnSaveOnTerminationCancel)
+	if err != nil {
 		return
 	}
+
+	rc, err := libSaveOnTerminationCancel()
+	if rc != 0 {
+		return checkResult(fnSaveOnTerminationCancel, rc, err)
+	}
+
+	lock.Lock()
+	terminationFilename = ""
+	lock.Unlock()
+	logInfo("undolr: termination save cancelled")
 	return nil
 }
 
+// currentEventLogSize returns the event log size, or zero if unavailable.
+func currentEventLogSize() int64 {
+	if require(fnEventLogSizeGet) != nil {
+		return 0
+	}
+	size, rc, _ := libEventLogSizeGet()
+	if rc != 0 {
+		return 0
+	}
+	return size
+}
+
 // EventLogSizeGet retrieves the current maximum size for the event log.
 func EventLogSizeGet() (size int64, err error) {
-	var cBytes C.long
-
-	lock.Lock()
-	defer lock.Unlock()
+	err = require(fnEventLogSizeGet)
+	if err != nil {
+		return
+	}
 
-	rc, err := C.undolr_event_log_size_get(&cBytes)
+	size, rc, err := libEventLogSizeGet()
 	if rc != 0 {
-		return 0, err
+		return 0, checkResult(fnEventLogSizeGet, rc, err)
 	}
-	return int64(cBytes), nil
+	return size, nil
 }
 
 // EventLogSizeSet set the maximum size for the event log.
 func EventLogSizeSet(size int64) (err error) {
-	lock.Lock()
-	defer lock.Unlock()
+	libLock.Lock()
+	defer libLock.Unlock()
 
-	rc, err := C.undolr_event_log_size_set(C.long(size))
-	if rc != 0 {
+	err = require(fnEventLogSizeSet)
+	if err != nil {
 		return
 	}
-	return nil
+
+	rc, err := libEventLogSizeSet(size)
+	if rc != 0 {
+		return checkResult(fnEventLogSizeSet, rc, err)
+	}
+	if size == 0 {
+		return nil
+	}
+	return checkReadBack(fnEventLogSizeSet, fnEventLogSizeGet, size, func() (interface{}, error) {
+		if err := require(fnEventLogSizeGet); err != nil {
+			return nil, err
+		}
+		size, rc, err := libEventLogSizeGet()
+		return size, checkResult(fnEventLogSizeGet, rc, err)
+	})
 }
 
 // IncludeSymbolFiles controls whether symbol files should be included in saved recordings.
 func IncludeSymbolFiles(include bool) (err error) {
-	var cInclude C.int
-	if include {
-		cInclude = 1
-	}
+	libLock.Lock()
+	defer libLock.Unlock()
 
-	lock.Lock()
-	defer lock.Unlock()
+	err = require(fnIncludeSymbolFiles)
+	if err != nil {
+		return
+	}
 
-	rc, err := C.undolr_include_symbol_files(cInclude)
+	rc, err := libIncludeSymbolFiles(include)
 	if rc != 0 {
-		return
+		return checkResult(fnIncludeSymbolFiles, rc, err)
 	}
+	lock.Lock()
+	includeSymbols = include
+	lock.Unlock()
 	return nil
 }
 
@@ -405,7 +721,8 @@ func IncludeSymbolFiles(include bool) (err error) {
 // When a shared memory log filename is set, all accesses to shared memory get logged to that
 // file, which can be written by multiple processes at the same time.
 // If this function is not called (or called with "" as the filename), then an external
-// shared memory log is not used.
+// shared memory log is not used. A relative filename is made absolute, so
+// processes with different working directories share the same log.
 //
 // This feature is currently used in the following way:
 // - A process creates some shared maps.
@@ -427,73 +744,107 @@ func IncludeSymbolFiles(include bool) (err error) {
 // This means that separate independent runs should not use the same shared memory log as
 // the old log is not discarded for the new run.
 func ShmemLogFilenameSet(filename string) (err error) {
-	var cstring *C.char
-
 	if len(filename) > 0 {
-		cstring = C.CString(filename)
-		defer C.free(unsafe.Pointer(cstring))
+		filename, err = normalizeFilename(filename)
+		if err != nil {
+			return
+		}
 	}
 
-	lock.Lock()
-	defer lock.Unlock()
+	libLock.Lock()
+	defer libLock.Unlock()
 
-	rc, err := C.undolr_shmem_log_filename_set(cstring)
-	if rc != 0 {
+	err = require(fnShmemLogFilenameSet)
+	if err != nil {
 		return
 	}
-	return nil
+
+	rc, err := libShmemLogFilenameSet(filename)
+	if rc != 0 {
+		return checkResult(fnShmemLogFilenameSet, rc, err)
+	}
+	return checkReadBack(fnShmemLogFilenameSet, fnShmemLogFilenameGet, filename, shmemLogFilenameGetValue)
 }
 
 // ShmemLogFilenameClear clears the path of the file for logging shared memory accesses.
 //
 // This has the effect of stopping shared memory logging.
 func ShmemLogFilenameClear() (err error) {
-	lock.Lock()
-	defer lock.Unlock()
+	libLock.Lock()
+	defer libLock.Unlock()
 
-	rc, err := C.undolr_shmem_log_filename_set((*C.char)(nil))
-	if rc != 0 {
+	err = require(fnShmemLogFilenameSet)
+	if err != nil {
 		return
 	}
-	return nil
+
+	rc, err := libShmemLogFilenameSet("")
+	if rc != 0 {
+		return checkResult(fnShmemLogFilenameSet, rc, err)
+	}
+	return checkReadBack(fnShmemLogFilenameSet, fnShmemLogFilenameGet, "", shmemLogFilenameGetValue)
 }
 
 // ShmemLogFilenameGet retrieves the current path for the shared memory access log.
 func ShmemLogFilenameGet() (filename string, err error) {
-	var cOFilename *C.char
-
-	lock.Lock()
-	defer lock.Unlock()
+	err = require(fnShmemLogFilenameGet)
+	if err != nil {
+		return
+	}
 
-	rc, err := C.undolr_shmem_log_filename_get(&cOFilename)
+	filename, rc, err := libShmemLogFilenameGet()
 	if rc != 0 {
-		return "", err
+		return "", checkResult(fnShmemLogFilenameGet, rc, err)
 	}
-	return C.GoString(cOFilename), nil
+	return filename, nil
+}
+
+// shmemLogFilenameGetValue reads back the shared memory log filename for
+// checkReadBack.
+func shmemLogFilenameGetValue() (interface{}, error) {
+	if err := require(fnShmemLogFilenameGet); err != nil {
+		return nil, err
+	}
+	filename, rc, err := libShmemLogFilenameGet()
+	return filename, checkResult(fnShmemLogFilenameGet, rc, err)
 }
 
 // ShmemLogSizeSet sets the maximum shared memory log access size.
 func ShmemLogSizeSet(size int64) (err error) {
-	lock.Lock()
-	defer lock.Unlock()
+	libLock.Lock()
+	defer libLock.Unlock()
 
-	rc, err := C.undolr_shmem_log_size_set(C.ulong(size))
-	if rc != 0 {
+	err = require(fnShmemLogSizeSet)
+	if err != nil {
 		return
 	}
-	return nil
+
+	rc, err := libShmemLogSizeSet(uint64(size))
+	if rc != 0 {
+		return checkResult(fnShmemLogSizeSet, rc, err)
+	}
+	if size == 0 {
+		return nil
+	}
+	return checkReadBack(fnShmemLogSizeSet, fnShmemLogSizeGet, size, func() (interface{}, error) {
+		if err := require(fnShmemLogSizeGet); err != nil {
+			return nil, err
+		}
+		size, rc, err := libShmemLogSizeGet()
+		return int64(size), checkResult(fnShmemLogSizeGet, rc, err)
+	})
 }
 
 // ShmemLogSizeGet retrieves the maximum shared memory log access size.
 func ShmemLogSizeGet() (size int64, err error) {
-	var cMaxSize C.ulong
-
-	lock.Lock()
-	defer lock.Unlock()
+	err = require(fnShmemLogSizeGet)
+	if err != nil {
+		return
+	}
 
-	rc, err := C.undolr_shmem_log_size_get(&cMaxSize)
+	maxSize, rc, err := libShmemLogSizeGet()
 	if rc != 0 {
-		return 0, err
+		return 0, checkResult(fnShmemLogSizeGet, rc, err)
 	}
-	return int64(cMaxSize), nil
+	return int64(maxSize), nil
 }
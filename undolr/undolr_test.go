@@ -9,10 +9,12 @@ package undolr
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"syscall"
 	"testing"
@@ -151,7 +153,7 @@ func TestAsyncRecordingSelect(t *testing.T) {
 
 	// Read from the FD
 	data := make([]byte, 1, 1)
-	n, err := syscall.Read(fd, data)
+	n, err := readFd(fd, data)
 	if n != 1 {
 		t.Fatal("Read failed:", err)
 	}
@@ -300,6 +302,10 @@ func doTestSaveOnTerminationHelper(t *testing.T) {
 		fmt.Fprintf(os.Stderr, "SaveOnTermination %s\n", err)
 		os.Exit(2)
 	}
+	if SaveOnTerminationGet() != args[0] {
+		fmt.Fprintf(os.Stderr, "SaveOnTerminationGet %q\n", SaveOnTerminationGet())
+		os.Exit(5)
+	}
 
 	err = Start()
 	if err != nil {
@@ -313,6 +319,10 @@ func doTestSaveOnTerminationHelper(t *testing.T) {
 			fmt.Fprintf(os.Stderr, "SaveOnTerminationCancel %s\n", err)
 			os.Exit(4)
 		}
+		if SaveOnTerminationGet() != "" {
+			fmt.Fprintf(os.Stderr, "SaveOnTerminationGet after cancel %q\n", SaveOnTerminationGet())
+			os.Exit(5)
+		}
 	}
 
 	os.Exit(0)
@@ -333,6 +343,19 @@ func TestSaveOnTerminationCancel(t *testing.T) {
 	}
 }
 
+func TestSaveOnTerminationGetUnarmed(t *testing.T) {
+	if filename := SaveOnTerminationGet(); filename != "" {
+		t.Fatal("Unexpected termination save armed:", filename)
+	}
+	if err := SaveOnTermination("\x00"); err == nil {
+		SaveOnTerminationCancel()
+		t.Fatal("Expected SaveOnTermination() to reject an invalid filename")
+	}
+	if filename := SaveOnTerminationGet(); filename != "" {
+		t.Fatal("Unexpected termination save armed after failure:", filename)
+	}
+}
+
 func TestEventLogSize(t *testing.T) {
 	size, err := EventLogSizeGet()
 	if err != nil {
@@ -400,7 +423,10 @@ func TestIncludeSymbolFiles(t *testing.T) {
 	}
 
 	// Restore to default
-	IncludeSymbolFiles(true)
+	err = IncludeSymbolFiles(true)
+	if err != nil {
+		t.Fatal("IncludeSymbolFiles:", err)
+	}
 
 	// This test assumes that symbols exist, and that
 	// the amount of recording between the two snapshots
@@ -449,7 +475,7 @@ func TestShmemLogFilenameSetInvalid(t *testing.T) {
 	err = ShmemLogFilenameSet(filename)
 	if err == nil {
 		t.Fatal("Unexpected success with invalid shmem log filename")
-	} else if err != syscall.EINVAL {
+	} else if !errors.Is(err, syscall.EINVAL) {
 		t.Fatal("ShmemLogFilenameSet:", err)
 	}
 }
@@ -478,7 +504,10 @@ func TestShmemLogSize(t *testing.T) {
 	}
 
 	// Restore
-	ShmemLogSizeSet(0)
+	err = ShmemLogSizeSet(0)
+	if err != nil {
+		t.Fatal("ShmemLogSizeSet:", err)
+	}
 }
 
 func TestShmemLogRecording(t *testing.T) {
@@ -519,9 +548,18 @@ func TestShmemLogRecording(t *testing.T) {
 	}
 
 	// Restore
-	StopAndDiscard()
-	ShmemLogFilenameClear()
-	ShmemLogSizeSet(0)
+	err = StopAndDiscard()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	err = ShmemLogFilenameClear()
+	if err != nil {
+		t.Fatal("ShmemLogFilenameClear:", err)
+	}
+	err = ShmemLogSizeSet(0)
+	if err != nil {
+		t.Fatal("ShmemLogSizeSet:", err)
+	}
 
 	verifyShmemRecording(t, shmemFilename)
 }
@@ -574,6 +612,17 @@ func verifyEmptyRecording(t *testing.T, filename string) (err error) {
 	return
 }
 
+// unwritableFilename returns the name of a recording in a directory which
+// does not exist, so that saving to it fails.
+func unwritableFilename(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "undolr_test_")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "missing", "recording.undo")
+}
+
 func tmpnam(extension string) (filename string, err error) {
 	iterations := 0
 
@@ -606,3 +655,28 @@ Restart:
 	tempfile.Close()
 	return tempfile.Name(), nil
 }
+
+func TestReadersDoNotWaitForSave(t *testing.T) {
+	// A long save holds libLock, which must not block calls that only
+	// read state.
+	libLock.Lock()
+	defer libLock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		GetVersionString()
+		EventLogSizeGet()
+		EventLogStats()
+		ShmemLogFilenameGet()
+		ShmemLogSizeGet()
+		CurrentMode()
+		StartFingerprint()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Reader blocked while libLock held")
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package undolrtest provides a fake undolr.Recorder for tests.
+//
+// Code taking an undolr.Recorder can be tested with a FakeRecorder in
+// place of undolr.LiveRecorder, without the UndoLR library or ptrace
+// permission:
+//
+//	recorder := &undolrtest.FakeRecorder{}
+//	err := runRecorded(recorder)
+//	...
+//	for _, call := range recorder.Calls() {
+//		t.Log(call.Method, call.Filename)
+//	}
+//
+// The fake follows the rules of the real recorder, such as only saving
+// while recording, and writes a small placeholder file for each save, so
+// code handling saved recordings can be exercised too. The placeholder
+// cannot be loaded by UndoDB.
+package undolrtest
+
+import (
+	"errors"
+	"io/ioutil"
+	"sync"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// ErrNotRecording is returned by FakeRecorder.Save and StopAndDiscard when the fake is not recording.
+var ErrNotRecording = errors.New("not recording")
+
+// Methods of the fake, as reported in Call.Method.
+const (
+	MethodStart            = "Start"
+	MethodSave             = "Save"
+	MethodStop             = "Stop"
+	MethodStopAndDiscard   = "StopAndDiscard"
+	MethodRecordingSave    = "Recording.Save"
+	MethodRecordingDiscard = "Recording.Discard"
+)
+
+// FakeContent is written to each file saved by the fake.
+const FakeContent = "undolrtest fake recording\n"
+
+// A Call is a call made to a FakeRecorder, or to a Recording it returned.
+type Call struct {
+	// Method is one of the Method constants.
+	Method string
+
+	// Filename is the file saved to, or "" for methods other than saves.
+	Filename string
+
+	// Err is the error returned.
+	Err error
+}
+
+// A FakeRecorder is an undolr.Recorder which records calls and writes placeholder files.
+//
+// The zero value is ready to use. A FakeRecorder is safe for concurrent
+// use.
+type FakeRecorder struct {
+	// Err, if set, is called before performing each call. If it returns
+	// an error the call fails with it, without otherwise taking effect.
+	Err func(call Call) error
+
+	mu        sync.Mutex
+	recording bool
+	calls     []Call
+}
+
+var _ undolr.Recorder = (*FakeRecorder)(nil)
+
+// Calls returns the calls made so far, in order.
+func (f *FakeRecorder) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Recording reports whether the fake is recording, having been started and not stopped.
+func (f *FakeRecorder) Recording() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.recording
+}
+
+// call records a call to method, returning the error from Err if set.
+// f.mu must be held.
+func (f *FakeRecorder) call(method, filename string, perform func() error) error {
+	call := Call{Method: method, Filename: filename}
+	if f.Err != nil {
+		call.Err = f.Err(call)
+	}
+	if call.Err == nil {
+		call.Err = perform()
+	}
+	f.calls = append(f.calls, call)
+	return call.Err
+}
+
+// Start starts the fake recording. It fails with undolr.ErrAlreadyRecording if already recording.
+func (f *FakeRecorder) Start() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(MethodStart, "", func() error {
+		if f.recording {
+			return undolr.ErrAlreadyRecording
+		}
+		f.recording = true
+		return nil
+	})
+}
+
+// Save writes a placeholder recording to filename. It fails with ErrNotRecording if not recording.
+func (f *FakeRecorder) Save(filename string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(MethodSave, filename, func() error {
+		if !f.recording {
+			return ErrNotRecording
+		}
+		return writeFake(filename)
+	})
+}
+
+// Stop stops the fake recording, returning a Recording which can be saved.
+//
+// It fails with undolr.ErrRecordingContextStopFailed if not recording, as
+// undolr.Stop does.
+func (f *FakeRecorder) Stop() (undolr.Recording, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.call(MethodStop, "", func() error {
+		if !f.recording {
+			return undolr.ErrRecordingContextStopFailed
+		}
+		f.recording = false
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRecording{recorder: f, valid: true}, nil
+}
+
+// StopAndDiscard stops the fake recording. It fails with ErrNotRecording if not recording.
+func (f *FakeRecorder) StopAndDiscard() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(MethodStopAndDiscard, "", func() error {
+		if !f.recording {
+			return ErrNotRecording
+		}
+		f.recording = false
+		return nil
+	})
+}
+
+// fakeRecording is the Recording returned by FakeRecorder.Stop. Its
+// calls are recorded by the recorder.
+type fakeRecording struct {
+	recorder *FakeRecorder
+	valid    bool
+}
+
+func (r *fakeRecording) Save(filename string) error {
+	r.recorder.mu.Lock()
+	defer r.recorder.mu.Unlock()
+	return r.recorder.call(MethodRecordingSave, filename, func() error {
+		if !r.valid {
+			return undolr.ErrRecordingContextDiscarded
+		}
+		return writeFake(filename)
+	})
+}
+
+func (r *fakeRecording) Discard() error {
+	r.recorder.mu.Lock()
+	defer r.recorder.mu.Unlock()
+	return r.recorder.call(MethodRecordingDiscard, "", func() error {
+		if !r.valid {
+			return undolr.ErrRecordingContextDiscarded
+		}
+		r.valid = false
+		return nil
+	})
+}
+
+// writeFake writes a placeholder recording to filename.
+func writeFake(filename string) error {
+	if filename == "" {
+		return &undolr.InvalidFilenameError{Filename: filename, Reason: "empty"}
+	}
+	return ioutil.WriteFile(filename, []byte(FakeContent), 0644)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolrtest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.undo.io/bindings/undolr"
+)
+
+func TestFakeRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undolrtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	saved := filepath.Join(dir, "saved.undo")
+	stopped := filepath.Join(dir, "stopped.undo")
+
+	var recorder undolr.Recorder = &FakeRecorder{}
+	if err := recorder.Save(saved); err != ErrNotRecording {
+		t.Fatal("Expected Save() before Start() to fail with ErrNotRecording:", err)
+	}
+	if err := recorder.Start(); err != nil {
+		t.Fatal("Start:", err)
+	}
+	if err := recorder.Start(); err != undolr.ErrAlreadyRecording {
+		t.Fatal("Expected a second Start() to fail with ErrAlreadyRecording:", err)
+	}
+	if err := recorder.Save(saved); err != nil {
+		t.Fatal("Save:", err)
+	}
+	recording, err := recorder.Stop()
+	if err != nil {
+		t.Fatal("Stop:", err)
+	}
+	if _, err := recorder.Stop(); err != undolr.ErrRecordingContextStopFailed {
+		t.Fatal("Expected a second Stop() to fail with ErrRecordingContextStopFailed:", err)
+	}
+	if err := recording.Save(stopped); err != nil {
+		t.Fatal("Recording.Save:", err)
+	}
+	if err := recording.Discard(); err != nil {
+		t.Fatal("Discard:", err)
+	}
+	if err := recording.Save(stopped); err != undolr.ErrRecordingContextDiscarded {
+		t.Fatal("Expected Save() after Discard() to fail with ErrRecordingContextDiscarded:", err)
+	}
+
+	for _, filename := range []string{saved, stopped} {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil || string(data) != FakeContent {
+			t.Fatalf("Unexpected content of %s: %q %v", filename, data, err)
+		}
+	}
+
+	expected := []Call{
+		{MethodSave, saved, ErrNotRecording},
+		{MethodStart, "", nil},
+		{MethodStart, "", undolr.ErrAlreadyRecording},
+		{MethodSave, saved, nil},
+		{MethodStop, "", nil},
+		{MethodStop, "", undolr.ErrRecordingContextStopFailed},
+		{MethodRecordingSave, stopped, nil},
+		{MethodRecordingDiscard, "", nil},
+		{MethodRecordingSave, stopped, undolr.ErrRecordingContextDiscarded},
+	}
+	if calls := recorder.(*FakeRecorder).Calls(); !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("Unexpected calls:\n%v\nexpected:\n%v", calls, expected)
+	}
+}
+
+func TestFakeRecorderErr(t *testing.T) {
+	injected := errors.New("injected")
+	recorder := &FakeRecorder{
+		Err: func(call Call) error {
+			if call.Method == MethodStart {
+				return injected
+			}
+			return nil
+		},
+	}
+	if err := recorder.Start(); err != injected {
+		t.Fatal("Expected Start() to fail with the injected error:", err)
+	}
+	if recorder.Recording() {
+		t.Fatal("Expected a failed Start() not to start recording")
+	}
+	if err := recorder.StopAndDiscard(); err != ErrNotRecording {
+		t.Fatal("Expected StopAndDiscard() to fail with ErrNotRecording:", err)
+	}
+	if err := recorder.Save(""); err != ErrNotRecording {
+		t.Fatal("Expected Save() to fail with ErrNotRecording:", err)
+	}
+	if calls := recorder.Calls(); len(calls) != 3 || calls[0].Err != injected {
+		t.Fatal("Unexpected calls:", calls)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package upload
+
+import (
+	"time"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// FromConfig returns an S3Uploader and Options for the upload settings of an undolr.Config.
+//
+// Credentials are read from the environment by S3CredentialsFromEnv, so
+// they are not kept in config files. Add the result as a save hook:
+//
+//	config, err := undolr.LoadConfig("/etc/undo/recorder.json")
+//	...
+//	if config.Upload != nil {
+//		u, opts, err := upload.FromConfig(*config.Upload)
+//		...
+//		undolr.AddSaveHook(upload.OnSave(u, opts))
+//	}
+func FromConfig(config undolr.UploadConfig) (*S3Uploader, Options, error) {
+	u, err := NewS3Uploader(config.Bucket, config.Region, S3CredentialsFromEnv())
+	if err != nil {
+		return nil, Options{}, err
+	}
+	u.Prefix = config.Prefix
+	u.Endpoint = config.Endpoint
+
+	opts := Options{
+		Timeout: time.Duration(config.Timeout),
+		Remove:  config.Remove,
+	}
+	return u, opts, nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package upload
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"go.undo.io/bindings/undolr"
+)
+
+func TestFromConfig(t *testing.T) {
+	config := undolr.UploadConfig{
+		Bucket:   "recordings",
+		Region:   "eu-west-2",
+		Prefix:   "staging/",
+		Endpoint: "http://localhost:9000",
+		Timeout:  undolr.ConfigDuration(time.Minute),
+		Remove:   true,
+	}
+
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+		defer os.Setenv(name, os.Getenv(name))
+		os.Unsetenv(name)
+	}
+	if _, _, err := FromConfig(config); err != ErrS3CredentialsMissing {
+		t.Fatal("Expected ErrS3CredentialsMissing, got", err)
+	}
+
+	os.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	u, opts, err := FromConfig(config)
+	if err != nil {
+		t.Fatal("FromConfig:", err)
+	}
+	if u.Bucket != "recordings" || u.Region != "eu-west-2" || u.Prefix != "staging/" ||
+		u.Endpoint != "http://localhost:9000" || u.Credentials.AccessKeyID != "AKIDEXAMPLE" {
+		t.Fatalf("Unexpected uploader: %+v", u)
+	}
+	if opts.Timeout != time.Minute || !opts.Remove {
+		t.Fatalf("Unexpected options: %+v", opts)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package upload
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrS3CredentialsMissing indicates no credentials were given for S3.
+var ErrS3CredentialsMissing = errors.New("S3 credentials missing")
+
+// S3Credentials authenticate requests to S3.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken is required for temporary credentials.
+	SessionToken string
+}
+
+// S3CredentialsFromEnv returns credentials from the standard AWS environment variables.
+func S3CredentialsFromEnv() S3Credentials {
+	return S3Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// An S3Uploader uploads recordings to an S3 bucket.
+//
+// Each recording is stored with a single PUT, so is limited to 5GiB; see
+// undolr.SplitRecording for larger recordings. Metadata is stored as
+// x-amz-meta- headers.
+type S3Uploader struct {
+	Bucket      string
+	Region      string
+	Credentials S3Credentials
+
+	// Prefix is prepended to the base name of each recording to form its
+	// object key.
+	Prefix string
+
+	// Endpoint is the URL of the S3 service, for S3 compatible stores.
+	// Requests use path-style addressing. It defaults to
+	// https://s3.<Region>.amazonaws.com.
+	Endpoint string
+
+	// HTTPClient is used for requests, or http.DefaultClient if nil.
+	HTTPClient *http.Client
+
+	// now is the time used for signing, for tests.
+	now func() time.Time
+}
+
+// NewS3Uploader returns an S3Uploader for bucket in region.
+func NewS3Uploader(bucket, region string, credentials S3Credentials) (*S3Uploader, error) {
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return nil, ErrS3CredentialsMissing
+	}
+	return &S3Uploader{Bucket: bucket, Region: region, Credentials: credentials}, nil
+}
+
+// Upload stores the recording at path in the bucket.
+func (u *S3Uploader) Upload(ctx context.Context, path string, metadata Metadata) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	endpoint := u.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + u.Region + ".amazonaws.com"
+	}
+	key := u.Prefix + filepath.Base(path)
+	target := strings.TrimSuffix(endpoint, "/") + "/" + s3Escape(u.Bucket) + "/" + s3Escape(key)
+
+	req, err := http.NewRequest(http.MethodPut, target, ioutil.NopCloser(f))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	for k, v := range metadata {
+		req.Header.Set("X-Amz-Meta-"+k, v)
+	}
+
+	now := time.Now
+	if u.now != nil {
+		now = u.now
+	}
+	u.sign(req, hex.EncodeToString(h.Sum(nil)), now().UTC())
+
+	client := u.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("S3 PUT %s: %s: %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// s3Escape escapes a bucket or key for use in a path, keeping '/'.
+func s3Escape(s string) string {
+	segments := strings.Split(s, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEncode encodes s as required by AWS Signature Version 4.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// sign adds AWS Signature Version 4 authentication to req.
+func (u *S3Uploader) sign(req *http.Request, payloadHash string, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if u.Credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", u.Credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lower := strings.ToLower(k)
+		if lower == "content-type" || lower == "range" || lower == "date" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.Join(strings.Fields(strings.Join(v, ",")), " ")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + u.Region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+u.Credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, u.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+u.Credentials.AccessKeyID+"/"+scope+
+		",SignedHeaders="+signedHeaders+",Signature="+signature)
+}
+
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for k, vs := range query {
+		for _, v := range vs {
+			params = append(params, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
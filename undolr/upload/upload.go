@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package upload ships saved recordings off the host.
+//
+// An Uploader transfers a recording file to storage. OnSave adapts one to
+// a save hook, so every recording is uploaded as soon as it is saved.
+// Adding it with undolr.AddSaveHook keeps other hooks, such as metrics:
+//
+//	s3, err := upload.NewS3Uploader("my-bucket", "eu-west-2", upload.S3CredentialsFromEnv())
+//	...
+//	undolr.AddSaveHook(upload.OnSave(s3, upload.Options{Remove: true}))
+//
+// S3Uploader implements Amazon S3, and compatible stores, using only the
+// standard library.
+package upload
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// Metadata is stored alongside an uploaded recording.
+type Metadata map[string]string
+
+// An Uploader transfers a recording file to storage.
+type Uploader interface {
+	Upload(ctx context.Context, path string, metadata Metadata) error
+}
+
+// The UploaderFunc type is an adapter to allow the use of ordinary functions as an Uploader.
+type UploaderFunc func(ctx context.Context, path string, metadata Metadata) error
+
+// Upload calls f(ctx, path, metadata).
+func (f UploaderFunc) Upload(ctx context.Context, path string, metadata Metadata) error {
+	return f(ctx, path, metadata)
+}
+
+// Options controls the uploads made by OnSave.
+type Options struct {
+	// Timeout bounds each upload. Zero means no limit.
+	Timeout time.Duration
+
+	// Remove deletes recordings once they are uploaded successfully.
+	Remove bool
+
+	// Metadata returns the metadata to store for a save. If nil,
+	// SaveMetadata is used.
+	Metadata func(event undolr.SaveEvent) Metadata
+
+	// OnResult, if set, is called with the outcome of each upload.
+	OnResult func(path string, err error)
+}
+
+// SaveMetadata returns the default metadata for a save: the save kind,
+// size and duration, and the host name and process ID of the saving
+// process.
+func SaveMetadata(event undolr.SaveEvent) Metadata {
+	metadata := Metadata{
+		"kind":        event.Kind.String(),
+		"bytes":       strconv.FormatInt(event.Stats.BytesWritten, 10),
+		"duration-ms": strconv.FormatInt(int64(event.Stats.Duration/time.Millisecond), 10),
+		"pid":         strconv.Itoa(os.Getpid()),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		metadata["hostname"] = hostname
+	}
+	return metadata
+}
+
+// OnSave returns a save hook uploading each recording file with u once it is saved.
+//
+// Uploads run in the background, so the hook returns immediately; their
+// outcome is reported through opts.OnResult.
+func OnSave(u Uploader, opts Options) func(undolr.SaveEvent) {
+	metadata := opts.Metadata
+	if metadata == nil {
+		metadata = SaveMetadata
+	}
+
+	return func(event undolr.SaveEvent) {
+		if event.Phase != undolr.SaveCompleted {
+			return
+		}
+		path := event.Stats.Filename
+		if path == "" {
+			path = event.Filename
+		}
+		if path == "" {
+			// Saved to a writer rather than a file.
+			return
+		}
+		meta := metadata(event)
+
+		go func() {
+			ctx := context.Background()
+			if opts.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+				defer cancel()
+			}
+
+			err := u.Upload(ctx, path, meta)
+			if err == nil && opts.Remove {
+				err = os.Remove(path)
+			}
+			if opts.OnResult != nil {
+				opts.OnResult(path, err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package upload
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"go.undo.io/bindings/undolr"
+)
+
+func TestOnSave(t *testing.T) {
+	f, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	uploaded := make(chan Metadata, 1)
+	u := UploaderFunc(func(ctx context.Context, path string, metadata Metadata) error {
+		if path != f.Name() {
+			t.Errorf("path = %q, want %q", path, f.Name())
+		}
+		uploaded <- metadata
+		return nil
+	})
+	results := make(chan error, 1)
+	hook := OnSave(u, Options{Remove: true, OnResult: func(path string, err error) { results <- err }})
+
+	hook(undolr.SaveEvent{Phase: undolr.SaveStarted, Filename: f.Name()})
+	hook(undolr.SaveEvent{Phase: undolr.SaveFailed, Filename: f.Name()})
+	hook(undolr.SaveEvent{
+		Phase:    undolr.SaveCompleted,
+		Kind:     undolr.SaveKindSync,
+		Filename: f.Name(),
+		Stats:    undolr.SaveStats{Filename: f.Name(), BytesWritten: 42},
+	})
+
+	select {
+	case metadata := <-uploaded:
+		if metadata["bytes"] != "42" || metadata["kind"] != undolr.SaveKindSync.String() {
+			t.Errorf("metadata = %v", metadata)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("recording not uploaded")
+	}
+	if err := <-results; err != nil {
+		t.Errorf("OnResult err = %v", err)
+	}
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("recording not removed after upload: %v", err)
+	}
+	select {
+	case <-uploaded:
+		t.Error("uploaded for a save that did not complete")
+	default:
+	}
+}
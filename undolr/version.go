@@ -0,0 +1,204 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ErrVersionInvalid indicates a version string without a version number.
+var ErrVersionInvalid = errors.New("invalid version")
+
+// ErrVersionTooOld indicates the UndoLR library is older than required.
+//
+// Errors returned by RequireVersion for an old library are of type
+// *VersionError and match this value with errors.Is.
+var ErrVersionTooOld = errors.New("UndoLR library too old")
+
+// A VersionError reports an UndoLR library older than the version required.
+type VersionError struct {
+	Required Version
+	Actual   Version
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%v: version %v loaded, %v or later required", ErrVersionTooOld, e.Actual, e.Required)
+}
+
+// Is reports whether target is ErrVersionTooOld.
+func (e *VersionError) Is(target error) bool {
+	return target == ErrVersionTooOld
+}
+
+// A Version is the version number of the UndoLR library.
+type Version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+// versionPattern matches the version number within a version string.
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseVersion parses the version number from a version string, such as returned by GetVersionString.
+//
+// The first number of the form major.minor or major.minor.patch is used,
+// so surrounding text such as a product name or build suffix is ignored.
+// A missing patch number is taken as zero. Errors wrap ErrVersionInvalid.
+func ParseVersion(s string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("%q: %w", s, ErrVersionInvalid)
+	}
+
+	var numbers [3]int
+	for i, m := range match[1:] {
+		if m == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m)
+		if err != nil {
+			return Version{}, fmt.Errorf("%q: %w: %v", s, ErrVersionInvalid, err)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// LibraryVersion returns the version of the UndoLR library in use.
+func LibraryVersion() (Version, error) {
+	err := require(fnGetVersionString)
+	if err != nil {
+		return Version{}, err
+	}
+	return ParseVersion(libGetVersionString())
+}
+
+// RequireVersion checks the UndoLR library in use is at least version min, such as "7.1".
+//
+// Call it at startup to fail fast with a clear message when code relies
+// on a newer library than is installed, rather than failing later when a
+// function is missing or rejects its arguments. An older library is
+// reported as a *VersionError. An invalid min wraps ErrVersionInvalid,
+// and if the library is not available the error matches ErrNotAvailable.
+// Where the capability needed is known, Supports checks for it directly.
+func RequireVersion(min string) error {
+	required, err := ParseVersion(min)
+	if err != nil {
+		return err
+	}
+	actual, err := LibraryVersion()
+	if err != nil {
+		return err
+	}
+	if actual.Less(required) {
+		return &VersionError{Required: required, Actual: actual}
+	}
+	return nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 as v is older than, the same as, or newer than w.
+func (v Version) Compare(w Version) int {
+	for _, d := range [...]int{v.Major - w.Major, v.Minor - w.Minor, v.Patch - w.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Less reports whether v is older than w.
+func (v Version) Less(w Version) bool {
+	return v.Compare(w) < 0
+}
+
+// AtLeast reports whether v is the same as or newer than w.
+func (v Version) AtLeast(w Version) bool {
+	return v.Compare(w) >= 0
+}
+
+// A Feature is a capability of the UndoLR library which older versions may lack.
+type Feature int
+
+// Values for Feature.
+const (
+	// FeatureAsyncSave is saving a stopped recording with SaveAsync, and
+	// waiting for it with Poll or GetSelectDescriptor.
+	FeatureAsyncSave Feature = iota
+
+	// FeatureSaveOnTermination is SaveOnTermination and
+	// SaveOnTerminationCancel.
+	FeatureSaveOnTermination
+
+	// FeatureEventLogSize is EventLogSizeGet and EventLogSizeSet.
+	FeatureEventLogSize
+
+	// FeatureIncludeSymbolFiles is IncludeSymbolFiles.
+	FeatureIncludeSymbolFiles
+
+	// FeatureShmemLog is logging shared memory accesses, with
+	// ShmemLogFilenameSet and ShmemLogSizeSet and their getters.
+	FeatureShmemLog
+
+	numFeatures
+)
+
+// featureFunctions are the library functions needed by each Feature.
+var featureFunctions = [numFeatures][]libFunction{
+	FeatureAsyncSave:          {fnSaveAsync, fnPollSavingProgress, fnGetSelectDescriptor},
+	FeatureSaveOnTermination:  {fnSaveOnTermination, fnSaveOnTerminationCancel},
+	FeatureEventLogSize:       {fnEventLogSizeGet, fnEventLogSizeSet},
+	FeatureIncludeSymbolFiles: {fnIncludeSymbolFiles},
+	FeatureShmemLog:           {fnShmemLogFilenameSet, fnShmemLogFilenameGet, fnShmemLogSizeSet, fnShmemLogSizeGet},
+}
+
+func (f Feature) String() string {
+	switch f {
+	case FeatureAsyncSave:
+		return "async-save"
+	case FeatureSaveOnTermination:
+		return "save-on-termination"
+	case FeatureEventLogSize:
+		return "event-log-size"
+	case FeatureIncludeSymbolFiles:
+		return "include-symbol-files"
+	case FeatureShmemLog:
+		return "shmem-log"
+	default:
+		return "unknown"
+	}
+}
+
+// Supports reports whether the UndoLR library in use provides a Feature.
+//
+// This is decided by whether the library provides the functions the
+// feature needs, rather than by its version, so is accurate for any
+// library. Use it to avoid a newer capability on older installs, rather
+// than failing with ErrNotSupportedByLibrary.
+func Supports(f Feature) bool {
+	if f < 0 || f >= numFeatures {
+		return false
+	}
+	libInit()
+	for _, fn := range featureFunctions[f] {
+		if !libHas[fn] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undolr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	for _, test := range []struct {
+		s       string
+		version Version
+	}{
+		{"7.2.1", Version{7, 2, 1}},
+		{"8.0", Version{8, 0, 0}},
+		{"UndoLR 6.10.3-r12345", Version{6, 10, 3}},
+		{"v7.1.0 (build 42)", Version{7, 1, 0}},
+	} {
+		version, err := ParseVersion(test.s)
+		if err != nil || version != test.version {
+			t.Errorf("ParseVersion(%q) = %v, %v; expected %v", test.s, version, err, test.version)
+		}
+	}
+
+	for _, s := range []string{"", "7", "unknown", "x.y.z"} {
+		if _, err := ParseVersion(s); !errors.Is(err, ErrVersionInvalid) {
+			t.Errorf("Expected ParseVersion(%q) to fail with ErrVersionInvalid: %v", s, err)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	versions := []Version{{6, 9, 9}, {6, 10, 0}, {6, 10, 1}, {7, 0, 0}}
+	for i, v := range versions {
+		for j, w := range versions {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			if c := v.Compare(w); c != expected {
+				t.Errorf("%v.Compare(%v) = %d; expected %d", v, w, c, expected)
+			}
+			if v.Less(w) != (i < j) || v.AtLeast(w) != (i >= j) {
+				t.Errorf("Unexpected ordering of %v and %v", v, w)
+			}
+		}
+	}
+	if s := (Version{7, 2, 1}).String(); s != "7.2.1" {
+		t.Error("Unexpected string", s)
+	}
+}
+
+func TestLibraryVersion(t *testing.T) {
+	version, err := LibraryVersion()
+	if err != nil {
+		t.Fatal("LibraryVersion:", err)
+	}
+	if version.Major == 0 {
+		t.Error("Unexpected version", version)
+	}
+}
+
+func TestSupports(t *testing.T) {
+	// The library the tests run against is expected to be complete.
+	for f := Feature(0); f < numFeatures; f++ {
+		if !Supports(f) {
+			t.Errorf("Expected %v to be supported", f)
+		}
+	}
+	if Supports(numFeatures) || Supports(-1) {
+		t.Error("Expected unknown features not to be supported")
+	}
+}
+
+func TestFeatureString(t *testing.T) {
+	if s := FeatureShmemLog.String(); s != "shmem-log" {
+		t.Error("Unexpected string", s)
+	}
+	if s := numFeatures.String(); s != "unknown" {
+		t.Error("Unexpected string", s)
+	}
+}
+
+func TestVersionError(t *testing.T) {
+	var err error = &VersionError{Required: Version{7, 1, 0}, Actual: Version{6, 10, 2}}
+	if !errors.Is(err, ErrVersionTooOld) {
+		t.Fatal("Expected error to match ErrVersionTooOld")
+	}
+	if err.Error() != "UndoLR library too old: version 6.10.2 loaded, 7.1.0 or later required" {
+		t.Fatal("Unexpected error string", err)
+	}
+}
+
+func TestRequireVersion(t *testing.T) {
+	if err := RequireVersion("7.x"); !errors.Is(err, ErrVersionInvalid) {
+		t.Fatal("Expected RequireVersion() to fail with ErrVersionInvalid:", err)
+	}
+
+	version, err := LibraryVersion()
+	if err != nil {
+		t.Fatal("LibraryVersion:", err)
+	}
+	if err := RequireVersion(version.String()); err != nil {
+		t.Fatal("RequireVersion:", err)
+	}
+	newer := Version{version.Major, version.Minor + 1, 0}
+	err = RequireVersion(newer.String())
+	var versionErr *VersionError
+	if !errors.As(err, &versionErr) || versionErr.Actual != version || versionErr.Required != newer {
+		t.Fatal("Expected RequireVersion() to fail with a *VersionError:", err)
+	}
+}
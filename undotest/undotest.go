@@ -0,0 +1,272 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package undotest re-runs failing tests under Live Recorder.
+//
+// Recording every test run in CI is slow, and the recordings are rarely
+// needed. Instead, call Main from a package's TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		undotest.Main(m)
+//	}
+//
+// and set UNDOTEST_RECORD_FAILURES to a directory in CI:
+//
+//	UNDOTEST_RECORD_FAILURES=$PWD/recordings go test ./...
+//
+// The tests then run as normal, without recording. Each top-level test
+// which fails is run again on its own, in a child process running the
+// test binary with -test.run, this time recorded. If it fails again the
+// recording is saved to the directory, named after the test, ready to be
+// debugged; if it passes the test may be flaky, and this is reported.
+// The outcome of the first run decides the exit status either way.
+//
+// To identify failing tests, the first run is itself made in a child
+// process with -test.v, its output passed through. Without
+// UNDOTEST_RECORD_FAILURES, or when already being recorded or run under
+// UndoDB (see undolr.UnderUndo), Main simply runs the tests.
+package undotest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.undo.io/bindings/undolr"
+)
+
+// Environment variables read by Main.
+const (
+	// EnvRecordFailures is the directory to save recordings of failing
+	// tests to. Re-running failures is enabled when it is set.
+	EnvRecordFailures = "UNDOTEST_RECORD_FAILURES"
+
+	// EnvMaxReruns is the largest number of failing tests re-run,
+	// DefaultMaxReruns if unset.
+	EnvMaxReruns = "UNDOTEST_MAX_RERUNS"
+
+	// envChild marks the child process making the first run, and
+	// envRecord gives the recording to save in a child re-running a test.
+	envChild  = "UNDOTEST_CHILD"
+	envRecord = "UNDOTEST_RECORD"
+)
+
+// DefaultMaxReruns is the number of failing tests re-run unless otherwise configured.
+const DefaultMaxReruns = 5
+
+// Options configures Run.
+type Options struct {
+	// Dir is the directory recordings of failing tests are saved to.
+	// Failing tests are only re-run if it is set.
+	Dir string
+
+	// MaxReruns limits the number of failing tests re-run under
+	// recording, as each re-run may take some time. Zero means
+	// DefaultMaxReruns.
+	MaxReruns int
+}
+
+// Main runs the tests as configured by the environment, then exits.
+//
+// It is intended to be called from TestMain. See the package
+// documentation for the environment variables read.
+func Main(m *testing.M) {
+	opts := Options{Dir: os.Getenv(EnvRecordFailures)}
+	if reruns := os.Getenv(EnvMaxReruns); reruns != "" {
+		n, err := strconv.Atoi(reruns)
+		if err != nil || n < 0 {
+			fmt.Fprintf(os.Stderr, "undotest: invalid %s %q\n", EnvMaxReruns, reruns)
+			os.Exit(2)
+		}
+		opts.MaxReruns = n
+	}
+	os.Exit(Run(m, opts))
+}
+
+// Run runs the tests, re-running failing tests under recording if opts.Dir is set, and returns the exit status.
+func Run(m *testing.M, opts Options) int {
+	if filename := os.Getenv(envRecord); filename != "" {
+		return runRecorded(m, filename)
+	}
+	if opts.Dir == "" || os.Getenv(envChild) != "" {
+		return m.Run()
+	}
+	if live, replay := undolr.UnderUndo(); live || replay {
+		return m.Run()
+	}
+	if opts.MaxReruns == 0 {
+		opts.MaxReruns = DefaultMaxReruns
+	}
+
+	rc, failed, err := runChild(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undotest:", err)
+		return 2
+	}
+
+	if len(failed) > opts.MaxReruns {
+		fmt.Fprintf(os.Stderr, "undotest: %d tests failed, re-running the first %d under recording\n",
+			len(failed), opts.MaxReruns)
+		failed = failed[:opts.MaxReruns]
+	}
+	if len(failed) > 0 {
+		err = os.MkdirAll(opts.Dir, 0755)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "undotest:", err)
+			return rc
+		}
+	}
+	for _, name := range failed {
+		rerun(name, recordingName(opts.Dir, name))
+	}
+	return rc
+}
+
+// runChild makes the first run of the tests in a child process, passing
+// through its output, and returns its exit status and the tests which
+// failed.
+func runChild(args []string) (int, []string, error) {
+	cmd := exec.Command(os.Args[0], append(args, "-test.v=true")...)
+	cmd.Env = append(os.Environ(), envChild+"=1")
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	failed, err := failedTests(stdout, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undotest: reading test output:", err)
+	}
+	err = cmd.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), failed, nil
+	}
+	return 0, failed, err
+}
+
+// failedTests copies verbose test output from r to w, returning the
+// top-level tests reported as failing. If a line is too long to scan,
+// the rest of the output is copied without looking for failures, so the
+// child is not left blocked writing it.
+func failedTests(r io.Reader, w io.Writer) ([]string, error) {
+	var failed []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(w, line)
+		if strings.HasPrefix(line, "--- FAIL: ") {
+			name := strings.Fields(strings.TrimPrefix(line, "--- FAIL: "))
+			if len(name) > 0 {
+				failed = append(failed, name[0])
+			}
+		}
+	}
+	err := scanner.Err()
+	if err != nil {
+		io.Copy(w, r)
+	}
+	return failed, err
+}
+
+// recordingName returns the path in dir of the recording of a re-run of
+// the named test.
+func recordingName(dir, name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == filepath.Separator || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	return filepath.Join(dir, name+".undo")
+}
+
+// rerunArgs returns the arguments to re-run only the named test.
+func rerunArgs(args []string, name string) []string {
+	rerun := make([]string, 0, len(args)+2)
+	for _, arg := range args {
+		if !isFlag(arg, "test.run") && !isFlag(arg, "test.count") {
+			rerun = append(rerun, arg)
+		}
+	}
+	return append(rerun, "-test.run=^"+regexp.QuoteMeta(name)+"$", "-test.count=1")
+}
+
+// isFlag reports whether arg sets the named flag with its value attached.
+// Flags whose value is a separate argument are not removed, but are
+// overridden by the flags added later.
+func isFlag(arg, name string) bool {
+	arg = strings.TrimLeft(arg, "-")
+	return arg == name || strings.HasPrefix(arg, name+"=")
+}
+
+// rerun runs the named test alone under recording, saving the recording
+// to filename if it fails again. Any recording left at filename by an
+// earlier run is removed first.
+func rerun(name, filename string) {
+	fmt.Fprintf(os.Stderr, "undotest: re-running %s under recording\n", name)
+	err := os.Remove(filename)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "undotest: cannot re-run %s: %v\n", name, err)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], rerunArgs(os.Args[1:], name)...)
+	cmd.Env = append(os.Environ(), envRecord+"="+filename)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+
+	if err == nil {
+		fmt.Fprintf(os.Stderr, "undotest: %s passed when re-run, it may be flaky\n", name)
+	} else if _, ok := err.(*exec.ExitError); !ok {
+		fmt.Fprintf(os.Stderr, "undotest: cannot re-run %s: %v\n", name, err)
+	} else if _, saved := os.Stat(filename); saved == nil {
+		fmt.Fprintf(os.Stderr, "undotest: %s failed again, recording saved to %s\n", name, filename)
+	} else {
+		fmt.Fprintf(os.Stderr, "undotest: %s failed again but was not recorded: %v\n", name, err)
+	}
+}
+
+// runRecorded runs the tests under recording, saving the recording to
+// filename if they fail.
+func runRecorded(m *testing.M, filename string) int {
+	err := undolr.Start()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undotest: cannot record:", err)
+		return m.Run()
+	}
+
+	// Tests which crash the process are saved on termination.
+	err = undolr.SaveOnTermination(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "undotest: cannot save on termination:", err)
+	}
+
+	rc := m.Run()
+	undolr.SaveOnTerminationCancel()
+	if rc != 0 {
+		err = undolr.Save(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "undotest: saving recording:", err)
+		}
+	}
+	undolr.StopAndDiscard()
+	return rc
+}
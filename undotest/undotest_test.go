@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+package undotest
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+func TestFailedTests(t *testing.T) {
+	output := `=== RUN   TestPass
+--- PASS: TestPass (0.00s)
+=== RUN   TestFail
+    x_test.go:10: broken
+--- FAIL: TestFail (0.01s)
+=== RUN   TestParent
+=== RUN   TestParent/child
+    --- FAIL: TestParent/child (0.00s)
+--- FAIL: TestParent (0.00s)
+FAIL
+`
+	var copied bytes.Buffer
+	failed, err := failedTests(strings.NewReader(output), &copied)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if expected := []string{"TestFail", "TestParent"}; !reflect.DeepEqual(failed, expected) {
+		t.Errorf("Failed tests %q, expected %q", failed, expected)
+	}
+	if copied.String() != output {
+		t.Errorf("Output not copied:\n%s", copied.String())
+	}
+}
+
+func TestFailedTestsLongLine(t *testing.T) {
+	output := "--- FAIL: TestBefore (0.00s)\n" + strings.Repeat("x", 2<<20) + "\n--- FAIL: TestAfter (0.00s)\n"
+	r := strings.NewReader(output)
+	var copied bytes.Buffer
+	failed, err := failedTests(r, &copied)
+	if err != bufio.ErrTooLong {
+		t.Errorf("Error %v, expected %v", err, bufio.ErrTooLong)
+	}
+	if expected := []string{"TestBefore"}; !reflect.DeepEqual(failed, expected) {
+		t.Errorf("Failed tests %q, expected %q", failed, expected)
+	}
+	if r.Len() != 0 {
+		t.Errorf("%d bytes of output not read", r.Len())
+	}
+	if !strings.HasSuffix(copied.String(), "--- FAIL: TestAfter (0.00s)\n") {
+		t.Error("Output after long line not copied")
+	}
+}
+
+func TestRerunArgs(t *testing.T) {
+	args := rerunArgs([]string{"-test.run=Foo", "-test.timeout=10m", "--test.count=3", "-test.v=true"}, "TestFoo.Bar")
+	expected := []string{"-test.timeout=10m", "-test.v=true", `-test.run=^TestFoo\.Bar$`, "-test.count=1"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Arguments %q, expected %q", args, expected)
+	}
+}
+
+func TestRecordingName(t *testing.T) {
+	if name := recordingName("/rec", "TestA/b c"); name != "/rec/TestA_b_c.undo" {
+		t.Errorf("Unexpected recording name %q", name)
+	}
+}
+
+// TestFailing fails when run by TestRerun.
+func TestFailing(t *testing.T) {
+	if os.Getenv("UNDOTEST_TEST_FAIL") == "" {
+		t.Skip("only run by TestRerun")
+	}
+	t.Fatal("failing as requested")
+}
+
+func TestRerun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undotest")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFailing$")
+	cmd.Env = append(os.Environ(), EnvRecordFailures+"="+dir, "UNDOTEST_TEST_FAIL=1")
+	output, err := cmd.CombinedOutput()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("Expected failing tests to fail: %v\n%s", err, output)
+	}
+
+	for _, expected := range []string{
+		"--- FAIL: TestFailing",
+		"undotest: re-running TestFailing under recording",
+		"undotest: TestFailing failed again",
+	} {
+		if !strings.Contains(string(output), expected) {
+			t.Errorf("Output does not contain %q:\n%s", expected, output)
+		}
+	}
+}
+
+// TestFlaky fails when run by TestRerunFlaky, but passes when re-run.
+func TestFlaky(t *testing.T) {
+	if os.Getenv("UNDOTEST_TEST_FLAKY") == "" {
+		t.Skip("only run by TestRerunFlaky")
+	}
+	if os.Getenv(envRecord) == "" {
+		t.Fatal("failing on the first run")
+	}
+}
+
+func TestRerunFlaky(t *testing.T) {
+	dir, err := ioutil.TempDir("", "undotest")
+	if err != nil {
+		t.Fatal("TempDir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A recording left by an earlier run must not be reported as new.
+	stale := recordingName(dir, "TestFlaky")
+	err = ioutil.WriteFile(stale, []byte("stale"), 0644)
+	if err != nil {
+		t.Fatal("WriteFile:", err)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFlaky$")
+	cmd.Env = append(os.Environ(), EnvRecordFailures+"="+dir, "UNDOTEST_TEST_FLAKY=1")
+	output, err := cmd.CombinedOutput()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("Expected failing tests to fail: %v\n%s", err, output)
+	}
+
+	if expected := "undotest: TestFlaky passed when re-run"; !strings.Contains(string(output), expected) {
+		t.Errorf("Output does not contain %q:\n%s", expected, output)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Stale recording not removed: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Command undovet reports misuse of the Undo Live Recorder bindings.
+//
+// It may be run directly on packages:
+//
+//	undovet ./...
+//
+// or through go vet:
+//
+//	go vet -vettool=$(which undovet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"go.undo.io/bindings/undovet"
+)
+
+func main() {
+	singlechecker.Main(undovet.Analyzer)
+}
@@ -0,0 +1,55 @@
+package a
+
+import (
+	"go.undo.io/bindings/undoex"
+	"go.undo.io/bindings/undolr"
+)
+
+func leaked() {
+	context, err := undolr.Stop() // want `RecordingContext returned by undolr.Stop is never discarded`
+	if err != nil {
+		return
+	}
+	context.SaveAsync("recording.undo")
+}
+
+func discarded() {
+	context, _ := undolr.Stop()
+	defer context.Discard()
+	context.SaveAsync("recording.undo")
+}
+
+func returned() (*undolr.RecordingContext, error) {
+	context, err := undolr.Stop()
+	return context, err
+}
+
+func passed(ch chan *undolr.RecordingContext) {
+	context, _ := undolr.Stop()
+	ch <- context
+}
+
+func saveAfterStop() {
+	undolr.StopAndDiscard()
+	undolr.Save("recording.undo") // want `undolr.Save called after recording was stopped`
+	undolr.Start()
+	undolr.Save("recording.undo")
+}
+
+func annotations(name string) {
+	undoex.AnnotationAddInt("u-reserved", "", 1) // want `annotation names starting with "u-" are reserved`
+	undoex.AnnotationAddInt("mine", "", 1)
+	undoex.AnnotationAddInt(name, "", 1)
+	undoex.AnnotationAddText("mine", "", 7, "text") // want `AnnotationAddText called with invalid content type 7`
+	undoex.AnnotationAddText("mine", "", undoex.JSON, "{}")
+
+	context, _ := undoex.AnnotationTestNew("u-test")       // want `annotation names starting with "u-" are reserved`
+	context.SetOutput(undoex.AnnotationContentType(0), "") // want `SetOutput called with invalid content type 0`
+	context.SetOutput(undoex.XML, "<a/>")
+	context.AddText("detail", 7, "text") // want `AddText called with invalid content type 7`
+	context.AddText("detail", undoex.JSON, "{}")
+
+	var annotator undoex.Annotator
+	annotator.AddText("mine", "", 7, "text") // want `AddText called with invalid content type 7`
+	annotator.AddText("mine", "", undoex.UnstructuredText, "text")
+}
@@ -0,0 +1,32 @@
+// Package undoex is a stub of the real package for testing the analyzer.
+package undoex
+
+type AnnotationContentType int
+
+const (
+	UnstructuredText AnnotationContentType = 100 + iota
+	JSON
+	XML
+)
+
+type AnnotationTestContext struct{}
+
+func AnnotationAddText(name, detail string, contentType AnnotationContentType, text string) error {
+	return nil
+}
+func AnnotationAddInt(name, detail string, value int64) error { return nil }
+func AnnotationTestNew(name string) (*AnnotationTestContext, error) {
+	return nil, nil
+}
+func (context *AnnotationTestContext) SetOutput(contentType AnnotationContentType, output string) error {
+	return nil
+}
+func (context *AnnotationTestContext) AddText(detail string, contentType AnnotationContentType, text string) error {
+	return nil
+}
+
+type Annotator struct{}
+
+func (a *Annotator) AddText(name, detail string, contentType AnnotationContentType, text string) error {
+	return nil
+}
@@ -0,0 +1,13 @@
+// Package undolr is a stub of the real package for testing the analyzer.
+package undolr
+
+type RecordingContext struct{}
+
+func Start() error                               { return nil }
+func Stop() (*RecordingContext, error)           { return nil, nil }
+func StopAndDiscard() error                      { return nil }
+func Save(filename string) error                 { return nil }
+func (context *RecordingContext) Discard() error { return nil }
+func (context *RecordingContext) SaveAsync(filename string) error {
+	return nil
+}
@@ -0,0 +1,311 @@
+/*
+Copyright (c) 2026, Undo Ltd.
+All rights reserved.
+
+SPDX-License-Identifier: BSD-3-Clause
+*/
+
+// Package undovet provides an analyzer reporting common misuses of the undolr and undoex packages.
+//
+// The following are reported:
+//   - a RecordingContext returned by undolr.Stop which is never discarded
+//     and does not otherwise leave the function;
+//   - undolr.Save (or a variant) called after undolr.Stop in the same
+//     block without recording being restarted;
+//   - annotation names starting with "u-", which are reserved;
+//   - constant annotation content types which are not one of JSON, XML or
+//     UnstructuredText.
+//
+// These are caught at runtime only by errors, or in the case of leaked
+// recording contexts a panic from the finalizer. The checks are
+// deliberately simple: they consider each function on its own and do not
+// follow control flow.
+package undovet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const (
+	undolrPath = "go.undo.io/bindings/undolr"
+	undoexPath = "go.undo.io/bindings/undoex"
+)
+
+// Analyzer reports misuses of the undolr and undoex packages.
+var Analyzer = &analysis.Analyzer{
+	Name:     "undovet",
+	Doc:      "report misuse of the Undo Live Recorder bindings",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// nameArgs maps undoex functions and methods, by types.Func.FullName, to
+// the index of their annotation name argument.
+var nameArgs = map[string]int{
+	undoexPath + ".AnnotationAddRawData": 0,
+	undoexPath + ".AnnotationAddText":    0,
+	undoexPath + ".AnnotationAddInt":     0,
+	undoexPath + ".AnnotationAddReader":  0,
+	undoexPath + ".AnnotationTestNew":    0,
+	undoexPath + ".AnnotationSuiteNew":   0,
+}
+
+// contentTypeArgs maps undoex functions and methods, by
+// types.Func.FullName, to the index of their content type argument.
+var contentTypeArgs = map[string]int{
+	undoexPath + ".AnnotationAddText":                          2,
+	undoexPath + ".AnnotationAddReader":                        2,
+	"(*" + undoexPath + ".AnnotationTestContext).SetOutput":    0,
+	"(*" + undoexPath + ".AnnotationTestContext).AddText":      1,
+	"(*" + undoexPath + ".AnnotationTestContext).OutputWriter": 1,
+	"(*" + undoexPath + ".Annotator).AddText":                  2,
+	"(*" + undoexPath + ".Annotator).AddReader":                2,
+	"(*" + undoexPath + ".Schema).AddText":                     2,
+}
+
+// Values of undoex.AnnotationContentType.
+var validContentTypes = map[int64]bool{
+	100: true, // UnstructuredText
+	101: true, // JSON
+	102: true, // XML
+}
+
+// saveFuncs are the undolr functions which save while recording.
+var saveFuncs = map[string]bool{
+	"Save":          true,
+	"SaveWithStats": true,
+	"SaveContext":   true,
+}
+
+// startFuncs are the undolr functions which start recording.
+var startFuncs = map[string]bool{
+	"Start":            true,
+	"StartWithOptions": true,
+	"StartOrDegrade":   true,
+}
+
+// stopFuncs are the undolr functions which stop recording.
+var stopFuncs = map[string]bool{
+	"Stop":           true,
+	"StopAndDiscard": true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+		(*ast.BlockStmt)(nil),
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			checkCall(pass, n)
+		case *ast.BlockStmt:
+			checkSaveAfterStop(pass, n)
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				checkDiscard(pass, n.Body)
+			}
+		case *ast.FuncLit:
+			checkDiscard(pass, n.Body)
+		}
+	})
+	return nil, nil
+}
+
+// calleeFunc returns the function or method called, if any.
+func calleeFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return nil
+	}
+	return fn
+}
+
+// callee returns the package path and name of the function or method
+// called, if any.
+func callee(pass *analysis.Pass, call *ast.CallExpr) (string, string) {
+	fn := calleeFunc(pass, call)
+	if fn == nil {
+		return "", ""
+	}
+	return fn.Pkg().Path(), fn.Name()
+}
+
+func checkCall(pass *analysis.Pass, call *ast.CallExpr) {
+	fn := calleeFunc(pass, call)
+	if fn == nil || fn.Pkg().Path() != undoexPath {
+		return
+	}
+	name, fullName := fn.Name(), fn.FullName()
+
+	if i, ok := nameArgs[fullName]; ok && i < len(call.Args) {
+		value := pass.TypesInfo.Types[call.Args[i]].Value
+		if value != nil && value.Kind() == constant.String &&
+			strings.HasPrefix(constant.StringVal(value), "u-") {
+			pass.Reportf(call.Args[i].Pos(),
+				"annotation names starting with \"u-\" are reserved for internal use")
+		}
+	}
+
+	if i, ok := contentTypeArgs[fullName]; ok && i < len(call.Args) {
+		value := pass.TypesInfo.Types[call.Args[i]].Value
+		if value != nil && value.Kind() == constant.Int {
+			v, exact := constant.Int64Val(value)
+			if !exact || !validContentTypes[v] {
+				pass.Reportf(call.Args[i].Pos(),
+					"%s called with invalid content type %s; use JSON, XML or UnstructuredText",
+					name, value)
+			}
+		}
+	}
+}
+
+// checkSaveAfterStop reports saves following a stop in the same block
+// with no intervening start.
+func checkSaveAfterStop(pass *analysis.Pass, block *ast.BlockStmt) {
+	stopped := false
+	for _, stmt := range block.List {
+		if _, ok := stmt.(*ast.DeferStmt); ok {
+			continue
+		}
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit, *ast.BlockStmt:
+				// Checked separately.
+				return false
+			case *ast.CallExpr:
+				path, name := callee(pass, n)
+				if path != undolrPath {
+					return true
+				}
+				switch {
+				case stopFuncs[name]:
+					stopped = true
+				case startFuncs[name]:
+					stopped = false
+				case saveFuncs[name] && stopped:
+					pass.Reportf(n.Pos(),
+						"undolr.%s called after recording was stopped; use SaveAsync on the RecordingContext returned by Stop",
+						name)
+				}
+			}
+			return true
+		})
+	}
+}
+
+// checkDiscard reports recording contexts from undolr.Stop which are
+// neither discarded nor leave the function body.
+func checkDiscard(pass *analysis.Pass, body *ast.BlockStmt) {
+	contexts := make(map[*types.Var]token.Pos)
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			// Checked separately, but uses of our variables count.
+			return true
+		}
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Rhs) != 1 || len(assign.Lhs) == 0 {
+			return true
+		}
+		call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if path, name := callee(pass, call); path != undolrPath || name != "Stop" {
+			return true
+		}
+		ident, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if v, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var); ok {
+			if _, seen := contexts[v]; !seen {
+				contexts[v] = ident.Pos()
+			}
+		}
+		return true
+	})
+
+	for v, pos := range contexts {
+		if !handled(pass, body, v) {
+			pass.Reportf(pos,
+				"RecordingContext returned by undolr.Stop is never discarded; call Discard when done with it")
+		}
+	}
+}
+
+// handled reports whether v is discarded, or escapes from body so may be
+// discarded elsewhere.
+func handled(pass *analysis.Pass, body *ast.BlockStmt, v *types.Var) bool {
+	isV := func(e ast.Expr) bool {
+		ident, ok := ast.Unparen(e).(*ast.Ident)
+		return ok && pass.TypesInfo.Uses[ident] == v
+	}
+
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && isV(sel.X) && sel.Sel.Name == "Discard" {
+				found = true
+			}
+			for _, arg := range n.Args {
+				if isV(arg) {
+					found = true
+				}
+			}
+		case *ast.ReturnStmt:
+			for _, result := range n.Results {
+				if isV(result) {
+					found = true
+				}
+			}
+		case *ast.AssignStmt:
+			for _, rhs := range n.Rhs {
+				if isV(rhs) {
+					found = true
+				}
+			}
+		case *ast.CompositeLit:
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					elt = kv.Value
+				}
+				if isV(elt) {
+					found = true
+				}
+			}
+		case *ast.SendStmt:
+			if isV(n.Value) {
+				found = true
+			}
+		}
+		return true
+	})
+	return found
+}